@@ -0,0 +1,493 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/reviews"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Actions that can be chosen for each owned resource when deleting an account.
+const (
+	DispositionTransfer = "transfer"
+	DispositionDelete   = "delete"
+)
+
+// ResourceDisposition is what to do with a resource owned by a user whose
+// account is being deleted.
+type ResourceDisposition struct {
+	// The resource type: model, world or collection
+	// required: true
+	Type string `json:"type" validate:"required,oneof=model world collection"`
+	// The name of the resource
+	// required: true
+	Name string `json:"name" validate:"required"`
+	// Either transfer or delete
+	// required: true
+	Action string `json:"action" validate:"required,oneof=transfer delete"`
+	// The user or organization that will own the resource, when transferred
+	DestOwner string `json:"destOwner"`
+}
+
+// AccountDeletion encapsulates the data required to delete a user account.
+// It must include a disposition for each resource owned by the user.
+type AccountDeletion struct {
+	Resources []ResourceDisposition `json:"resources" validate:"dive"`
+}
+
+// AccountDeletionResponse is returned once an account was deleted.
+//
+// swagger:model
+type AccountDeletionResponse struct {
+	users.UserResponse
+	// The resources moved to an organization, as type/name
+	Transferred []string `json:"transferred"`
+	// The resources removed, as type/name
+	Deleted []string `json:"deleted"`
+	// The transfer requests sent to other users. They can still be accepted
+	// once the account is deleted.
+	TransferRequests commonres.TransferRequestResponses `json:"transfer_requests"`
+}
+
+// exportLike is a like given by a user, as included in the data export.
+type exportLike struct {
+	Owner   string    `json:"owner"`
+	Name    string    `json:"name"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+// checkAccountOwner returns an error if the JWT user is not the given user, or
+// if it was authenticated with a scoped access token.
+func checkAccountOwner(tx *gorm.DB, username string, jwtUser *users.User) (*users.User, *gz.ErrMsg) {
+	user, em := users.ByUsername(tx, username, false)
+	if em != nil {
+		return nil, em
+	}
+	if *user.Identity != *jwtUser.Identity || len(jwtUser.TokenScopes) > 0 {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	return user, nil
+}
+
+// ownedResources returns the models, worlds and collections owned by a user,
+// indexed by type/name.
+func ownedResources(tx *gorm.DB, owner string) (map[string]commonres.Resource, *gz.ErrMsg) {
+	var ms models.Models
+	var ws worlds.Worlds
+	var cs collections.Collections
+	for _, list := range []interface{}{&ms, &ws, &cs} {
+		if err := tx.Where("owner = ?", owner).Find(list).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+		}
+	}
+	resources := make(map[string]commonres.Resource)
+	for i := range ms {
+		resources["model/"+*ms[i].Name] = &ms[i]
+	}
+	for i := range ws {
+		resources["world/"+*ws[i].Name] = &ws[i]
+	}
+	for i := range cs {
+		resources["collection/"+*cs[i].Name] = &cs[i]
+	}
+	return resources, nil
+}
+
+// userLikes returns the non deleted models or worlds liked by a user.
+func userLikes(tx *gorm.DB, user *users.User, table, likesTable, column string) ([]exportLike, *gz.ErrMsg) {
+	likes := []exportLike{}
+	err := tx.Table(likesTable).
+		Select(fmt.Sprintf("%s.owner, %s.name, %s.created_at AS liked_at", table, table, likesTable)).
+		Joins(fmt.Sprintf("JOIN %s ON %s.id = %s.%s", table, table, likesTable, column)).
+		Where(fmt.Sprintf("%s.user_id = ? AND %s.deleted_at IS NULL", likesTable, table), user.ID).
+		Scan(&likes).Error
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return likes, nil
+}
+
+// addJSONToZip adds a file with the JSON encoding of v to a zip archive.
+func addJSONToZip(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// addResourceFilesToZip adds the zip file with the latest version of a model or
+// world to a zip archive.
+func addResourceFilesToZip(ctx context.Context, zw *zip.Writer, name string,
+	res commonres.Resource, subfolder string) error {
+
+	version, err := commonres.GetLatestVersion(ctx, res)
+	if err != nil {
+		return err
+	}
+	path, _, em := commonres.GetZip(ctx, res, subfolder, strconv.Itoa(version))
+	if em != nil {
+		return em.BaseError
+	}
+	src, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// writeUserExport writes a zip archive with the data of a user to w: the
+// profile, organizations, likes, reviews, access tokens metadata and all the
+// owned resources, including the files of the latest version of the models and
+// worlds.
+func writeUserExport(ctx context.Context, tx *gorm.DB, user *users.User, w io.Writer) *gz.ErrMsg {
+	profile := users.CreateUserResponse(tx, user, user)
+	modelLikes, em := userLikes(tx, user, "models", "model_likes", "model_id")
+	if em != nil {
+		return em
+	}
+	worldLikes, em := userLikes(tx, user, "worlds", "world_likes", "world_id")
+	if em != nil {
+		return em
+	}
+	var modelReviews reviews.ModelReviews
+	if err := tx.Where("creator = ?", *user.Username).Find(&modelReviews).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	tokens, em := users.UserAccessTokens(tx, user)
+	if em != nil {
+		return em
+	}
+	resources, em := ownedResources(tx, *user.Username)
+	if em != nil {
+		return em
+	}
+
+	zw := zip.NewWriter(w)
+	files := map[string]interface{}{
+		"profile.json":       profile,
+		"organizations.json": globals.Permissions.GetGroupsAndRolesForUser(*user.Username),
+		"likes.json": map[string][]exportLike{
+			"models": modelLikes,
+			"worlds": worldLikes,
+		},
+		"reviews.json":       modelReviews,
+		"access_tokens.json": tokens,
+	}
+	for key, res := range resources {
+		files[key+"/metadata.json"] = res
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := addJSONToZip(zw, name, files[name]); err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+
+	for key, res := range resources {
+		var subfolder string
+		switch res.(type) {
+		case *models.Model:
+			subfolder = "models"
+		case *worlds.World:
+			subfolder = "worlds"
+		default:
+			continue
+		}
+		if err := addResourceFilesToZip(ctx, zw, key+"/files.zip", res, subfolder); err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorZipNotAvailable, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	return nil
+}
+
+// UserExport returns a zip archive with all the data of a user: the profile,
+// organizations, likes, reviews, access tokens metadata and all the owned
+// models, worlds and collections. Only the user can export its data.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/users/{username}/export
+//	  --header 'authorization: Bearer <A_VALID_AUTH0_JWT_TOKEN>' -o export.zip
+func UserExport(username string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	user, em := checkAccountOwner(tx, username, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+
+	// The archive is written to a temporary file first, to be able to report
+	// errors before writing data to the ResponseWriter.
+	f, err := ioutil.TempFile("", "fuel-export-*.zip")
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if em := writeUserExport(r.Context(), tx, user, f); em != nil {
+		return nil, em
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"fuel-export-%s.zip\"", username))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, f); err != nil {
+		gz.LoggerFromRequest(r).Error("Unable to write user export: ", err)
+	}
+	return nil, nil
+}
+
+// checkDispositions returns an error unless there is exactly one disposition
+// for each of the given owned resources.
+func checkDispositions(dispositions []ResourceDisposition,
+	resources map[string]commonres.Resource) *gz.ErrMsg {
+
+	seen := make(map[string]bool)
+	for _, d := range dispositions {
+		key := d.Type + "/" + d.Name
+		if _, ok := resources[key]; !ok {
+			extra := fmt.Sprintf("Resource [%s] not found", key)
+			return gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, nil, []string{extra})
+		}
+		if seen[key] {
+			extra := fmt.Sprintf("Resource [%s] listed more than once", key)
+			return gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+		}
+		if d.Action == DispositionTransfer && d.DestOwner == "" {
+			extra := fmt.Sprintf("Missing destOwner for [%s]", key)
+			return gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+		}
+		seen[key] = true
+	}
+	var missing []string
+	for key := range resources {
+		if !seen[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		extra := "Choose to transfer or delete each owned resource. Missing: " +
+			strings.Join(missing, ", ")
+		return gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+	}
+	return nil
+}
+
+// removeOwnedResource removes a model, world or collection of a user whose
+// account is being deleted.
+func removeOwnedResource(ctx context.Context, tx *gorm.DB, res commonres.Resource,
+	user *users.User) *gz.ErrMsg {
+
+	owner, name := *res.GetOwner(), *res.GetName()
+	switch v := res.(type) {
+	case *models.Model:
+		if em := (&models.Service{Storage: globals.Storage}).RemoveModel(ctx, tx, owner, name, user); em != nil {
+			return em
+		}
+		if err := (&collections.Service{}).RemoveAssetFromAllCollections(tx, v.ID); err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	case *worlds.World:
+		if em := (&worlds.Service{Storage: globals.Storage}).RemoveWorld(ctx, tx, owner, name, user); em != nil {
+			return em
+		}
+		if err := (&collections.Service{}).RemoveAssetFromAllCollections(tx, v.ID); err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	case *collections.Collection:
+		return (&collections.Service{}).RemoveCollection(tx, owner, name, user)
+	}
+	return nil
+}
+
+// handOffResource transfers a resource of a user whose account is being
+// deleted. Resources given to an organization are moved right away, and the
+// user must be able to write the organization resources. Resources given to another
+// user are proposed with a transfer request that the user must accept.
+// Returns the moved resource or the created transfer request.
+func handOffResource(tx *gorm.DB, d ResourceDisposition, res commonres.Resource,
+	user *users.User) (*transferredResource, *commonres.TransferRequest, *gz.ErrMsg) {
+
+	source := *user.Username
+	if d.DestOwner == source {
+		return nil, nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+			[]string{"Source and destination owners are identical"})
+	}
+	if _, em := users.OwnerByName(tx, d.DestOwner, false); em != nil {
+		return nil, nil, em
+	}
+	if em := checkTransferName(tx, d.Type, d.DestOwner, d.Name); em != nil {
+		return nil, nil, em
+	}
+
+	// Pending transfer requests of the resource are replaced.
+	if err := commonres.QueryForPendingTransfers(tx).Where("resource_uuid = ?", *res.GetUUID()).
+		Update("status", commonres.TransferCancelled).Error; err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	if _, em := users.ByOrganizationName(tx, d.DestOwner, false); em == nil {
+		if ok, em := globals.Permissions.IsAuthorized(source, d.DestOwner, permissions.Write); !ok {
+			return nil, nil, em
+		}
+		moved, em := transferMoveResource(tx, res, source, d.DestOwner)
+		if em != nil {
+			return nil, nil, em
+		}
+		if err := tx.Save(res).Error; err != nil {
+			// Revert move
+			moved.revert()
+			return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		return moved, nil, nil
+	}
+
+	tr := commonres.TransferRequest{
+		ResourceType: d.Type,
+		ResourceUUID: *res.GetUUID(),
+		ResourceName: d.Name,
+		SourceOwner:  source,
+		DestOwner:    d.DestOwner,
+		Requestor:    source,
+		Status:       commonres.TransferPending,
+		ExpiresAt:    time.Now().Add(commonres.DefaultTransferExpiryDays * 24 * time.Hour),
+	}
+	if err := tx.Create(&tr).Error; err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil, &tr, nil
+}
+
+// UserAccountDelete deletes a user account, once the user chose what to do
+// with each owned resource: transfer it to an organization or another user, or
+// delete it. The access tokens of the user, and its permissions, organization
+// and team memberships are removed as well.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/users/{username}/account-deletion
+//	  --header 'authorization: Bearer <A_VALID_AUTH0_JWT_TOKEN>'
+//	  -d '{"resources":[{"type":"model","name":"box","action":"transfer","destOwner":"my_org"}]}'
+func UserAccountDelete(username string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	user, em := checkAccountOwner(tx, username, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+
+	var ad AccountDeletion
+	if em := ParseStruct(&ad, r, false); em != nil {
+		return nil, em
+	}
+	resources, em := ownedResources(tx, username)
+	if em != nil {
+		return nil, em
+	}
+	if em := checkDispositions(ad.Resources, resources); em != nil {
+		return nil, em
+	}
+
+	response := AccountDeletionResponse{
+		Transferred:      []string{},
+		Deleted:          []string{},
+		TransferRequests: commonres.TransferRequestResponses{},
+	}
+	var moved []*transferredResource
+	var requests []*commonres.TransferRequest
+	// The moved resources are moved back if the deletion fails.
+	committed := false
+	defer func() {
+		if !committed {
+			revertTransfers(r, moved...)
+		}
+	}()
+	for _, d := range ad.Resources {
+		key := d.Type + "/" + d.Name
+		res := resources[key]
+		if d.Action == DispositionDelete {
+			if em := removeOwnedResource(r.Context(), tx, res, user); em != nil {
+				return nil, em
+			}
+			response.Deleted = append(response.Deleted, key)
+			continue
+		}
+		m, tr, em := handOffResource(tx, d, res, user)
+		if em != nil {
+			return nil, em
+		}
+		if tr != nil {
+			requests = append(requests, tr)
+			response.TransferRequests = append(response.TransferRequests,
+				commonres.NewTransferRequestResponse(tr))
+		} else {
+			moved = append(moved, m)
+			response.Transferred = append(response.Transferred, key)
+		}
+	}
+
+	// Pending transfers to the user can no longer be accepted.
+	if err := commonres.QueryForPendingTransfers(tx).Where("dest_owner = ?", username).
+		Update("status", commonres.TransferCancelled).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	ur, em := users.RemoveUser(r.Context(), tx, username, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+	response.UserResponse = *ur
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	committed = true
+
+	for _, tr := range requests {
+		notifyTransfer(tr, username, r)
+	}
+	return &response, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gazebo-web/fuel-server/bundles/activity"
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// recordActivity adds an activity to the activity stream, unless any of the
+// involved resources is private. The stream is visible to any follower.
+func recordActivity(tx *gorm.DB, a *activity.Activity, private ...*bool) *gz.ErrMsg {
+	for _, p := range private {
+		if p != nil && *p {
+			return nil
+		}
+	}
+	return activity.Record(tx, a)
+}
+
+// recordVersionActivity records that a new version of a model or world was
+// uploaded.
+func recordVersionActivity(tx *gorm.DB, actor, resType string, res commonres.Resource,
+	version int, private *bool) *gz.ErrMsg {
+
+	return recordActivity(tx, &activity.Activity{
+		Actor:        actor,
+		Verb:         activity.VerbVersion,
+		ObjectType:   resType,
+		Owner:        *res.GetOwner(),
+		Name:         *res.GetName(),
+		Version:      &version,
+		ResourceUUID: *res.GetUUID(),
+	}, private)
+}
+
+// collectionAsset returns the UUID and the privacy setting of a model or world
+// added to, or removed from, a collection.
+func collectionAsset(tx *gorm.DB, assetType string, no collections.NameOwnerPair) (string, *bool) {
+	var asset struct {
+		UUID    string
+		Private *bool
+	}
+	var table interface{} = &models.Model{}
+	if assetType == collections.TWorld {
+		table = &worlds.World{}
+	}
+	tx.Model(table).Select("uuid, private").Where("owner = ? AND name = ?", no.Owner, no.Name).
+		Scan(&asset)
+	return asset.UUID, asset.Private
+}
+
+// collectionAssetActivity records that a model or world was added to, or
+// removed from, a collection.
+func collectionAssetActivity(tx *gorm.DB, verb, assetType string, actor *users.User,
+	col *collections.Collection, no collections.NameOwnerPair) *gz.ErrMsg {
+
+	assetUUID, assetPrivate := collectionAsset(tx, assetType, no)
+	return recordActivity(tx, &activity.Activity{
+		Actor:        *actor.Username,
+		Verb:         verb,
+		ObjectType:   "collection",
+		Owner:        *col.Owner,
+		Name:         *col.Name,
+		ResourceUUID: *col.UUID,
+		AssetType:    assetType,
+		AssetOwner:   no.Owner,
+		AssetName:    no.Name,
+		AssetUUID:    assetUUID,
+	}, col.Private, assetPrivate)
+}
+
+// visibleResources returns a function that builds subqueries selecting the
+// models, worlds and collections visible to the given user in lists whose UUID
+// is in the given column: public or readable resources, not hidden by
+// moderators and not owned by suspended users. The subqueries are correlated
+// with the column, so each resource is looked up by its indexed UUID.
+func visibleResources(tx *gorm.DB, user *users.User) func(column string) []interface{} {
+	return func(column string) []interface{} {
+		tables := []interface{}{&models.Model{}, &worlds.World{}, &collections.Collection{}}
+		var visible []interface{}
+		for _, table := range tables {
+			uuid := tx.NewScope(table).TableName() + ".uuid = " + column
+			q := commonres.QueryForResourceVisibility(tx, tx.Model(table).Select("1").Where(uuid), nil, user)
+			visible = append(visible, commonres.QueryForHidden(q, user).QueryExpr())
+		}
+		return visible
+	}
+}
+
+// FollowingList returns a paginated list of the users and organizations
+// followed by a user.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/users/{username}/following
+func FollowingList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	follower, em := users.ByUsername(tx, mux.Vars(r)["username"], false)
+	if em != nil {
+		return nil, nil, em
+	}
+	return activity.Following(p, tx, *follower.Username)
+}
+
+// FollowCreate makes a user follow another user or organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/users/{username}/following
+//	  --header 'authorization: Bearer <your-jwt-token-here>' -d '{"owner":"my_org"}'
+func FollowCreate(username string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if username != *user.Username {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	var cf activity.CreateFollow
+	if em := ParseStruct(&cf, r, false); em != nil {
+		return nil, em
+	}
+	if cf.Owner == username {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+			[]string{"Users cannot follow themselves"})
+	}
+	if _, em := users.OwnerByName(tx, cf.Owner, false); em != nil {
+		return nil, em
+	}
+
+	follow, em := activity.AddFollow(tx, username, cf.Owner)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return follow, nil
+}
+
+// FollowRemove makes a user stop following another user or organization.
+// Returns the removed follow.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/users/{username}/following/{owner}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func FollowRemove(username string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if username != *user.Username {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	follow, em := activity.RemoveFollow(tx, username, mux.Vars(r)["owner"])
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return follow, nil
+}
+
+// UserFeed returns a paginated list of the activities of the users and
+// organizations followed by a user, newest first. Users can only see their
+// own feed. Activities on resources that are no longer visible to the user,
+// because they were made private, hidden, removed or their owner was
+// suspended, are left out.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/users/{username}/feed
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func UserFeed(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	if mux.Vars(r)["username"] != *user.Username {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	return activity.Feed(p, tx, *user.Username, visibleResources(tx, user))
+}
@@ -38,6 +38,9 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/gazebo-web/fuel-server/bundles/audit"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/moderation"
 	"github.com/gazebo-web/fuel-server/bundles/subt"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/fuel-server/migrate"
@@ -46,6 +49,7 @@ import (
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/gazebo-web/gz-go/v7/storage"
 	"github.com/go-playground/form"
+	"github.com/jinzhu/gorm"
 	"github.com/johannesboyne/gofakes3"
 	"github.com/johannesboyne/gofakes3/backend/s3mem"
 	"gopkg.in/go-playground/validator.v9"
@@ -53,13 +57,34 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // Impl note: we move this as a constant as it is used by tests.
 const sysAdminForTest = "rootfortests"
 
+// jobs tracks the background jobs that must finish their current run before
+// the server exits, and stopJobs asks them to stop.
+var (
+	jobs     sync.WaitGroup
+	stopJobs = func() {}
+)
+
+// startJob runs a background job in its own goroutine, until the given context
+// is done. The job is tracked by jobs.
+func startJob(ctx context.Context, job func(context.Context, *gorm.DB)) {
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		job(ctx, globals.Server.Db)
+	}()
+}
+
 // init initializes the config for the web fuel server.
 //
 // Environment variables:
@@ -69,6 +94,16 @@ const sysAdminForTest = "rootfortests"
 //	IGN_DB_ADDRESS   : Mysql address (host:port)
 //	IGN_DB_NAME      : Mysql database name (such as "fuel")
 //	IGN_FUEL_RESOURCE_DIR : Directory with all resources (models, worlds)
+//	IGN_FUEL_TRASH_RETENTION_DAYS : Days a deleted resource can be restored (default 30)
+//	IGN_FUEL_AUDIT_RETENTION_DAYS : Days audit log entries are kept (default 365)
+//	IGN_FUEL_AUTO_HIDE_REPORTERS : Distinct users that must report a public model
+//	  or world to hide it pending review, or 0 to disable it (default 5)
+//	IGN_FUEL_AUTO_HIDE_WINDOW_HOURS : Hours in which the reports are counted to
+//	  hide a resource (default 24)
+//	IGN_FUEL_TRUSTED_PROXIES : Comma separated IPs or CIDRs of the reverse proxies
+//	  allowed to set the X-Forwarded-For header (default none)
+//	IGN_FUEL_WEBHOOK_ALLOWED_NETWORKS : Comma separated IPs or CIDRs of private
+//	  networks webhooks can be delivered to (default none)
 //	AUTH0_RSA256_PUBLIC_KEY   : Auth0 public RSA 256 key
 func init() {
 	var err error
@@ -122,17 +157,19 @@ func init() {
 		log.Fatal("Failed to initialize web server:", err)
 	}
 	// Create the main Router and set it to the server.
-	// Note: here it is the place to define multiple APIs
+	// Note: here it is the place to define multiple APIs.
+	// The mutating routes are recorded in the audit log. Requests to renamed
+	// models and worlds are redirected before being recorded.
 	s := globals.Server
 	mainRouter := gz.NewRouter()
 	apiPrefix := "/" + globals.APIVersion
 	r := mainRouter.PathPrefix(apiPrefix).Subrouter()
-	s.ConfigureRouterWithRoutes(apiPrefix, r, routes)
+	s.ConfigureRouterWithRoutes(apiPrefix, r, aliasRoutes(auditRoutes(routes)))
 
 	// Now create a sub router for SubT, enabled with /subt/
 	subtPrefix := apiPrefix + "/subt"
 	sub := mainRouter.PathPrefix(subtPrefix).Subrouter()
-	s.ConfigureRouterWithRoutes(subtPrefix, sub, subTRoutes)
+	s.ConfigureRouterWithRoutes(subtPrefix, sub, auditRoutes(subTRoutes))
 
 	// Special swagger.json file server route
 	swaggerRoute := "/" + globals.APIVersion + "/swagger.json"
@@ -148,7 +185,7 @@ func init() {
                   Authorization`)
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 
-		w.Header().Set("Access-Control-Expose-Headers", "Link, X-Total-Count, X-Ign-Resource-Version")
+		w.Header().Set("Access-Control-Expose-Headers", "Link, X-Total-Count, X-Ign-Resource-Version, X-Ign-Resource-Deprecated, X-Ign-Resource-Successor")
 
 		http.ServeFile(w, req, "swagger.json")
 	})
@@ -182,6 +219,50 @@ func init() {
 		}
 	}
 
+	globals.TrashRetention = commonres.DefaultTrashRetentionDays * 24 * time.Hour
+	if value, err := gz.ReadEnvVar("IGN_FUEL_TRASH_RETENTION_DAYS"); err == nil {
+		if days, err := strconv.Atoi(value); err == nil {
+			globals.TrashRetention = time.Duration(days) * 24 * time.Hour
+		}
+	}
+
+	globals.AuditRetention = audit.DefaultRetentionDays * 24 * time.Hour
+	if value, err := gz.ReadEnvVar("IGN_FUEL_AUDIT_RETENTION_DAYS"); err == nil {
+		if days, err := strconv.Atoi(value); err == nil {
+			globals.AuditRetention = time.Duration(days) * 24 * time.Hour
+		}
+	}
+
+	globals.AutoHideReporters = moderation.DefaultAutoHideReporters
+	if value, err := gz.ReadEnvVar("IGN_FUEL_AUTO_HIDE_REPORTERS"); err == nil {
+		reporters, err := strconv.Atoi(value)
+		if err != nil || reporters < 0 {
+			log.Fatal("Invalid IGN_FUEL_AUTO_HIDE_REPORTERS env variable: ", value)
+		}
+		globals.AutoHideReporters = reporters
+	}
+
+	globals.AutoHideWindow = moderation.DefaultAutoHideWindowHours * time.Hour
+	if value, err := gz.ReadEnvVar("IGN_FUEL_AUTO_HIDE_WINDOW_HOURS"); err == nil {
+		hours, err := strconv.Atoi(value)
+		if err != nil || hours <= 0 {
+			log.Fatal("Invalid IGN_FUEL_AUTO_HIDE_WINDOW_HOURS env variable: ", value)
+		}
+		globals.AutoHideWindow = time.Duration(hours) * time.Hour
+	}
+
+	if value, err := gz.ReadEnvVar("IGN_FUEL_TRUSTED_PROXIES"); err == nil {
+		if globals.TrustedProxies, err = parseNetworks(value); err != nil {
+			log.Fatal("Invalid IGN_FUEL_TRUSTED_PROXIES env variable: ", err)
+		}
+	}
+
+	if value, err := gz.ReadEnvVar("IGN_FUEL_WEBHOOK_ALLOWED_NETWORKS"); err == nil {
+		if globals.WebhookAllowedNetworks, err = parseNetworks(value); err != nil {
+			log.Fatal("Invalid IGN_FUEL_WEBHOOK_ALLOWED_NETWORKS env variable: ", err)
+		}
+	}
+
 	// initialize permissions
 	// override sys admin for tests
 	var sysAdmin string
@@ -275,6 +356,22 @@ func init() {
 
 	// Connect to ElasticSearch.
 	_ = connectToElasticSearch(logCtx)
+
+	// Periodically purge the resources that are past the trash retention window,
+	// and the audit entries that are past the audit retention window. Also send
+	// the pending notification emails and webhook deliveries, and stream the
+	// resource events to the /events subscribers, and clear the cached lists
+	// when suspensions expire.
+	if !isGoTest {
+		jobsCtx, cancel := context.WithCancel(logCtx)
+		stopJobs = cancel
+		startJob(jobsCtx, runTrashPurgeJob)
+		startJob(jobsCtx, runAuditPurgeJob)
+		startJob(jobsCtx, runNotificationEmailJob)
+		startJob(jobsCtx, runWebhookDeliveryJob)
+		startJob(jobsCtx, runEventBroker)
+		startJob(jobsCtx, runSuspensionExpiryJob)
+	}
 }
 
 func initValidator() *validator.Validate {
@@ -285,5 +382,14 @@ func initValidator() *validator.Validate {
 
 // main runs the router and server
 func main() {
+	// On shutdown, let the background jobs finish their current run.
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+		stopJobs()
+		jobs.Wait()
+		os.Exit(0)
+	}()
 	globals.Server.Run()
 }
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/audit"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+	"github.com/satori/go.uuid"
+)
+
+// auditPurgeInterval is how often the audit purge job runs.
+const auditPurgeInterval = 24 * time.Hour
+
+// auditMaxBody is the maximum length of the response body read to identify the
+// target of an audited operation.
+const auditMaxBody = 64 * 1024
+
+// auditedMethods are the HTTP methods of the mutating routes. Requests to these
+// methods are recorded in the audit log.
+var auditedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// auditRoutes returns a copy of the given routes, where the handlers of the
+// mutating methods record an entry in the audit log.
+func auditRoutes(routes gz.Routes) gz.Routes {
+	audited := make(gz.Routes, len(routes))
+	for i, route := range routes {
+		route.Methods = auditMethods(route.Name, route.Methods)
+		route.SecureMethods = auditMethods(route.Name, route.SecureMethods)
+		audited[i] = route
+	}
+	return audited
+}
+
+// auditMethods returns a copy of the given methods, where the handlers of the
+// mutating methods are wrapped with auditHandler.
+func auditMethods(action string, methods []gz.Method) []gz.Method {
+	audited := make([]gz.Method, len(methods))
+	for i, m := range methods {
+		if auditedMethods[m.Type] {
+			handlers := make(gz.FormatHandlers, len(m.Handlers))
+			for j, fh := range m.Handlers {
+				fh.Handler = auditHandler(action, fh.Handler)
+				handlers[j] = fh
+			}
+			m.Handlers = handlers
+		}
+		audited[i] = m
+	}
+	return audited
+}
+
+// auditResponseWriter is an http.ResponseWriter that keeps the status code and
+// the beginning of the response body, to identify the target in the audit log.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader keeps the status code and sends it to the wrapped writer.
+func (aw *auditResponseWriter) WriteHeader(code int) {
+	if aw.status == 0 {
+		aw.status = code
+	}
+	aw.ResponseWriter.WriteHeader(code)
+}
+
+// Write keeps the beginning of the response body and sends it to the wrapped
+// writer.
+func (aw *auditResponseWriter) Write(b []byte) (int, error) {
+	if aw.status == 0 {
+		aw.status = http.StatusOK
+	}
+	if remaining := auditMaxBody - aw.body.Len(); remaining > 0 {
+		if len(b) > remaining {
+			aw.body.Write(b[:remaining])
+		} else {
+			aw.body.Write(b)
+		}
+	}
+	return aw.ResponseWriter.Write(b)
+}
+
+// auditHandler wraps a handler to record an entry in the audit log once the
+// request is processed. The entry is available to the wrapped handler through
+// the request context, so it can set the actor and the state of the target
+// before the operation.
+// The request ID is read from the X-Request-ID header, or generated if missing,
+// and returned in the response X-Request-ID header.
+func auditHandler(action string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = uuid.NewV4().String()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		target := strings.TrimPrefix(r.URL.Path, "/"+globals.APIVersion)
+		entry := &audit.Entry{
+			Action:    action,
+			Method:    r.Method,
+			Target:    target,
+			Owner:     auditOwner(target, r),
+			RequestID: requestID,
+			IP:        requestIP(r),
+		}
+
+		aw := &auditResponseWriter{ResponseWriter: w}
+		handler.ServeHTTP(aw, r.WithContext(audit.NewContext(r.Context(), entry)))
+
+		entry.Status = aw.status
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+		if entry.Status < http.StatusMultipleChoices &&
+			strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			entry.After = audit.Identify(aw.body.Bytes())
+		}
+		if em := audit.Record(globals.Server.Db, entry); em != nil {
+			gz.LoggerFromContext(r.Context()).Error("Unable to record audit entry: ", em.LogString())
+		}
+	})
+}
+
+// setAuditBefore records the owner, name and UUID of a resource as the state
+// of the target before the audited operation.
+func setAuditBefore(r *http.Request, res commonres.Resource) {
+	audit.SetBefore(r.Context(), map[string]string{
+		"owner": *res.GetOwner(),
+		"name":  *res.GetName(),
+		"uuid":  *res.GetUUID(),
+	})
+}
+
+// auditOwner returns the user or organization that owns the target of a
+// request, based on the route parameters.
+func auditOwner(target string, r *http.Request) string {
+	params := mux.Vars(r)
+	if strings.HasPrefix(target, "/organizations/") {
+		return params["name"]
+	}
+	return params["username"]
+}
+
+// parseAuditFilter reads the audit log filter from the query parameters:
+// actor, action, owner, target, and since and until as RFC 3339 dates.
+func parseAuditFilter(r *http.Request) (*audit.Filter, *gz.ErrMsg) {
+	query := r.URL.Query()
+	f := audit.Filter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		Owner:  query.Get("owner"),
+		Target: query.Get("target"),
+	}
+	for name, dst := range map[string]**time.Time{"since": &f.Since, "until": &f.Until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, err, []string{name})
+		}
+		*dst = &t
+	}
+	return &f, nil
+}
+
+// AuditList returns a paginated list of audit entries. Only system admins can
+// see the audit log.
+// The list can be filtered with the actor, action, owner, target, since and
+// until query parameters.
+//
+//	curl -k -X GET http://localhost:8000/1.0/admin/audit?actor=user1 --header "Private-token: YOUR_TOKEN"
+func AuditList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	f, em := parseAuditFilter(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	return audit.List(p, tx, *f)
+}
+
+// OrganizationAuditList returns a paginated list of the audit entries of an
+// organization, ie. the operations on the organization and its resources.
+// Only the organization owners can see them.
+//
+//	curl -k -X GET https://localhost:4430/1.0/organizations/{orgName}/audit
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func OrganizationAuditList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	orgName, em := getName(tx, r)
+	if em != nil {
+		return nil, nil, em
+	}
+	org, em := users.ByOrganizationName(tx, *orgName, false)
+	if em != nil {
+		return nil, nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorizedForRole(*user.Username, *org.Name,
+		permissions.Owner); !ok {
+		return nil, nil, em
+	}
+
+	f, em := parseAuditFilter(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	f.Owner = *org.Name
+	return audit.List(p, tx, *f)
+}
+
+// runAuditPurgeJob periodically removes the audit entries that are past the
+// retention window, until the given context is done. It is expected to be run
+// in its own goroutine.
+func runAuditPurgeJob(ctx context.Context, db *gorm.DB) {
+	ticker := time.NewTicker(auditPurgeInterval)
+	defer ticker.Stop()
+	for {
+		if em := audit.PurgeExpired(db, globals.AuditRetention); em != nil {
+			gz.LoggerFromContext(ctx).Error("Failed to purge expired audit entries: ", em.LogString())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package activity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Verbs of the recorded activities.
+const (
+	// VerbCreate is recorded when a model, world or collection is created,
+	// including clones.
+	VerbCreate = "create"
+	// VerbVersion is recorded when a new version of a model or world is
+	// uploaded.
+	VerbVersion = "version"
+	// VerbUpdate is recorded when a collection is updated.
+	VerbUpdate = "update"
+	// VerbLike is recorded when a user likes a model or world.
+	VerbLike = "like"
+	// VerbAddAsset is recorded when a model or world is added to a collection.
+	VerbAddAsset = "add_asset"
+	// VerbRemoveAsset is recorded when a model or world is removed from a
+	// collection.
+	VerbRemoveAsset = "remove_asset"
+)
+
+// Activity is an entry of the activity stream. It records that an actor
+// performed an action (the verb) on an object, owned by a user or
+// organization. Only activities on public objects are recorded, as the stream
+// is visible to any follower.
+//
+// swagger:model
+type Activity struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL;index" json:"created_at"`
+	// UpdatedAt and DeletedAt are not included. Activities cannot be modified.
+
+	// The username of the user that performed the action
+	Actor string `gorm:"not null;index" json:"actor"`
+	// The action: create, version, update, like, add_asset or remove_asset
+	Verb string `gorm:"not null" json:"verb"`
+	// The object type: model, world or collection
+	ObjectType string `gorm:"not null" json:"object_type"`
+	// The owner of the object
+	Owner string `gorm:"not null;index" json:"owner"`
+	// The name of the object
+	Name string `gorm:"not null" json:"name"`
+	// The UUID of the object. It is used to leave out of the feeds the
+	// activities on objects that are no longer visible.
+	ResourceUUID string `gorm:"index" json:"-"`
+	// The new version of the object, for version activities
+	Version *int `json:"version,omitempty"`
+	// The type of the model or world added to or removed from a collection
+	AssetType string `json:"asset_type,omitempty"`
+	// The owner of the model or world added to or removed from a collection
+	AssetOwner string `json:"asset_owner,omitempty"`
+	// The name of the model or world added to or removed from a collection
+	AssetName string `json:"asset_name,omitempty"`
+	// The UUID of the model or world added to or removed from a collection
+	AssetUUID string `gorm:"index" json:"-"`
+}
+
+// TableName sets the table name of activities.
+func (Activity) TableName() string {
+	return "activities"
+}
+
+// Activities is a slice of Activity
+//
+// swagger:model
+type Activities []Activity
+
+// Follow records that a user follows another user or an organization.
+type Follow struct {
+	ID        uint      `gorm:"primary_key" json:"-"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The username of the follower
+	Follower string `gorm:"not null;unique_index:idx_follower_followee" json:"follower"`
+	// The user or organization being followed
+	Followee string `gorm:"not null;unique_index:idx_follower_followee;index" json:"followee"`
+}
+
+// Follows is a slice of Follow
+//
+// swagger:model
+type Follows []Follow
+
+// CreateFollow encapsulates data required to follow a user or organization.
+type CreateFollow struct {
+	// The user or organization to follow
+	// required: true
+	Owner string `json:"owner" validate:"required"`
+}
+
+// Record adds an activity to the activity stream.
+func Record(tx *gorm.DB, a *Activity) *gz.ErrMsg {
+	if err := tx.Create(a).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// AddFollow makes a user follow another user or organization. Following twice
+// is not an error.
+func AddFollow(tx *gorm.DB, follower, followee string) (*Follow, *gz.ErrMsg) {
+	f := Follow{Follower: follower, Followee: followee}
+	if err := tx.Where(f).FirstOrCreate(&f).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return &f, nil
+}
+
+// RemoveFollow makes a user stop following another user or organization.
+// Returns the removed follow.
+func RemoveFollow(tx *gorm.DB, follower, followee string) (*Follow, *gz.ErrMsg) {
+	var f Follow
+	if tx.Where("follower = ? AND followee = ?", follower, followee).First(&f).RecordNotFound() {
+		return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	if err := tx.Delete(&f).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return &f, nil
+}
+
+// Following returns a paginated list of the users and organizations followed
+// by a user.
+func Following(p *gz.PaginationRequest, tx *gorm.DB, follower string) (*Follows, *gz.PaginationResult, *gz.ErrMsg) {
+	var follows Follows
+	q := tx.Model(&Follow{}).Where("follower = ?", follower).Order("created_at desc, id desc")
+	pagination, err := gz.PaginateQuery(q, &follows, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &follows, pagination, nil
+}
+
+// Feed returns a paginated list of the activities of the users and
+// organizations followed by a user, newest first. It includes the activities
+// performed by the followed users, and the activities on the objects of the
+// followed users and organizations.
+// The visible function returns subqueries that select the resources the
+// follower can see whose UUID is in the given column of the activities table.
+// Activities on any other resource, or on any other added or removed asset, are
+// left out. The activities are found by their indexed owner and actor, and each
+// one is then checked against the subqueries.
+func Feed(p *gz.PaginationRequest, tx *gorm.DB, follower string,
+	visible func(column string) []interface{}) (*Activities, *gz.PaginationResult, *gz.ErrMsg) {
+
+	var followees []string
+	if err := tx.Model(&Follow{}).Where("follower = ?", follower).Pluck("followee", &followees).Error; err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	table := Activity{}.TableName()
+	resources := visible(table + ".resource_uuid")
+	assets := visible(table + ".asset_uuid")
+	q := tx.Model(&Activity{}).Where("owner IN (?) OR actor IN (?)", followees, followees)
+	q = q.Where(queryExists(len(resources)), resources...)
+	q = q.Where("asset_uuid = '' OR asset_uuid IS NULL OR "+queryExists(len(assets)), assets...)
+	q = q.Order("created_at desc, id desc")
+
+	var activities Activities
+	pagination, err := gz.PaginateQuery(q, &activities, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &activities, pagination, nil
+}
+
+// queryExists returns a condition that matches if any of n subqueries returns
+// a row. It never matches if n is zero.
+func queryExists(n int) string {
+	if n == 0 {
+		return "1 = 0"
+	}
+	conds := make([]string, n)
+	for i := range conds {
+		conds[i] = "EXISTS (?)"
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
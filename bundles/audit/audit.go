@@ -0,0 +1,212 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// DefaultRetentionDays is the number of days audit entries are kept, when
+// IGN_FUEL_AUDIT_RETENTION_DAYS is not set.
+const DefaultRetentionDays = 365
+
+// MaxSummaryLength is the maximum length of the before and after summaries
+// stored in an audit entry. Longer summaries are truncated.
+const MaxSummaryLength = 2048
+
+// Entry is a record of a mutating operation. Entries are immutable: they are
+// only created by the audit middleware, and only removed once they are past the
+// retention window.
+//
+// swagger:model AuditEntry
+type Entry struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL;index" json:"created_at"`
+	// UpdatedAt and DeletedAt are not included. Entries cannot be modified.
+
+	// The username of the user that performed the operation. It is empty if
+	// the request was not authenticated.
+	Actor string `gorm:"index" json:"actor"`
+	// The ID of the personal access token used to authenticate, if any
+	TokenID *uint `json:"token_id,omitempty"`
+	// The operation, as the name of the route (eg. ModelTransfer)
+	Action string `gorm:"index" json:"action"`
+	// The HTTP method
+	Method string `json:"method"`
+	// The path of the target resource (eg. /{username}/models/{name})
+	Target string `gorm:"type:varchar(1024)" json:"target"`
+	// The user or organization that owns the target resource, if any
+	Owner string `gorm:"index" json:"owner,omitempty"`
+	// The HTTP status code of the response
+	Status int `json:"status"`
+	// Summary of the target before the operation. It is only set by the
+	// operations that remove resources, or change their owner or the members
+	// of an organization.
+	Before string `gorm:"type:text" json:"before,omitempty"`
+	// The identifiers of the target after the operation (eg. its name, owner
+	// and status), taken from the response. Other fields of the response,
+	// such as credentials, are not stored.
+	After string `gorm:"type:text" json:"after,omitempty"`
+	// The request ID, as returned in the X-Request-ID response header
+	RequestID string `gorm:"index" json:"request_id"`
+	// The IP address of the client
+	IP string `json:"ip"`
+}
+
+// TableName sets the table name of audit entries.
+func (Entry) TableName() string {
+	return "audit_entries"
+}
+
+// Entries is a slice of Entry
+//
+// swagger:model AuditEntries
+type Entries []Entry
+
+// Filter encapsulates the optional criteria to query audit entries.
+type Filter struct {
+	Actor  string
+	Action string
+	Owner  string
+	Target string
+	Since  *time.Time
+	Until  *time.Time
+}
+
+// contextKey is the type of the key used to store the audit entry of a
+// request in its context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that holds the given entry. The entry can
+// be completed by request handlers while processing the request.
+func NewContext(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext returns the audit entry stored in ctx, or nil if the request is
+// not audited.
+func FromContext(ctx context.Context) *Entry {
+	entry, _ := ctx.Value(contextKey{}).(*Entry)
+	return entry
+}
+
+// SetActor sets the user, and optionally the access token, that performed the
+// audited operation. It does nothing if the request is not audited.
+func SetActor(ctx context.Context, username string, tokenID *uint) {
+	if entry := FromContext(ctx); entry != nil {
+		entry.Actor = username
+		entry.TokenID = tokenID
+	}
+}
+
+// SetBefore sets the summary of the target before the audited operation. It
+// does nothing if the request is not audited.
+func SetBefore(ctx context.Context, v interface{}) {
+	if entry := FromContext(ctx); entry != nil {
+		entry.Before = Summarize(v)
+	}
+}
+
+// Summarize returns a JSON summary of the given value, truncated to the
+// maximum summary length.
+func Summarize(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return Truncate(string(b))
+}
+
+// Truncate shortens a summary to the maximum summary length.
+func Truncate(s string) string {
+	if len(s) > MaxSummaryLength {
+		return s[:MaxSummaryLength]
+	}
+	return s
+}
+
+// identifierFields are the fields of a response kept in the after summary of an
+// audit entry. Responses can include credentials, such as the key of a new
+// access token, so only the fields that identify the target are kept.
+var identifierFields = []string{"id", "uuid", "name", "owner", "username", "role",
+	"status", "version", "permission"}
+
+// Identify returns a JSON summary with the identifier fields of the given JSON
+// object. It returns an empty string if the value is not a JSON object, or has
+// none of the identifier fields.
+func Identify(body []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	ids := make(map[string]json.RawMessage)
+	for _, name := range identifierFields {
+		if value, ok := fields[name]; ok {
+			ids[name] = value
+		}
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	return Summarize(ids)
+}
+
+// Record stores an audit entry.
+func Record(db *gorm.DB, entry *Entry) *gz.ErrMsg {
+	if err := db.Create(entry).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// QueryForEntries returns a gorm query configured to find the audit entries
+// that match the given filter, newest first.
+func QueryForEntries(q *gorm.DB, f Filter) *gorm.DB {
+	q = q.Model(&Entry{})
+	if f.Actor != "" {
+		q = q.Where("actor = ?", f.Actor)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.Owner != "" {
+		q = q.Where("owner = ?", f.Owner)
+	}
+	if f.Target != "" {
+		q = q.Where("target LIKE ?", f.Target+"%")
+	}
+	if f.Since != nil {
+		q = q.Where("created_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		q = q.Where("created_at < ?", *f.Until)
+	}
+	return q.Order("created_at desc, id desc")
+}
+
+// List returns a paginated list of the audit entries that match the given
+// filter.
+func List(p *gz.PaginationRequest, tx *gorm.DB, f Filter) (*Entries, *gz.PaginationResult, *gz.ErrMsg) {
+	var entries Entries
+	pagination, err := gz.PaginateQuery(QueryForEntries(tx, f), &entries, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &entries, pagination, nil
+}
+
+// PurgeExpired permanently removes the audit entries older than the given
+// retention.
+func PurgeExpired(db *gorm.DB, retention time.Duration) *gz.ErrMsg {
+	cutoff := time.Now().Add(-retention)
+	if err := db.Where("created_at < ?", cutoff).Delete(&Entry{}).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return nil
+}
@@ -36,7 +36,7 @@ type Collection struct {
 	Name *string `gorm:"unique_index:idx_colname_owner" json:"name,omitempty"`
 
 	// Unique identifier
-	UUID *string `json:"-"`
+	UUID *string `gorm:"index" json:"-"`
 
 	// A description of the collection (max 65,535 chars)
 	Description *string `gorm:"type:text" json:"description,omitempty"`
@@ -53,8 +53,21 @@ type Collection struct {
 	// Private - True to make this a private resource
 	Private *bool `json:"private,omitempty"`
 
+	// Archived - True to make this a read-only resource
+	Archived *bool `gorm:"default:false" json:"archived,omitempty"`
+
+	// Hidden - True if a moderator hid this collection from lists and search results
+	Hidden bool `gorm:"not null;default:false" json:"hidden,omitempty"`
+
 	// A list of thumbnail urls from the associated models/worlds.
 	ThumbnailUrls []string `gorm:"-" json:"thumbnails,omitempty"`
+
+	// ForkedFromID is the ID of the collection this collection was cloned from, if any.
+	ForkedFromID *uint `sql:"index" json:"-"`
+
+	// The collection this collection was cloned from, if it is visible to the
+	// requesting user.
+	ForkedFrom *NameOwnerPair `gorm:"-" json:"forked_from,omitempty"`
 }
 
 // Collections is an array of Collection
@@ -153,6 +166,15 @@ func ByName(tx *gorm.DB, name, owner string) (*Collection, error) {
 	return &res, nil
 }
 
+// ByID queries a Collection by its ID.
+func ByID(tx *gorm.DB, id uint) (*Collection, error) {
+	var res Collection
+	if err := QueryForCollections(tx).Where("id = ?", id).First(&res).Error; err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 // FindAssociation queries CollectionAssets by name, owner and type.
 func FindAssociation(tx *gorm.DB, colID uint, owner, name,
 	assetType string) (*CollectionAsset, error) {
@@ -221,11 +243,13 @@ type UpdateCollection struct {
 	File string `json:"file" validate:"omitempty,gt=0" form:"-"`
 	// Private privacy/visibility setting
 	Private *bool `json:"private" validate:"omitempty" form:"private"`
+	// Archived read-only setting
+	Archived *bool `json:"archived" validate:"omitempty" form:"archived"`
 }
 
 // IsEmpty returns true is the struct is empty.
 func (uc UpdateCollection) IsEmpty() bool {
-	return uc.Description == nil && uc.Private == nil
+	return uc.Description == nil && uc.Private == nil && uc.Archived == nil
 }
 
 // NameOwnerPair describes a name and owner to find an asset.
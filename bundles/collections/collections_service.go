@@ -43,6 +43,7 @@ func (s *Service) GetCollection(tx *gorm.DB, owner, name string,
 	if em := populateCollectionThumbnails(blankQuery, c, user); em != nil {
 		return nil, em
 	}
+	populateCollectionsForkedFrom(blankQuery, []*Collection{c}, user)
 
 	return c, nil
 }
@@ -58,7 +59,7 @@ func (s *Service) internalGetCollection(tx *gorm.DB, owner, name string,
 	// Create query
 	q := QueryForCollections(tx)
 	// filter resources based on privacy setting
-	q = res.QueryForResourceVisibility(tx, q, &owner, user)
+	q = res.QueryForSharedResourceVisibility(tx, q, &owner, user)
 	// Find the collection
 	c, err := ByName(q, name, owner)
 	if err != nil {
@@ -71,13 +72,15 @@ func (s *Service) internalGetCollection(tx *gorm.DB, owner, name string,
 // CollectionList returns a paginated list of Collections.
 // Note: 'extend' argument is to only return collections that the user can
 // add/remove assets (which is not the same as 'updating the collection details').
+// If the archived argument is set, only collections with that archived state are
+// returned. Collections hidden by moderators are only listed to their owners.
 func (s *Service) CollectionList(p *gz.PaginationRequest, tx *gorm.DB,
-	owner *string, order, search string, extend bool, user *users.User) (*Collections,
-	*gz.PaginationResult, *gz.ErrMsg) {
+	owner *string, order, search string, extend bool, user *users.User,
+	archived *bool) (*Collections, *gz.PaginationResult, *gz.ErrMsg) {
 
 	var list Collections
 	// Create query
-	q := QueryForCollections(tx)
+	q := res.QueryForHidden(res.QueryForArchived(QueryForCollections(tx), archived), user)
 
 	// Override default Order BY, unless the user explicitly requested ASC order
 	if !(order != "" && strings.ToLower(order) == "asc") {
@@ -91,7 +94,7 @@ func (s *Service) CollectionList(p *gz.PaginationRequest, tx *gorm.DB,
 		q = q.Where("owner IN (?)", userGroups)
 	} else {
 		// filter resources based on privacy setting
-		q = res.QueryForResourceVisibility(tx, q, owner, user)
+		q = res.QueryForSharedResourceVisibility(tx, q, owner, user)
 	}
 
 	// If a search criteria was defined, then also apply a fulltext search on "world's name + description"
@@ -136,6 +139,11 @@ func (s *Service) CollectionList(p *gz.PaginationRequest, tx *gorm.DB,
 		}
 		result = append(result, col)
 	}
+	cols := make([]*Collection, len(result))
+	for i := range result {
+		cols[i] = &result[i]
+	}
+	populateCollectionsForkedFrom(blankQuery, cols, user)
 	return &result, paginationResult, nil
 }
 
@@ -180,6 +188,56 @@ func populateCollectionThumbnails(tx *gorm.DB,
 	return nil
 }
 
+// populateCollectionsForkedFrom sets the ForkedFrom field of the given
+// collections that are forks, as long as their source is visible to the user.
+// The sources of all the collections are loaded in a single query.
+func populateCollectionsForkedFrom(tx *gorm.DB, cols []*Collection, user *users.User) {
+	var ids []uint
+	for _, col := range cols {
+		if col.ForkedFromID != nil {
+			ids = append(ids, *col.ForkedFromID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	var sources Collections
+	if err := QueryForCollections(tx).Where("id IN (?)", ids).Find(&sources).Error; err != nil {
+		return
+	}
+	byID := make(map[uint]*Collection, len(sources))
+	for i := range sources {
+		byID[sources[i].ID] = &sources[i]
+	}
+	for _, col := range cols {
+		if col.ForkedFromID == nil {
+			continue
+		}
+		source, ok := byID[*col.ForkedFromID]
+		if !ok {
+			continue
+		}
+		if ok, _ := users.CheckPermissions(tx, *source.UUID, user, *source.Private, permissions.Read); !ok {
+			continue
+		}
+		col.ForkedFrom = &NameOwnerPair{Name: *source.Name, Owner: *source.Owner}
+	}
+}
+
+// CollectionForks returns a paginated list of the collections that were cloned
+// from the given collection. Only forks visible to the requesting user are returned.
+func (s *Service) CollectionForks(p *gz.PaginationRequest, tx *gorm.DB, owner,
+	name string, user *users.User) (*Collections, *gz.PaginationResult, *gz.ErrMsg) {
+
+	col, em := s.internalGetCollection(tx, owner, name, user)
+	if em != nil {
+		return nil, nil, em
+	}
+
+	q := tx.Where("forked_from_id = ?", col.ID)
+	return s.CollectionList(p, q, nil, "", "", false, user, nil)
+}
+
 // RemoveCollection removes a Collection. The user argument is the requesting user. It
 // is used to check if the user can perform the operation.
 func (s *Service) RemoveCollection(tx *gorm.DB, owner, name string, user *users.User) *gz.ErrMsg {
@@ -190,7 +248,7 @@ func (s *Service) RemoveCollection(tx *gorm.DB, owner, name string, user *users.
 	}
 
 	// make sure the user requesting removal has the correct permissions
-	ok, err := globals.Permissions.IsAuthorized(*user.Username, *col.UUID, permissions.Write)
+	ok, err := globals.Permissions.CanPerformAsOwner(*user.Username, *col.Owner, permissions.Delete)
 	if !ok {
 		return err
 	}
@@ -211,11 +269,11 @@ func (s *Service) RemoveCollection(tx *gorm.DB, owner, name string, user *users.
 
 // UpdateCollection updates a collection. The user argument is the requesting
 // user. It is used to check if the user can perform the operation.
-// Fields that can be currently updated: desc, private.
+// Fields that can be currently updated: desc, private, archived.
 // The filesPath argument points to a tmp folder from which to read the new files.
 // Returns the updated collection. Note: it will be the same instance as 'col' arg.
 func (s *Service) UpdateCollection(ctx context.Context, tx *gorm.DB, colOwner,
-	colName string, desc, filesPath *string, private *bool,
+	colName string, desc, filesPath *string, private, archived *bool,
 	user *users.User) (*Collection, *gz.ErrMsg) {
 
 	col, em := s.internalGetCollection(tx, colOwner, colName, user)
@@ -244,6 +302,14 @@ func (s *Service) UpdateCollection(ctx context.Context, tx *gorm.DB, colOwner,
 		tx.Model(&col).Update("Private", *private)
 	}
 
+	// Update the archived state, if present. Only Owners and Admins can do that.
+	if archived != nil {
+		if ok, em := users.CanPerformWithRole(tx, *col.Owner, *user.Username, permissions.Admin); !ok {
+			return nil, em
+		}
+		tx.Model(&col).Update("Archived", *archived)
+	}
+
 	// Update files, if present
 	if filesPath != nil {
 		// Replace ALL files with the new ones
@@ -270,7 +336,7 @@ func (s *Service) CreateCollection(ctx context.Context, tx *gorm.DB, cc CreateCo
 	// Set the owner
 	owner := cc.Owner
 	if owner == "" {
-		owner = *creator.Username
+		owner = creator.DefaultOwner()
 	} else {
 		ok, em := users.VerifyOwner(tx, owner, *creator.Username, permissions.Read)
 		if !ok {
@@ -331,6 +397,11 @@ func (s *Service) AddAsset(ctx context.Context, tx *gorm.DB, owner, name string,
 		return nil, em
 	}
 
+	// Archived collections are read-only
+	if res.IsArchived(col.Archived) {
+		return nil, res.NewArchivedErrorMessage(*col.Name)
+	}
+
 	if em := validateAssetType(assetType); em != nil {
 		return nil, em
 	}
@@ -389,6 +460,11 @@ func (s *Service) RemoveAsset(ctx context.Context, tx *gorm.DB, owner, name stri
 		return nil, em
 	}
 
+	// Archived collections are read-only
+	if res.IsArchived(col.Archived) {
+		return nil, res.NewArchivedErrorMessage(*col.Name)
+	}
+
 	if em := validateAssetType(assetType); em != nil {
 		return nil, em
 	}
@@ -437,9 +513,9 @@ func (s *Service) GetCollectionAssets(p *gz.PaginationRequest, tx *gorm.DB,
 
 	// Delegate to corresponding service based on type
 	if assetsType == TModel {
-		return (&models.Service{Storage: globals.Storage}).ModelList(p, q, nil, "", "", nil, user, nil, true)
+		return (&models.Service{Storage: globals.Storage}).ModelList(p, q, nil, res.ListOptions{User: user, IgnoreMemcache: true})
 	}
-	return (&worlds.Service{Storage: globals.Storage}).WorldList(p, q, nil, "", "", nil, user)
+	return (&worlds.Service{Storage: globals.Storage}).WorldList(p, q, nil, res.ListOptions{User: user})
 }
 
 // GetAssociatedCollections returns a paginated list of collections given the
@@ -467,7 +543,7 @@ func (s *Service) GetAssociatedCollections(p *gz.PaginationRequest, tx *gorm.DB,
 	q = q.Where("asset_owner = ? AND asset_name = ? AND type = ?", no.Owner,
 		no.Name, assetType)
 
-	return s.CollectionList(p, q, nil, "", "", false, user)
+	return s.CollectionList(p, q, nil, "", "", false, user, nil)
 }
 
 // GetFile returns the contents (bytes) of a collection file. Version is considered.
@@ -499,7 +575,7 @@ func (s *Service) CloneCollection(ctx context.Context, tx *gorm.DB,
 	// Set the owner
 	owner := cloneData.Owner
 	if owner == "" {
-		owner = *creator.Username
+		owner = creator.DefaultOwner()
 	} else {
 		ok, em := users.VerifyOwner(tx, owner, *creator.Username, permissions.Read)
 		if !ok {
@@ -543,6 +619,8 @@ func (s *Service) CloneCollection(ctx context.Context, tx *gorm.DB,
 	if err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
 	}
+	// Keep track of the collection lineage
+	clone.ForkedFromID = &sourceCollection.ID
 
 	// If everything went OK then create the  new model in DB.
 	if err := tx.Create(&clone).Error; err != nil {
@@ -615,3 +693,82 @@ func insertAssets(tx *gorm.DB, assets *CollectionAssets, collectionID uint) erro
 
 	return nil
 }
+
+// CollectionTrash returns the list of soft-deleted collections of an owner
+// that can still be restored.
+func (s *Service) CollectionTrash(tx *gorm.DB, owner string) (res.TrashItems, *gz.ErrMsg) {
+	var list Collections
+	if err := res.QueryForTrash(tx.Model(&Collection{}), owner).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	items := res.TrashItems{}
+	for _, c := range list {
+		items = append(items, res.NewTrashItem("collection", *c.Name, *c.Owner, *c.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreCollection restores a soft-deleted collection, as long as it is still
+// within the trash retention window. If the collection name is already in use,
+// a new name must be given in the RestoreResource argument.
+// The user argument is the requesting user. It must be the owner or have write
+// access to the owner organization.
+func (s *Service) RestoreCollection(ctx context.Context, tx *gorm.DB, owner,
+	name string, rr res.RestoreResource, user *users.User) (*Collection, *gz.ErrMsg) {
+
+	if user == nil {
+		return nil, gz.NewErrorMessage(gz.ErrorAuthNoUser)
+	}
+	if ok, em := users.VerifyOwner(tx, owner, *user.Username, permissions.Write); !ok {
+		return nil, em
+	}
+
+	var col Collection
+	if err := res.QueryForTrash(tx.Model(&Collection{}), owner).Where("name = ?", name).
+		First(&col).Error; err != nil {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, err, []string{owner, name})
+	}
+
+	// Name-conflict handling: the restored collection cannot take the name of
+	// an existing one.
+	newName := name
+	if rr.Name != "" {
+		newName = rr.Name
+	}
+	if _, err := ByName(tx, newName, owner); err == nil {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{newName})
+	}
+
+	if em := res.Restore(tx, &col, newName); em != nil {
+		return nil, em
+	}
+
+	return s.GetCollection(tx, owner, newName, user)
+}
+
+// PurgeExpiredCollections permanently removes from the database all the
+// collections that were soft-deleted before the trash retention window. The
+// files of the purged collections must be removed once the transaction is
+// committed.
+func (s *Service) PurgeExpiredCollections(ctx context.Context, tx *gorm.DB) ([]*res.PurgedResource, *gz.ErrMsg) {
+	var list Collections
+	if err := res.QueryForExpiredTrash(tx.Model(&Collection{})).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+
+	var purged []*res.PurgedResource
+	for i := range list {
+		col := &list[i]
+		// Remove the collection assets
+		if err := tx.Where("col_id = ?", col.ID).Delete(&CollectionAsset{}).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+		p, em := res.Purge(tx, col, collections, nil)
+		if em != nil {
+			return nil, em
+		}
+		purged = append(purged, p)
+		gz.LoggerFromContext(ctx).Info("Purged collection: ", *col.Owner, "/", *col.Name)
+	}
+	return purged, nil
+}
@@ -0,0 +1,267 @@
+package comments
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Comment is a comment on a model or world. Replies reference their parent
+// comment, which forms the threads of the resource discussion.
+//
+// swagger:model
+type Comment struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+	// DeletedAt is not included. Deleted comments are kept, without their body,
+	// so their replies stay in the thread.
+
+	// The resource type: model or world
+	ResourceType string `gorm:"not null" json:"-"`
+	// The UUID of the commented resource
+	ResourceUUID string `gorm:"not null;index" json:"-"`
+	// The comment this one replies to, if any
+	ParentID *uint `gorm:"index" json:"parent_id,omitempty"`
+	// The username of the author
+	Author string `gorm:"not null" json:"author"`
+	// The comment, in markdown. Empty for deleted and hidden comments.
+	Body string `gorm:"type:text" json:"body"`
+	// The number of replies
+	Replies int `gorm:"not null" json:"replies"`
+	// Date and time of the last edit, if any
+	EditedAt *time.Time `gorm:"type:timestamp(3) NULL" json:"edited_at,omitempty"`
+	// Whether the author or a moderator deleted the comment
+	Deleted bool `gorm:"not null" json:"deleted"`
+	// Whether a moderator hid the comment
+	Hidden bool `gorm:"not null" json:"hidden"`
+	// The username of the moderator that hid the comment
+	HiddenBy string `json:"-"`
+}
+
+// Comments is a slice of Comment
+//
+// swagger:model
+type Comments []Comment
+
+// Thread holds the moderation state of the comments of a resource. Resources
+// without a thread accept new comments.
+//
+// swagger:model CommentThread
+type Thread struct {
+	ID        uint      `gorm:"primary_key" json:"-"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"-"`
+	UpdatedAt time.Time `json:"-"`
+
+	// The UUID of the commented resource
+	ResourceUUID string `gorm:"not null;unique_index" json:"-"`
+	// Locked threads don't accept new comments, replies or edits
+	Locked bool `gorm:"not null" json:"locked"`
+	// The username of the moderator that locked the thread
+	LockedBy string `json:"locked_by,omitempty"`
+}
+
+// TableName sets the table name of comment threads.
+func (Thread) TableName() string {
+	return "comment_threads"
+}
+
+// UpdateThread encapsulates data required to lock or unlock a comment thread.
+type UpdateThread struct {
+	// required: true
+	Locked *bool `json:"locked" validate:"required"`
+}
+
+// CreateComment encapsulates data required to create a comment.
+type CreateComment struct {
+	// The comment, in markdown
+	// required: true
+	Body string `json:"body" validate:"required,max=10000"`
+	// Optional. The ID of the comment to reply to.
+	ParentID *uint `json:"parent_id"`
+}
+
+// UpdateComment encapsulates data required to update a comment. The body can
+// only be changed by the author, and the hidden flag by the moderators.
+// Missing fields are not changed.
+type UpdateComment struct {
+	Body   *string `json:"body" validate:"omitempty,min=1,max=10000"`
+	Hidden *bool   `json:"hidden"`
+}
+
+// mentionRegex matches @username mentions. The mention must not be preceded by
+// a word character, so email addresses are not mentions.
+var mentionRegex = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9][A-Za-z0-9_-]*)`)
+
+// Mentions returns the usernames mentioned in a comment body, without
+// duplicates, in order of appearance.
+func Mentions(body string) []string {
+	var usernames []string
+	seen := map[string]bool{}
+	for _, m := range mentionRegex.FindAllStringSubmatch(body, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			usernames = append(usernames, m[1])
+		}
+	}
+	return usernames
+}
+
+// Redact clears the body of a deleted or hidden comment. Hidden comments are
+// still shown to the given user if canSeeHidden is true.
+func (c *Comment) Redact(canSeeHidden bool) {
+	if c.Deleted || (c.Hidden && !canSeeHidden) {
+		c.Body = ""
+	}
+}
+
+// GetThread returns the thread of a resource. Resources without comments get
+// an unlocked thread.
+func GetThread(tx *gorm.DB, uuid string) (*Thread, *gz.ErrMsg) {
+	t := Thread{ResourceUUID: uuid}
+	if q := tx.Where("resource_uuid = ?", uuid).First(&t); q.Error != nil && !q.RecordNotFound() {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, q.Error)
+	}
+	return &t, nil
+}
+
+// SetLocked locks or unlocks the thread of a resource.
+func SetLocked(tx *gorm.DB, uuid, username string, locked bool) (*Thread, *gz.ErrMsg) {
+	t, em := GetThread(tx, uuid)
+	if em != nil {
+		return nil, em
+	}
+	t.Locked = locked
+	t.LockedBy = ""
+	if locked {
+		t.LockedBy = username
+	}
+	if err := tx.Save(t).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return t, nil
+}
+
+// checkUnlocked returns an error if the thread of a resource is locked.
+func checkUnlocked(tx *gorm.DB, uuid string) *gz.ErrMsg {
+	t, em := GetThread(tx, uuid)
+	if em != nil {
+		return em
+	}
+	if t.Locked {
+		return gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil, []string{"The comment thread is locked"})
+	}
+	return nil
+}
+
+// Create adds a comment, or a reply, to a resource.
+func Create(tx *gorm.DB, resType, uuid, author string, cc CreateComment) (*Comment, *gz.ErrMsg) {
+	if em := checkUnlocked(tx, uuid); em != nil {
+		return nil, em
+	}
+	if cc.ParentID != nil {
+		parent, em := Get(tx, uuid, *cc.ParentID)
+		if em != nil {
+			return nil, em
+		}
+		if parent.Deleted {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"parent_id"})
+		}
+		if err := tx.Model(parent).UpdateColumn("replies", gorm.Expr("replies + 1")).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+	}
+	c := Comment{
+		ResourceType: resType,
+		ResourceUUID: uuid,
+		ParentID:     cc.ParentID,
+		Author:       author,
+		Body:         cc.Body,
+	}
+	if err := tx.Create(&c).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return &c, nil
+}
+
+// Get returns a comment of a resource.
+func Get(tx *gorm.DB, uuid string, id uint) (*Comment, *gz.ErrMsg) {
+	var c Comment
+	if q := tx.Where("resource_uuid = ? AND id = ?", uuid, id).First(&c); q.Error != nil {
+		if q.RecordNotFound() {
+			return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+		}
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, q.Error)
+	}
+	return &c, nil
+}
+
+// List returns a paginated list of the comments of a resource, oldest first.
+// If parentID is nil the top level comments are returned. Otherwise, the
+// replies to the given comment.
+func List(p *gz.PaginationRequest, tx *gorm.DB, uuid string,
+	parentID *uint) (*Comments, *gz.PaginationResult, *gz.ErrMsg) {
+
+	q := tx.Model(&Comment{}).Where("resource_uuid = ?", uuid)
+	if parentID == nil {
+		q = q.Where("parent_id IS NULL")
+	} else {
+		q = q.Where("parent_id = ?", *parentID)
+	}
+	var list Comments
+	pagination, err := gz.PaginateQuery(q.Order("created_at, id"), &list, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &list, pagination, nil
+}
+
+// Edit changes the body of a comment. Deleted comments and comments in locked
+// threads cannot be edited.
+func Edit(tx *gorm.DB, c *Comment, body string) *gz.ErrMsg {
+	if em := checkUnlocked(tx, c.ResourceUUID); em != nil {
+		return em
+	}
+	if c.Deleted {
+		return gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	now := time.Now()
+	c.Body = body
+	c.EditedAt = &now
+	if err := tx.Save(c).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// SetHidden hides or shows a comment. The moderator is recorded when hiding.
+func SetHidden(tx *gorm.DB, c *Comment, moderator string, hidden bool) *gz.ErrMsg {
+	c.Hidden = hidden
+	c.HiddenBy = ""
+	if hidden {
+		c.HiddenBy = moderator
+	}
+	if err := tx.Save(c).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// Remove soft deletes a comment. The comment stays in the thread, without its
+// body, so its replies can still be read.
+func Remove(tx *gorm.DB, c *Comment) *gz.ErrMsg {
+	if c.Deleted {
+		return gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	c.Deleted = true
+	c.Body = ""
+	if err := tx.Save(c).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package commonres
+
+import (
+	"errors"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// ErrArchived is the base error used when trying to modify an archived resource.
+var ErrArchived = errors.New("the resource is archived and is read-only")
+
+// IsArchived returns true if the given archived flag is set.
+func IsArchived(archived *bool) bool {
+	return archived != nil && *archived
+}
+
+// NewArchivedErrorMessage returns the error message used to reject changes to
+// the archived resource with the given name.
+func NewArchivedErrorMessage(name string) *gz.ErrMsg {
+	return gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, ErrArchived, []string{name})
+}
+
+// QueryForArchived filters the given query by the archived state of the
+// resources. If archived is nil, the query is returned unmodified.
+func QueryForArchived(q *gorm.DB, archived *bool) *gorm.DB {
+	if archived == nil {
+		return q
+	}
+	return q.Where("archived = ?", *archived)
+}
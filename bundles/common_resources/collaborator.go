@@ -0,0 +1,240 @@
+package commonres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Collaborator grants a user, or an organization team, access to a model,
+// world or collection owned by someone else. The grant itself is stored in the
+// permissions DB (casbin). This table keeps track of the grants so they can be
+// listed and used to filter resource lists.
+//
+// swagger:model
+type Collaborator struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key" json:"-"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The UUID of the shared resource
+	ResourceUUID *string `gorm:"unique_index:idx_collaborator" json:"-"`
+	// The subject of the grant in the permissions DB. It is the username or the
+	// casbin group name of the team.
+	Subject *string `gorm:"unique_index:idx_collaborator;index" json:"-"`
+
+	// The collaborating user, if the resource was shared with a user
+	Username *string `json:"username,omitempty"`
+	// The organization of the collaborating team, if the resource was shared
+	// with a team
+	Organization *string `json:"organization,omitempty"`
+	// The collaborating team, if the resource was shared with a team
+	Team *string `json:"team,omitempty"`
+	// The granted permission: read or write
+	Permission string `json:"permission"`
+}
+
+// Collaborators is an array of Collaborator
+//
+// swagger:model
+type Collaborators []Collaborator
+
+// AddCollaborator encapsulates the data required to share a resource with a
+// user or an organization team.
+type AddCollaborator struct {
+	// The user to share the resource with. Required if no team is given.
+	Username string `json:"username" validate:"omitempty,noforwardslash" form:"username"`
+	// The organization of the team to share the resource with.
+	Organization string `json:"organization" validate:"omitempty,noforwardslash" form:"organization"`
+	// The team to share the resource with. Requires organization.
+	Team string `json:"team" validate:"omitempty,noforwardslash" form:"team"`
+	// The permission to grant: read or write. Defaults to read.
+	Permission string `json:"permission" validate:"omitempty,oneof=read write" form:"permission"`
+}
+
+// collaboratorSubject validates the collaborator data and returns the subject
+// to use in the permissions DB.
+func collaboratorSubject(tx *gorm.DB, ac *AddCollaborator) (string, *gz.ErrMsg) {
+	if (ac.Username == "") == (ac.Team == "") {
+		return "", gz.NewErrorMessageWithArgs(gz.ErrorMissingField, nil,
+			[]string{"Either username or team must be given"})
+	}
+
+	if ac.Username != "" {
+		if _, em := users.ByUsername(tx, ac.Username, false); em != nil {
+			return "", em
+		}
+		return ac.Username, nil
+	}
+
+	if ac.Organization == "" {
+		return "", gz.NewErrorMessageWithArgs(gz.ErrorMissingField, nil, []string{"organization"})
+	}
+	org, em := users.ByOrganizationName(tx, ac.Organization, false)
+	if em != nil {
+		return "", em
+	}
+	// Team names are only unique within an organization.
+	team, em := users.TeamOfOrganization(tx, org, ac.Team)
+	if em != nil {
+		return "", em
+	}
+	return users.CasbinNameForTeam(*org.Name, *team.Name), nil
+}
+
+// grantPermission adds the permissions DB policies for the given subject and
+// resource. A write permission also grants read access.
+func grantPermission(subject, uuid string, per permissions.Action) *gz.ErrMsg {
+	if _, err := globals.Permissions.AddPermission(subject, uuid, permissions.Read); err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	if per == permissions.Write {
+		if _, err := globals.Permissions.AddPermission(subject, uuid, permissions.Write); err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+	return nil
+}
+
+// revokePermission removes the permissions DB policies of the given subject on
+// the resource.
+func revokePermission(subject, uuid string) *gz.ErrMsg {
+	if _, err := globals.Permissions.RemovePermission(subject, uuid, permissions.Read); err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	if _, err := globals.Permissions.RemovePermission(subject, uuid, permissions.Write); err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	return nil
+}
+
+// GrantPermissions adds the permissions DB policies of the collaborator,
+// replacing the ones it had. It must be called once the collaborator is
+// committed, so the policies are not left behind if the transaction fails.
+func (col *Collaborator) GrantPermissions() *gz.ErrMsg {
+	if em := revokePermission(*col.Subject, *col.ResourceUUID); em != nil {
+		return em
+	}
+	return grantPermission(*col.Subject, *col.ResourceUUID, permissions.ActionFrom(col.Permission))
+}
+
+// RevokePermissions removes the permissions DB policies of the collaborator. It
+// must be called once the collaborator removal is committed, so the policies
+// are kept if the transaction fails.
+func (col *Collaborator) RevokePermissions() *gz.ErrMsg {
+	return revokePermission(*col.Subject, *col.ResourceUUID)
+}
+
+// GetCollaborators returns the list of collaborators of a resource.
+func GetCollaborators(tx *gorm.DB, res Resource) (*Collaborators, *gz.ErrMsg) {
+	var list Collaborators
+	if err := tx.Where("resource_uuid = ?", *res.GetUUID()).Order("id").Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return &list, nil
+}
+
+// ShareResource shares a resource with a user or an organization team. If the
+// user or team is already a collaborator, its permission is updated. The caller
+// must grant the permission with GrantPermissions once the transaction is
+// committed.
+func ShareResource(ctx context.Context, tx *gorm.DB, res Resource,
+	ac *AddCollaborator) (*Collaborator, *gz.ErrMsg) {
+
+	if ac.Permission == "" {
+		ac.Permission = permissions.Read.String()
+	}
+	per := permissions.ActionFrom(ac.Permission)
+	if per != permissions.Read && per != permissions.Write {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"permission"})
+	}
+
+	subject, em := collaboratorSubject(tx, ac)
+	if em != nil {
+		return nil, em
+	}
+	// The owner already has full access to the resource.
+	if subject == *res.GetOwner() {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+			[]string{"Cannot share a resource with its owner"})
+	}
+
+	uuid := *res.GetUUID()
+	var col Collaborator
+	tx.Where("resource_uuid = ? AND subject = ?", uuid, subject).First(&col)
+	if col.ID == 0 {
+		col = Collaborator{ResourceUUID: &uuid, Subject: &subject}
+		if ac.Username != "" {
+			col.Username = &ac.Username
+		} else {
+			col.Organization = &ac.Organization
+			col.Team = &ac.Team
+		}
+	}
+	col.Permission = per.String()
+
+	if err := tx.Save(&col).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Resource shared. Owner:[%s] Name:[%s] Subject:[%s] Permission:[%s]",
+		*res.GetOwner(), *res.GetName(), subject, col.Permission))
+	return &col, nil
+}
+
+// UnshareResource stops sharing a resource with a user or an organization
+// team. Returns the removed collaborator. The caller must revoke its permission
+// with RevokePermissions once the transaction is committed.
+func UnshareResource(ctx context.Context, tx *gorm.DB, res Resource,
+	ac *AddCollaborator) (*Collaborator, *gz.ErrMsg) {
+
+	subject, em := collaboratorSubject(tx, ac)
+	if em != nil {
+		return nil, em
+	}
+
+	uuid := *res.GetUUID()
+	var col Collaborator
+	if tx.Where("resource_uuid = ? AND subject = ?", uuid, subject).First(&col).RecordNotFound() {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNonExistentResource, nil, []string{subject})
+	}
+	if err := tx.Delete(&col).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Resource unshared. Owner:[%s] Name:[%s] Subject:[%s]",
+		*res.GetOwner(), *res.GetName(), subject))
+	return &col, nil
+}
+
+// restoreCollaborators adds back the permissions of the collaborators of a
+// resource. They are removed from the permissions DB when the resource is
+// deleted.
+func restoreCollaborators(tx *gorm.DB, res Resource) *gz.ErrMsg {
+	list, em := GetCollaborators(tx, res)
+	if em != nil {
+		return em
+	}
+	for _, col := range *list {
+		if em := grantPermission(*col.Subject, *col.ResourceUUID,
+			permissions.ActionFrom(col.Permission)); em != nil {
+			return em
+		}
+	}
+	return nil
+}
+
+// querySharedWith returns a subquery with the UUIDs of the resources shared
+// with any of the given subjects.
+func querySharedWith(tx *gorm.DB, subjects []string) interface{} {
+	return tx.Model(&Collaborator{}).Select("resource_uuid").
+		Where("subject IN (?)", subjects).QueryExpr()
+}
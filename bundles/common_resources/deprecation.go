@@ -0,0 +1,29 @@
+package commonres
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// DeprecateResource encapsulates the data required to deprecate a model or
+// world.
+type DeprecateResource struct {
+	// Optional message explaining why the resource was deprecated
+	Message string `json:"message" validate:"omitempty,max=1000" form:"message"`
+	// Optional owner of the successor resource. Defaults to the owner of the
+	// deprecated resource.
+	SuccessorOwner string `json:"successor_owner" validate:"omitempty,noforwardslash" form:"successor_owner"`
+	// Optional name of the resource that replaces the deprecated one
+	SuccessorName string `json:"successor_name" validate:"omitempty,noforwardslash" form:"successor_name"`
+}
+
+// QueryForDeprecated filters the given query by the deprecated state of the
+// resources. If deprecated is nil, the query is returned unmodified.
+func QueryForDeprecated(q *gorm.DB, deprecated *bool) *gorm.DB {
+	if deprecated == nil {
+		return q
+	}
+	if *deprecated {
+		return q.Where("deprecated_at IS NOT NULL")
+	}
+	return q.Where("deprecated_at IS NULL")
+}
@@ -322,6 +322,19 @@ func CloneResourceRepo(ctx context.Context, res, clone Resource) (vcs.VCS, *gz.E
 // and the resource owner to formulate a database query to determine whether a
 // resource is visible to the user
 func QueryForResourceVisibility(tx, q *gorm.DB, owner *string, user *users.User) *gorm.DB {
+	return queryForVisibility(tx, q, owner, user, false)
+}
+
+// QueryForSharedResourceVisibility is like QueryForResourceVisibility, but it
+// also makes visible the private resources that were shared with the requestor
+// or with any of its teams. The queried table must have a 'uuid' column.
+func QueryForSharedResourceVisibility(tx, q *gorm.DB, owner *string, user *users.User) *gorm.DB {
+	return queryForVisibility(tx, q, owner, user, true)
+}
+
+// queryForVisibility formulates the visibility query. If shared is true, the
+// resources shared with the requestor are also visible.
+func queryForVisibility(tx, q *gorm.DB, owner *string, user *users.User, shared bool) *gorm.DB {
 	// Check resource visibility
 	publicOnly := false
 	// if owner is specified
@@ -342,15 +355,17 @@ func QueryForResourceVisibility(tx, q *gorm.DB, owner *string, user *users.User)
 					publicOnly = true
 				}
 			} else if *user.Username != *owner {
-				// if owner is not an org then this is another user's resource
-				// TODO check permissions when resource sharing is implemented
-				// but for now assume user can only acccess other user's public
-				// resources
+				// if owner is not an org then this is another user's resource,
+				// and the user can only access its public resources
 				publicOnly = true
 			}
 		}
 		if !publicOnly {
 			q = q.Where("owner = ?", *owner)
+		} else if shared && user != nil {
+			// private resources shared with the requestor are also visible
+			q = q.Where("owner = ? AND (private = ? OR uuid IN (?))", *owner, 0,
+				querySharedWith(tx, sharingSubjects(user)))
 		} else {
 			q = q.Where("owner = ? AND private = ?", *owner, 0)
 		}
@@ -361,33 +376,74 @@ func QueryForResourceVisibility(tx, q *gorm.DB, owner *string, user *users.User)
 		if user == nil {
 			q = q.Where("private = ?", 0)
 		} else {
-			userGroups := globals.Permissions.GetGroupsForUser(*user.Username)
-			userGroups = append(userGroups, *user.Username)
-			q = q.Where("private = ? OR owner IN (?)", 0, userGroups)
+			userGroups := sharingSubjects(user)
+			if shared {
+				q = q.Where("private = ? OR owner IN (?) OR uuid IN (?)", 0, userGroups,
+					querySharedWith(tx, userGroups))
+			} else {
+				q = q.Where("private = ? OR owner IN (?)", 0, userGroups)
+			}
 		}
 	}
 	return q
 }
 
-// MoveResource will move a resource's on-disk location from sourceOwner to destOwner.
-func MoveResource(resource Resource, destOwner string) *gz.ErrMsg {
+// sharingSubjects returns the groups (organizations and teams) of the user,
+// plus the user itself.
+func sharingSubjects(user *users.User) []string {
+	userGroups := globals.Permissions.GetGroupsForUser(*user.Username)
+	return append(userGroups, *user.Username)
+}
+
+// MovedResource is a resource whose owner and files were moved by
+// MoveResource. The move can be undone with Revert, if the change of owner
+// could not be saved.
+type MovedResource struct {
+	// The previous location of the resource files
+	From string
+	// The new location of the resource files
+	To string
+	// The moved resource and its previous owner
+	resource    Resource
+	sourceOwner string
+}
+
+// MoveResource will move a resource's on-disk location to the one of destOwner,
+// and change its owner to destOwner.
+func MoveResource(resource Resource, destOwner string) (*MovedResource, *gz.ErrMsg) {
 	searchStr := "/" + *resource.GetOwner() + "/"
 	replaceStr := "/" + destOwner + "/"
 	newLocation := strings.Replace(*resource.GetLocation(), searchStr, replaceStr, 1)
 
 	if newLocation == *resource.GetLocation() {
-		return gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil, []string{"Source and destination owners are identical"})
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil, []string{"Source and destination owners are identical"})
 	}
 
 	// Move resource on disk
+	if err := os.MkdirAll(filepath.Dir(newLocation), 0711); err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorCreatingDir, err)
+	}
 	if err := os.Rename(*resource.GetLocation(), newLocation); err != nil {
-		return gz.NewErrorMessageWithBase(gz.ErrorCreatingDir, err)
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorCreatingDir, err)
 	}
+	moved := &MovedResource{From: *resource.GetLocation(), To: newLocation,
+		resource: resource, sourceOwner: *resource.GetOwner()}
 
 	// Set the new location and owner
 	resource.SetLocation(newLocation)
 	resource.SetOwner(destOwner)
 
+	return moved, nil
+}
+
+// Revert moves the resource files back to their previous location, and
+// restores the previous owner of the resource.
+func (m *MovedResource) Revert() *gz.ErrMsg {
+	if err := os.Rename(m.To, m.From); err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorCreatingDir, err)
+	}
+	m.resource.SetLocation(m.From)
+	m.resource.SetOwner(m.sourceOwner)
 	return nil
 }
 
@@ -0,0 +1,32 @@
+package commonres
+
+import (
+	"github.com/gazebo-web/fuel-server/bundles/category"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+)
+
+// ListOptions are the options of the model and world lists. The zero value
+// lists all the resources visible to anonymous users, newest first.
+type ListOptions struct {
+	// The sort direction, "asc" or "desc"
+	Order string
+	// The sort criteria. If it is SortByRating, the best rated resources come
+	// first.
+	SortBy string
+	// The text to search for in the name, description and tags
+	Search string
+	// If set, only the resources liked by this user are listed
+	LikedBy *users.User
+	// The requesting user. It is nil for anonymous requests.
+	User *users.User
+	// If set, only the resources with one of these categories are listed. It is
+	// only used by models.
+	Categories *category.Categories
+	// If set, only the resources with this archived state are listed
+	Archived *bool
+	// If set, only the resources with this deprecated state are listed.
+	// Otherwise, deprecated resources rank lower.
+	Deprecated *bool
+	// If true, the memory cache is not used. It is only used by models.
+	IgnoreMemcache bool
+}
@@ -0,0 +1,20 @@
+package commonres
+
+import (
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/jinzhu/gorm"
+)
+
+// QueryForHidden excludes the resources hidden by the moderators, and the
+// resources of suspended users, from the given query. Hidden resources are
+// still listed to their owners and to the members of the owner organization.
+// The resources of suspended users are listed again once they are reinstated
+// or their suspension expires. The queried table must have the 'hidden' and
+// 'owner' columns.
+func QueryForHidden(q *gorm.DB, user *users.User) *gorm.DB {
+	q = q.Where("owner NOT IN (?)", users.QuerySuspendedUsernames(q.New()))
+	if user == nil {
+		return q.Where("hidden = ?", false)
+	}
+	return q.Where("hidden = ? OR owner IN (?)", false, sharingSubjects(user))
+}
@@ -0,0 +1,18 @@
+package commonres
+
+import "strings"
+
+// SortByRating is the value of the sort query parameter that sorts models and
+// worlds by their star ratings.
+const SortByRating = "rating"
+
+// RatingOrder returns the ORDER BY clauses that sort resources by their average
+// star rating, and then by their number of ratings. The best rated resources
+// come first, unless order is "asc".
+func RatingOrder(order string) []string {
+	dir := "desc"
+	if strings.ToLower(order) == "asc" {
+		dir = "asc"
+	}
+	return []string{"rating_average " + dir, "rating_count " + dir}
+}
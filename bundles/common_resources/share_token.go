@@ -0,0 +1,219 @@
+package commonres
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// ShareTokenScopeRead is the only scope currently supported by share tokens.
+// It allows downloading the zip, files and file tree of a resource.
+const ShareTokenScopeRead = "read"
+
+// shareTokenPrefixAttempts is the number of random prefixes tried when creating
+// a share token.
+const shareTokenPrefixAttempts = 5
+
+// MaxShareTokenDuration is the maximum time a share token can be valid.
+const MaxShareTokenDuration = 365 * 24 * time.Hour
+
+// ShareToken allows anyone holding it to read a private model or world, without
+// a Fuel account, until it expires or is revoked.
+// Only a hash of the token secret is stored. The full token is returned once,
+// when the token is created.
+//
+// swagger:model
+type ShareToken struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The UUID of the shared resource
+	ResourceUUID *string `gorm:"index" json:"-"`
+	// Optional description of the token, eg. who it was sent to
+	Name string `json:"name,omitempty"`
+	// The public part of the token. It is used to find the token and to help
+	// users identify it.
+	Prefix string `gorm:"unique_index" json:"prefix"`
+	// The hash of the secret part of the token
+	SecretHash string `json:"-"`
+	// The scope of the token. Only 'read' is supported.
+	Scope string `json:"scope"`
+	// The user that created the token
+	Creator *string `json:"creator"`
+	// Date and time the token expires
+	ExpiresAt time.Time `gorm:"type:timestamp(3) NULL" json:"expires_at"`
+}
+
+// ShareTokens is an array of ShareToken
+//
+// swagger:model
+type ShareTokens []ShareToken
+
+// CreateShareToken encapsulates the data required to create a share token.
+type CreateShareToken struct {
+	// Optional description of the token
+	Name string `json:"name" validate:"omitempty,max=255"`
+	// Date and time the token expires. It must be in the future and within a
+	// year.
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+	// The scope of the token. Defaults to 'read'.
+	Scope string `json:"scope" validate:"omitempty,oneof=read"`
+}
+
+// ShareTokenCreateResponse is the response of creating a share token. It is the
+// only time the full token is returned.
+//
+// swagger:model
+type ShareTokenCreateResponse struct {
+	ShareToken
+	// The token to use in the 'share_token' query parameter
+	Token string `json:"token"`
+}
+
+// shareTokenContextKey is the key used to store a share token in a context.
+type shareTokenContextKey struct{}
+
+// NewContextWithShareToken returns a new context that carries the given share
+// token.
+func NewContextWithShareToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, shareTokenContextKey{}, token)
+}
+
+// ShareTokenFromContext returns the share token stored in the context, if any.
+func ShareTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(shareTokenContextKey{}).(string)
+	return token, ok && token != ""
+}
+
+// hashShareTokenSecret returns the hex encoded hash of a share token secret.
+func hashShareTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHexString returns the hex encoding of n cryptographically secure random
+// bytes.
+func randomHexString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newShareTokenPrefix returns a random share token prefix that is not used by
+// other tokens. Collisions are unlikely, but the prefix is unique, so a few
+// prefixes are tried before giving up.
+func newShareTokenPrefix(tx *gorm.DB) (string, *gz.ErrMsg) {
+	for i := 0; i < shareTokenPrefixAttempts; i++ {
+		prefix, err := randomHexString(8)
+		if err != nil {
+			return "", gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+		if tx.Where("prefix = ?", prefix).First(&ShareToken{}).RecordNotFound() {
+			return prefix, nil
+		}
+	}
+	return "", gz.NewErrorMessageWithArgs(gz.ErrorUnexpected, nil,
+		[]string{"Failed to generate a unique share token prefix"})
+}
+
+// NewShareToken creates a share token for the given resource.
+func NewShareToken(ctx context.Context, tx *gorm.DB, res Resource, cst *CreateShareToken,
+	user *users.User) (*ShareTokenCreateResponse, *gz.ErrMsg) {
+
+	now := time.Now()
+	if !cst.ExpiresAt.After(now) || cst.ExpiresAt.After(now.Add(MaxShareTokenDuration)) {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"expires_at"})
+	}
+	if cst.Scope == "" {
+		cst.Scope = ShareTokenScopeRead
+	}
+
+	prefix, em := newShareTokenPrefix(tx)
+	if em != nil {
+		return nil, em
+	}
+	secret, err := randomHexString(24)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	token := ShareToken{
+		ResourceUUID: res.GetUUID(),
+		Name:         cst.Name,
+		Prefix:       prefix,
+		SecretHash:   hashShareTokenSecret(secret),
+		Scope:        cst.Scope,
+		Creator:      user.Username,
+		ExpiresAt:    cst.ExpiresAt,
+	}
+	if err := tx.Create(&token).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Share token created. Owner:[%s] Name:[%s] Prefix:[%s]",
+		*res.GetOwner(), *res.GetName(), prefix))
+	return &ShareTokenCreateResponse{ShareToken: token, Token: prefix + "." + secret}, nil
+}
+
+// GetShareTokens returns the share tokens of a resource, including the expired
+// ones.
+func GetShareTokens(tx *gorm.DB, res Resource) (*ShareTokens, *gz.ErrMsg) {
+	var list ShareTokens
+	if err := tx.Where("resource_uuid = ?", *res.GetUUID()).Order("id").Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return &list, nil
+}
+
+// RevokeShareToken removes the share token of a resource with the given prefix.
+// Returns the removed token.
+func RevokeShareToken(ctx context.Context, tx *gorm.DB, res Resource,
+	prefix string) (*ShareToken, *gz.ErrMsg) {
+
+	var token ShareToken
+	if tx.Where("resource_uuid = ? AND prefix = ?", *res.GetUUID(), prefix).First(&token).RecordNotFound() {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNonExistentResource, nil, []string{prefix})
+	}
+	if err := tx.Delete(&token).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Share token revoked. Owner:[%s] Name:[%s] Prefix:[%s]",
+		*res.GetOwner(), *res.GetName(), prefix))
+	return &token, nil
+}
+
+// CanReadWithShareToken returns true if the context carries a share token that
+// grants read access to the given resource. The token must not be expired.
+func CanReadWithShareToken(ctx context.Context, tx *gorm.DB, res Resource) bool {
+	value, ok := ShareTokenFromContext(ctx)
+	if !ok {
+		return false
+	}
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	var token ShareToken
+	if err := tx.Where("resource_uuid = ? AND prefix = ? AND expires_at > ?",
+		*res.GetUUID(), parts[0], time.Now()).First(&token).Error; err != nil {
+		return false
+	}
+	hash := hashShareTokenSecret(parts[1])
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(token.SecretHash)) == 1 &&
+		token.Scope == ShareTokenScopeRead
+}
@@ -0,0 +1,108 @@
+package commonres
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Status of a transfer request.
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferRejected  = "rejected"
+	TransferCancelled = "cancelled"
+	// TransferExpired is not stored. It is reported for pending transfer
+	// requests that are past their expiration date.
+	TransferExpired = "expired"
+)
+
+// DefaultTransferExpiryDays is the number of days a transfer request can be
+// accepted, when not set by the source owner.
+const DefaultTransferExpiryDays = 14
+
+// TransferRequest is a proposal to transfer a model, world or collection to
+// another user or organization. The resource is only moved once the
+// destination owner accepts the request.
+type TransferRequest struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL"`
+	UpdatedAt time.Time
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The resource type: model, world or collection
+	ResourceType string `gorm:"not null"`
+	// The UUID of the resource
+	ResourceUUID string `gorm:"not null;index"`
+	// The name of the resource when the transfer was requested
+	ResourceName string `gorm:"not null"`
+	// The current owner of the resource
+	SourceOwner string `gorm:"not null;index"`
+	// The user or organization that will own the resource
+	DestOwner string `gorm:"not null;index"`
+	// The username of the user that requested the transfer
+	Requestor string
+	// The status of the request: pending, accepted, rejected or cancelled
+	Status string `gorm:"not null;index"`
+	// Date and time after which the request can no longer be accepted
+	ExpiresAt time.Time `gorm:"type:timestamp(3) NULL"`
+}
+
+// TransferRequestResponse is a transfer request, as returned in REST
+// responses.
+//
+// swagger:model
+type TransferRequestResponse struct {
+	ID           uint      `json:"id"`
+	ResourceType string    `json:"resource_type"`
+	ResourceName string    `json:"resource_name"`
+	SourceOwner  string    `json:"source_owner"`
+	DestOwner    string    `json:"dest_owner"`
+	Requestor    string    `json:"requestor"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// TransferRequestResponses is a slice of TransferRequestResponse
+//
+// swagger:model
+type TransferRequestResponses []TransferRequestResponse
+
+// CreateTransferRequest encapsulates data required to propose the transfer of
+// a resource.
+type CreateTransferRequest struct {
+	// The user or organization that will own the resource
+	// required: true
+	DestOwner string `json:"destOwner" validate:"required"`
+	// Optional number of days the request can be accepted. Default 14.
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=60"`
+}
+
+// NewTransferRequestResponse creates a response from a transfer request.
+// Pending requests past their expiration date are reported as expired.
+func NewTransferRequestResponse(tr *TransferRequest) TransferRequestResponse {
+	status := tr.Status
+	if status == TransferPending && tr.ExpiresAt.Before(time.Now()) {
+		status = TransferExpired
+	}
+	return TransferRequestResponse{
+		ID:           tr.ID,
+		ResourceType: tr.ResourceType,
+		ResourceName: tr.ResourceName,
+		SourceOwner:  tr.SourceOwner,
+		DestOwner:    tr.DestOwner,
+		Requestor:    tr.Requestor,
+		Status:       status,
+		CreatedAt:    tr.CreatedAt,
+		ExpiresAt:    tr.ExpiresAt,
+	}
+}
+
+// QueryForPendingTransfers returns a gorm query configured to find the pending
+// and not expired transfer requests.
+func QueryForPendingTransfers(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&TransferRequest{}).Where("status = ? AND expires_at > ?",
+		TransferPending, time.Now())
+}
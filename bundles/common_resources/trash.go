@@ -0,0 +1,187 @@
+package commonres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/activity"
+	"github.com/gazebo-web/fuel-server/bundles/comments"
+	"github.com/gazebo-web/fuel-server/bundles/events"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gazebo-web/gz-go/v7/storage"
+	"github.com/jinzhu/gorm"
+)
+
+// DefaultTrashRetentionDays is the number of days a soft-deleted resource can
+// be restored, when IGN_FUEL_TRASH_RETENTION_DAYS is not set.
+const DefaultTrashRetentionDays = 30
+
+// TrashItem describes a soft-deleted resource that can still be restored.
+//
+// swagger:model
+type TrashItem struct {
+	// The resource type: model, world or collection
+	Type string `json:"type"`
+	// The name of the resource
+	Name string `json:"name"`
+	// The owner of the resource
+	Owner string `json:"owner"`
+	// Date and time the resource was deleted
+	DeletedAt time.Time `json:"deleted_at"`
+	// Date and time after which the resource can no longer be restored
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TrashItems is a list of TrashItem
+//
+// swagger:model
+type TrashItems []TrashItem
+
+// RestoreResource encapsulates data required to restore a deleted resource.
+type RestoreResource struct {
+	// Optional new name for the restored resource. Used when the original name
+	// is already taken by another resource.
+	Name string `json:"name" validate:"omitempty,noforwardslash,min=3,nopercent"`
+}
+
+// storageRemover is an optional interface that can be implemented by storage
+// backends able to permanently remove the uploaded files of a resource.
+type storageRemover interface {
+	Remove(ctx context.Context, resource storage.Resource) error
+}
+
+// trashCutoff returns the time before which soft-deleted resources are
+// considered expired.
+func trashCutoff() time.Time {
+	return time.Now().Add(-globals.TrashRetention)
+}
+
+// NewTrashItem creates a TrashItem for the given resource type, name, owner and
+// deletion date.
+func NewTrashItem(resType, name, owner string, deletedAt time.Time) TrashItem {
+	return TrashItem{Type: resType, Name: name, Owner: owner, DeletedAt: deletedAt,
+		ExpiresAt: deletedAt.Add(globals.TrashRetention)}
+}
+
+// QueryForTrash returns a gorm query configured to find the soft-deleted
+// resources of an owner that are still within the trash retention window.
+func QueryForTrash(q *gorm.DB, owner string) *gorm.DB {
+	return q.Unscoped().Where("owner = ? AND deleted_at IS NOT NULL AND deleted_at > ?",
+		owner, trashCutoff()).Order("deleted_at desc")
+}
+
+// QueryForExpiredTrash returns a gorm query configured to find all the
+// soft-deleted resources that are past the trash retention window.
+func QueryForExpiredTrash(q *gorm.DB) *gorm.DB {
+	return q.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at <= ?", trashCutoff())
+}
+
+// Restore brings back a soft-deleted resource, using the given name.
+// The resource owner gets back its read and write permissions.
+// It is the responsibility of the caller to check the new name is not in use.
+func Restore(tx *gorm.DB, res Resource, name string) *gz.ErrMsg {
+	if err := tx.Unscoped().Model(res).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"name":       name,
+	}).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	// Permissions were removed when the resource was deleted.
+	owner := *res.GetOwner()
+	if _, err := globals.Permissions.AddPermission(owner, *res.GetUUID(), permissions.Read); err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	if _, err := globals.Permissions.AddPermission(owner, *res.GetUUID(), permissions.Write); err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	return restoreCollaborators(tx, res)
+}
+
+// PurgedResource is a resource removed from the database by Purge. Its files
+// are removed with RemoveFiles once the purge transaction is committed, as a
+// rollback cannot restore them.
+type PurgedResource struct {
+	Resource Resource
+	// The resource type folder for the user (eg. models, worlds)
+	Subfolder string
+	// The storage of the uploaded zip files. It can be nil.
+	Storage storage.Storage
+}
+
+// Purge permanently removes a soft-deleted resource from the database, with the
+// rows that reference it by UUID: collaborators, share tokens, transfer
+// requests, comments, watches, events and activities.
+// subfolder arg is the resource type folder for the user (eg. models, worlds).
+// Rows referencing the resource by ID should be removed by the caller
+// beforehand. The files of the resource are not removed. Call RemoveFiles on
+// the returned PurgedResource once the transaction is committed.
+func Purge(tx *gorm.DB, res Resource, subfolder string,
+	s storage.Storage) (*PurgedResource, *gz.ErrMsg) {
+
+	// Collaborator permissions were removed when the resource was deleted.
+	rows := []interface{}{
+		&Collaborator{},
+		&ShareToken{},
+		&TransferRequest{},
+		&comments.Comment{},
+		&comments.Thread{},
+		&notifications.Watch{},
+		&events.Event{},
+		&activity.Activity{},
+	}
+	for _, row := range rows {
+		if err := tx.Unscoped().Where("resource_uuid = ?", *res.GetUUID()).Delete(row).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	}
+	// Also remove the activities that added or removed it from collections.
+	if err := tx.Where("asset_uuid = ?", *res.GetUUID()).Delete(&activity.Activity{}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+
+	// Remove the resource from the database (hard-delete).
+	if err := tx.Unscoped().Delete(res).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return &PurgedResource{Resource: res, Subfolder: subfolder, Storage: s}, nil
+}
+
+// RemoveFiles removes the repository, the zip files and the uploaded storage
+// objects of a purged resource. The resource is already gone from the
+// database, so errors are logged and the remaining files are still removed.
+func (p *PurgedResource) RemoveFiles(ctx context.Context) {
+	res := p.Resource
+	// Remove the uploaded zip files, one per version.
+	if remover, ok := p.Storage.(storageRemover); ok && res.GetLocation() != nil {
+		if latest, err := GetLatestVersion(ctx, res); err == nil {
+			for v := 1; v <= latest; v++ {
+				if err := remover.Remove(ctx, CastResourceToStorageResource(res, uint64(v))); err != nil {
+					gz.LoggerFromContext(ctx).Error("Unable to remove storage object: ", *res.GetUUID(), v, err)
+				}
+			}
+		}
+	}
+
+	// Remove the local zip files
+	zipsFolder := filepath.Join(globals.ResourceDir, *res.GetOwner(), p.Subfolder, ".zips")
+	zips, _ := filepath.Glob(filepath.Join(zipsFolder, strings.ReplaceAll(*res.GetUUID(), " ", "_")+"*.zip"))
+	for _, z := range zips {
+		if err := os.Remove(z); err != nil {
+			gz.LoggerFromContext(ctx).Error("Unable to remove zip file: ", z)
+		}
+	}
+
+	// Remove the repository
+	if res.GetLocation() != nil {
+		if err := os.RemoveAll(*res.GetLocation()); err != nil {
+			gz.LoggerFromContext(ctx).Error("Unable to remove repository: ", *res.GetLocation(), err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Retention is how long events are kept. Clients reconnecting with a
+// Last-Event-ID older than that miss the events in between.
+const Retention = 24 * time.Hour
+
+// Event is a lifecycle event of a model, world or collection, such as its
+// creation, a new version or a like. Events are stored so that every server
+// instance can stream them to its subscribers once the transaction that
+// produced them is committed.
+//
+// swagger:model ResourceEvent
+type Event struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL;index" json:"created_at"`
+	// UpdatedAt and DeletedAt are not included. Events cannot be modified.
+
+	// The event, eg. model.create
+	Event string `gorm:"not null" json:"event"`
+	// The username of the user that caused the event, if known
+	Actor string `json:"actor,omitempty"`
+	// The resource type: model, world or collection
+	ResourceType string `gorm:"not null" json:"resource_type"`
+	// The owner of the resource
+	Owner string `gorm:"not null;index" json:"owner"`
+	// The name of the resource
+	Name string `gorm:"not null" json:"name"`
+	// The UUID of the resource, used to check the visibility of private
+	// resources
+	ResourceUUID string `gorm:"not null" json:"-"`
+	// Whether the resource is private
+	Private bool `gorm:"not null" json:"private"`
+	// Event specific data, as JSON
+	Details string `gorm:"type:text" json:"details,omitempty"`
+}
+
+// Events is a slice of Event
+//
+// swagger:model ResourceEvents
+type Events []Event
+
+// Filter selects the events a subscriber is interested in. Empty fields
+// match any value.
+type Filter struct {
+	Owner        string
+	ResourceType string
+	Name         string
+}
+
+// Match returns true if the given event passes the filter.
+func (f *Filter) Match(e *Event) bool {
+	return (f.Owner == "" || f.Owner == e.Owner) &&
+		(f.ResourceType == "" || f.ResourceType == e.ResourceType) &&
+		(f.Name == "" || f.Name == e.Name)
+}
+
+// Record stores an event.
+func Record(tx *gorm.DB, e *Event) *gz.ErrMsg {
+	if err := tx.Create(e).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// LastID returns the ID of the newest event, or 0 if there are no events.
+func LastID(tx *gorm.DB) (uint, *gz.ErrMsg) {
+	var ids []uint
+	if err := tx.Model(&Event{}).Order("id desc").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// After returns up to limit events newer than the given event ID, oldest
+// first.
+func After(tx *gorm.DB, id uint, limit int) (Events, *gz.ErrMsg) {
+	var list Events
+	if err := tx.Where("id > ?", id).Order("id").Limit(limit).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return list, nil
+}
+
+// PurgeExpired removes the events older than the Retention.
+func PurgeExpired(tx *gorm.DB) *gz.ErrMsg {
+	if err := tx.Where("created_at < ?", time.Now().Add(-Retention)).Delete(&Event{}).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return nil
+}
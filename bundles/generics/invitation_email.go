@@ -0,0 +1,57 @@
+package generics
+
+import (
+	"fmt"
+	"github.com/gazebo-web/gz-go/v7"
+	"net/http"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/globals"
+)
+
+// SendInvitationEmail notifies a user about an invitation to join an
+// organization. The username is used to link to the user's pending
+// invitations. It can be empty for invitations sent by email to people without
+// an account. In that case, the email links to the acceptance of the
+// invitation with the given id and token, as the token is the only proof of
+// owning the address.
+func SendInvitationEmail(recipient, username, organization, inviter, role string,
+	id uint, token string, expiresAt time.Time, r *http.Request) *gz.ErrMsg {
+
+	// Don't fall back to the default recipient. The invitation is private.
+	if recipient == "" {
+		return nil
+	}
+
+	subject := fmt.Sprintf("You have been invited to join %s", organization)
+
+	var scheme = "http"
+	if globals.Server.IsUsingSSL() {
+		scheme = "https"
+	}
+	link := fmt.Sprintf("%s://%s", scheme, r.Host)
+	if username != "" {
+		link = fmt.Sprintf("%s/%s/users/%s/invitations", link, globals.APIVersion, username)
+	} else if token != "" {
+		link = fmt.Sprintf("%s/%s/users/{username}/invitations/%d/accept?token=%s", link,
+			globals.APIVersion, id, token)
+	}
+
+	templateFilename := "templates/email/organization_invitation.html"
+
+	templateData := struct {
+		Organization string
+		Inviter      string
+		Role         string
+		Link         string
+		ExpiresAt    string
+	}{
+		Organization: organization,
+		Inviter:      inviter,
+		Role:         role,
+		Link:         link,
+		ExpiresAt:    expiresAt.Format(time.RFC1123),
+	}
+
+	return SendEmail(&recipient, nil, subject, templateFilename, templateData)
+}
@@ -0,0 +1,32 @@
+package generics
+
+import (
+	"fmt"
+	"github.com/gazebo-web/gz-go/v7"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/globals"
+)
+
+// SendNotificationEmail emails a notification about a watched resource to a
+// user. The username is used to point to the user's notifications.
+func SendNotificationEmail(recipient, username, subject, message string) *gz.ErrMsg {
+	// Don't fall back to the default recipient. Notifications are private.
+	if recipient == "" {
+		return nil
+	}
+
+	templateFilename := "templates/email/notification.html"
+
+	templateData := struct {
+		Message string
+		Path    string
+		Time    string
+	}{
+		Message: message,
+		Path:    fmt.Sprintf("/%s/users/%s/notifications", globals.APIVersion, username),
+		Time:    time.Now().String(),
+	}
+
+	return SendEmail(&recipient, nil, subject, templateFilename, templateData)
+}
@@ -9,4 +9,7 @@ type Report struct {
 	gorm.Model
 	// Reason is the justification why the resource was reported
 	Reason *string `gorm:"type:text" json:"reason,omitempty"`
+	// ReporterID is the ID of the user that reported the resource. It is nil
+	// for anonymous reports.
+	ReporterID *uint `gorm:"index" json:"-"`
 }
@@ -0,0 +1,40 @@
+package generics
+
+import (
+	"fmt"
+	"github.com/gazebo-web/gz-go/v7"
+	"net/http"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/globals"
+)
+
+// SendTransferEmail notifies an owner about a transfer request. The message
+// describes what happened to the request, and the owner is used to link to its
+// pending transfers.
+func SendTransferEmail(recipient, owner, subject, message string, r *http.Request) *gz.ErrMsg {
+	// Don't fall back to the default recipient. Transfers are private.
+	if recipient == "" {
+		return nil
+	}
+
+	var scheme = "http"
+	if globals.Server.IsUsingSSL() {
+		scheme = "https"
+	}
+	link := fmt.Sprintf("%s://%s/%s/%s/transfers", scheme, r.Host, globals.APIVersion, owner)
+
+	templateFilename := "templates/email/transfer_request.html"
+
+	templateData := struct {
+		Message string
+		Link    string
+		Time    string
+	}{
+		Message: message,
+		Link:    link,
+		Time:    time.Now().String(),
+	}
+
+	return SendEmail(&recipient, nil, subject, templateFilename, templateData)
+}
@@ -62,7 +62,7 @@ type Model struct {
 	URLName *string `json:"url_name,omitempty"`
 
 	// Unique identifier for the the model
-	UUID *string `json:"-"`
+	UUID *string `gorm:"index" json:"-"`
 
 	// A description of the model (max 65,535 chars)
 	// Interesting post about TEXT vs VARCHAR(30000) performance:
@@ -72,6 +72,12 @@ type Model struct {
 	// Number of likes
 	Likes int `json:"likes,omitempty"`
 
+	// Average of the star ratings of all the versions, from 1 to 5
+	RatingAverage float64 `gorm:"not null;default:0" json:"rating_average,omitempty"`
+
+	// Number of star ratings
+	RatingCount int `gorm:"not null;default:0" json:"rating_count,omitempty"`
+
 	// Bytes of the model, when downloaded as a zip
 	Filesize int `json:"filesize,omitempty"`
 
@@ -109,8 +115,29 @@ type Model struct {
 	// Private - True to make this a private resource
 	Private *bool `json:"private,omitempty"`
 
+	// Archived - True to make this a read-only resource
+	Archived *bool `gorm:"default:false" json:"archived,omitempty"`
+
+	// Hidden - True if a moderator hid this model from lists and search results
+	Hidden bool `gorm:"not null;default:false" json:"hidden,omitempty"`
+
+	// DeprecatedAt is the date and time the model was deprecated, if it is.
+	DeprecatedAt *time.Time `gorm:"type:timestamp(3) NULL" json:"deprecated_at,omitempty"`
+
+	// DeprecationMessage is an optional message explaining the deprecation.
+	DeprecationMessage *string `gorm:"type:text" json:"deprecation_message,omitempty"`
+
+	// SuccessorID is the ID of the model replacing this deprecated model, if any.
+	SuccessorID *uint `json:"-"`
+
 	// Categories associated to this model
 	Categories category.Categories `gorm:"many2many:model_categories;" json:"categories,omitempty"`
+
+	// ForkedFromID is the ID of the model this model was cloned from, if any.
+	ForkedFromID *uint `sql:"index" json:"-"`
+
+	// ForkedFromVersion is the version of the source model at the time of cloning.
+	ForkedFromVersion *int `json:"-"`
 }
 
 // GetID returns the ID
@@ -166,6 +193,15 @@ func GetModelByName(tx *gorm.DB, modelName string, owner string) (*Model, error)
 	return &model, nil
 }
 
+// GetModelByID queries a Model by its ID.
+func GetModelByID(tx *gorm.DB, id uint) (*Model, error) {
+	var model Model
+	if err := QueryForModels(tx).Where("id = ?", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
 // NewModelAndUUID creates a Model struct with a new UUID.
 func NewModelAndUUID(name, urlName, desc, location, owner, creator *string, lic license.License, permission int, tags Tags, private bool, categories *category.Categories, metadata *ModelMetadata) (Model, error) {
 	uuidStr, _, err := users.NewUUID(*owner, models)
@@ -250,6 +286,9 @@ type CloneModel struct {
 
 // UpdateModel encapsulates data that can be updated in a model
 type UpdateModel struct {
+	// Optional new name. The previous name is kept as an alias that redirects
+	// to the new one.
+	Name *string `json:"name" validate:"omitempty,noforwardslash,min=3,nopercent" form:"name"`
 	// Optional description
 	Description *string `json:"description" form:"description"`
 	// Optional list of tags (comma separated)
@@ -258,6 +297,8 @@ type UpdateModel struct {
 	File string `json:"file" validate:"omitempty,gt=0" form:"-"`
 	// Private privacy/visibility setting
 	Private *bool `json:"private" validate:"omitempty" form:"private"`
+	// Archived read-only setting
+	Archived *bool `json:"archived" validate:"omitempty" form:"archived"`
 	// Metadata associated to this model
 	Metadata *ModelMetadata `json:"metadata" form:"metadata"`
 	// Optional pair of categories (comma separated)
@@ -271,5 +312,5 @@ type CreateReport struct {
 
 // IsEmpty returns true is the struct is empty.
 func (um UpdateModel) IsEmpty() bool {
-	return um.Description == nil && um.Tags == nil
+	return um.Name == nil && um.Description == nil && um.Tags == nil && um.Archived == nil
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ModelAlias records a previous name of a renamed model. It is used to
+// redirect requests made to the old name to the current one.
+type ModelAlias struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL"`
+	UpdatedAt time.Time
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The ID of the renamed model
+	ModelID uint `sql:"index"`
+
+	// The owner of the model at the time it was renamed
+	Owner *string `gorm:"unique_index:idx_model_alias"`
+
+	// The previous name of the model
+	Name *string `gorm:"unique_index:idx_model_alias"`
+}
+
+// GetModelByAlias queries a Model by one of its previous names and owner.
+func GetModelByAlias(tx *gorm.DB, name string, owner string) (*Model, error) {
+	var alias ModelAlias
+	if err := tx.Where("owner = ? AND name = ?", owner, name).First(&alias).Error; err != nil {
+		return nil, err
+	}
+	return GetModelByID(tx, alias.ModelID)
+}
+
+// removeModelAlias removes the alias with the given owner and name, if any.
+// It is used when a model takes a name that was previously used as an alias.
+func removeModelAlias(tx *gorm.DB, owner, name string) error {
+	return tx.Where("owner = ? AND name = ?", owner, name).Delete(&ModelAlias{}).Error
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
 	"github.com/gazebo-web/fuel-server/bundles/category"
+	res "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/jinzhu/gorm"
@@ -28,7 +29,12 @@ type modelElastic struct {
 	Tags        string `json:"tags,omitempty"`
 	Categories  string `json:"categories"`
 	Creator     string `json:"creator"`
-	Collections string `json:"collections"`
+	Archived    bool   `json:"archived"`
+	Deprecated  bool   `json:"deprecated"`
+	// The average star rating and the number of ratings, to sort by rating
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
+	Collections   string  `json:"collections"`
 }
 
 // ElasticSearchRemoveModel removes a model from elastic search
@@ -92,13 +98,17 @@ func ElasticSearchUpdateModel(ctx context.Context, tx *gorm.DB, model Model) {
 
 	// Build the ElasticSearch struct.
 	m := modelElastic{
-		Name:        *model.Name,
-		Owner:       *model.Owner,
-		Creator:     *model.Creator,
-		Description: *model.Description,
-		Tags:        tags,
-		Categories:  categories,
-		Collections: collectionBuilder.String(),
+		Name:          *model.Name,
+		Owner:         *model.Owner,
+		Creator:       *model.Creator,
+		Archived:      res.IsArchived(model.Archived),
+		Deprecated:    model.DeprecatedAt != nil,
+		RatingAverage: model.RatingAverage,
+		RatingCount:   model.RatingCount,
+		Description:   *model.Description,
+		Tags:          tags,
+		Categories:    categories,
+		Collections:   collectionBuilder.String(),
 	}
 
 	// Add in metadata
@@ -49,6 +49,23 @@ func (ms *Service) GetModel(tx *gorm.DB, owner, name string,
 	return model, nil
 }
 
+// getModelForDownload returns a model by its name and owner's name, to read its
+// files. Private models can also be read with a valid share token carried
+// by the context, even without a user.
+func (ms *Service) getModelForDownload(ctx context.Context, tx *gorm.DB, owner, name string,
+	user *users.User) (*Model, *gz.ErrMsg) {
+
+	model, em := ms.GetModel(tx, owner, name, user)
+	if em == nil || em.ErrCode != gz.ErrorUnauthorized {
+		return model, em
+	}
+	shared, err := GetModelByName(tx, name, owner)
+	if err != nil || !res.CanReadWithShareToken(ctx, tx, shared) {
+		return nil, em
+	}
+	return shared, nil
+}
+
 // GetModelProto returns a model proto struct, given a model name and owner.
 // The user argument is the user requesting the operation.
 func (ms *Service) GetModelProto(ctx context.Context, tx *gorm.DB, owner,
@@ -70,6 +87,8 @@ func (ms *Service) GetModelProto(ctx context.Context, tx *gorm.DB, owner,
 
 	fuelModel := ms.ModelToProto(model)
 	fuelModel.Version = proto.Int64(int64(latestVersion))
+	fuelModel.ForkedFrom = ms.forkedFromProto(tx, model, user)
+	fuelModel.Successor = ms.SuccessorProto(tx, model, user)
 
 	if user != nil {
 		if ml, _ := ms.getModelLike(tx, model, user); ml != nil {
@@ -80,14 +99,17 @@ func (ms *Service) GetModelProto(ctx context.Context, tx *gorm.DB, owner,
 	return fuelModel, nil
 }
 
-// ModelList returns a paginated list of models.
-// If the likedBy argument is set, it will return the list of models liked by a user.
+// ModelList returns a paginated list of models, filtered and sorted with the
+// given options. See res.ListOptions.
+// Models hidden by moderators are only listed to their owners.
 // This function returns a list of fuel.Model that can then be mashalled into json or protobuf.
 // TODO: find a way to MERGE this with the one from Worlds service.
 func (ms *Service) ModelList(p *gz.PaginationRequest, tx *gorm.DB, owner *string,
-	order, search string, likedBy *users.User, user *users.User, categories *category.Categories, ignoreMemcache bool) (*fuel.Models, *gz.PaginationResult, *gz.ErrMsg) {
+	opts res.ListOptions) (*fuel.Models, *gz.PaginationResult, *gz.ErrMsg) {
 
-	basicQuery := isbasicModelListQuery(p, owner, order, search, likedBy, ignoreMemcache)
+	order, sortBy, search, likedBy, user := opts.Order, opts.SortBy, opts.Search, opts.LikedBy, opts.User
+	categories, archived, deprecated := opts.Categories, opts.Archived, opts.Deprecated
+	basicQuery := isbasicModelListQuery(p, owner, opts)
 
 	paginationCacheKey := "models_list_pagination"
 	modelsCacheKey := "models_list_models"
@@ -104,7 +126,8 @@ func (ms *Service) ModelList(p *gz.PaginationRequest, tx *gorm.DB, owner *string
 
 	var modelList Models
 	// Create query
-	q := QueryForModels(tx)
+	q := res.QueryForDeprecated(res.QueryForArchived(QueryForModels(tx), archived), deprecated)
+	q = res.QueryForHidden(q, user)
 	var categoryIds []uint
 	if categories != nil && len(*categories) > 0 {
 		for _, c := range *categories {
@@ -121,11 +144,21 @@ func (ms *Service) ModelList(p *gz.PaginationRequest, tx *gorm.DB, owner *string
 		}
 	}
 
+	// Deprecated models rank lower, unless they were explicitly requested.
+	var orderBy []string
+	if deprecated == nil {
+		orderBy = append(orderBy, "deprecated_at IS NOT NULL")
+	}
+	if sortBy == res.SortByRating {
+		orderBy = append(orderBy, res.RatingOrder(order)...)
+	}
 	// Override default Order BY, unless the user explicitly requested ASC order
 	if !(order != "" && strings.ToLower(order) == "asc") {
-		// Important: you need to reassign 'q' to keep the updated query
-		q = q.Order("created_at desc, id", true)
+		orderBy = append(orderBy, "created_at desc")
 	}
+	orderBy = append(orderBy, "id")
+	// Important: you need to reassign 'q' to keep the updated query
+	q = q.Order(strings.Join(orderBy, ", "), true)
 
 	// Check if we should return the list of liked models instead.
 	if likedBy != nil {
@@ -133,7 +166,7 @@ func (ms *Service) ModelList(p *gz.PaginationRequest, tx *gorm.DB, owner *string
 	} else {
 
 		// filter resources based on privacy setting
-		q = res.QueryForResourceVisibility(tx, q, owner, user)
+		q = res.QueryForSharedResourceVisibility(tx, q, owner, user)
 
 		// If a search criteria was defined, then also apply a fulltext search on "models + tags"
 		if search != "" {
@@ -174,8 +207,12 @@ func (ms *Service) ModelList(p *gz.PaginationRequest, tx *gorm.DB, owner *string
 
 	var modelsProto fuel.Models
 	// Encode models into a protobuf message
+	blankQuery := tx.New()
+	related := relatedModels(blankQuery, modelList)
 	for _, model := range modelList {
 		fuelModel := ms.ModelToProto(&model)
+		fuelModel.ForkedFrom = forkedFromProto(blankQuery, &model, related, user)
+		fuelModel.Successor = successorProto(blankQuery, &model, related, user)
 		modelsProto.Models = append(modelsProto.Models, fuelModel)
 	}
 
@@ -219,7 +256,7 @@ func (ms *Service) RemoveModel(ctx context.Context, tx *gorm.DB, owner, modelNam
 	}
 
 	// make sure the user requesting removal has the correct permissions
-	ok, err := globals.Permissions.IsAuthorized(*user.Username, *model.UUID, permissions.Write)
+	ok, err := globals.Permissions.CanPerformAsOwner(*user.Username, *model.Owner, permissions.Delete)
 	if !ok {
 		return err
 	}
@@ -244,15 +281,17 @@ func (ms *Service) RemoveModel(ctx context.Context, tx *gorm.DB, owner, modelNam
 func (ms *Service) ModelToProto(model *Model) *fuel.Model {
 	fuelModel := fuel.Model{
 		// Note: time.RFC3339 is the format expected by Go's JSON unmarshal
-		CreatedAt:  proto.String(model.CreatedAt.UTC().Format(time.RFC3339)),
-		UpdatedAt:  proto.String(model.UpdatedAt.UTC().Format(time.RFC3339)),
-		Name:       proto.String(*model.Name),
-		Owner:      proto.String(*model.Owner),
-		Likes:      proto.Int64(int64(model.Likes)),
-		Downloads:  proto.Int64(int64(model.Downloads)),
-		Filesize:   proto.Int64(int64(model.Filesize)),
-		Permission: proto.Int64(int64(model.Permission)),
-		LicenseId:  proto.Uint64(uint64(model.LicenseID)),
+		CreatedAt:     proto.String(model.CreatedAt.UTC().Format(time.RFC3339)),
+		UpdatedAt:     proto.String(model.UpdatedAt.UTC().Format(time.RFC3339)),
+		Name:          proto.String(*model.Name),
+		Owner:         proto.String(*model.Owner),
+		Likes:         proto.Int64(int64(model.Likes)),
+		Downloads:     proto.Int64(int64(model.Downloads)),
+		Filesize:      proto.Int64(int64(model.Filesize)),
+		Permission:    proto.Int64(int64(model.Permission)),
+		LicenseId:     proto.Uint64(uint64(model.LicenseID)),
+		RatingAverage: proto.Float64(model.RatingAverage),
+		RatingCount:   proto.Int64(int64(model.RatingCount)),
 	}
 
 	// Optional fields
@@ -286,6 +325,15 @@ func (ms *Service) ModelToProto(model *Model) *fuel.Model {
 	if model.Private != nil {
 		fuelModel.Private = proto.Bool(*model.Private)
 	}
+	if model.Archived != nil {
+		fuelModel.Archived = proto.Bool(*model.Archived)
+	}
+	if model.DeprecatedAt != nil {
+		fuelModel.Deprecated = proto.Bool(true)
+		if model.DeprecationMessage != nil {
+			fuelModel.DeprecationMessage = proto.String(*model.DeprecationMessage)
+		}
+	}
 
 	if len(model.Tags) > 0 {
 		tags := []string{}
@@ -332,7 +380,7 @@ func (ms *Service) ModelToProto(model *Model) *fuel.Model {
 func (ms *Service) ModelFileTree(ctx context.Context, tx *gorm.DB, owner, modelName,
 	version string, user *users.User) (*fuel.FileTree, *gz.ErrMsg) {
 
-	model, em := ms.GetModel(tx, owner, modelName, user)
+	model, em := ms.getModelForDownload(ctx, tx, owner, modelName, user)
 	if em != nil {
 		return nil, em
 	}
@@ -374,8 +422,10 @@ func (ms *Service) CreateModelLike(tx *gorm.DB, owner, modelName string, user *u
 	return &modelLike, nil
 }
 
-// CreateModelReport creates a ModelReport
-func (ms *Service) CreateModelReport(tx *gorm.DB, owner, modelName, reason string) (*ModelReport, *gz.ErrMsg) {
+// CreateModelReport creates a ModelReport. The reporter is nil for anonymous
+// reports.
+func (ms *Service) CreateModelReport(tx *gorm.DB, owner, modelName, reason string,
+	reporter *users.User) (*ModelReport, *gz.ErrMsg) {
 	model, err := GetModelByName(tx, modelName, owner)
 
 	if err != nil {
@@ -388,6 +438,9 @@ func (ms *Service) CreateModelReport(tx *gorm.DB, owner, modelName, reason strin
 		},
 		ModelID: &model.ID,
 	}
+	if reporter != nil {
+		modelReport.ReporterID = &reporter.ID
+	}
 
 	if err = tx.Create(&modelReport).Error; err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
@@ -396,6 +449,18 @@ func (ms *Service) CreateModelReport(tx *gorm.DB, owner, modelName, reason strin
 	return &modelReport, nil
 }
 
+// CountModelReporters returns the number of distinct users that reported a
+// model since the given time. Anonymous reports are not counted.
+func (ms *Service) CountModelReporters(tx *gorm.DB, model *Model, since time.Time) (int, *gz.ErrMsg) {
+	var count int
+	if err := tx.Model(&ModelReport{}).
+		Where("model_id = ? AND reporter_id IS NOT NULL AND created_at >= ?", model.ID, since).
+		Select("COUNT(DISTINCT(reporter_id))").Count(&count).Error; err != nil {
+		return 0, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return count, nil
+}
+
 // RemoveModelLike removes a ModelLike.
 // Returns the removed modelLike or a gz.errMsg.
 func (ms *Service) RemoveModelLike(tx *gorm.DB, owner, modelName string, user *users.User) (*ModelLike, *gz.ErrMsg) {
@@ -506,7 +571,7 @@ func (ms *Service) increaseDownloadCounter(tx *gorm.DB, model *Model, delta uint
 func (ms *Service) GetFile(ctx context.Context, tx *gorm.DB, owner, name, path,
 	version string, user *users.User) (*[]byte, int, *gz.ErrMsg) {
 
-	model, em := ms.GetModel(tx, owner, name, user)
+	model, em := ms.getModelForDownload(ctx, tx, owner, name, user)
 	if em != nil {
 		return nil, -1, em
 	}
@@ -523,7 +588,7 @@ func (ms *Service) GetFile(ctx context.Context, tx *gorm.DB, owner, name, path,
 func (ms *Service) DownloadZip(ctx context.Context, tx *gorm.DB, owner, modelName, version string,
 	u *users.User, agent string, zipGetter res.GetZipResource) (*Model, *string, int, *gz.ErrMsg) {
 
-	model, em := ms.GetModel(tx, owner, modelName, u)
+	model, em := ms.getModelForDownload(ctx, tx, owner, modelName, u)
 	if em != nil {
 		return nil, nil, 0, em
 	}
@@ -558,11 +623,12 @@ func (ms *Service) DownloadZip(ctx context.Context, tx *gorm.DB, owner, modelNam
 
 // UpdateModel updates a model. The user argument is the requesting user. It
 // is used to check if the user can perform the operation.
-// Fields that can be currently updated: desc, tags, and the model files.
+// Fields that can be currently updated: name, desc, tags, archived state and
+// the model files. Files cannot be updated while the model is archived.
 // The filesPath argument points to a tmp folder from which to read the model's files.
 // Returns the updated model
 func (ms *Service) UpdateModel(ctx context.Context, tx *gorm.DB, owner,
-	modelName string, desc, tagstr, filesPath *string, private *bool,
+	modelName string, newName, desc, tagstr, filesPath *string, private, archived *bool,
 	user *users.User, metadata *ModelMetadata, categories *string) (*Model, *gz.ErrMsg) {
 
 	model, em := ms.GetModel(tx, owner, modelName, user)
@@ -575,6 +641,13 @@ func (ms *Service) UpdateModel(ctx context.Context, tx *gorm.DB, owner,
 		return nil, err
 	}
 
+	// Rename the model, if a new name is present.
+	if newName != nil && *newName != *model.Name {
+		if em := renameModel(tx, model, *newName); em != nil {
+			return nil, em
+		}
+	}
+
 	// Edit the model description, if present.
 	if desc != nil {
 		tx.Model(&model).Update("Description", *desc)
@@ -627,8 +700,20 @@ func (ms *Service) UpdateModel(ctx context.Context, tx *gorm.DB, owner,
 	// Update the modification date.
 	tx.Model(&model).Update("ModifyDate", time.Now())
 
+	// Update the archived state, if present. Only Owners and Admins can do that.
+	if archived != nil {
+		if ok, em := users.CanPerformWithRole(tx, *model.Owner, *user.Username, permissions.Admin); !ok {
+			return nil, em
+		}
+		tx.Model(&model).Update("Archived", *archived)
+	}
+
 	// Update files, if present
 	if filesPath != nil {
+		// Archived models are read-only
+		if res.IsArchived(model.Archived) {
+			return nil, res.NewArchivedErrorMessage(*model.Name)
+		}
 		// Replace ALL model files with the new ones
 		repo := globals.VCSRepoFactory(ctx, *model.Location)
 		if err := repo.ReplaceFiles(ctx, *filesPath, *user.Username); err != nil {
@@ -659,6 +744,30 @@ func (ms *Service) UpdateModel(ctx context.Context, tx *gorm.DB, owner,
 	return model, nil
 }
 
+// renameModel changes the name of a model. The previous name is recorded as an
+// alias, so that requests using it can be redirected to the new name.
+func renameModel(tx *gorm.DB, model *Model, newName string) *gz.ErrMsg {
+	if _, err := GetModelByName(tx, newName, *model.Owner); err == nil {
+		return gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{newName})
+	}
+	// The new name could have been used by this or another model in the past,
+	// and the old name could be an alias left by another model that had it
+	// before this one. Aliases only point to the last model with a name.
+	for _, name := range []string{newName, *model.Name} {
+		if err := removeModelAlias(tx, *model.Owner, name); err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	}
+	alias := ModelAlias{ModelID: model.ID, Owner: model.Owner, Name: model.Name}
+	if err := tx.Create(&alias).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	if err := tx.Model(model).Update("Name", newName).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
 // updateModelZip creates a new zip file for the given model and also
 // updates its Filesize field in DB.
 func (ms *Service) updateModelZip(ctx context.Context, repo vcs.VCS, model *Model) *gz.ErrMsg {
@@ -718,7 +827,7 @@ func (ms *Service) CreateModel(ctx context.Context, tx *gorm.DB, cm CreateModel,
 	// Set the owner
 	owner := cm.Owner
 	if owner == "" {
-		owner = *creator.Username
+		owner = creator.DefaultOwner()
 	} else {
 		ok, em := users.VerifyOwner(tx, owner, *creator.Username, permissions.Read)
 		if !ok {
@@ -797,7 +906,7 @@ func (ms *Service) CloneModel(ctx context.Context, tx *gorm.DB, smOwner,
 	// Set the owner
 	owner := cm.Owner
 	if owner == "" {
-		owner = *creator.Username
+		owner = creator.DefaultOwner()
 	} else {
 		ok, em := users.VerifyOwner(tx, owner, *creator.Username, permissions.Read)
 		if !ok {
@@ -835,6 +944,12 @@ func (ms *Service) CloneModel(ctx context.Context, tx *gorm.DB, smOwner,
 		clonePrivate = *cm.Private
 	}
 
+	// Get the source model version being cloned
+	sourceVersion, err := res.GetLatestVersion(ctx, model)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+
 	// Load the metadata
 	tx.Model(&model).Related(&model.Metadata)
 
@@ -845,6 +960,9 @@ func (ms *Service) CloneModel(ctx context.Context, tx *gorm.DB, smOwner,
 	if err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorCreatingDir, err)
 	}
+	// Keep track of the model lineage
+	clone.ForkedFromID = &model.ID
+	clone.ForkedFromVersion = &sourceVersion
 
 	repo, em := res.CloneResourceRepo(ctx, model, &clone)
 	if em != nil {
@@ -880,6 +998,201 @@ func (ms *Service) CloneModel(ctx context.Context, tx *gorm.DB, smOwner,
 	return &clone, nil
 }
 
+// forkedFromProto returns a 'fuel.ForkedFrom' describing the model from which
+// the given model was cloned. It returns nil if the model is not a fork, or if
+// the source model no longer exists or is not visible to the requesting user.
+func (ms *Service) forkedFromProto(tx *gorm.DB, model *Model,
+	user *users.User) *fuel.ForkedFrom {
+
+	return forkedFromProto(tx, model, relatedModels(tx, Models{*model}), user)
+}
+
+// forkedFromProto returns a 'fuel.ForkedFrom' describing the model from which
+// the given model was cloned, looking up the source model in the given related
+// models.
+func forkedFromProto(tx *gorm.DB, model *Model, related map[uint]*Model,
+	user *users.User) *fuel.ForkedFrom {
+
+	if model.ForkedFromID == nil {
+		return nil
+	}
+	source, ok := related[*model.ForkedFromID]
+	if !ok {
+		return nil
+	}
+	if ok, _ := users.CheckPermissions(tx, *source.UUID, user, *source.Private, permissions.Read); !ok {
+		return nil
+	}
+
+	forkedFrom := fuel.ForkedFrom{
+		Owner: proto.String(*source.Owner),
+		Name:  proto.String(*source.Name),
+	}
+	if model.ForkedFromVersion != nil {
+		forkedFrom.Version = proto.Int64(int64(*model.ForkedFromVersion))
+	}
+	return &forkedFrom
+}
+
+// relatedModels returns the models from which the given models were cloned, and
+// their successors, indexed by ID. They are loaded with a single query.
+func relatedModels(tx *gorm.DB, list Models) map[uint]*Model {
+	related := make(map[uint]*Model)
+	var ids []uint
+	for _, model := range list {
+		if model.ForkedFromID != nil {
+			ids = append(ids, *model.ForkedFromID)
+		}
+		if model.SuccessorID != nil {
+			ids = append(ids, *model.SuccessorID)
+		}
+	}
+	if len(ids) == 0 {
+		return related
+	}
+	var found Models
+	if err := tx.Model(&Model{}).Where("id IN (?)", ids).Find(&found).Error; err != nil {
+		return related
+	}
+	for i := range found {
+		related[found[i].ID] = &found[i]
+	}
+	return related
+}
+
+// ModelForks returns a paginated list of the models that were cloned from the
+// given model. Only forks visible to the requesting user are returned.
+func (ms *Service) ModelForks(p *gz.PaginationRequest, tx *gorm.DB, owner,
+	name string, user *users.User) (*fuel.Models, *gz.PaginationResult, *gz.ErrMsg) {
+
+	model, em := ms.GetModel(tx, owner, name, user)
+	if em != nil {
+		return nil, nil, em
+	}
+
+	q := tx.Where("forked_from_id = ?", model.ID)
+	return ms.ModelList(p, q, nil, res.ListOptions{User: user, IgnoreMemcache: true})
+}
+
+// DeprecateModel marks a model as deprecated, with an optional message and
+// successor model. Deprecating an already deprecated model updates its message
+// and successor. Only owners and admins can deprecate a model.
+// The user argument is the user requesting the operation.
+func (ms *Service) DeprecateModel(ctx context.Context, tx *gorm.DB, owner, name string,
+	dr res.DeprecateResource, user *users.User) (*Model, *gz.ErrMsg) {
+
+	model, em := ms.GetModel(tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := users.CanPerformWithRole(tx, *model.Owner, *user.Username, permissions.Admin); !ok {
+		return nil, em
+	}
+
+	var successorID *uint
+	if dr.SuccessorName != "" {
+		successorOwner := dr.SuccessorOwner
+		if successorOwner == "" {
+			successorOwner = *model.Owner
+		}
+		successor, em := ms.GetModel(tx, successorOwner, dr.SuccessorName, user)
+		if em != nil {
+			return nil, em
+		}
+		if successor.ID == model.ID {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+				[]string{"successor_name"})
+		}
+		successorID = &successor.ID
+	}
+
+	deprecatedAt := time.Now()
+	if model.DeprecatedAt != nil {
+		deprecatedAt = *model.DeprecatedAt
+	}
+	var message *string
+	if dr.Message != "" {
+		message = &dr.Message
+	}
+	if err := tx.Model(model).Updates(map[string]interface{}{
+		"deprecated_at":       deprecatedAt,
+		"deprecation_message": message,
+		"successor_id":        successorID,
+	}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	model.DeprecatedAt = &deprecatedAt
+	model.DeprecationMessage = message
+	model.SuccessorID = successorID
+
+	ElasticSearchUpdateModel(ctx, tx, *model)
+	if err := globals.QueryCache.DeleteAll(); err != nil {
+		gz.LoggerFromContext(ctx).Error("Failed to clear the memory cache.")
+	}
+	return model, nil
+}
+
+// UndeprecateModel removes the deprecation of a model.
+// The user argument is the user requesting the operation.
+func (ms *Service) UndeprecateModel(ctx context.Context, tx *gorm.DB, owner, name string,
+	user *users.User) (*Model, *gz.ErrMsg) {
+
+	model, em := ms.GetModel(tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := users.CanPerformWithRole(tx, *model.Owner, *user.Username, permissions.Admin); !ok {
+		return nil, em
+	}
+
+	if err := tx.Model(model).Updates(map[string]interface{}{
+		"deprecated_at":       nil,
+		"deprecation_message": nil,
+		"successor_id":        nil,
+	}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	model.DeprecatedAt = nil
+	model.DeprecationMessage = nil
+	model.SuccessorID = nil
+
+	ElasticSearchUpdateModel(ctx, tx, *model)
+	if err := globals.QueryCache.DeleteAll(); err != nil {
+		gz.LoggerFromContext(ctx).Error("Failed to clear the memory cache.")
+	}
+	return model, nil
+}
+
+// SuccessorProto returns a 'fuel.Successor' describing the model replacing the
+// given deprecated model. It returns nil if the model has no successor, or if
+// the successor no longer exists or is not visible to the requesting user.
+func (ms *Service) SuccessorProto(tx *gorm.DB, model *Model,
+	user *users.User) *fuel.Successor {
+
+	return successorProto(tx, model, relatedModels(tx, Models{*model}), user)
+}
+
+// successorProto returns a 'fuel.Successor' describing the model replacing the
+// given deprecated model, looking up the successor in the given related models.
+func successorProto(tx *gorm.DB, model *Model, related map[uint]*Model,
+	user *users.User) *fuel.Successor {
+
+	if model.SuccessorID == nil {
+		return nil
+	}
+	successor, ok := related[*model.SuccessorID]
+	if !ok {
+		return nil
+	}
+	if ok, _ := users.CheckPermissions(tx, *successor.UUID, user, *successor.Private, permissions.Read); !ok {
+		return nil
+	}
+	return &fuel.Successor{
+		Owner: proto.String(*successor.Owner),
+		Name:  proto.String(*successor.Name),
+	}
+}
+
 // createUniqueModelName is an internal helper to disambiguate among model names
 func (ms *Service) createUniqueModelName(tx *gorm.DB, name, owner string) (string, error) {
 	// Find an unused name variation
@@ -901,9 +1214,10 @@ func (ms *Service) createUniqueModelName(tx *gorm.DB, name, owner string) (strin
 // In this case, we can ideally use the memdory cache to reduce the
 // DB burden.
 // Note: the PerPage default value is 20.
-func isbasicModelListQuery(p *gz.PaginationRequest, owner *string,
-	order, search string, likedBy *users.User, ignoreMemcache bool) bool {
-	return !ignoreMemcache && owner == nil && order == "" && search == "" && likedBy == nil && p != nil && (!p.PageRequested || (p.PageRequested && p.PerPage == 20))
+// Requests of logged in users are not basic queries, as their results depend on
+// the user (eg. hidden resources, and forks of private resources).
+func isbasicModelListQuery(p *gz.PaginationRequest, owner *string, opts res.ListOptions) bool {
+	return !opts.IgnoreMemcache && owner == nil && opts.Order == "" && opts.SortBy == "" && opts.Search == "" && opts.LikedBy == nil && opts.User == nil && opts.Archived == nil && opts.Deprecated == nil && p != nil && (!p.PageRequested || (p.PageRequested && p.PerPage == 20))
 }
 
 // getModelListCache attempts to get a query result from memcache.
@@ -930,3 +1244,121 @@ func getModelListCache(basicQuery bool, modelsCacheKey, paginationCacheKey strin
 	}
 	return nil, nil, false
 }
+
+// ModelTrash returns the list of soft-deleted models of an owner that can
+// still be restored.
+func (ms *Service) ModelTrash(tx *gorm.DB, owner string) (res.TrashItems, *gz.ErrMsg) {
+	var list Models
+	if err := res.QueryForTrash(tx.Model(&Model{}), owner).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	items := res.TrashItems{}
+	for _, m := range list {
+		items = append(items, res.NewTrashItem("model", *m.Name, *m.Owner, *m.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreModel restores a soft-deleted model, as long as it is still within
+// the trash retention window. If the model name is already in use, a new name
+// must be given in the RestoreResource argument.
+// The user argument is the requesting user. It must be the owner or have write
+// access to the owner organization.
+func (ms *Service) RestoreModel(ctx context.Context, tx *gorm.DB, owner,
+	name string, rr res.RestoreResource, user *users.User) (*Model, *gz.ErrMsg) {
+
+	if user == nil {
+		return nil, gz.NewErrorMessage(gz.ErrorAuthNoUser)
+	}
+	if ok, em := users.VerifyOwner(tx, owner, *user.Username, permissions.Write); !ok {
+		return nil, em
+	}
+
+	var model Model
+	if err := res.QueryForTrash(tx.Model(&Model{}), owner).Where("name = ?", name).
+		First(&model).Error; err != nil {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, err, []string{name})
+	}
+
+	// Name-conflict handling: the restored model cannot take the name of an
+	// existing one.
+	newName := name
+	if rr.Name != "" {
+		newName = rr.Name
+	}
+	if _, err := GetModelByName(tx, newName, owner); err == nil {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{newName})
+	}
+
+	if em := res.Restore(tx, &model, newName); em != nil {
+		return nil, em
+	}
+
+	restored, err := GetModelByName(tx, newName, owner)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	tx.Model(restored).Related(&restored.Metadata)
+
+	// Add the model back to ElasticSearch
+	ElasticSearchUpdateModel(ctx, tx, *restored)
+	if err := globals.QueryCache.DeleteAll(); err != nil {
+		gz.LoggerFromContext(ctx).Error("Failed to clear the memory cache.")
+	}
+
+	return restored, nil
+}
+
+// PurgeExpiredModels permanently removes from the database all the models that
+// were soft-deleted before the trash retention window. The files of the purged
+// models must be removed once the transaction is committed.
+func (ms *Service) PurgeExpiredModels(ctx context.Context, tx *gorm.DB) ([]*res.PurgedResource, *gz.ErrMsg) {
+	var list Models
+	if err := res.QueryForExpiredTrash(tx.Model(&Model{})).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+
+	var purged []*res.PurgedResource
+	for i := range list {
+		model := &list[i]
+		if em := purgeModelRows(tx, model); em != nil {
+			return nil, em
+		}
+		p, em := res.Purge(tx, model, models, ms.Storage)
+		if em != nil {
+			return nil, em
+		}
+		purged = append(purged, p)
+		gz.LoggerFromContext(ctx).Info("Purged model: ", *model.Owner, "/", *model.Name)
+	}
+	return purged, nil
+}
+
+// purgeModelRows removes the rows that reference a model by ID, before the
+// model is purged.
+func purgeModelRows(tx *gorm.DB, model *Model) *gz.ErrMsg {
+	statements := []string{
+		"DELETE FROM model_tags WHERE model_id = ?",
+		"DELETE FROM model_categories WHERE model_id = ?",
+		"DELETE FROM model_reviews WHERE model_id = ?",
+		"DELETE FROM ratings WHERE resource_type = 'model' AND resource_id = ?",
+	}
+	for _, sql := range statements {
+		if err := tx.Exec(sql, model.ID).Error; err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	}
+	rows := []interface{}{
+		&ModelMetadatum{},
+		&ModelLike{},
+		&ModelDownload{},
+		&ModelReport{},
+		&ModelAlias{},
+	}
+	for _, row := range rows {
+		if err := tx.Unscoped().Where("model_id = ?", model.ID).Delete(row).Error; err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	}
+	return nil
+}
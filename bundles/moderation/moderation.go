@@ -0,0 +1,256 @@
+package moderation
+
+import (
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Types of the reported resources.
+const (
+	TypeModel      = "model"
+	TypeWorld      = "world"
+	TypeCollection = "collection"
+	TypeUser       = "user"
+	TypeComment    = "comment"
+)
+
+// Status of a moderation case.
+const (
+	// StatusOpen cases are in the moderation queue.
+	StatusOpen = "open"
+	// StatusDismissed cases were reviewed and required no action.
+	StatusDismissed = "dismissed"
+	// StatusActioned cases were resolved with a moderation action.
+	StatusActioned = "actioned"
+)
+
+// DefaultAutoHideReporters is the number of distinct users that must report a
+// public model or world for it to be hidden automatically, when
+// IGN_FUEL_AUTO_HIDE_REPORTERS is not set.
+const DefaultAutoHideReporters = 5
+
+// DefaultAutoHideWindowHours is the number of hours in which the reports are
+// counted, when IGN_FUEL_AUTO_HIDE_WINDOW_HOURS is not set.
+const DefaultAutoHideWindowHours = 24
+
+// Moderation actions.
+const (
+	// ActionHide hides a model, world or collection from lists and search
+	// results, or hides a comment.
+	ActionHide = "hide"
+	// ActionMakePrivate forces a model, world or collection private.
+	ActionMakePrivate = "make_private"
+	// ActionSuspendOwner suspends the owner of a resource, the author of a
+	// comment or the reported user.
+	ActionSuspendOwner = "suspend_owner"
+)
+
+// Case groups the reports against a resource, so moderators review each
+// resource once. A resource has at most one open case. Reports filed after its
+// case was resolved open a new case.
+//
+// swagger:model ModerationCase
+type Case struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The resource type: model, world, collection, user or comment
+	ResourceType string `gorm:"not null;index:idx_case_resource" json:"resource_type"`
+	// The ID of the reported resource
+	ResourceID uint `gorm:"not null;index:idx_case_resource" json:"resource_id"`
+	// The owner of the resource. The author for comments, and the user itself
+	// for users.
+	Owner string `gorm:"not null" json:"owner"`
+	// The name of the resource. The commented resource for comments, as
+	// owner/type/name, and the username for users.
+	Name string `gorm:"not null" json:"name"`
+	// The status of the case: open, dismissed or actioned
+	Status string `gorm:"not null;index" json:"status"`
+	// The number of reports
+	ReportCount int `gorm:"not null" json:"report_count"`
+	// Whether the resource was hidden automatically after being reported by
+	// several users, pending the review of the case
+	AutoHidden bool `gorm:"not null" json:"auto_hidden"`
+	// Notes of the moderators
+	Notes string `gorm:"type:text" json:"notes,omitempty"`
+	// The action taken, for actioned cases: hide, make_private or
+	// suspend_owner
+	Action string `json:"action,omitempty"`
+	// The username of the moderator that resolved the case
+	ModeratedBy string `json:"moderated_by,omitempty"`
+	// Date and time the case was resolved
+	ResolvedAt *time.Time `gorm:"type:timestamp(3) NULL" json:"resolved_at,omitempty"`
+	// The reports of the case. Only returned for a single case.
+	Reports Reports `gorm:"-" json:"reports,omitempty"`
+}
+
+// TableName sets the table name of moderation cases.
+func (Case) TableName() string {
+	return "moderation_cases"
+}
+
+// Cases is a slice of Case
+//
+// swagger:model ModerationCases
+type Cases []Case
+
+// Report is a user report against a resource.
+//
+// swagger:model ModerationReport
+type Report struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The case that groups the reports against the resource
+	CaseID uint `gorm:"not null;index" json:"-"`
+	// The username of the reporter. Empty for anonymous reports.
+	Reporter string `json:"reporter,omitempty"`
+	// The justification why the resource was reported
+	Reason string `gorm:"type:text" json:"reason,omitempty"`
+}
+
+// TableName sets the table name of moderation reports.
+func (Report) TableName() string {
+	return "moderation_reports"
+}
+
+// Reports is a slice of Report
+//
+// swagger:model ModerationReports
+type Reports []Report
+
+// Subject identifies a reported resource.
+type Subject struct {
+	ResourceType string
+	ResourceID   uint
+	Owner        string
+	Name         string
+}
+
+// CreateReport encapsulates the data required to report a collection, a user
+// or a comment.
+type CreateReport struct {
+	Reason string `json:"reason" validate:"max=10000" form:"reason"`
+}
+
+// UpdateCase encapsulates the data required to resolve a moderation case, or
+// to change its notes. Resolved cases cannot change their status. Actioned
+// cases require an action. Missing fields are not changed.
+type UpdateCase struct {
+	// dismissed or actioned
+	Status *string `json:"status" validate:"omitempty,oneof=dismissed actioned"`
+	// hide, make_private or suspend_owner
+	Action string `json:"action" validate:"omitempty,oneof=hide make_private suspend_owner"`
+	// Notes of the moderators
+	Notes *string `json:"notes" validate:"omitempty,max=10000"`
+}
+
+// File adds a report against a resource to its open case, opening a new case
+// if needed.
+func File(tx *gorm.DB, s Subject, reporter, reason string) (*Case, *gz.ErrMsg) {
+	c := Case{
+		ResourceType: s.ResourceType,
+		ResourceID:   s.ResourceID,
+		Owner:        s.Owner,
+		Name:         s.Name,
+		Status:       StatusOpen,
+	}
+	q := tx.Where("resource_type = ? AND resource_id = ? AND status = ?",
+		s.ResourceType, s.ResourceID, StatusOpen).First(&c)
+	if q.Error != nil && !q.RecordNotFound() {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, q.Error)
+	}
+	// Keep the owner and name up to date, in case the resource was moved.
+	c.Owner = s.Owner
+	c.Name = s.Name
+	c.ReportCount++
+	if err := tx.Save(&c).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	report := Report{CaseID: c.ID, Reporter: reporter, Reason: reason}
+	if err := tx.Create(&report).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return &c, nil
+}
+
+// Get returns a moderation case with its reports, oldest first.
+func Get(tx *gorm.DB, id uint) (*Case, *gz.ErrMsg) {
+	var c Case
+	if q := tx.First(&c, id); q.Error != nil {
+		if q.RecordNotFound() {
+			return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+		}
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, q.Error)
+	}
+	if err := tx.Where("case_id = ?", c.ID).Order("created_at, id").Find(&c.Reports).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return &c, nil
+}
+
+// List returns a paginated list of the moderation cases with the given status.
+// The most reported cases come first, and then the oldest ones. If resType is
+// not empty, only the cases of that resource type are returned.
+func List(p *gz.PaginationRequest, tx *gorm.DB, status, resType string) (*Cases, *gz.PaginationResult, *gz.ErrMsg) {
+	q := tx.Model(&Case{}).Where("status = ?", status)
+	if resType != "" {
+		q = q.Where("resource_type = ?", resType)
+	}
+	var list Cases
+	pagination, err := gz.PaginateQuery(q.Order("report_count desc, created_at, id"), &list, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &list, pagination, nil
+}
+
+// Reporters returns the usernames of the users that reported a case, without
+// duplicates. Anonymous reporters are not included.
+func Reporters(tx *gorm.DB, c *Case) ([]string, *gz.ErrMsg) {
+	var reporters []string
+	if err := tx.Model(&Report{}).Where("case_id = ? AND reporter <> ''", c.ID).
+		Pluck("DISTINCT(reporter)", &reporters).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return reporters, nil
+}
+
+// Resolve closes an open case with the given status and action.
+func Resolve(tx *gorm.DB, c *Case, moderator, status, action string) *gz.ErrMsg {
+	now := time.Now()
+	c.Status = status
+	c.Action = action
+	c.ModeratedBy = moderator
+	c.ResolvedAt = &now
+	if err := tx.Save(c).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// SetAutoHidden records that the resource of a case was hidden automatically.
+func SetAutoHidden(tx *gorm.DB, c *Case) *gz.ErrMsg {
+	c.AutoHidden = true
+	if err := tx.Model(c).UpdateColumn("auto_hidden", true).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// SetNotes changes the moderator notes of a case.
+func SetNotes(tx *gorm.DB, c *Case, notes string) *gz.ErrMsg {
+	c.Notes = notes
+	if err := tx.Model(c).UpdateColumn("notes", notes).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
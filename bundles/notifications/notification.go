@@ -0,0 +1,310 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Events that notify the watchers of a resource.
+const (
+	// EventVersion is a new version of a model or world.
+	EventVersion = "version"
+	// EventTransfer is the transfer of a resource to another owner.
+	EventTransfer = "transfer"
+	// EventDeprecation is the deprecation of a model or world.
+	EventDeprecation = "deprecation"
+	// EventCollection is a change in a collection, including its assets.
+	EventCollection = "collection"
+	// EventMention is a mention of the user in a comment. Mentions notify the
+	// mentioned user, who doesn't need to watch the resource.
+	EventMention = "mention"
+	// EventModeration is the resolution of a moderation case. It notifies the
+	// reporters and the owner of the reported resource.
+	EventModeration = "moderation"
+)
+
+// Watch records that a user watches a model, world or collection. Watches
+// follow the resource by UUID, so they survive transfers and renames.
+type Watch struct {
+	ID        uint      `gorm:"primary_key" json:"-"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The username of the watcher
+	Username string `gorm:"not null;unique_index:idx_watch_user_resource" json:"-"`
+	// The resource type: model, world or collection
+	ResourceType string `gorm:"not null" json:"resource_type"`
+	// The UUID of the resource
+	ResourceUUID string `gorm:"not null;unique_index:idx_watch_user_resource;index" json:"-"`
+}
+
+// WatchResponse is a watched resource, as returned in REST responses.
+//
+// swagger:model
+type WatchResponse struct {
+	ResourceType string    `json:"resource_type"`
+	Owner        string    `json:"owner"`
+	Name         string    `json:"name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// WatchResponses is a slice of WatchResponse
+//
+// swagger:model
+type WatchResponses []WatchResponse
+
+// Notification is an in-app notification about a change in a watched
+// resource.
+//
+// swagger:model
+type Notification struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL;index" json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The username of the recipient
+	Username string `gorm:"not null;index" json:"-"`
+	// The event: version, transfer, deprecation, collection, mention or
+	// moderation
+	Event string `gorm:"not null" json:"event"`
+	// The username of the user that caused the event, if known
+	Actor string `json:"actor,omitempty"`
+	// The resource type: model, world or collection. Moderation notifications
+	// can also be about a user or a comment.
+	ResourceType string `gorm:"not null" json:"resource_type"`
+	// The owner of the resource, after the event
+	Owner string `gorm:"not null" json:"owner"`
+	// The name of the resource
+	Name string `gorm:"not null" json:"name"`
+	// A description of the event
+	Message string `gorm:"type:text" json:"message"`
+	// Whether the recipient has read the notification
+	Read bool `gorm:"column:is_read;not null;index" json:"read"`
+	// Whether the notification still has to be emailed to the recipient
+	EmailPending bool `gorm:"not null;index" json:"-"`
+}
+
+// Notifications is a slice of Notification
+//
+// swagger:model
+type Notifications []Notification
+
+// UpdateNotification encapsulates data required to mark a notification as
+// read or unread.
+type UpdateNotification struct {
+	// required: true
+	Read *bool `json:"read" validate:"required"`
+}
+
+// Preferences are the notification settings of a user. Notifications are
+// always shown in-app. Each setting enables email delivery of an event.
+//
+// swagger:model NotificationPreferences
+type Preferences struct {
+	ID        uint      `gorm:"primary_key" json:"-"`
+	UpdatedAt time.Time `json:"-"`
+
+	// The username of the user
+	Username string `gorm:"not null;unique_index" json:"-"`
+	// Email new versions of watched models and worlds
+	EmailVersions bool `json:"email_versions"`
+	// Email transfers of watched resources
+	EmailTransfers bool `json:"email_transfers"`
+	// Email deprecations of watched models and worlds
+	EmailDeprecations bool `json:"email_deprecations"`
+	// Email changes in watched collections
+	EmailCollections bool `json:"email_collections"`
+	// Email mentions in comments
+	EmailMentions bool `json:"email_mentions"`
+}
+
+// TableName sets the table name of notification preferences.
+func (Preferences) TableName() string {
+	return "notification_preferences"
+}
+
+// UpdatePreferences encapsulates data required to change the notification
+// preferences of a user. Missing fields are not changed.
+type UpdatePreferences struct {
+	EmailVersions     *bool `json:"email_versions"`
+	EmailTransfers    *bool `json:"email_transfers"`
+	EmailDeprecations *bool `json:"email_deprecations"`
+	EmailCollections  *bool `json:"email_collections"`
+	EmailMentions     *bool `json:"email_mentions"`
+}
+
+// Email returns true if the given event should be emailed.
+func (p *Preferences) Email(event string) bool {
+	switch event {
+	case EventVersion:
+		return p.EmailVersions
+	case EventTransfer:
+		return p.EmailTransfers
+	case EventDeprecation:
+		return p.EmailDeprecations
+	case EventCollection:
+		return p.EmailCollections
+	case EventMention:
+		return p.EmailMentions
+	}
+	return false
+}
+
+// GetPreferences returns the notification preferences of a user. Users that
+// never changed them get the defaults: no emails.
+func GetPreferences(tx *gorm.DB, username string) (*Preferences, *gz.ErrMsg) {
+	p := Preferences{Username: username}
+	if q := tx.Where("username = ?", username).First(&p); q.Error != nil && !q.RecordNotFound() {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, q.Error)
+	}
+	return &p, nil
+}
+
+// SetPreferences changes the notification preferences of a user.
+func SetPreferences(tx *gorm.DB, username string, up UpdatePreferences) (*Preferences, *gz.ErrMsg) {
+	p, em := GetPreferences(tx, username)
+	if em != nil {
+		return nil, em
+	}
+	if up.EmailVersions != nil {
+		p.EmailVersions = *up.EmailVersions
+	}
+	if up.EmailTransfers != nil {
+		p.EmailTransfers = *up.EmailTransfers
+	}
+	if up.EmailDeprecations != nil {
+		p.EmailDeprecations = *up.EmailDeprecations
+	}
+	if up.EmailCollections != nil {
+		p.EmailCollections = *up.EmailCollections
+	}
+	if up.EmailMentions != nil {
+		p.EmailMentions = *up.EmailMentions
+	}
+	if err := tx.Save(p).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return p, nil
+}
+
+// AddWatch makes a user watch a resource. Watching twice is not an error.
+func AddWatch(tx *gorm.DB, username, resType, uuid string) (*Watch, *gz.ErrMsg) {
+	w := Watch{Username: username, ResourceUUID: uuid}
+	if err := tx.Where(w).Attrs(Watch{ResourceType: resType}).FirstOrCreate(&w).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return &w, nil
+}
+
+// RemoveWatch makes a user stop watching a resource.
+func RemoveWatch(tx *gorm.DB, username, uuid string) *gz.ErrMsg {
+	q := tx.Where("username = ? AND resource_uuid = ?", username, uuid).Delete(&Watch{})
+	if q.Error != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, q.Error)
+	}
+	if q.RowsAffected == 0 {
+		return gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	return nil
+}
+
+// Watchers returns the usernames of the users watching a resource.
+func Watchers(tx *gorm.DB, uuid string) ([]string, *gz.ErrMsg) {
+	var usernames []string
+	if err := tx.Model(&Watch{}).Where("resource_uuid = ?", uuid).
+		Pluck("username", &usernames).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return usernames, nil
+}
+
+// UserWatches returns a paginated list of the watches of a user, newest first.
+func UserWatches(p *gz.PaginationRequest, tx *gorm.DB, username string) ([]Watch, *gz.PaginationResult, *gz.ErrMsg) {
+	var watches []Watch
+	q := tx.Model(&Watch{}).Where("username = ?", username).Order("created_at desc, id desc")
+	pagination, err := gz.PaginateQuery(q, &watches, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return watches, pagination, nil
+}
+
+// Create stores a notification for a user. The notification is flagged to be
+// emailed if the user preferences ask for it.
+func Create(tx *gorm.DB, n *Notification) *gz.ErrMsg {
+	prefs, em := GetPreferences(tx, n.Username)
+	if em != nil {
+		return em
+	}
+	n.EmailPending = prefs.Email(n.Event)
+	if err := tx.Create(n).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// List returns a paginated list of the notifications of a user, newest first.
+// If unreadOnly is true, only the unread notifications are returned.
+func List(p *gz.PaginationRequest, tx *gorm.DB, username string,
+	unreadOnly bool) (*Notifications, *gz.PaginationResult, *gz.ErrMsg) {
+
+	q := tx.Model(&Notification{}).Where("username = ?", username)
+	if unreadOnly {
+		q = q.Where("is_read = ?", false)
+	}
+	var list Notifications
+	pagination, err := gz.PaginateQuery(q.Order("created_at desc, id desc"), &list, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &list, pagination, nil
+}
+
+// MarkRead marks a notification of a user as read or unread. If id is nil,
+// all the notifications of the user are marked.
+func MarkRead(tx *gorm.DB, username string, id *uint, read bool) *gz.ErrMsg {
+	q := tx.Model(&Notification{}).Where("username = ?", username)
+	if id != nil {
+		q = q.Where("id = ?", *id)
+	}
+	q = q.Update("is_read", read)
+	if q.Error != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, q.Error)
+	}
+	if id != nil && q.RowsAffected == 0 {
+		var count int
+		tx.Model(&Notification{}).Where("username = ? AND id = ?", username, *id).Count(&count)
+		if count == 0 {
+			return gz.NewErrorMessage(gz.ErrorNonExistentResource)
+		}
+	}
+	return nil
+}
+
+// PendingEmails returns up to limit notifications that still have to be
+// emailed, oldest first.
+func PendingEmails(tx *gorm.DB, limit int) (Notifications, *gz.ErrMsg) {
+	var list Notifications
+	if err := tx.Where("email_pending = ?", true).Order("id").Limit(limit).
+		Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return list, nil
+}
+
+// EmailSent clears the email pending flag of a notification.
+func EmailSent(tx *gorm.DB, n *Notification) *gz.ErrMsg {
+	if err := tx.Model(n).UpdateColumn("email_pending", false).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
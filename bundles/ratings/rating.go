@@ -0,0 +1,141 @@
+package ratings
+
+import (
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Rating is a star rating of a version of a model or world, with an optional
+// written review. Users rate each version of a resource at most once, and count
+// once in the rating aggregates of the resource.
+//
+// swagger:model
+type Rating struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The resource type: model or world
+	ResourceType string `gorm:"not null;unique_index:idx_rating_user_resource_version" json:"-"`
+	// The ID of the rated model or world
+	ResourceID uint `gorm:"not null;unique_index:idx_rating_user_resource_version" json:"-"`
+	// The rated version
+	Version int `gorm:"not null;unique_index:idx_rating_user_resource_version" json:"version"`
+	// The ID of the user that rated the resource
+	UserID uint `gorm:"not null;unique_index:idx_rating_user_resource_version" json:"-"`
+	// The username of the user that rated the resource
+	Username string `gorm:"not null" json:"username"`
+	// The number of stars, from 1 to 5
+	Stars int `gorm:"not null" json:"stars"`
+	// The optional written review
+	Text string `gorm:"type:text" json:"text,omitempty"`
+}
+
+// Ratings is a slice of Rating
+//
+// swagger:model
+type Ratings []Rating
+
+// CreateRating encapsulates data required to rate a model or world.
+type CreateRating struct {
+	// The number of stars, from 1 to 5
+	// required: true
+	Stars int `json:"stars" validate:"required,min=1,max=5"`
+	// Optional written review
+	Text string `json:"text" validate:"max=10000"`
+	// Optional. The rated version. Defaults to the latest version.
+	Version *int `json:"version" validate:"omitempty,min=1"`
+}
+
+// Rate creates or replaces the rating of a user for a version of a model or
+// world, and updates the rating aggregates of the resource. The resource
+// argument is the model or world, which gets the new aggregates. It also
+// returns true if the aggregates changed.
+func Rate(tx *gorm.DB, resType string, resource interface{}, resourceID uint, version int,
+	userID uint, username string, cr CreateRating) (*Rating, bool, *gz.ErrMsg) {
+
+	rating := Rating{ResourceType: resType, ResourceID: resourceID, Version: version, UserID: userID,
+		Username: username, Stars: cr.Stars, Text: cr.Text}
+	// Replace the existing rating of the user for the version in the same
+	// statement, so concurrent ratings don't fail on the unique index.
+	upsert := "ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), username = VALUES(username), " +
+		"stars = VALUES(stars), text = VALUES(text), updated_at = VALUES(updated_at)"
+	if err := tx.Set("gorm:insert_option", upsert).Create(&rating).Error; err != nil {
+		return nil, false, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	// Reload the rating, to get the creation date of replaced ratings.
+	if err := tx.First(&rating, rating.ID).Error; err != nil {
+		return nil, false, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	changed, em := updateAggregates(tx, resType, resource, resourceID)
+	if em != nil {
+		return nil, false, em
+	}
+	return &rating, changed, nil
+}
+
+// Remove removes the rating of a user for a version of a model or world, and
+// updates the rating aggregates of the resource. It returns true if the
+// aggregates changed.
+func Remove(tx *gorm.DB, resType string, resource interface{}, resourceID uint, version int,
+	userID uint) (bool, *gz.ErrMsg) {
+
+	q := tx.Where("resource_type = ? AND resource_id = ? AND version = ? AND user_id = ?",
+		resType, resourceID, version, userID).Delete(&Rating{})
+	if q.Error != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, q.Error)
+	}
+	if q.RowsAffected == 0 {
+		return false, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	return updateAggregates(tx, resType, resource, resourceID)
+}
+
+// updateAggregates stores the average star rating and the number of ratings
+// of a model or world. Each user is counted once, with their rating of the
+// latest version they rated. It returns true if the aggregates changed.
+func updateAggregates(tx *gorm.DB, resType string, resource interface{}, resourceID uint) (bool, *gz.ErrMsg) {
+	var aggregates struct {
+		Average float64
+		Count   int
+	}
+	if err := tx.Model(&Rating{}).Select("COALESCE(AVG(stars), 0) AS average, COUNT(*) AS count").
+		Where("resource_type = ? AND resource_id = ?", resType, resourceID).
+		Where("version = (SELECT MAX(r.version) FROM ratings r WHERE r.resource_type = ratings.resource_type " +
+			"AND r.resource_id = ratings.resource_id AND r.user_id = ratings.user_id)").
+		Scan(&aggregates).Error; err != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	q := tx.Model(resource).Where("rating_average <> ? OR rating_count <> ?", aggregates.Average, aggregates.Count).
+		UpdateColumns(map[string]interface{}{
+			"rating_average": aggregates.Average,
+			"rating_count":   aggregates.Count,
+		})
+	if q.Error != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorDbSave, q.Error)
+	}
+	return q.RowsAffected > 0, nil
+}
+
+// List returns a paginated list of the ratings of a model or world, newest
+// first. If version is set, only the ratings of that version are returned.
+func List(p *gz.PaginationRequest, tx *gorm.DB, resType string, resourceID uint,
+	version *int) (*Ratings, *gz.PaginationResult, *gz.ErrMsg) {
+
+	q := tx.Model(&Rating{}).Where("resource_type = ? AND resource_id = ?", resType, resourceID)
+	if version != nil {
+		q = q.Where("version = ?", *version)
+	}
+	var list Ratings
+	pagination, err := gz.PaginateQuery(q.Order("created_at desc, id desc"), &list, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &list, pagination, nil
+}
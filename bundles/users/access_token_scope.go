@@ -0,0 +1,186 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Personal access token scopes. A token without scopes has the same power as
+// its user. A token with scopes can only perform the requests its scopes grant,
+// with the permissions of its user. The read scope grants all read requests
+// (GET, HEAD and OPTIONS). The other scopes grant read and write requests to
+// the resources they cover.
+const (
+	// ScopeRead only allows read requests.
+	ScopeRead = "read"
+	// ScopeWriteModels allows read and write requests to models.
+	ScopeWriteModels = "models:write"
+	// ScopeWriteWorlds allows read and write requests to worlds.
+	ScopeWriteWorlds = "worlds:write"
+	// ScopeOrgAdminPrefix is the prefix of the 'org:<name>:admin' scopes. They
+	// allow read and write requests to resources owned by the organization, and
+	// to the organization itself.
+	ScopeOrgAdminPrefix = "org:"
+	// ScopeResourcePrefix is the prefix of the
+	// 'resource:<owner>/<models|worlds|collections>/<name>' scopes. They allow
+	// read and write requests to a single resource.
+	ScopeResourcePrefix = "resource:"
+)
+
+// AccessTokenDetails extends a personal access token with the fields that are
+// not part of gz.AccessToken: its scopes and where it was last used from.
+type AccessTokenDetails struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL"`
+	UpdatedAt time.Time
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The ID of the gz.AccessToken
+	AccessTokenID uint `gorm:"unique_index"`
+	// Space separated list of scopes. Empty means unrestricted.
+	Scopes string `gorm:"type:text"`
+	// IP address of the last request made with the token
+	LastUsedIP *string
+}
+
+// AccessTokenCreateRequest is the request to create a personal access token,
+// with optional scopes and expiration.
+type AccessTokenCreateRequest struct {
+	gz.AccessTokenCreateRequest
+	// Optional list of scopes. If empty, the token has the same power as its user.
+	Scopes []string `json:"scopes" validate:"omitempty,dive,required,max=255"`
+	// Optional date and time the token expires
+	Expires *time.Time `json:"expires"`
+}
+
+// AccessTokenResponse is a personal access token, as returned by the access
+// token list.
+//
+// swagger:model
+type AccessTokenResponse struct {
+	gz.AccessToken
+	// The scopes of the token. Empty means unrestricted.
+	Scopes []string `json:"scopes,omitempty"`
+	// IP address of the last request made with the token
+	LastUsedIP *string `json:"last_used_ip,omitempty"`
+}
+
+// AccessTokenResponses is a slice of AccessTokenResponse
+//
+// swagger:model
+type AccessTokenResponses []AccessTokenResponse
+
+// ValidateScope returns an error if the given scope is not a valid personal
+// access token scope.
+func ValidateScope(scope string) *gz.ErrMsg {
+	switch {
+	case scope == ScopeRead, scope == ScopeWriteModels, scope == ScopeWriteWorlds:
+		return nil
+	case strings.HasPrefix(scope, ScopeOrgAdminPrefix):
+		parts := strings.Split(scope, ":")
+		if len(parts) == 3 && parts[1] != "" && parts[2] == "admin" {
+			return nil
+		}
+	case strings.HasPrefix(scope, ScopeResourcePrefix):
+		parts := strings.Split(strings.TrimPrefix(scope, ScopeResourcePrefix), "/")
+		if len(parts) == 3 && parts[0] != "" && parts[2] != "" &&
+			(parts[1] == "models" || parts[1] == "worlds" || parts[1] == "collections") {
+			return nil
+		}
+	}
+	return gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+		[]string{fmt.Sprintf("Invalid scope [%s]", scope)})
+}
+
+// ScopesFromString splits a space separated list of scopes.
+func ScopesFromString(scopes string) []string {
+	return strings.Fields(scopes)
+}
+
+// ScopesAllowRequest returns true if the given scopes allow a request with the
+// given method, URL path and route variables. The path must not include the API
+// version.
+func ScopesAllowRequest(scopes []string, method, path string, vars map[string]string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	read := method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+
+	// Find out the resource type and owner of the request.
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	resType := ""
+	for _, s := range segments {
+		if s == "models" || s == "worlds" || s == "collections" {
+			resType = s
+			break
+		}
+	}
+	owner := vars["username"]
+	if len(segments) > 0 && segments[0] == "organizations" {
+		owner = vars["name"]
+	}
+	resName := vars[strings.TrimSuffix(resType, "s")]
+
+	for _, scope := range scopes {
+		switch {
+		case scope == ScopeRead && read:
+			return true
+		case scope == ScopeWriteModels && resType == "models":
+			return true
+		case scope == ScopeWriteWorlds && resType == "worlds":
+			return true
+		case strings.HasPrefix(scope, ScopeOrgAdminPrefix):
+			if owner != "" && scope == ScopeOrgAdminPrefix+owner+":admin" {
+				return true
+			}
+		case strings.HasPrefix(scope, ScopeResourcePrefix):
+			if owner != "" && resName != "" &&
+				scope == ScopeResourcePrefix+owner+"/"+resType+"/"+resName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetAccessTokenDetails returns the details of a personal access token. If the
+// token has no details, an empty AccessTokenDetails is returned.
+func GetAccessTokenDetails(tx *gorm.DB, accessTokenID uint) *AccessTokenDetails {
+	var details AccessTokenDetails
+	tx.Where("access_token_id = ?", accessTokenID).First(&details)
+	details.AccessTokenID = accessTokenID
+	return &details
+}
+
+// AccessTokenUseInterval is the minimum amount of time between two updates of
+// the last use of a personal access token. It avoids writing to the DB on every
+// authenticated request.
+const AccessTokenUseInterval = 5 * time.Minute
+
+// RecordAccessTokenUse records the time and the IP address of the last request
+// made with a personal access token. Uses within AccessTokenUseInterval of the
+// recorded one are not recorded.
+// Recording is best-effort. The given DB should not be the request transaction,
+// so a failure does not affect the request, nor a rollback the recording.
+func RecordAccessTokenUse(ctx context.Context, db *gorm.DB, token *gz.AccessToken, ip string) {
+	now := time.Now()
+	if token.LastUsed != nil && now.Sub(*token.LastUsed) < AccessTokenUseInterval {
+		return
+	}
+	if err := db.Model(token).UpdateColumn("last_used", now).Error; err != nil {
+		gz.LoggerFromContext(ctx).Error("Unable to record the use of access token: ", token.ID, err)
+		return
+	}
+	details := GetAccessTokenDetails(db, token.ID)
+	details.LastUsedIP = &ip
+	if err := db.Save(details).Error; err != nil {
+		gz.LoggerFromContext(ctx).Error("Unable to record the use of access token: ", token.ID, err)
+	}
+}
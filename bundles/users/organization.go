@@ -67,7 +67,8 @@ func (uo UpdateOrganization) IsEmpty() bool {
 // AddUserToOrgInput is the input data to add a user to an org.
 type AddUserToOrgInput struct {
 	Username string `json:"username" validate:"required,alphanum" form:"username"`
-	Role     string `json:"role" validate:"required,oneof=owner admin member" form:"role"`
+	// The role of the user: owner, admin, member or a custom role of the org
+	Role string `json:"role" validate:"required,alphanum,max=64" form:"role"`
 }
 
 // ByOrganizationName queries an organization by name.
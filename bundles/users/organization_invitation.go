@@ -0,0 +1,397 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Status of an organization invitation.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+	// InvitationExpired is not stored. It is reported for pending invitations
+	// that are past their expiration date.
+	InvitationExpired = "expired"
+)
+
+// DefaultInvitationExpiryDays is the number of days an invitation can be
+// accepted, when not set by the inviter.
+const DefaultInvitationExpiryDays = 7
+
+// OrganizationInvitation is an invitation to join an organization. Unlike
+// adding a user to an organization, the invitee needs to accept the invitation
+// to become a member.
+// Users can be invited by username or by email. Invitations sent by email can
+// be accepted by any user holding the single-use token mailed to that address,
+// as user emails are not verified.
+type OrganizationInvitation struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL"`
+	UpdatedAt time.Time
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The name of the organization
+	Organization string `gorm:"not null;index"`
+	// The username of the invitee, if invited by username
+	Username *string `gorm:"index"`
+	// The email of the invitee, if invited by email
+	Email *string `gorm:"index"`
+	// The role the invitee will have in the organization
+	Role string `gorm:"not null"`
+	// JSON list of the teams the invitee will be added to
+	Teams string `gorm:"type:text"`
+	// The username of the user that sent the invitation
+	Inviter string
+	// The status of the invitation: pending, accepted, declined or revoked
+	Status string `gorm:"not null;index"`
+	// Date and time after which the invitation can no longer be accepted
+	ExpiresAt time.Time `gorm:"type:timestamp(3) NULL"`
+	// The hash of the token mailed to the invitee, if invited by email
+	TokenHash string
+	// The token mailed to the invitee, if invited by email. It is only set
+	// when the invitation is created. Only its hash is stored.
+	Token string `gorm:"-"`
+}
+
+// InvitationResponse is an organization invitation, as returned in REST
+// responses.
+//
+// swagger:model
+type InvitationResponse struct {
+	ID           uint      `json:"id"`
+	Organization string    `json:"organization"`
+	Username     string    `json:"username,omitempty"`
+	Email        string    `json:"email,omitempty"`
+	Role         string    `json:"role"`
+	Teams        []string  `json:"teams"`
+	Inviter      string    `json:"inviter"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// InvitationResponses is a slice of InvitationResponse
+//
+// swagger:model
+type InvitationResponses []InvitationResponse
+
+// CreateInvitation encapsulates data required to invite a user to an
+// organization.
+type CreateInvitation struct {
+	// The username of the invitee. Either username or email is required.
+	Username string `json:"username" validate:"required_without=Email,omitempty,min=3"`
+	// The email of the invitee. Either username or email is required.
+	Email string `json:"email" validate:"required_without=Username,omitempty,email"`
+	// The role of the invitee: owner, admin, member or a custom role
+	// required: true
+	Role string `json:"role" validate:"required,alphanum,max=64"`
+	// Optional list of teams the invitee will be added to
+	Teams []string `json:"teams" validate:"omitempty,dive,required"`
+	// Optional number of days the invitation can be accepted. Default 7.
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=30"`
+}
+
+// status returns the status of the invitation, reporting pending invitations
+// past their expiration date as expired.
+func (inv *OrganizationInvitation) status() string {
+	if inv.Status == InvitationPending && inv.ExpiresAt.Before(time.Now()) {
+		return InvitationExpired
+	}
+	return inv.Status
+}
+
+// teams returns the teams the invitee will be added to.
+func (inv *OrganizationInvitation) teams() []string {
+	teams := make([]string, 0)
+	if inv.Teams != "" {
+		_ = json.Unmarshal([]byte(inv.Teams), &teams)
+	}
+	return teams
+}
+
+// CreateInvitationResponse creates a response from an invitation.
+func CreateInvitationResponse(inv *OrganizationInvitation) InvitationResponse {
+	resp := InvitationResponse{
+		ID:           inv.ID,
+		Organization: inv.Organization,
+		Role:         inv.Role,
+		Teams:        inv.teams(),
+		Inviter:      inv.Inviter,
+		Status:       inv.status(),
+		CreatedAt:    inv.CreatedAt,
+		ExpiresAt:    inv.ExpiresAt,
+	}
+	if inv.Username != nil {
+		resp.Username = *inv.Username
+	}
+	if inv.Email != nil {
+		resp.Email = *inv.Email
+	}
+	return resp
+}
+
+// InvitationRecipient returns the email address the invitation should be sent
+// to, or an empty string if unknown.
+func InvitationRecipient(tx *gorm.DB, inv *OrganizationInvitation) string {
+	if inv.Email != nil {
+		return *inv.Email
+	}
+	if user, em := ByUsername(tx, *inv.Username, false); em == nil && user.Email != nil {
+		return *user.Email
+	}
+	return ""
+}
+
+// queryForPendingInvitations returns a gorm query configured to find the
+// pending and not expired invitations.
+func queryForPendingInvitations(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&OrganizationInvitation{}).Where("status = ? AND expires_at > ?",
+		InvitationPending, time.Now())
+}
+
+// CreateInvitation invites a user to an organization, by username or by email.
+// The requestor must be able to manage the members of the organization, and to
+// give the invitation role.
+func (ms *OrganizationService) CreateInvitation(ctx context.Context, tx *gorm.DB,
+	orgName string, ci CreateInvitation, requestor *User) (*OrganizationInvitation, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.CanPerformInGroup(*requestor.Username, *org.Name,
+		permissions.ManageMembers); !ok {
+		return nil, em
+	}
+	if em := checkCanGrantRole(tx, *org.Name, ci.Role, requestor); em != nil {
+		return nil, em
+	}
+	for _, name := range ci.Teams {
+		if _, em := TeamOfOrganization(tx, org, name); em != nil {
+			return nil, em
+		}
+	}
+
+	inv := OrganizationInvitation{Organization: *org.Name, Role: ci.Role,
+		Inviter: *requestor.Username, Status: InvitationPending}
+	pending := queryForPendingInvitations(tx).Where("organization = ?", *org.Name)
+	if ci.Username != "" {
+		user, em := ByUsername(tx, ci.Username, false)
+		if em != nil {
+			return nil, em
+		}
+		// Service accounts only belong to the organization that owns them.
+		if user.IsServiceAccount() {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil,
+				[]string{"Service accounts cannot be invited to organizations"})
+		}
+		if globals.Permissions.UserBelongsToGroup(*user.Username, *org.Name) {
+			extra := fmt.Sprintf("User [%s] already belongs to Organization [%s]", *user.Username, *org.Name)
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{extra})
+		}
+		inv.Username = user.Username
+		pending = pending.Where("username = ?", *user.Username)
+	} else {
+		token, err := randomInvitationToken()
+		if err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+		inv.Email = &ci.Email
+		inv.Token = token
+		inv.TokenHash = hashInvitationToken(token)
+		pending = pending.Where("email = ?", ci.Email)
+	}
+	var count int
+	if err := pending.Count(&count).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	if count > 0 {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil,
+			[]string{"A pending invitation already exists"})
+	}
+
+	if len(ci.Teams) > 0 {
+		teams, _ := json.Marshal(ci.Teams)
+		inv.Teams = string(teams)
+	}
+	days := ci.ExpiresInDays
+	if days == 0 {
+		days = DefaultInvitationExpiryDays
+	}
+	inv.ExpiresAt = time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	if err := tx.Create(&inv).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Invitation [%d] created. Org:[%s]", inv.ID, *org.Name))
+	return &inv, nil
+}
+
+// GetInvitations returns the pending invitations of an organization. The
+// requestor must be able to manage the members of the organization.
+func (ms *OrganizationService) GetInvitations(p *gz.PaginationRequest, tx *gorm.DB,
+	orgName string, requestor *User) (*InvitationResponses, *gz.PaginationResult, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, nil, em
+	}
+	if ok, em := globals.Permissions.CanPerformInGroup(*requestor.Username, *org.Name,
+		permissions.ManageMembers); !ok {
+		return nil, nil, em
+	}
+	q := queryForPendingInvitations(tx).Where("organization = ?", *org.Name).Order("created_at desc")
+	return paginateInvitations(p, q)
+}
+
+// paginateInvitations returns a page of the invitations found by the given
+// query.
+func paginateInvitations(p *gz.PaginationRequest, q *gorm.DB) (*InvitationResponses,
+	*gz.PaginationResult, *gz.ErrMsg) {
+
+	var invitations []OrganizationInvitation
+	pagination, err := gz.PaginateQuery(q, &invitations, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	responses := make(InvitationResponses, 0, len(invitations))
+	for i := range invitations {
+		responses = append(responses, CreateInvitationResponse(&invitations[i]))
+	}
+	return &responses, pagination, nil
+}
+
+// RevokeInvitation revokes a pending invitation of an organization. The
+// requestor must be able to manage the members of the organization.
+func (ms *OrganizationService) RevokeInvitation(ctx context.Context, tx *gorm.DB,
+	orgName string, id uint, requestor *User) (*InvitationResponse, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.CanPerformInGroup(*requestor.Username, *org.Name,
+		permissions.ManageMembers); !ok {
+		return nil, em
+	}
+	var inv OrganizationInvitation
+	if queryForPendingInvitations(tx).Where("organization = ? AND id = ?", *org.Name, id).
+		First(&inv).RecordNotFound() {
+		return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	if err := tx.Model(&inv).Update("status", InvitationRevoked).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Invitation [%d] revoked. Org:[%s]", inv.ID, *org.Name))
+	response := CreateInvitationResponse(&inv)
+	return &response, nil
+}
+
+// randomInvitationToken returns a new token for an invitation sent by email.
+func randomInvitationToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashInvitationToken returns the hex encoded hash of an invitation token.
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// queryForUserInvitations returns a gorm query configured to find the pending
+// invitations of a user, sent to its username. If a token is given, the
+// invitation sent by email with that token is also found. Invitations sent by
+// email are never matched against the user email, as it is not verified.
+func queryForUserInvitations(tx *gorm.DB, user *User, token string) *gorm.DB {
+	q := queryForPendingInvitations(tx)
+	if token != "" {
+		return q.Where("username = ? OR (username IS NULL AND token_hash = ?)", *user.Username,
+			hashInvitationToken(token))
+	}
+	return q.Where("username = ?", *user.Username)
+}
+
+// GetUserInvitations returns the pending invitations of a user.
+func GetUserInvitations(p *gz.PaginationRequest, tx *gorm.DB,
+	user *User) (*InvitationResponses, *gz.PaginationResult, *gz.ErrMsg) {
+
+	return paginateInvitations(p, queryForUserInvitations(tx, user, "").Order("created_at desc"))
+}
+
+// RespondToInvitation accepts or declines a pending invitation of a user. On
+// acceptance, the user is added to the organization with the invitation role,
+// and to the invitation teams. The token is required for invitations sent by
+// email. It can only be used once.
+func RespondToInvitation(ctx context.Context, tx *gorm.DB, user *User, id uint,
+	token string, accept bool) (*InvitationResponse, *gz.ErrMsg) {
+
+	var inv OrganizationInvitation
+	if queryForUserInvitations(tx, user, token).Where("id = ?", id).First(&inv).RecordNotFound() {
+		return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+
+	inv.Status = InvitationDeclined
+	if accept {
+		inv.Status = InvitationAccepted
+		org, em := ByOrganizationName(tx, inv.Organization, false)
+		if em != nil {
+			return nil, em
+		}
+		// The user could have been added to the organization after the invitation
+		// was sent. In that case, the role is kept.
+		if !globals.Permissions.UserBelongsToGroup(*user.Username, *org.Name) {
+			if !permissions.IsBuiltinRole(inv.Role) {
+				if _, em := ByOrganizationRoleName(tx, *org.Name, inv.Role); em != nil {
+					return nil, em
+				}
+			}
+			if em := addUserWithRole(*user.Username, *org.Name, inv.Role); em != nil {
+				return nil, em
+			}
+		}
+		// Teams removed after the invitation was sent are ignored.
+		for _, name := range inv.teams() {
+			team, em := TeamOfOrganization(tx, org, name)
+			if em != nil {
+				continue
+			}
+			teamGroupName := getCasbinNameForTeam(*org.Name, *team.Name)
+			if globals.Permissions.UserBelongsToGroup(*user.Username, teamGroupName) {
+				continue
+			}
+			if em := addUserToTeam(ctx, tx, team, *user.Username); em != nil {
+				return nil, em
+			}
+		}
+	}
+	if err := tx.Model(&inv).Updates(map[string]interface{}{"status": inv.Status,
+		"token_hash": ""}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Invitation [%d] %s by [%s]. Org:[%s]",
+		inv.ID, inv.Status, *user.Username, inv.Organization))
+	response := CreateInvitationResponse(&inv)
+	return &response, nil
+}
@@ -0,0 +1,203 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// OrganizationRole is a custom role of an organization. Besides the built-in
+// owner, admin and member roles, organizations can define roles that map to a
+// set of actions, eg. a 'viewer' that can only read private resources, or a
+// 'maintainer' that can update resources but not delete them.
+// The actions are enforced through casbin. This table keeps the role
+// description and allows listing the roles of an organization.
+type OrganizationRole struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key" json:"-"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The name of the organization
+	Organization string `gorm:"unique_index:idx_org_role" json:"-"`
+	// The name of the role
+	Name string `gorm:"unique_index:idx_org_role" json:"name"`
+	// Optional description of the role
+	Description string `json:"description,omitempty"`
+	// Space separated list of the actions the role can perform
+	Actions string `gorm:"type:text" json:"-"`
+}
+
+// OrganizationRoleResponse is a custom role of an organization, as returned
+// in REST responses.
+//
+// swagger:model
+type OrganizationRoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Actions     []string `json:"actions"`
+}
+
+// OrganizationRoleResponses is a slice of OrganizationRoleResponse
+//
+// swagger:model
+type OrganizationRoleResponses []OrganizationRoleResponse
+
+// CreateOrganizationRole encapsulates data required to create a custom role.
+type CreateOrganizationRole struct {
+	// The name of the role. It cannot be the name of a built-in role.
+	// required: true
+	Name string `json:"name" validate:"required,min=3,max=64,alphanum"`
+	// Optional description
+	Description string `json:"description" validate:"omitempty,max=255"`
+	// The actions the role can perform: read, write, delete, transfer and
+	// manage-members. Users with a custom role can always read the organization.
+	// required: true
+	Actions []string `json:"actions" validate:"required,min=1,dive,oneof=read write delete transfer manage-members"`
+}
+
+// ByOrganizationRoleName returns the custom role of an organization with the
+// given name.
+func ByOrganizationRoleName(tx *gorm.DB, orgName, name string) (*OrganizationRole, *gz.ErrMsg) {
+	var role OrganizationRole
+	if tx.Where("organization = ? AND name = ?", orgName, name).First(&role).RecordNotFound() {
+		extra := fmt.Sprintf("Role [%s] not found in Organization [%s]", name, orgName)
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, nil, []string{extra})
+	}
+	return &role, nil
+}
+
+// CreateOrganizationRoleResponse creates a response from a custom role.
+func CreateOrganizationRoleResponse(role *OrganizationRole) OrganizationRoleResponse {
+	return OrganizationRoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Actions:     strings.Fields(role.Actions),
+	}
+}
+
+// AddPermissions sets up the custom role and its actions in the permissions DB.
+// It must be called once the role is committed, so the permissions are not
+// left behind if the transaction fails.
+func (role *OrganizationRole) AddPermissions() *gz.ErrMsg {
+	actions := make([]permissions.Action, 0)
+	for _, a := range strings.Fields(role.Actions) {
+		actions = append(actions, permissions.ActionFrom(a))
+	}
+	if ok, em := globals.Permissions.AddCustomRole(role.Organization, role.Name, actions); !ok {
+		return em
+	}
+	return nil
+}
+
+// RemovePermissions removes the custom role from the permissions DB. It must be
+// called once the role removal is committed, so the permissions are kept if the
+// transaction fails.
+func (role *OrganizationRole) RemovePermissions() *gz.ErrMsg {
+	if ok, em := globals.Permissions.RemoveCustomRole(role.Organization, role.Name); !ok {
+		return em
+	}
+	return nil
+}
+
+// CreateRole creates a custom role in an organization. The requestor must be
+// an admin of the organization. The caller must set up the role in the
+// permissions DB with AddPermissions once the transaction is committed.
+func (ms *OrganizationService) CreateRole(ctx context.Context, tx *gorm.DB,
+	orgName string, cor CreateOrganizationRole, requestor *User) (*OrganizationRole, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username, *org.Name,
+		permissions.Admin); !ok {
+		return nil, em
+	}
+
+	// Sanity check (hack): custom roles cannot be named like built-in roles,
+	// nor like the casbin resource used to store their actions.
+	name := strings.ToLower(cor.Name)
+	if permissions.IsBuiltinRole(name) || name == "actions" {
+		extra := fmt.Sprintf("Role cannot be named [%s]", cor.Name)
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+	}
+	if _, em := ByOrganizationRoleName(tx, *org.Name, cor.Name); em == nil {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{cor.Name})
+	}
+
+	role := OrganizationRole{Organization: *org.Name, Name: cor.Name,
+		Description: cor.Description, Actions: strings.Join(cor.Actions, " ")}
+	if err := tx.Create(&role).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("A new role has been created. Org:[%s] Role:[%s]",
+		*org.Name, role.Name))
+	return &role, nil
+}
+
+// GetRoles returns the custom roles of an organization. The requestor must be
+// a member of the organization.
+func (ms *OrganizationService) GetRoles(tx *gorm.DB, orgName string,
+	requestor *User) (*OrganizationRoleResponses, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorized(*requestor.Username, *org.Name,
+		permissions.Read); !ok {
+		return nil, em
+	}
+
+	var roles []OrganizationRole
+	if err := tx.Where("organization = ?", *org.Name).Order("name").Find(&roles).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	responses := make(OrganizationRoleResponses, 0, len(roles))
+	for i := range roles {
+		responses = append(responses, CreateOrganizationRoleResponse(&roles[i]))
+	}
+	return &responses, nil
+}
+
+// RemoveRole removes a custom role from an organization. The requestor must be
+// an admin of the organization. A role cannot be removed while users have it.
+// The caller must remove the role from the permissions DB with
+// RemovePermissions once the transaction is committed.
+func (ms *OrganizationService) RemoveRole(ctx context.Context, tx *gorm.DB,
+	orgName, roleName string, requestor *User) (*OrganizationRole, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username, *org.Name,
+		permissions.Admin); !ok {
+		return nil, em
+	}
+	role, em := ByOrganizationRoleName(tx, *org.Name, roleName)
+	if em != nil {
+		return nil, em
+	}
+	if users := globals.Permissions.GetUsersForCustomRole(*org.Name, role.Name); len(users) > 0 {
+		extra := fmt.Sprintf("Role [%s] is assigned to %d users", role.Name, len(users))
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+	}
+
+	if err := tx.Delete(role).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Role removed. Org:[%s] Role:[%s]",
+		*org.Name, role.Name))
+	return role, nil
+}
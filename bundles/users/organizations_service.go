@@ -228,27 +228,22 @@ func (ms *OrganizationService) AddUserToOrg(ctx context.Context, tx *gorm.DB,
 	if em != nil {
 		return nil, em
 	}
-
-	// First check write permissions of the requesting user
-	if ok, em := globals.Permissions.IsAuthorized(*requestor.Username, *org.Name,
-		permissions.Write); !ok {
-		return nil, em
+	// Service accounts only belong to the organization that owns them.
+	if user.IsServiceAccount() {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil,
+			[]string{"Service accounts cannot be added to organizations"})
 	}
-	// Now check if the requesting user can add other users using the given role.
-	r, em := permissions.RoleFrom(role)
-	if em != nil {
+
+	// First check the requesting user can manage the members of the org
+	if ok, em := globals.Permissions.CanPerformInGroup(*requestor.Username, *org.Name,
+		permissions.ManageMembers); !ok {
 		return nil, em
 	}
-	if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username,
-		*org.Name, r); !ok {
+
+	if em := checkCanGrantRole(tx, *org.Name, role, requestor); em != nil {
 		return nil, em
 	}
-
-	// Add the user to the org. We do this by updating the permissions.
-	// Note, adding the user to the org group means adding user to the default
-	// team.
-	ok, em := globals.Permissions.AddUserGroupRoleString(*user.Username, *org.Name, role)
-	if !ok {
+	if em := addUserWithRole(*user.Username, *org.Name, role); em != nil {
 		return nil, em
 	}
 
@@ -258,6 +253,50 @@ func (ms *OrganizationService) AddUserToOrg(ctx context.Context, tx *gorm.DB,
 	return &response, nil
 }
 
+// checkCanGrantRole returns an error if the requestor cannot give a role of an
+// organization to other users. Built-in roles can only be given by users with
+// that role (or a higher one). Custom roles can only be given by users that can
+// perform all their actions.
+func checkCanGrantRole(tx *gorm.DB, orgName, role string, requestor *User) *gz.ErrMsg {
+	if !permissions.IsBuiltinRole(role) {
+		if _, em := ByOrganizationRoleName(tx, orgName, role); em != nil {
+			return em
+		}
+		for _, a := range globals.Permissions.GetCustomRoleActions(orgName, role) {
+			if ok, em := globals.Permissions.CanPerformInGroup(*requestor.Username, orgName, a); !ok {
+				return em
+			}
+		}
+		return nil
+	}
+	r, em := permissions.RoleFrom(role)
+	if em != nil {
+		return em
+	}
+	if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username, orgName, r); !ok {
+		return em
+	}
+	return nil
+}
+
+// addUserWithRole adds a user to an organization with a built-in or custom
+// role. We do this by updating the permissions.
+// Note, adding the user to the org group means adding user to the default
+// team.
+func addUserWithRole(username, orgName, role string) *gz.ErrMsg {
+	var ok bool
+	var em *gz.ErrMsg
+	if permissions.IsBuiltinRole(role) {
+		ok, em = globals.Permissions.AddUserGroupRoleString(username, orgName, role)
+	} else {
+		ok, em = globals.Permissions.AddUserGroupCustomRole(username, orgName, role)
+	}
+	if !ok {
+		return em
+	}
+	return nil
+}
+
 // RemoveUserFromOrg removes an user from an organization.
 // NOTE: the owner of an Org cannot be removed (will return ErrorUnexpected)
 func (ms *OrganizationService) RemoveUserFromOrg(ctx context.Context, tx *gorm.DB, orgName, username string,
@@ -275,18 +314,22 @@ func (ms *OrganizationService) RemoveUserFromOrg(ctx context.Context, tx *gorm.D
 	// user should be able to remove self from organization
 	// Otherwise check permissions of the requesting user
 	if *requestor.Username != username {
-		ok, em := globals.Permissions.IsAuthorized(*requestor.Username, *org.Name, permissions.Write)
+		ok, em := globals.Permissions.CanPerformInGroup(*requestor.Username, *org.Name,
+			permissions.ManageMembers)
 		if !ok {
 			return nil, em
 		}
 		// Now check if the requesting user can remove other users based on roles.
+		// Users with a custom role can be removed by anyone managing members.
 		role, em := globals.Permissions.GetUserRoleForGroup(*user.Username, *org.Name)
-		if em != nil {
+		if em != nil && !globals.Permissions.UserBelongsToGroup(*user.Username, *org.Name) {
 			return nil, gz.NewErrorMessage(gz.ErrorNameNotFound)
 		}
-		if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username,
-			*org.Name, role); !ok {
-			return nil, em
+		if em == nil {
+			if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username,
+				*org.Name, role); !ok {
+				return nil, em
+			}
 		}
 	}
 
@@ -318,7 +361,7 @@ func (ms *OrganizationService) GetOrgUsers(p *gz.PaginationRequest, tx *gorm.DB,
 	// Get all the users that have that role.
 	usernames := globals.Permissions.GetUsersForGroup(orgName)
 	q := tx.Where("username in (?)", usernames)
-	return UserList(p, q, user)
+	return UserList(p, q, user, false)
 }
 
 // CreateTeam creates a new team within an organization. Returns a Team
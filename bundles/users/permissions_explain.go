@@ -0,0 +1,145 @@
+package users
+
+import (
+	"strings"
+
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/jinzhu/gorm"
+)
+
+// Sources of a permission grant. They explain how a user obtained an action on
+// a resource.
+const (
+	// GrantSourceDirect is a permission granted to the user itself, eg. the
+	// owner of a resource or a collaborator.
+	GrantSourceDirect = "direct"
+	// GrantSourceOrganization is a permission granted to an organization, and
+	// obtained through the user role in the organization.
+	GrantSourceOrganization = "organization"
+	// GrantSourceTeam is a permission granted to an organization team, and
+	// obtained by belonging to the team.
+	GrantSourceTeam = "team"
+	// GrantSourceSysAdmin is a permission system admins have on any resource.
+	GrantSourceSysAdmin = "sysadmin"
+)
+
+// PermissionGrant explains how a user obtained an action on a resource.
+//
+// swagger:model
+type PermissionGrant struct {
+	// The user granted the action
+	Username string `json:"username"`
+	// The action: read or write
+	Action string `json:"action"`
+	// How the grant was derived: direct, organization, team or sysadmin
+	Source string `json:"source"`
+	// The organization granting the action, for organization and team grants
+	Organization string `json:"organization,omitempty"`
+	// The team granting the action, for team grants
+	Team string `json:"team,omitempty"`
+	// The role of the user in the organization or team
+	Role string `json:"role,omitempty"`
+}
+
+// PermissionGrants is a slice of PermissionGrant
+//
+// swagger:model
+type PermissionGrants []PermissionGrant
+
+// ResourcePermissions lists the grants of a resource.
+//
+// swagger:model
+type ResourcePermissions struct {
+	// Public resources can be read by anyone
+	Private bool `json:"private"`
+	// The users that can read or write the resource, and how
+	Grants PermissionGrants `json:"grants"`
+}
+
+// PermissionExplanation explains the permissions of a single user on a
+// resource.
+//
+// swagger:model
+type PermissionExplanation struct {
+	Username string `json:"username"`
+	// Result of evaluating the read permission
+	Read bool `json:"read"`
+	// Result of evaluating the write permission
+	Write bool `json:"write"`
+	// The steps followed to reach the result
+	Reasons []string `json:"reasons"`
+}
+
+// GetResourcePermissions returns every user that can read or write a
+// resource, and explains how each grant was derived. The resource argument is
+// the resource UUID.
+func GetResourcePermissions(tx *gorm.DB, resource string, isPrivate bool) *ResourcePermissions {
+	grants := make(PermissionGrants, 0)
+	for _, policy := range globals.Permissions.GetResourcePolicies(resource) {
+		subject := policy[permissions.PolicyUser]
+		action := permissions.ActionFrom(policy[permissions.PolicyAction])
+		grants = append(grants, expandGrant(tx, subject, action)...)
+	}
+	for _, admin := range globals.Permissions.GetSystemAdmins() {
+		for _, action := range []permissions.Action{permissions.Read, permissions.Write} {
+			grants = append(grants, PermissionGrant{Username: admin,
+				Action: action.String(), Source: GrantSourceSysAdmin})
+		}
+	}
+	return &ResourcePermissions{Private: isPrivate, Grants: grants}
+}
+
+// expandGrant returns the users that obtain an action granted to a subject.
+// The subject can be a user, an organization or a team.
+func expandGrant(tx *gorm.DB, subject string, action permissions.Action) PermissionGrants {
+	grants := make(PermissionGrants, 0)
+
+	// Organization grants apply to all its members, if their role allows it.
+	if org, em := ByOrganizationName(tx, subject, false); em == nil {
+		for _, u := range globals.Permissions.GetUsersForGroup(*org.Name) {
+			if ok, _ := globals.Permissions.CanPerformInGroup(u, *org.Name, action); !ok {
+				continue
+			}
+			grants = append(grants, PermissionGrant{Username: u, Action: action.String(),
+				Source: GrantSourceOrganization, Organization: *org.Name,
+				Role: globals.Permissions.GetGroupsAndRolesForUser(u)[*org.Name]})
+		}
+		return grants
+	}
+
+	// Team grants apply to all the team members.
+	if parts := strings.SplitN(subject, "_t_", 2); len(parts) == 2 {
+		if _, em := ByOrganizationName(tx, parts[0], false); em == nil {
+			for _, u := range globals.Permissions.GetUsersForGroup(subject) {
+				grants = append(grants, PermissionGrant{Username: u, Action: action.String(),
+					Source: GrantSourceTeam, Organization: parts[0], Team: parts[1],
+					Role: globals.Permissions.GetGroupsAndRolesForUser(u)[subject]})
+			}
+			return grants
+		}
+	}
+
+	return append(grants, PermissionGrant{Username: subject, Action: action.String(),
+		Source: GrantSourceDirect})
+}
+
+// ExplainPermissions evaluates the read and write permissions of a user on a
+// resource, and returns the reasoning chain. It follows the same steps as
+// Permissions.IsAuthorized, through Permissions.ExplainAuthorized.
+func ExplainPermissions(resource string, isPrivate bool, username string) *PermissionExplanation {
+	exp := PermissionExplanation{Username: username, Reasons: make([]string, 0)}
+	readOK, readReasons := globals.Permissions.ExplainAuthorized(username, resource, permissions.Read)
+	writeOK, writeReasons := globals.Permissions.ExplainAuthorized(username, resource, permissions.Write)
+	exp.Read = readOK || !isPrivate
+	exp.Write = writeOK
+
+	if !isPrivate {
+		exp.Reasons = append(exp.Reasons, "The resource is public: anyone can read it")
+	}
+	if readOK || isPrivate {
+		exp.Reasons = append(exp.Reasons, readReasons...)
+	}
+	exp.Reasons = append(exp.Reasons, writeReasons...)
+	return &exp
+}
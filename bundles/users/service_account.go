@@ -0,0 +1,169 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// serviceAccountIdentityPrefix is the prefix of the identity given to service
+// accounts. Service accounts have no Auth0 identity, so they cannot log in with
+// a JWT. They authenticate with personal access tokens instead.
+const serviceAccountIdentityPrefix = "service-account|"
+
+// CreateServiceAccount encapsulates data required to create a service account.
+type CreateServiceAccount struct {
+	// The username of the service account
+	// required: true
+	Username string `json:"username" validate:"required,min=3,alphanum,notinblacklist"`
+	// Optional description of the service account
+	Name string `json:"name" validate:"omitempty,max=255"`
+	// The role of the service account in the organization: admin or member.
+	// Defaults to member.
+	Role string `json:"role" validate:"omitempty,oneof=admin member"`
+}
+
+// IsServiceAccount returns true if the user is a service account owned by an
+// organization.
+func (u *User) IsServiceAccount() bool {
+	return u.ServiceAccountOrg != nil
+}
+
+// DefaultOwner returns the owner of the resources created by the user when no
+// owner is given. Resources created by service accounts belong to their
+// organization.
+func (u *User) DefaultOwner() string {
+	if u.IsServiceAccount() {
+		return *u.ServiceAccountOrg
+	}
+	return *u.Username
+}
+
+// CreateServiceAccount creates a service account owned by an organization. The
+// service account is added to the organization with the given role.
+// The requestor must be an admin of the organization, and cannot give the
+// service account a higher role than its own.
+func (ms *OrganizationService) CreateServiceAccount(ctx context.Context, tx *gorm.DB,
+	orgName string, csa CreateServiceAccount, requestor *User) (*UserResponse, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, em
+	}
+
+	if csa.Role == "" {
+		csa.Role = permissions.Member.String()
+	}
+	role, em := permissions.RoleFrom(csa.Role)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username, *org.Name,
+		permissions.Admin); !ok {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username, *org.Name,
+		role); !ok {
+		return nil, em
+	}
+
+	identity := serviceAccountIdentityPrefix + csa.Username
+	name := csa.Name
+	email := ""
+	if org.Email != nil {
+		email = *org.Email
+	}
+	u := User{Identity: &identity, Username: &csa.Username, Name: &name, Email: &email,
+		ServiceAccountOrg: org.Name}
+	if _, em := CreateUser(ctx, tx, &u, true); em != nil {
+		return nil, em
+	}
+
+	if ok, em := globals.Permissions.AddUserGroupRole(*u.Username, *org.Name, role); !ok {
+		return nil, em
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Service account [%s] created in Organization [%s]",
+		*u.Username, *org.Name))
+
+	response := CreateUserResponse(tx, &u, requestor)
+	return &response, nil
+}
+
+// GetServiceAccount returns a service account of an organization. The
+// requestor must be an admin of the organization.
+func (ms *OrganizationService) GetServiceAccount(tx *gorm.DB, orgName, username string,
+	requestor *User) (*User, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorizedForRole(*requestor.Username, *org.Name,
+		permissions.Admin); !ok {
+		return nil, em
+	}
+
+	user, em := ByUsername(tx, username, false)
+	if em != nil {
+		return nil, em
+	}
+	if !user.IsServiceAccount() || *user.ServiceAccountOrg != *org.Name {
+		return nil, gz.NewErrorMessage(gz.ErrorUserUnknown)
+	}
+	return user, nil
+}
+
+// GetServiceAccounts returns the paginated list of service accounts of an
+// organization. The requestor must be a member of the organization.
+func (ms *OrganizationService) GetServiceAccounts(p *gz.PaginationRequest, tx *gorm.DB,
+	orgName string, requestor *User) (*UserResponses, *gz.PaginationResult, *gz.ErrMsg) {
+
+	org, em := ByOrganizationName(tx, orgName, false)
+	if em != nil {
+		return nil, nil, em
+	}
+	if ok, em := globals.Permissions.IsAuthorized(*requestor.Username, *org.Name,
+		permissions.Read); !ok {
+		return nil, nil, em
+	}
+
+	q := tx.Where("service_account_org = ?", *org.Name)
+	return UserList(p, q, requestor, true)
+}
+
+// RemoveServiceAccount removes a service account of an organization, along
+// with its access tokens. The requestor must be an admin of the organization.
+func (ms *OrganizationService) RemoveServiceAccount(ctx context.Context, tx *gorm.DB,
+	orgName, username string, requestor *User) (*UserResponse, *gz.ErrMsg) {
+
+	user, em := ms.GetServiceAccount(tx, orgName, username, requestor)
+	if em != nil {
+		return nil, em
+	}
+
+	// Remove the access tokens of the service account. They are the only way
+	// to authenticate as the service account.
+	if em := removeAccessTokens(tx, user); em != nil {
+		return nil, em
+	}
+
+	// Remove the service account from the database (soft-delete).
+	owner := UniqueOwner{Name: user.Username}
+	if err := tx.Delete(user).Delete(&owner).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	if ok, em := globals.Permissions.RemoveUser(*user.Username); !ok {
+		return nil, em
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Service account [%s] removed from Organization [%s]",
+		username, orgName))
+
+	response := CreateUserResponse(tx, user, requestor)
+	return &response, nil
+}
@@ -0,0 +1,165 @@
+package users
+
+import (
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Actions recorded in the suspension history of a user.
+const (
+	SuspensionActionSuspend   = "suspend"
+	SuspensionActionReinstate = "reinstate"
+)
+
+// SuspensionEntry is an entry of the suspension history of a user. Every
+// suspension and reinstatement is recorded, with its reason and the system
+// admin that performed it.
+//
+// swagger:model
+type SuspensionEntry struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The ID of the suspended user
+	UserID uint `gorm:"not null;index" json:"-"`
+	// The username of the suspended user
+	Username string `gorm:"not null" json:"username"`
+	// The action: suspend or reinstate
+	Action string `gorm:"not null" json:"action"`
+	// The justification of the action
+	Reason string `gorm:"type:text" json:"reason,omitempty"`
+	// Date and time the suspension expires. Empty for suspensions without
+	// expiry and for reinstatements.
+	ExpiresAt *time.Time `gorm:"type:timestamp(3) NULL" json:"expires_at,omitempty"`
+	// The username of the system admin, or moderator, that performed the action
+	By string `gorm:"not null" json:"by"`
+}
+
+// TableName sets the table name of the suspension history.
+func (SuspensionEntry) TableName() string {
+	return "user_suspensions"
+}
+
+// SuspensionEntries is a slice of SuspensionEntry
+//
+// swagger:model
+type SuspensionEntries []SuspensionEntry
+
+// CreateSuspension encapsulates data required to suspend a user.
+type CreateSuspension struct {
+	// The justification of the suspension
+	// required: true
+	Reason string `json:"reason" validate:"required,max=10000"`
+	// Optional date and time the suspension expires, in RFC 3339 format.
+	// Suspensions without expiry last until the user is reinstated.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ReinstateUser encapsulates data required to lift the suspension of a user.
+type ReinstateUser struct {
+	// The justification of the reinstatement
+	// required: true
+	Reason string `json:"reason" validate:"required,max=10000"`
+}
+
+// IsSuspended returns true if the user is suspended, ie. was suspended by a
+// moderator and the suspension has not expired.
+func (u *User) IsSuspended() bool {
+	if u.SuspendedAt == nil {
+		return false
+	}
+	return u.SuspendedUntil == nil || u.SuspendedUntil.After(time.Now())
+}
+
+// Suspend suspends a user until the given expiry, or until the user is
+// reinstated if expiresAt is nil. Suspended users cannot authenticate, neither
+// with a JWT nor with an access token, and their resources are hidden from
+// lists and search results. Suspending a suspended user replaces the reason
+// and expiry of the suspension.
+func Suspend(tx *gorm.DB, user *User, by, reason string, expiresAt *time.Time) (*SuspensionEntry, *gz.ErrMsg) {
+	now := time.Now()
+	if err := tx.Model(user).UpdateColumns(map[string]interface{}{
+		"suspended_at":    &now,
+		"suspended_until": expiresAt,
+	}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	user.SuspendedAt = &now
+	user.SuspendedUntil = expiresAt
+	return recordSuspension(tx, user, SuspensionActionSuspend, by, reason, expiresAt)
+}
+
+// Reinstate lifts the suspension of a user. It returns ErrorNonExistentResource
+// if the user is not suspended.
+func Reinstate(tx *gorm.DB, user *User, by, reason string) (*SuspensionEntry, *gz.ErrMsg) {
+	if !user.IsSuspended() {
+		return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	if err := tx.Model(user).UpdateColumns(map[string]interface{}{
+		"suspended_at":    nil,
+		"suspended_until": nil,
+	}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	user.SuspendedAt = nil
+	user.SuspendedUntil = nil
+	return recordSuspension(tx, user, SuspensionActionReinstate, by, reason, nil)
+}
+
+// recordSuspension adds an entry to the suspension history of a user.
+func recordSuspension(tx *gorm.DB, user *User, action, by, reason string,
+	expiresAt *time.Time) (*SuspensionEntry, *gz.ErrMsg) {
+
+	entry := SuspensionEntry{
+		UserID:    user.ID,
+		Username:  *user.Username,
+		Action:    action,
+		Reason:    reason,
+		ExpiresAt: expiresAt,
+		By:        by,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return &entry, nil
+}
+
+// SuspensionHistory returns a paginated list of the suspensions and
+// reinstatements of a user, newest first.
+func SuspensionHistory(p *gz.PaginationRequest, tx *gorm.DB,
+	user *User) (*SuspensionEntries, *gz.PaginationResult, *gz.ErrMsg) {
+
+	q := tx.Model(&SuspensionEntry{}).Where("user_id = ?", user.ID).Order("created_at desc, id desc")
+	var list SuspensionEntries
+	pagination, err := gz.PaginateQuery(q, &list, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &list, pagination, nil
+}
+
+// CountExpiredSuspensions returns the number of suspensions that expired after
+// since and until now. The resources of those users are listed again, without
+// any change in the database.
+func CountExpiredSuspensions(tx *gorm.DB, since, now time.Time) (int, *gz.ErrMsg) {
+	var count int
+	if err := tx.Model(&User{}).Where("suspended_until > ? AND suspended_until <= ? AND suspended_at IS NOT NULL",
+		since, now).Count(&count).Error; err != nil {
+		return 0, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return count, nil
+}
+
+// QuerySuspendedUsernames returns a subquery with the usernames of the users
+// that are currently suspended, to be used in "owner NOT IN (?)" conditions.
+func QuerySuspendedUsernames(tx *gorm.DB) interface{} {
+	return tx.Model(&User{}).Select("username").
+		Where("suspended_at IS NOT NULL AND (suspended_until IS NULL OR suspended_until > ?)",
+			time.Now()).QueryExpr()
+}
@@ -1,6 +1,8 @@
 package users
 
 import (
+	"fmt"
+
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/jinzhu/gorm"
 )
@@ -83,3 +85,19 @@ func ByTeamName(tx *gorm.DB, name string, deleted bool) (*Team, *gz.ErrMsg) {
 	}
 	return &team, nil
 }
+
+// TeamOfOrganization returns the team of an organization with the given name.
+func TeamOfOrganization(tx *gorm.DB, org *Organization, name string) (*Team, *gz.ErrMsg) {
+	var team Team
+	if QueryForTeams(tx).Where("organization_id = ? AND name = ?", org.ID, name).
+		First(&team).RecordNotFound() {
+		extra := fmt.Sprintf("Team [%s] not found in Organization [%s]", name, *org.Name)
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, nil, []string{extra})
+	}
+	return &team, nil
+}
+
+// CasbinNameForTeam returns the name of the permissions DB group of a team.
+func CasbinNameForTeam(org, team string) string {
+	return getCasbinNameForTeam(org, team)
+}
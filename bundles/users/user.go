@@ -60,6 +60,21 @@ type User struct {
 
 	// AccessTokens are personal access tokens granted to a user by a user.
 	AccessTokens gz.AccessTokens
+
+	// ServiceAccountOrg is the name of the organization that owns the user, if
+	// the user is a service account. It is nil for regular users.
+	ServiceAccountOrg *string `gorm:"index" json:"-"`
+
+	// SuspendedAt is the date and time a moderator suspended the user, if they
+	// are. Suspended users cannot authenticate.
+	SuspendedAt *time.Time `gorm:"type:timestamp(3) NULL" json:"-"`
+	// SuspendedUntil is the date and time the suspension of the user expires.
+	// It is nil for suspensions without expiry.
+	SuspendedUntil *time.Time `gorm:"type:timestamp(3) NULL;index" json:"-"`
+
+	// TokenScopes are the scopes of the personal access token used to
+	// authenticate the current request, if any. It is not stored in the DB.
+	TokenScopes []string `gorm:"-" json:"-"`
 }
 
 // Users is an slice of User
@@ -82,6 +97,8 @@ type UserResponse struct {
 	ExpFeatures string `json:"exp_features,omitempty"`
 	// True if the user is a system administrator
 	SysAdmin bool `json:"sysAdmin"`
+	// The organization that owns the user, if the user is a service account
+	ServiceAccountOrg string `json:"service_account_org,omitempty"`
 }
 
 // UserResponses is a slice of UserResponse
@@ -8,6 +8,7 @@ import (
 	"github.com/jinzhu/gorm"
 	"os"
 	"path"
+	"strings"
 	"time"
 )
 
@@ -34,12 +35,19 @@ func RemoveUser(ctx context.Context, tx *gorm.DB, username string, reqUser *User
 
 	// NOTE: we are not removing the user's folder.
 
+	// Remove the access tokens of the user. They should not outlive the
+	// account.
+	if em := removeAccessTokens(tx, user); em != nil {
+		return nil, em
+	}
+
 	// Remove the user from the database (soft-delete).
 	owner := UniqueOwner{Name: user.Username}
 	if err := tx.Delete(user).Delete(&owner).Error; err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
 	}
 
+	// This also removes the user from its organizations and teams.
 	ok, em := globals.Permissions.RemoveUser(*user.Username)
 	if !ok {
 		return nil, em
@@ -91,13 +99,17 @@ func UpdateUser(ctx context.Context, tx *gorm.DB, username string,
 }
 
 // UserList returns a list of paginated UserResponses.
+// Service accounts are only included if serviceAccounts is true.
 func UserList(p *gz.PaginationRequest, tx *gorm.DB,
-	reqUser *User) (*UserResponses, *gz.PaginationResult, *gz.ErrMsg) {
+	reqUser *User, serviceAccounts bool) (*UserResponses, *gz.PaginationResult, *gz.ErrMsg) {
 	// Get the users
 	var us Users
 
 	// Create the DB query
 	q := tx.Model(&User{})
+	if !serviceAccounts {
+		q = q.Where("service_account_org IS NULL")
+	}
 
 	pagination, err := gz.PaginateQuery(q, &us, *p)
 	if err != nil {
@@ -149,6 +161,9 @@ func CreateUserResponse(tx *gorm.DB, user, requestor *User) UserResponse {
 	}
 
 	response.SysAdmin = false
+	if user.ServiceAccountOrg != nil {
+		response.ServiceAccountOrg = *user.ServiceAccountOrg
+	}
 
 	// Private data should be included if the user is the same as the requestor or
 	// if the requestor is a sysAdmin.
@@ -266,7 +281,9 @@ func VerifyOwner(tx *gorm.DB, owner, user string,
 
 // CanPerformWithRole checks to see if the 'owner' arg is an organization or a
 // user. If the 'owner' is an organization, it verifies that the given 'user' arg
-// is authorized to act as the given Role (or above) in the organization.
+// is authorized to act as the given Role (or above) in the organization. Users
+// with a custom organization role can act as an Admin or Member if their role
+// allows all the actions of that built-in role.
 // If the 'owner' is a user, it verifies that the 'user' arg is the same as
 // the owner.
 func CanPerformWithRole(tx *gorm.DB, owner, user string,
@@ -291,6 +308,9 @@ func CanPerformWithRole(tx *gorm.DB, owner, user string,
 // CheckPermissions validates if the given user has the requested permission on
 // the resource. The resource can be public or private, and that is extracted
 // from the argument isPrivate.
+// Actions other than Read and Write (eg. Delete) require write access to the
+// resource, and a role that allows the action if the access is granted through
+// an organization.
 func CheckPermissions(tx *gorm.DB, resource string, user *User, isPrivate bool,
 	per permissions.Action) (bool, *gz.ErrMsg) {
 
@@ -354,9 +374,10 @@ func GetOwnerProfile(tx *gorm.DB, owner string, user *User) (*OwnerProfile, *gz.
 	return &OwnerProfile{OwnerType: OwnerTypeOrg, Org: &or}, nil
 }
 
-// AccessTokenList returns a list of paginated AccessTokens.
+// AccessTokenList returns a list of paginated AccessTokens, including their
+// scopes and the IP address they were last used from.
 func AccessTokenList(p *gz.PaginationRequest, tx *gorm.DB,
-	reqUser *User) (*gz.AccessTokens, *gz.PaginationResult, *gz.ErrMsg) {
+	reqUser *User) (*AccessTokenResponses, *gz.PaginationResult, *gz.ErrMsg) {
 
 	var accessTokens gz.AccessTokens
 
@@ -370,12 +391,47 @@ func AccessTokenList(p *gz.PaginationRequest, tx *gorm.DB,
 		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
 	}
 
-	// Strip out the keys
-	for i := range accessTokens {
-		accessTokens[i].Key = ""
+	responses := accessTokenResponses(tx, accessTokens)
+	return &responses, pagination, nil
+}
+
+// UserAccessTokens returns all the access tokens of a user, without their
+// keys.
+func UserAccessTokens(tx *gorm.DB, user *User) (AccessTokenResponses, *gz.ErrMsg) {
+	var accessTokens gz.AccessTokens
+	if err := tx.Where("user_id = ?", user.ID).Find(&accessTokens).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
 	}
+	return accessTokenResponses(tx, accessTokens), nil
+}
+
+// accessTokenResponses creates the responses of the given access tokens,
+// stripping out their keys.
+func accessTokenResponses(tx *gorm.DB, accessTokens gz.AccessTokens) AccessTokenResponses {
+	responses := make(AccessTokenResponses, 0, len(accessTokens))
+	for _, token := range accessTokens {
+		// Strip out the keys
+		token.Key = ""
+		details := GetAccessTokenDetails(tx, token.ID)
+		responses = append(responses, AccessTokenResponse{AccessToken: token,
+			Scopes: ScopesFromString(details.Scopes), LastUsedIP: details.LastUsedIP})
+	}
+	return responses
+}
 
-	return &accessTokens, pagination, nil
+// removeAccessTokens permanently deletes the access tokens of a user, and
+// their details.
+func removeAccessTokens(tx *gorm.DB, user *User) *gz.ErrMsg {
+	var tokenIDs []uint
+	tx.Model(&gz.AccessToken{}).Where("user_id = ?", user.ID).Pluck("id", &tokenIDs)
+	if len(tokenIDs) == 0 {
+		return nil
+	}
+	tx.Where("access_token_id IN (?)", tokenIDs).Delete(&AccessTokenDetails{})
+	if err := tx.Unscoped().Where("id IN (?)", tokenIDs).Delete(&gz.AccessToken{}).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return nil
 }
 
 // AccessTokenDelete removes a personal access token. This function requires the user's JWT, which
@@ -389,13 +445,24 @@ func AccessTokenDelete(jwtUser *User, tx *gorm.DB, accessToken gz.AccessToken) (
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
 	}
 
-	// Permanently delete the token
+	// Permanently delete the token, and its details
+	tx.Where("access_token_id = ?", token.ID).Delete(&AccessTokenDetails{})
 	tx.Unscoped().Delete(&token)
 	return nil, nil
 }
 
-// AccessTokenCreate creates a new access token for a user.
-func AccessTokenCreate(jwtUser *User, tx *gorm.DB, accessTokenCreateRequest gz.AccessTokenCreateRequest) (interface{}, *gz.ErrMsg) {
+// AccessTokenCreate creates a new access token for a user, with optional scopes
+// and expiration.
+func AccessTokenCreate(jwtUser *User, tx *gorm.DB, accessTokenCreateRequest AccessTokenCreateRequest) (interface{}, *gz.ErrMsg) {
+
+	for _, scope := range accessTokenCreateRequest.Scopes {
+		if em := ValidateScope(scope); em != nil {
+			return nil, em
+		}
+	}
+	if accessTokenCreateRequest.Expires != nil && !accessTokenCreateRequest.Expires.After(time.Now()) {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"expires"})
+	}
 
 	newToken, saltedToken, err := accessTokenCreateRequest.Create(tx)
 
@@ -403,6 +470,15 @@ func AccessTokenCreate(jwtUser *User, tx *gorm.DB, accessTokenCreateRequest gz.A
 		return nil, err
 	}
 
+	saltedToken.Expires = accessTokenCreateRequest.Expires
 	tx.Model(jwtUser).Association("AccessTokens").Append(saltedToken)
+
+	if len(accessTokenCreateRequest.Scopes) > 0 {
+		details := AccessTokenDetails{AccessTokenID: saltedToken.ID,
+			Scopes: strings.Join(accessTokenCreateRequest.Scopes, " ")}
+		if err := tx.Create(&details).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+	}
 	return newToken, nil
 }
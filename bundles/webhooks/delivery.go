@@ -0,0 +1,326 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Status of a delivery.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// MaxAttempts is the number of times a payload is sent before the delivery is
+// marked as failed.
+const MaxAttempts = 5
+
+// retryDelay is the delay before the first retry of a delivery. It doubles on
+// each attempt.
+const retryDelay = time.Minute
+
+// claimTimeout is the time a claimed delivery has to be sent and its result
+// recorded. After it, the delivery is due again.
+const claimTimeout = time.Minute
+
+// Headers of the payload requests.
+const (
+	// EventHeader is the name of the event, eg. model.create
+	EventHeader = "X-Fuel-Event"
+	// DeliveryHeader is the ID of the delivery. It is the same in retries.
+	DeliveryHeader = "X-Fuel-Delivery"
+	// SignatureHeader is the HMAC-SHA256 of the body, keyed with the webhook
+	// secret, as "sha256=<hex digest>".
+	SignatureHeader = "X-Fuel-Signature-256"
+)
+
+// client is the HTTP client used to send the payloads. It does not follow
+// redirects, and only connects to public addresses, so webhooks cannot be used
+// to reach the internal network or the cloud metadata service.
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: checkDialAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// blockedNetworks are the networks webhooks cannot reach, besides the
+// loopback, private, link-local and multicast ones.
+var blockedNetworks = []*net.IPNet{
+	// "This" network
+	mustParseCIDR("0.0.0.0/8"),
+	// Shared address space, used by some cloud metadata services
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+// mustParseCIDR parses a CIDR. It panics if the CIDR is invalid.
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isBlockedIP returns true if webhooks cannot be delivered to the given IP.
+// The networks in globals.WebhookAllowedNetworks are never blocked.
+func isBlockedIP(ip net.IP) bool {
+	for _, network := range globals.WebhookAllowedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDialAddress is the dialer control function of the webhook client. It
+// is called with the resolved address, so it also rejects host names that
+// resolve to blocked IPs.
+func checkDialAddress(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isBlockedIP(ip) {
+		return fmt.Errorf("address %s is not allowed", host)
+	}
+	return nil
+}
+
+// Payload is the JSON body POSTed to webhooks.
+type Payload struct {
+	// The event, eg. model.create
+	Event string `json:"event"`
+	// Date and time of the event
+	Timestamp time.Time `json:"timestamp"`
+	// The username of the user that caused the event, if known
+	Actor string `json:"actor,omitempty"`
+	// The resource type: model, world or collection
+	ResourceType string `json:"resource_type"`
+	// The owner of the resource
+	Owner string `json:"owner"`
+	// The name of the resource
+	Name string `json:"name"`
+	// Event specific data, eg. the new version or the report reason
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Delivery is an entry in the delivery log of a webhook. Pending deliveries
+// are sent by a periodic job, and retried with an increasing delay until they
+// succeed or MaxAttempts is reached.
+type Delivery struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The webhook
+	WebhookID uint `gorm:"not null;index" json:"webhook_id"`
+	// The event, eg. model.create
+	Event string `gorm:"not null" json:"event"`
+	// The JSON payload
+	Payload string `gorm:"type:text" json:"payload"`
+	// The status of the delivery: pending, succeeded or failed
+	Status string `gorm:"not null;index" json:"status"`
+	// The number of times the payload was sent
+	Attempts int `json:"attempts"`
+	// Date and time of the next attempt, for pending deliveries
+	NextAttemptAt time.Time `gorm:"type:timestamp(3) NULL;index" json:"next_attempt_at"`
+	// The HTTP status code of the last response, if any
+	ResponseStatus int `json:"response_status,omitempty"`
+	// The error of the last attempt, if it did not get a response. Response
+	// bodies are not stored.
+	Response string `gorm:"type:text" json:"response,omitempty"`
+}
+
+// TableName sets the table name of webhook deliveries.
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Deliveries is a slice of Delivery
+//
+// swagger:model WebhookDeliveries
+type Deliveries []Delivery
+
+// Sign returns the signature of a payload, as sent in the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue adds a pending delivery of the payload to each active webhook of
+// the owner subscribed to the event. Events webhooks cannot subscribe to are
+// ignored. The deliveries are sent once the transaction is committed.
+func Enqueue(tx *gorm.DB, owner string, payload Payload) *gz.ErrMsg {
+	if !IsEvent(payload.Event) {
+		return nil
+	}
+	var hooks []Webhook
+	if err := tx.Where("owner = ? AND active = ?", owner, true).Find(&hooks).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorMarshalJSON, err)
+	}
+	for _, wh := range hooks {
+		if !wh.Subscribed(payload.Event) {
+			continue
+		}
+		d := Delivery{
+			WebhookID:     wh.ID,
+			Event:         payload.Event,
+			Payload:       string(body),
+			Status:        DeliveryPending,
+			NextAttemptAt: payload.Timestamp,
+		}
+		if err := tx.Create(&d).Error; err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+	}
+	return nil
+}
+
+// ListDeliveries returns a paginated list of the deliveries of a webhook,
+// newest first.
+func ListDeliveries(p *gz.PaginationRequest, tx *gorm.DB, webhookID uint) (*Deliveries, *gz.PaginationResult, *gz.ErrMsg) {
+	var list Deliveries
+	q := tx.Model(&Delivery{}).Where("webhook_id = ?", webhookID).Order("created_at desc, id desc")
+	pagination, err := gz.PaginateQuery(q, &list, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &list, pagination, nil
+}
+
+// DueDeliveries returns up to limit pending deliveries whose next attempt is
+// due, oldest first.
+func DueDeliveries(tx *gorm.DB, limit int) (Deliveries, *gz.ErrMsg) {
+	var list Deliveries
+	if err := tx.Where("status = ? AND next_attempt_at <= ?", DeliveryPending, time.Now()).
+		Order("next_attempt_at, id").Limit(limit).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return list, nil
+}
+
+// Deliver sends a pending delivery to its webhook and records the result. A
+// 2xx response marks the delivery as succeeded. Otherwise it is retried later,
+// or marked as failed after MaxAttempts. Deliveries of inactive webhooks are
+// marked as failed without being sent.
+// The delivery is claimed first, so it is not sent again by other servers that
+// found it due at the same time. Deliveries claimed by others are skipped.
+func Deliver(tx *gorm.DB, d *Delivery) *gz.ErrMsg {
+	q := tx.Model(&Delivery{}).Where("id = ? AND status = ? AND attempts = ?",
+		d.ID, DeliveryPending, d.Attempts).Updates(map[string]interface{}{
+		"attempts":        d.Attempts + 1,
+		"next_attempt_at": time.Now().Add(claimTimeout),
+	})
+	if q.Error != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, q.Error)
+	}
+	if q.RowsAffected == 0 {
+		return nil
+	}
+	d.Attempts++
+
+	var wh Webhook
+	if err := tx.First(&wh, d.WebhookID).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	if !wh.Active {
+		d.Status = DeliveryFailed
+		d.Response = "The webhook is inactive"
+		if err := tx.Save(d).Error; err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		return nil
+	}
+
+	d.ResponseStatus = 0
+	status, err := send(&wh, d)
+	if err != nil {
+		d.Response = err.Error()
+	} else {
+		d.ResponseStatus = status
+		d.Response = ""
+	}
+
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		d.Status = DeliverySucceeded
+	case d.Attempts >= MaxAttempts:
+		d.Status = DeliveryFailed
+	default:
+		d.NextAttemptAt = time.Now().Add(retryDelay << uint(d.Attempts-1))
+	}
+	if err := tx.Save(d).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// send POSTs the payload of a delivery to a webhook. It returns the response
+// status code. Redirects are not followed.
+func send(wh *Webhook, d *Delivery) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Fuel-Webhook")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(SignatureHeader, Sign(wh.Secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("delivery failed: %w", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
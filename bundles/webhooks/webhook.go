@@ -0,0 +1,245 @@
+package webhooks
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Events that can be subscribed to. Model and world events are prefixed with
+// the resource type, eg. model.create.
+const (
+	EventCreate   = "create"
+	EventUpdate   = "update"
+	EventDelete   = "delete"
+	EventTransfer = "transfer"
+	EventLike     = "like"
+	EventReport   = "report"
+	// EventReview is the creation of a model review, with its status.
+	EventReview = "review"
+	// EventUnlike is the removal of a like. It is only streamed to the
+	// /events subscribers, to keep the like counters up to date.
+	EventUnlike = "unlike"
+)
+
+// Events is the list of all the events a webhook can subscribe to.
+var Events = []string{
+	"model.create", "model.update", "model.delete", "model.transfer", "model.like",
+	"model.report", "model.review",
+	"world.create", "world.update", "world.delete", "world.transfer", "world.like",
+	"world.report",
+	"collection.create", "collection.update", "collection.delete", "collection.transfer",
+	"collection.report",
+}
+
+// Webhook is a subscription of a user or organization to the events of its
+// resources. Each event is POSTed as a JSON payload to the webhook URL, signed
+// with the webhook secret.
+type Webhook struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The user or organization whose resources trigger the webhook
+	Owner string `gorm:"not null;index" json:"owner"`
+	// The URL the payloads are POSTed to
+	URL string `gorm:"type:varchar(1024);not null" json:"url"`
+	// The key used to sign the payloads. It is never returned.
+	Secret string `gorm:"not null" json:"-"`
+	// Comma separated list of the subscribed events. Empty means all events.
+	EventList string `gorm:"column:events;type:text" json:"-"`
+	// The subscribed events, as returned in REST responses
+	Events []string `gorm:"-" json:"events"`
+	// Inactive webhooks don't fire
+	Active bool `gorm:"not null" json:"active"`
+	// The username of the user that created the webhook
+	Creator string `json:"creator"`
+}
+
+// Webhooks is a slice of Webhook
+//
+// swagger:model
+type Webhooks []Webhook
+
+// AfterFind fills the events of a webhook read from the DB.
+func (wh *Webhook) AfterFind() error {
+	wh.Events = []string{}
+	if wh.EventList != "" {
+		wh.Events = strings.Split(wh.EventList, ",")
+	}
+	return nil
+}
+
+// Subscribed returns true if the webhook fires on the given event.
+func (wh *Webhook) Subscribed(event string) bool {
+	if wh.EventList == "" {
+		return true
+	}
+	for _, e := range strings.Split(wh.EventList, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhook encapsulates data required to create a webhook.
+type CreateWebhook struct {
+	// The http or https URL the payloads are POSTed to
+	// required: true
+	URL string `json:"url" validate:"required,url,max=1024"`
+	// The key used to sign the payloads with HMAC-SHA256
+	// required: true
+	Secret string `json:"secret" validate:"required,min=16,max=256"`
+	// Optional list of events. All events are sent if empty.
+	Events []string `json:"events" validate:"omitempty,dive,webhookevent"`
+	// Optional. Defaults to true.
+	Active *bool `json:"active"`
+}
+
+// UpdateWebhook encapsulates data required to update a webhook. Missing
+// fields are not changed.
+type UpdateWebhook struct {
+	URL    *string   `json:"url" validate:"omitempty,url,max=1024"`
+	Secret *string   `json:"secret" validate:"omitempty,min=16,max=256"`
+	Events *[]string `json:"events" validate:"omitempty,dive,webhookevent"`
+	Active *bool     `json:"active"`
+}
+
+// IsEvent returns true if the given string is an event webhooks can subscribe
+// to.
+func IsEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL returns an error if the given URL is not an http or https URL, or
+// if its host is a blocked IP address. Host names are checked when the
+// payloads are delivered, as they can resolve to different addresses.
+func checkURL(rawURL string) *gz.ErrMsg {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, err, []string{"url"})
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && isBlockedIP(ip) {
+		return gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"url"})
+	}
+	return nil
+}
+
+// Create creates a webhook for an owner.
+func Create(tx *gorm.DB, owner, creator string, cw CreateWebhook) (*Webhook, *gz.ErrMsg) {
+	if em := checkURL(cw.URL); em != nil {
+		return nil, em
+	}
+	wh := Webhook{
+		Owner:     owner,
+		URL:       cw.URL,
+		Secret:    cw.Secret,
+		EventList: strings.Join(cw.Events, ","),
+		Active:    cw.Active == nil || *cw.Active,
+		Creator:   creator,
+	}
+	if err := tx.Create(&wh).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	wh.AfterFind()
+	return &wh, nil
+}
+
+// List returns a paginated list of the webhooks of an owner.
+func List(p *gz.PaginationRequest, tx *gorm.DB, owner string) (*Webhooks, *gz.PaginationResult, *gz.ErrMsg) {
+	var list Webhooks
+	q := tx.Model(&Webhook{}).Where("owner = ?", owner).Order("id")
+	pagination, err := gz.PaginateQuery(q, &list, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	return &list, pagination, nil
+}
+
+// Get returns a webhook of an owner.
+func Get(tx *gorm.DB, owner string, id uint) (*Webhook, *gz.ErrMsg) {
+	var wh Webhook
+	if q := tx.Where("owner = ? AND id = ?", owner, id).First(&wh); q.Error != nil {
+		if q.RecordNotFound() {
+			return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+		}
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, q.Error)
+	}
+	return &wh, nil
+}
+
+// Update changes a webhook of an owner.
+func Update(tx *gorm.DB, owner string, id uint, uw UpdateWebhook) (*Webhook, *gz.ErrMsg) {
+	wh, em := Get(tx, owner, id)
+	if em != nil {
+		return nil, em
+	}
+	if uw.URL != nil {
+		if em := checkURL(*uw.URL); em != nil {
+			return nil, em
+		}
+		wh.URL = *uw.URL
+	}
+	if uw.Secret != nil {
+		wh.Secret = *uw.Secret
+	}
+	if uw.Events != nil {
+		wh.EventList = strings.Join(*uw.Events, ",")
+	}
+	if uw.Active != nil {
+		wh.Active = *uw.Active
+	}
+	if err := tx.Save(wh).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	wh.AfterFind()
+	return wh, nil
+}
+
+// Remove removes a webhook of an owner, and its delivery log.
+func Remove(tx *gorm.DB, owner string, id uint) *gz.ErrMsg {
+	wh, em := Get(tx, owner, id)
+	if em != nil {
+		return em
+	}
+	if err := tx.Where("webhook_id = ?", wh.ID).Delete(&Delivery{}).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	if err := tx.Delete(wh).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return nil
+}
+
+// RemoveOwnerWebhooks removes all the webhooks of an owner, and their delivery
+// logs.
+func RemoveOwnerWebhooks(tx *gorm.DB, owner string) *gz.ErrMsg {
+	var ids []uint
+	if err := tx.Model(&Webhook{}).Where("owner = ?", owner).Pluck("id", &ids).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("webhook_id IN (?)", ids).Delete(&Delivery{}).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	if err := tx.Where("id IN (?)", ids).Delete(&Webhook{}).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return nil
+}
@@ -51,7 +51,7 @@ type World struct {
 	Name *string `gorm:"unique_index:idx_world_owner" json:"name,omitempty"`
 
 	// Unique identifier for the world
-	UUID *string `json:"-"`
+	UUID *string `gorm:"index" json:"-"`
 
 	// A description of the world (max 65,535 chars)
 	// Interesting post about TEXT vs VARCHAR(30000) performance:
@@ -61,6 +61,12 @@ type World struct {
 	// Number of likes
 	Likes int `json:"likes,omitempty"`
 
+	// Average of the star ratings of all the versions, from 1 to 5
+	RatingAverage float64 `gorm:"not null;default:0" json:"rating_average,omitempty"`
+
+	// Number of star ratings
+	RatingCount int `gorm:"not null;default:0" json:"rating_count,omitempty"`
+
 	// Bytes of the world, when downloaded as a zip
 	Filesize int `json:"filesize,omitempty"`
 
@@ -97,6 +103,27 @@ type World struct {
 
 	// Private - True to make this a private resource
 	Private *bool `json:"private,omitempty"`
+
+	// Archived - True to make this a read-only resource
+	Archived *bool `gorm:"default:false" json:"archived,omitempty"`
+
+	// Hidden - True if a moderator hid this world from lists and search results
+	Hidden bool `gorm:"not null;default:false" json:"hidden,omitempty"`
+
+	// DeprecatedAt is the date and time the world was deprecated, if it is.
+	DeprecatedAt *time.Time `gorm:"type:timestamp(3) NULL" json:"deprecated_at,omitempty"`
+
+	// DeprecationMessage is an optional message explaining the deprecation.
+	DeprecationMessage *string `gorm:"type:text" json:"deprecation_message,omitempty"`
+
+	// SuccessorID is the ID of the world replacing this deprecated world, if any.
+	SuccessorID *uint `json:"-"`
+
+	// ForkedFromID is the ID of the world this world was cloned from, if any.
+	ForkedFromID *uint `sql:"index" json:"-"`
+
+	// ForkedFromVersion is the version of the source world at the time of cloning.
+	ForkedFromVersion *int `json:"-"`
 }
 
 // ModelInclude represents an external model "included" in a world
@@ -174,6 +201,15 @@ func GetWorldByName(tx *gorm.DB, name string, owner string) (*World, error) {
 	return &w, nil
 }
 
+// GetWorldByID queries a World by its ID.
+func GetWorldByID(tx *gorm.DB, id uint) (*World, error) {
+	var w World
+	if err := QueryForWorlds(tx).Where("id = ?", id).First(&w).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
 // NewWorldAndUUID creates a World struct with a new UUID.
 func NewWorldAndUUID(name, desc, location, owner, creator *string,
 	lic license.License, permission int, tags models.Tags,
@@ -254,6 +290,9 @@ type CloneWorld struct {
 
 // UpdateWorld encapsulates data that can be updated in a world
 type UpdateWorld struct {
+	// Optional new name. The previous name is kept as an alias that redirects
+	// to the new one.
+	Name *string `json:"name" validate:"omitempty,noforwardslash,min=3,nopercent" form:"name"`
 	// Optional description
 	Description *string `json:"description" form:"description"`
 	// Optional list of tags (comma separated)
@@ -262,6 +301,8 @@ type UpdateWorld struct {
 	File string `json:"file" validate:"omitempty,gt=0" form:"-"`
 	// Optional privacy/visibility setting.
 	Private *bool `json:"private" validate:"omitempty" form:"private"`
+	// Archived read-only setting
+	Archived *bool `json:"archived" validate:"omitempty" form:"archived"`
 	// Metadata associated to this world
 	Metadata *WorldMetadata `json:"metadata" form:"metadata"`
 }
@@ -273,5 +314,5 @@ type CreateReport struct {
 
 // IsEmpty returns true is the struct is empty.
 func (uw UpdateWorld) IsEmpty() bool {
-	return uw.Description == nil && uw.Tags == nil
+	return uw.Name == nil && uw.Description == nil && uw.Tags == nil && uw.Archived == nil
 }
@@ -0,0 +1,41 @@
+package worlds
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// WorldAlias records a previous name of a renamed world. It is used to
+// redirect requests made to the old name to the current one.
+type WorldAlias struct {
+	// Override default GORM Model fields
+	ID        uint      `gorm:"primary_key"`
+	CreatedAt time.Time `gorm:"type:timestamp(3) NULL"`
+	UpdatedAt time.Time
+	// DeletedAt is not included in order to disable the soft delete feature.
+
+	// The ID of the renamed world
+	WorldID uint `sql:"index"`
+
+	// The owner of the world at the time it was renamed
+	Owner *string `gorm:"unique_index:idx_world_alias"`
+
+	// The previous name of the world
+	Name *string `gorm:"unique_index:idx_world_alias"`
+}
+
+// GetWorldByAlias queries a World by one of its previous names and owner.
+func GetWorldByAlias(tx *gorm.DB, name string, owner string) (*World, error) {
+	var alias WorldAlias
+	if err := tx.Where("owner = ? AND name = ?", owner, name).First(&alias).Error; err != nil {
+		return nil, err
+	}
+	return GetWorldByID(tx, alias.WorldID)
+}
+
+// removeWorldAlias removes the alias with the given owner and name, if any.
+// It is used when a world takes a name that was previously used as an alias.
+func removeWorldAlias(tx *gorm.DB, owner, name string) error {
+	return tx.Where("owner = ? AND name = ?", owner, name).Delete(&WorldAlias{}).Error
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
+	res "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/jinzhu/gorm"
@@ -26,6 +27,11 @@ type worldElastic struct {
 	Owner       string `json:"owner"`
 	Tags        string `json:"tags,omitempty"`
 	Creator     string `json:"creator"`
+	Archived    bool   `json:"archived"`
+	Deprecated  bool   `json:"deprecated"`
+	// The average star rating and the number of ratings, to sort by rating
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
 }
 
 // ElasticSearchRemoveWorld removes a world from elastic search
@@ -72,11 +78,15 @@ func ElasticSearchUpdateWorld(ctx context.Context, world World) {
 
 	// Build the ElasticSearch struct.
 	m := worldElastic{
-		Name:        *world.Name,
-		Owner:       *world.Owner,
-		Creator:     *world.Creator,
-		Description: *world.Description,
-		Tags:        tagsBuilder.String(),
+		Name:          *world.Name,
+		Owner:         *world.Owner,
+		Creator:       *world.Creator,
+		Archived:      res.IsArchived(world.Archived),
+		Deprecated:    world.DeprecatedAt != nil,
+		RatingAverage: world.RatingAverage,
+		RatingCount:   world.RatingCount,
+		Description:   *world.Description,
+		Tags:          tagsBuilder.String(),
 	}
 
 	// Add in metadata
@@ -57,6 +57,23 @@ func (ws *Service) GetWorld(tx *gorm.DB, owner, name string,
 	return w, nil
 }
 
+// getWorldForDownload returns a world by its name and owner's name, to read its
+// files. Private worlds can also be read with a valid share token carried
+// by the context, even without a user.
+func (ws *Service) getWorldForDownload(ctx context.Context, tx *gorm.DB, owner, name string,
+	user *users.User) (*World, *gz.ErrMsg) {
+
+	world, em := ws.GetWorld(tx, owner, name, user)
+	if em == nil || em.ErrCode != gz.ErrorUnauthorized {
+		return world, em
+	}
+	shared, err := GetWorldByName(tx, name, owner)
+	if err != nil || !res.CanReadWithShareToken(ctx, tx, shared) {
+		return nil, em
+	}
+	return shared, nil
+}
+
 // GetWorldProto returns a world proto struct, given a world name and owner.
 // The user argument is the user requesting the operation.
 func (ws *Service) GetWorldProto(ctx context.Context, tx *gorm.DB, owner,
@@ -77,6 +94,8 @@ func (ws *Service) GetWorldProto(ctx context.Context, tx *gorm.DB, owner,
 
 	fuelWorld := ws.WorldToProto(world)
 	fuelWorld.Version = proto.Int64(int64(latestVersion))
+	fuelWorld.ForkedFrom = ws.forkedFromProto(tx, world, user)
+	fuelWorld.Successor = ws.SuccessorProto(tx, world, user)
 
 	if user != nil {
 		if ml, _ := ws.getWorldLike(tx, world, user); ml != nil {
@@ -87,20 +106,35 @@ func (ws *Service) GetWorldProto(ctx context.Context, tx *gorm.DB, owner,
 	return fuelWorld, nil
 }
 
-// WorldList returns a paginated list of worlds.
-// If the likedBy argument is set, it will return the list of worlds liked by an user.
+// WorldList returns a paginated list of worlds, filtered and sorted with the
+// given options. See res.ListOptions.
+// Worlds hidden by moderators are only listed to their owners.
 // TODO: find a way to MERGE this with the one from Worlds service.
 func (ws *Service) WorldList(p *gz.PaginationRequest, tx *gorm.DB, owner *string,
-	order, search string, likedBy *users.User, user *users.User) (*fuel.Worlds, *gz.PaginationResult, *gz.ErrMsg) {
+	opts res.ListOptions) (*fuel.Worlds, *gz.PaginationResult, *gz.ErrMsg) {
+
+	order, sortBy, search, likedBy, user := opts.Order, opts.SortBy, opts.Search, opts.LikedBy, opts.User
+	archived, deprecated := opts.Archived, opts.Deprecated
 
 	var worldList Worlds
 	// Create query
-	q := QueryForWorlds(tx)
+	q := res.QueryForDeprecated(res.QueryForArchived(QueryForWorlds(tx), archived), deprecated)
+	q = res.QueryForHidden(q, user)
 
+	// Deprecated worlds rank lower, unless they were explicitly requested.
+	var orderBy []string
+	if deprecated == nil {
+		orderBy = append(orderBy, "deprecated_at IS NOT NULL")
+	}
+	if sortBy == res.SortByRating {
+		orderBy = append(orderBy, res.RatingOrder(order)...)
+	}
 	// Override default Order BY, unless the user explicitly requested ASC order
 	if !(order != "" && strings.ToLower(order) == "asc") {
-		q = q.Order("created_at desc, id", true)
+		orderBy = append(orderBy, "created_at desc")
 	}
+	orderBy = append(orderBy, "id")
+	q = q.Order(strings.Join(orderBy, ", "), true)
 
 	// Check if we should return the list of liked worlds instead.
 	if likedBy != nil {
@@ -108,7 +142,7 @@ func (ws *Service) WorldList(p *gz.PaginationRequest, tx *gorm.DB, owner *string
 	} else {
 
 		// filter resources based on privacy setting
-		q = res.QueryForResourceVisibility(tx, q, owner, user)
+		q = res.QueryForSharedResourceVisibility(tx, q, owner, user)
 
 		// If a search criteria was defined, then also apply a fulltext search on "world's name + description + tags"
 		if search != "" {
@@ -149,8 +183,12 @@ func (ws *Service) WorldList(p *gz.PaginationRequest, tx *gorm.DB, owner *string
 
 	var worldsProto fuel.Worlds
 	// Encode worlds into a protobuf message
+	blankQuery := tx.New()
+	related := relatedWorlds(blankQuery, worldList)
 	for _, w := range worldList {
 		fuelWorld := ws.WorldToProto(&w)
+		fuelWorld.ForkedFrom = forkedFromProto(blankQuery, &w, related, user)
+		fuelWorld.Successor = successorProto(blankQuery, &w, related, user)
 		worldsProto.Worlds = append(worldsProto.Worlds, fuelWorld)
 	}
 
@@ -167,7 +205,7 @@ func (ws *Service) RemoveWorld(ctx context.Context, tx *gorm.DB, owner, name str
 	}
 
 	// make sure the user requesting removal has the correct permissions
-	ok, err := globals.Permissions.IsAuthorized(*user.Username, *world.UUID, permissions.Write)
+	ok, err := globals.Permissions.CanPerformAsOwner(*user.Username, *world.Owner, permissions.Delete)
 	if !ok {
 		return err
 	}
@@ -190,15 +228,17 @@ func (ws *Service) RemoveWorld(ctx context.Context, tx *gorm.DB, owner, name str
 func (ws *Service) WorldToProto(world *World) *fuel.World {
 	fuelWorld := fuel.World{
 		// Note: time.RFC3339 is the format expected by Go's JSON unmarshal
-		CreatedAt:  proto.String(world.CreatedAt.UTC().Format(time.RFC3339)),
-		UpdatedAt:  proto.String(world.UpdatedAt.UTC().Format(time.RFC3339)),
-		Name:       proto.String(*world.Name),
-		Owner:      proto.String(*world.Owner),
-		Likes:      proto.Int64(int64(world.Likes)),
-		Downloads:  proto.Int64(int64(world.Downloads)),
-		Filesize:   proto.Int64(int64(world.Filesize)),
-		Permission: proto.Int64(int64(world.Permission)),
-		LicenseId:  proto.Uint64(uint64(world.LicenseID)),
+		CreatedAt:     proto.String(world.CreatedAt.UTC().Format(time.RFC3339)),
+		UpdatedAt:     proto.String(world.UpdatedAt.UTC().Format(time.RFC3339)),
+		Name:          proto.String(*world.Name),
+		Owner:         proto.String(*world.Owner),
+		Likes:         proto.Int64(int64(world.Likes)),
+		Downloads:     proto.Int64(int64(world.Downloads)),
+		Filesize:      proto.Int64(int64(world.Filesize)),
+		Permission:    proto.Int64(int64(world.Permission)),
+		LicenseId:     proto.Uint64(uint64(world.LicenseID)),
+		RatingAverage: proto.Float64(world.RatingAverage),
+		RatingCount:   proto.Int64(int64(world.RatingCount)),
 	}
 
 	// Optional fields
@@ -229,6 +269,15 @@ func (ws *Service) WorldToProto(world *World) *fuel.World {
 	if world.Private != nil {
 		fuelWorld.Private = proto.Bool(*world.Private)
 	}
+	if world.Archived != nil {
+		fuelWorld.Archived = proto.Bool(*world.Archived)
+	}
+	if world.DeprecatedAt != nil {
+		fuelWorld.Deprecated = proto.Bool(true)
+		if world.DeprecationMessage != nil {
+			fuelWorld.DeprecationMessage = proto.String(*world.DeprecationMessage)
+		}
+	}
 
 	if len(world.Tags) > 0 {
 		tags := []string{}
@@ -297,8 +346,10 @@ func (ws *Service) CreateWorldLike(tx *gorm.DB, owner, worldName string, user *u
 	return &worldLike, nil
 }
 
-// CreateWorldReport creates a WorldReport
-func (ws *Service) CreateWorldReport(tx *gorm.DB, owner, worldName, reason string) (*WorldReport, *gz.ErrMsg) {
+// CreateWorldReport creates a WorldReport. The reporter is nil for anonymous
+// reports.
+func (ws *Service) CreateWorldReport(tx *gorm.DB, owner, worldName, reason string,
+	reporter *users.User) (*WorldReport, *gz.ErrMsg) {
 
 	world, err := GetWorldByName(tx, worldName, owner)
 
@@ -312,6 +363,9 @@ func (ws *Service) CreateWorldReport(tx *gorm.DB, owner, worldName, reason strin
 		},
 		WorldID: &world.ID,
 	}
+	if reporter != nil {
+		worldReport.ReporterID = &reporter.ID
+	}
 
 	if err := tx.Create(&worldReport).Error; err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
@@ -320,6 +374,18 @@ func (ws *Service) CreateWorldReport(tx *gorm.DB, owner, worldName, reason strin
 	return &worldReport, nil
 }
 
+// CountWorldReporters returns the number of distinct users that reported a
+// world since the given time. Anonymous reports are not counted.
+func (ws *Service) CountWorldReporters(tx *gorm.DB, world *World, since time.Time) (int, *gz.ErrMsg) {
+	var count int
+	if err := tx.Model(&WorldReport{}).
+		Where("world_id = ? AND reporter_id IS NOT NULL AND created_at >= ?", world.ID, since).
+		Select("COUNT(DISTINCT(reporter_id))").Count(&count).Error; err != nil {
+		return 0, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	return count, nil
+}
+
 // RemoveWorldLike removes a worldLike.
 // Returns the removed worldLike or a gz.errMsg.
 func (ws *Service) RemoveWorldLike(tx *gorm.DB, owner, worldName string, user *users.User) (*WorldLike, *gz.ErrMsg) {
@@ -433,7 +499,7 @@ func (ws *Service) increaseDownloadCounter(tx *gorm.DB, world *World, delta uint
 func (ws *Service) GetFile(ctx context.Context, tx *gorm.DB, owner, name, path,
 	version string, user *users.User) (*[]byte, int, *gz.ErrMsg) {
 
-	world, em := ws.GetWorld(tx, owner, name, user)
+	world, em := ws.getWorldForDownload(ctx, tx, owner, name, user)
 	if em != nil {
 		return nil, -1, em
 	}
@@ -445,7 +511,7 @@ func (ws *Service) GetFile(ctx context.Context, tx *gorm.DB, owner, name, path,
 func (ws *Service) FileTree(ctx context.Context, tx *gorm.DB, owner, worldName,
 	version string, user *users.User) (*fuel.FileTree, *gz.ErrMsg) {
 
-	world, em := ws.GetWorld(tx, owner, worldName, user)
+	world, em := ws.getWorldForDownload(ctx, tx, owner, worldName, user)
 	if em != nil {
 		return nil, em
 	}
@@ -462,7 +528,7 @@ func (ws *Service) FileTree(ctx context.Context, tx *gorm.DB, owner, worldName,
 func (ws *Service) DownloadZip(ctx context.Context, tx *gorm.DB, owner, worldName, version string,
 	u *users.User, agent string, zipGetter res.GetZipResource) (*World, *string, int, *gz.ErrMsg) {
 
-	world, em := ws.GetWorld(tx, owner, worldName, u)
+	world, em := ws.getWorldForDownload(ctx, tx, owner, worldName, u)
 	if em != nil {
 		return nil, nil, 0, em
 	}
@@ -492,10 +558,11 @@ func (ws *Service) DownloadZip(ctx context.Context, tx *gorm.DB, owner, worldNam
 
 // UpdateWorld updates a world. The user argument is the requesting user. It
 // is used to check if the user can perform the operation.
-// Fields that can be currently updated: desc, tags, and files.
+// Fields that can be currently updated: name, desc, tags, archived state and
+// files. Files cannot be updated while the world is archived.
 // The filesPath argument points to a tmp folder from which to read the new files.
 func (ws *Service) UpdateWorld(ctx context.Context, tx *gorm.DB, owner,
-	worldName string, desc, tagstr, filesPath *string, private *bool,
+	worldName string, newName, desc, tagstr, filesPath *string, private, archived *bool,
 	user *users.User, metadata *WorldMetadata) (*World, *gz.ErrMsg) {
 
 	world, em := ws.GetWorld(tx, owner, worldName, user)
@@ -509,6 +576,13 @@ func (ws *Service) UpdateWorld(ctx context.Context, tx *gorm.DB, owner,
 		return nil, err
 	}
 
+	// Rename the world, if a new name is present.
+	if newName != nil && *newName != *world.Name {
+		if em := renameWorld(tx, world, *newName); em != nil {
+			return nil, em
+		}
+	}
+
 	// Edit the description, if present.
 	if desc != nil {
 		tx.Model(&world).Update("Description", *desc)
@@ -537,8 +611,20 @@ func (ws *Service) UpdateWorld(ctx context.Context, tx *gorm.DB, owner,
 	// Update the modification date.
 	tx.Model(&world).Update("ModifyDate", time.Now())
 
+	// Update the archived state, if present. Only Owners and Admins can do that.
+	if archived != nil {
+		if ok, em := users.CanPerformWithRole(tx, *world.Owner, *user.Username, permissions.Admin); !ok {
+			return nil, em
+		}
+		tx.Model(&world).Update("Archived", *archived)
+	}
+
 	// Update files, if present
 	if filesPath != nil {
+		// Archived worlds are read-only
+		if res.IsArchived(world.Archived) {
+			return nil, res.NewArchivedErrorMessage(*world.Name)
+		}
 		// Replace ALL files with the new ones
 		repo := globals.VCSRepoFactory(ctx, *world.Location)
 		if err := repo.ReplaceFiles(ctx, *filesPath, *user.Username); err != nil {
@@ -610,7 +696,7 @@ func (ws *Service) CreateWorld(ctx context.Context, tx *gorm.DB, cm CreateWorld,
 	// Set the owner
 	owner := cm.Owner
 	if owner == "" {
-		owner = *creator.Username
+		owner = creator.DefaultOwner()
 	} else {
 		ok, em := users.VerifyOwner(tx, owner, *creator.Username, permissions.Read)
 		if !ok {
@@ -698,6 +784,8 @@ func populateModelIncludes(ctx context.Context, tx *gorm.DB, world *World,
 	if em != nil {
 		return em
 	}
+	// References to a renamed model are stored with its current name.
+	resolveModelIncludeAliases(tx, *incs)
 	for _, mi := range *incs {
 		// Add Model Includes to DB
 		if err := tx.Create(&mi).Error; err != nil {
@@ -707,6 +795,30 @@ func populateModelIncludes(ctx context.Context, tx *gorm.DB, world *World,
 	return nil
 }
 
+// renameWorld changes the name of a world. The previous name is recorded as an
+// alias, so that requests using it can be redirected to the new name.
+func renameWorld(tx *gorm.DB, world *World, newName string) *gz.ErrMsg {
+	if _, err := GetWorldByName(tx, newName, *world.Owner); err == nil {
+		return gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{newName})
+	}
+	// The new name could have been used by this or another world in the past,
+	// and the old name could be an alias left by another world that had it
+	// before this one. Aliases only point to the last world with a name.
+	for _, name := range []string{newName, *world.Name} {
+		if err := removeWorldAlias(tx, *world.Owner, name); err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	}
+	alias := WorldAlias{WorldID: world.ID, Owner: world.Owner, Name: world.Name}
+	if err := tx.Create(&alias).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	if err := tx.Model(world).Update("Name", newName).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
 // getWorldMainFile returns the first file path with extension '.world' on the
 // given folder.
 // Otherwise it returns an error.
@@ -814,7 +926,7 @@ func (ws *Service) CloneWorld(ctx context.Context, tx *gorm.DB, swOwner,
 	// Set the owner
 	owner := cw.Owner
 	if owner == "" {
-		owner = *creator.Username
+		owner = creator.DefaultOwner()
 	} else {
 		ok, em := users.VerifyOwner(tx, owner, *creator.Username, permissions.Read)
 		if !ok {
@@ -852,6 +964,12 @@ func (ws *Service) CloneWorld(ctx context.Context, tx *gorm.DB, swOwner,
 		clonePrivate = *cw.Private
 	}
 
+	// Get the source world version being cloned
+	sourceVersion, err := res.GetLatestVersion(ctx, world)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+
 	// Load the metadata
 	tx.Model(&world).Related(&world.Metadata)
 
@@ -862,6 +980,9 @@ func (ws *Service) CloneWorld(ctx context.Context, tx *gorm.DB, swOwner,
 	if err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorCreatingDir, err)
 	}
+	// Keep track of the world lineage
+	clone.ForkedFromID = &world.ID
+	clone.ForkedFromVersion = &sourceVersion
 
 	repo, em := res.CloneResourceRepo(ctx, world, &clone)
 	if em != nil {
@@ -901,6 +1022,195 @@ func (ws *Service) CloneWorld(ctx context.Context, tx *gorm.DB, swOwner,
 	return &clone, nil
 }
 
+// forkedFromProto returns a 'fuel.ForkedFrom' describing the world from which
+// the given world was cloned. It returns nil if the world is not a fork, or if
+// the source world no longer exists or is not visible to the requesting user.
+func (ws *Service) forkedFromProto(tx *gorm.DB, world *World,
+	user *users.User) *fuel.ForkedFrom {
+
+	return forkedFromProto(tx, world, relatedWorlds(tx, Worlds{*world}), user)
+}
+
+// forkedFromProto returns a 'fuel.ForkedFrom' describing the world from which
+// the given world was cloned, looking up the source world in the given related
+// worlds.
+func forkedFromProto(tx *gorm.DB, world *World, related map[uint]*World,
+	user *users.User) *fuel.ForkedFrom {
+
+	if world.ForkedFromID == nil {
+		return nil
+	}
+	source, ok := related[*world.ForkedFromID]
+	if !ok {
+		return nil
+	}
+	if ok, _ := users.CheckPermissions(tx, *source.UUID, user, *source.Private, permissions.Read); !ok {
+		return nil
+	}
+
+	forkedFrom := fuel.ForkedFrom{
+		Owner: proto.String(*source.Owner),
+		Name:  proto.String(*source.Name),
+	}
+	if world.ForkedFromVersion != nil {
+		forkedFrom.Version = proto.Int64(int64(*world.ForkedFromVersion))
+	}
+	return &forkedFrom
+}
+
+// relatedWorlds returns the worlds from which the given worlds were cloned, and
+// their successors, indexed by ID. They are loaded with a single query.
+func relatedWorlds(tx *gorm.DB, list Worlds) map[uint]*World {
+	related := make(map[uint]*World)
+	var ids []uint
+	for _, world := range list {
+		if world.ForkedFromID != nil {
+			ids = append(ids, *world.ForkedFromID)
+		}
+		if world.SuccessorID != nil {
+			ids = append(ids, *world.SuccessorID)
+		}
+	}
+	if len(ids) == 0 {
+		return related
+	}
+	var found Worlds
+	if err := tx.Model(&World{}).Where("id IN (?)", ids).Find(&found).Error; err != nil {
+		return related
+	}
+	for i := range found {
+		related[found[i].ID] = &found[i]
+	}
+	return related
+}
+
+// WorldForks returns a paginated list of the worlds that were cloned from the
+// given world. Only forks visible to the requesting user are returned.
+func (ws *Service) WorldForks(p *gz.PaginationRequest, tx *gorm.DB, owner,
+	name string, user *users.User) (*fuel.Worlds, *gz.PaginationResult, *gz.ErrMsg) {
+
+	world, em := ws.GetWorld(tx, owner, name, user)
+	if em != nil {
+		return nil, nil, em
+	}
+
+	q := tx.Where("forked_from_id = ?", world.ID)
+	return ws.WorldList(p, q, nil, res.ListOptions{User: user})
+}
+
+// DeprecateWorld marks a world as deprecated, with an optional message and
+// successor world. Deprecating an already deprecated world updates its message
+// and successor. Only owners and admins can deprecate a world.
+// The user argument is the user requesting the operation.
+func (ws *Service) DeprecateWorld(ctx context.Context, tx *gorm.DB, owner, name string,
+	dr res.DeprecateResource, user *users.User) (*World, *gz.ErrMsg) {
+
+	world, em := ws.GetWorld(tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := users.CanPerformWithRole(tx, *world.Owner, *user.Username, permissions.Admin); !ok {
+		return nil, em
+	}
+
+	var successorID *uint
+	if dr.SuccessorName != "" {
+		successorOwner := dr.SuccessorOwner
+		if successorOwner == "" {
+			successorOwner = *world.Owner
+		}
+		successor, em := ws.GetWorld(tx, successorOwner, dr.SuccessorName, user)
+		if em != nil {
+			return nil, em
+		}
+		if successor.ID == world.ID {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+				[]string{"successor_name"})
+		}
+		successorID = &successor.ID
+	}
+
+	deprecatedAt := time.Now()
+	if world.DeprecatedAt != nil {
+		deprecatedAt = *world.DeprecatedAt
+	}
+	var message *string
+	if dr.Message != "" {
+		message = &dr.Message
+	}
+	if err := tx.Model(world).Updates(map[string]interface{}{
+		"deprecated_at":       deprecatedAt,
+		"deprecation_message": message,
+		"successor_id":        successorID,
+	}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	world.DeprecatedAt = &deprecatedAt
+	world.DeprecationMessage = message
+	world.SuccessorID = successorID
+
+	ElasticSearchUpdateWorld(ctx, *world)
+	return world, nil
+}
+
+// UndeprecateWorld removes the deprecation of a world.
+// The user argument is the user requesting the operation.
+func (ws *Service) UndeprecateWorld(ctx context.Context, tx *gorm.DB, owner, name string,
+	user *users.User) (*World, *gz.ErrMsg) {
+
+	world, em := ws.GetWorld(tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := users.CanPerformWithRole(tx, *world.Owner, *user.Username, permissions.Admin); !ok {
+		return nil, em
+	}
+
+	if err := tx.Model(world).Updates(map[string]interface{}{
+		"deprecated_at":       nil,
+		"deprecation_message": nil,
+		"successor_id":        nil,
+	}).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	world.DeprecatedAt = nil
+	world.DeprecationMessage = nil
+	world.SuccessorID = nil
+
+	ElasticSearchUpdateWorld(ctx, *world)
+	return world, nil
+}
+
+// SuccessorProto returns a 'fuel.Successor' describing the world replacing the
+// given deprecated world. It returns nil if the world has no successor, or if
+// the successor no longer exists or is not visible to the requesting user.
+func (ws *Service) SuccessorProto(tx *gorm.DB, world *World,
+	user *users.User) *fuel.Successor {
+
+	return successorProto(tx, world, relatedWorlds(tx, Worlds{*world}), user)
+}
+
+// successorProto returns a 'fuel.Successor' describing the world replacing the
+// given deprecated world, looking up the successor in the given related worlds.
+func successorProto(tx *gorm.DB, world *World, related map[uint]*World,
+	user *users.User) *fuel.Successor {
+
+	if world.SuccessorID == nil {
+		return nil
+	}
+	successor, ok := related[*world.SuccessorID]
+	if !ok {
+		return nil
+	}
+	if ok, _ := users.CheckPermissions(tx, *successor.UUID, user, *successor.Private, permissions.Read); !ok {
+		return nil
+	}
+	return &fuel.Successor{
+		Owner: proto.String(*successor.Owner),
+		Name:  proto.String(*successor.Name),
+	}
+}
+
 // createUniqueName is an internal helper to disambiguate among resource names
 func (ws *Service) createUniqueName(tx *gorm.DB, name, owner string) (string, error) {
 	// Find an unused name variation
@@ -947,5 +1257,141 @@ func (ws *Service) GetModelReferences(ctx context.Context, p *gz.PaginationReque
 		em := gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
 		return nil, nil, em
 	}
+	resolveModelIncludeAliases(tx, includes)
 	return &includes, paginationResult, nil
 }
+
+// resolveModelIncludeAliases updates the given model includes that reference a
+// renamed model, so they use the current owner and name of the model. It is
+// used when the includes are stored, and when they are returned, as the model
+// could have been renamed in between.
+// Includes without an owner (eg. model://) are left untouched.
+func resolveModelIncludeAliases(tx *gorm.DB, includes ModelIncludes) {
+	for i := range includes {
+		mi := &includes[i]
+		if mi.ModelOwner == nil || mi.ModelName == nil {
+			continue
+		}
+		if _, err := models.GetModelByName(tx, *mi.ModelName, *mi.ModelOwner); err == nil {
+			continue
+		}
+		if model, err := models.GetModelByAlias(tx, *mi.ModelName, *mi.ModelOwner); err == nil {
+			mi.ModelOwner = model.Owner
+			mi.ModelName = model.Name
+		}
+	}
+}
+
+// WorldTrash returns the list of soft-deleted worlds of an owner that can
+// still be restored.
+func (ws *Service) WorldTrash(tx *gorm.DB, owner string) (res.TrashItems, *gz.ErrMsg) {
+	var list Worlds
+	if err := res.QueryForTrash(tx.Model(&World{}), owner).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	items := res.TrashItems{}
+	for _, w := range list {
+		items = append(items, res.NewTrashItem("world", *w.Name, *w.Owner, *w.DeletedAt))
+	}
+	return items, nil
+}
+
+// RestoreWorld restores a soft-deleted world, as long as it is still within
+// the trash retention window. If the world name is already in use, a new name
+// must be given in the RestoreResource argument.
+// The user argument is the requesting user. It must be the owner or have write
+// access to the owner organization.
+func (ws *Service) RestoreWorld(ctx context.Context, tx *gorm.DB, owner,
+	name string, rr res.RestoreResource, user *users.User) (*World, *gz.ErrMsg) {
+
+	if user == nil {
+		return nil, gz.NewErrorMessage(gz.ErrorAuthNoUser)
+	}
+	if ok, em := users.VerifyOwner(tx, owner, *user.Username, permissions.Write); !ok {
+		return nil, em
+	}
+
+	var world World
+	if err := res.QueryForTrash(tx.Model(&World{}), owner).Where("name = ?", name).
+		First(&world).Error; err != nil {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, err, []string{name})
+	}
+
+	// Name-conflict handling: the restored world cannot take the name of an
+	// existing one.
+	newName := name
+	if rr.Name != "" {
+		newName = rr.Name
+	}
+	if _, err := GetWorldByName(tx, newName, owner); err == nil {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{newName})
+	}
+
+	if em := res.Restore(tx, &world, newName); em != nil {
+		return nil, em
+	}
+
+	restored, err := GetWorldByName(tx, newName, owner)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	tx.Model(restored).Related(&restored.Metadata)
+
+	// Add the world back to ElasticSearch
+	ElasticSearchUpdateWorld(ctx, *restored)
+
+	return restored, nil
+}
+
+// PurgeExpiredWorlds permanently removes from the database all the worlds that
+// were soft-deleted before the trash retention window. The files of the purged
+// worlds must be removed once the transaction is committed.
+func (ws *Service) PurgeExpiredWorlds(ctx context.Context, tx *gorm.DB) ([]*res.PurgedResource, *gz.ErrMsg) {
+	var list Worlds
+	if err := res.QueryForExpiredTrash(tx.Model(&World{})).Find(&list).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+
+	var purged []*res.PurgedResource
+	for i := range list {
+		world := &list[i]
+		if em := purgeWorldRows(tx, world); em != nil {
+			return nil, em
+		}
+		p, em := res.Purge(tx, world, worlds, ws.Storage)
+		if em != nil {
+			return nil, em
+		}
+		purged = append(purged, p)
+		gz.LoggerFromContext(ctx).Info("Purged world: ", *world.Owner, "/", *world.Name)
+	}
+	return purged, nil
+}
+
+// purgeWorldRows removes the rows that reference a world by ID, before the
+// world is purged.
+func purgeWorldRows(tx *gorm.DB, world *World) *gz.ErrMsg {
+	statements := []string{
+		"DELETE FROM world_tags WHERE world_id = ?",
+		"DELETE FROM ratings WHERE resource_type = 'world' AND resource_id = ?",
+	}
+	for _, sql := range statements {
+		if err := tx.Exec(sql, world.ID).Error; err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	}
+	rows := []interface{}{
+		&WorldMetadatum{},
+		&ModelInclude{},
+		&WorldLike{},
+		&WorldDownload{},
+		&WorldReport{},
+		&WorldAlias{},
+	}
+	for _, row := range rows {
+		if err := tx.Unscoped().Where("world_id = ?", world.ID).Delete(row).Error; err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// The casbin checker finds drift between the permission policies and the
+// organizations, teams and resources in the database, and optionally fixes it.
+// It can also export and import the full set of policies as JSON, for backups
+// and for moving data between environments.
+//
+// It uses the same database environment variables as the server, and must be
+// run from the repository root to find the permissions policy model:
+//
+//	$ go run ./cmd/casbin-checker [-fix]
+//	$ go run ./cmd/casbin-checker -export policies.json
+//	$ go run ./cmd/casbin-checker -import policies.json [-replace]
+//
+// Running servers keep the policies in memory and don't see the changes made by
+// -fix or -import. Restart them afterwards so they load the updated policies.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/migrate"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "Fix the issues found")
+	exportFile := flag.String("export", "", "Export the policies to the given JSON file")
+	importFile := flag.String("import", "", "Import the policies from the given JSON file")
+	replace := flag.Bool("replace", false, "Remove the existing policies before importing")
+	flag.Parse()
+
+	db, err := setupDB()
+	if err != nil {
+		log.Fatalln("Failed to set up to MySQL database conn:", err)
+	}
+	defer gz.Close(db)
+
+	sysAdmin, _ := gz.ReadEnvVar("IGN_FUEL_SYSTEM_ADMIN")
+	globals.Permissions = &permissions.Permissions{}
+	if err := globals.Permissions.Init(db, sysAdmin); err != nil {
+		log.Fatalln("Failed to initialize permissions:", err)
+	}
+
+	switch {
+	case *exportFile != "":
+		exportPolicies(*exportFile)
+	case *importFile != "":
+		importPolicies(db, *importFile, *replace)
+	default:
+		check(db, *fix)
+	}
+}
+
+func check(db *gorm.DB, fix bool) {
+	report, em := migrate.CheckCasbinConsistency(context.Background(), db, fix)
+	if em != nil {
+		log.Fatalln("Failed to check the policies:", em.LogString())
+	}
+	for _, issue := range report.Issues {
+		status := ""
+		if issue.Fixed {
+			status = " (fixed)"
+		}
+		log.Printf("[%s] %s %v%s\n", issue.Type, issue.Description, issue.Rule, status)
+	}
+	log.Printf("Found %d issues\n", len(report.Issues))
+	if fix && len(report.Issues) > 0 {
+		log.Println("Restart the running servers to load the fixed policies")
+	}
+}
+
+func exportPolicies(path string) {
+	data, err := json.MarshalIndent(globals.Permissions.ExportPolicies(), "", "  ")
+	if err != nil {
+		log.Fatalln("Failed to encode the policies:", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		log.Fatalln("Failed to write the policies:", err)
+	}
+	log.Println("Policies exported to", path)
+}
+
+func importPolicies(db *gorm.DB, path string, replace bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalln("Failed to read the policies:", err)
+	}
+	var set permissions.PolicySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		log.Fatalln("Failed to decode the policies:", err)
+	}
+	if _, em := globals.Permissions.ImportPolicies(db, &set, replace); em != nil {
+		log.Fatalln("Failed to import the policies:", em.LogString())
+	}
+	log.Printf("Imported %d policies and %d roles\n", len(set.Policies), len(set.Roles))
+	log.Println("Restart the running servers to load the imported policies")
+}
+
+func setupDB() (*gorm.DB, error) {
+	cfg, err := gz.NewDatabaseConfigFromEnvVars()
+	if err != nil {
+		return nil, err
+	}
+	return gz.InitDbWithCfg(&cfg)
+}
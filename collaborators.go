@@ -0,0 +1,150 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// getResource returns the model, world or collection with the given owner and
+// name, if the user can read it.
+func getResource(resType, owner, name string, user *users.User,
+	tx *gorm.DB) (commonres.Resource, *gz.ErrMsg) {
+
+	switch resType {
+	case "model":
+		return (&models.Service{Storage: globals.Storage}).GetModel(tx, owner, name, user)
+	case "world":
+		return (&worlds.Service{Storage: globals.Storage}).GetWorld(tx, owner, name, user)
+	case "collection":
+		return (&collections.Service{}).GetCollection(tx, owner, name, user)
+	}
+	return nil, gz.NewErrorMessage(gz.ErrorUnexpected)
+}
+
+// getSharedResource returns the model, world or collection with the given
+// owner and name. It also checks that the user can manage the collaborators of
+// the resource, ie. the user is the owner or an admin of the owner organization.
+func getSharedResource(resType, owner, name string, user *users.User,
+	tx *gorm.DB) (commonres.Resource, *gz.ErrMsg) {
+
+	resource, em := getResource(resType, owner, name, user, tx)
+	if em != nil {
+		return nil, em
+	}
+
+	if ok, em := users.CanPerformWithRole(tx, owner, *user.Username, permissions.Admin); !ok {
+		return nil, em
+	}
+	return resource, nil
+}
+
+// ResourceCollaborators returns a handler that lists the users and teams a
+// model, world or collection is shared with. The returned value will be of type
+// "commonres.Collaborators".
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model-name}/collaborators
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ResourceCollaborators(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		resource, em := getSharedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		return commonres.GetCollaborators(tx, resource)
+	}
+}
+
+// ResourceCollaboratorAdd returns a handler that shares a model, world or
+// collection with a user or an organization team. Sharing it again with the
+// same user or team updates the granted permission. The returned value will be
+// of type "commonres.Collaborator".
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model-name}/collaborators
+//	  -d '{"username":"user", "permission":"read|write"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ResourceCollaboratorAdd(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		var ac commonres.AddCollaborator
+		if em := ParseStruct(&ac, r, false); em != nil {
+			return nil, em
+		}
+
+		resource, em := getSharedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		col, em := commonres.ShareResource(r.Context(), tx, resource, &ac)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		if em := col.GrantPermissions(); em != nil {
+			return nil, em
+		}
+		return col, nil
+	}
+}
+
+// ResourceCollaboratorRemove returns a handler that stops sharing a model,
+// world or collection with a user or an organization team. The user or team is
+// given with the 'username' or 'organization' and 'team' query parameters. The
+// returned value will be of type "commonres.Collaborator".
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/models/{model-name}/collaborators?username=user
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ResourceCollaboratorRemove(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		params := r.URL.Query()
+		ac := commonres.AddCollaborator{
+			Username:     params.Get("username"),
+			Organization: params.Get("organization"),
+			Team:         params.Get("team"),
+		}
+
+		resource, em := getSharedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		col, em := commonres.UnshareResource(r.Context(), tx, resource, &ac)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+		if em := col.RevokePermissions(); em != nil {
+			return nil, em
+		}
+		return col, nil
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/proto"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"os"
+	"testing"
+)
+
+// TestModelCollaborators tests sharing a private model with another user.
+func TestModelCollaborators(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	testUser2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(testUser2, jwt2, t)
+	createTestModelWithOwner(t, &jwt, "private_model", testUser, true)
+
+	modelURI := modelURL(testUser, "private_model", "")
+	uri := modelURI + "/collaborators"
+	listURI := fmt.Sprintf("/%s/%s/models", apiVersion, testUser)
+
+	// The other user cannot see the private model
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", modelURI, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+	var list []*fuel.Model
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", listURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	assert.Len(t, list, 0)
+
+	// Only the owner can share the model
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.AddCollaborator{Username: testUser2}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, b, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.AddCollaborator{Username: testUser2}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, b, http.StatusOK, &jwt, ctJSON, t)
+	var col commonres.Collaborator
+	require.NoError(t, json.Unmarshal(*bslice, &col))
+	assert.Equal(t, testUser2, *col.Username)
+	assert.Equal(t, "read", col.Permission)
+
+	var cols commonres.Collaborators
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &cols))
+	require.Len(t, cols, 1)
+
+	// Now the other user can see the model, in both the model page and lists
+	gztest.AssertRouteMultipleArgs("GET", modelURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", listURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "private_model", list[0].GetName())
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", "/1.0/models", nil, http.StatusOK, &jwt2, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	assert.Len(t, list, 1)
+
+	// Stop sharing the model
+	gztest.AssertRouteMultipleArgs("DELETE", uri+"?username="+testUser2, nil, http.StatusOK, &jwt, ctJSON, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", modelURI, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	// Teams are looked up within the given organization
+	org := createOrganization(t)
+	defer removeOrganization(org, t)
+	otherOrg := createOrganization(t)
+	defer removeOrganization(otherOrg, t)
+	visible := true
+	addTeamToOrg(org, jwt, users.CreateTeamForm{Name: "reviewers", Visible: &visible}, t)
+	notFound := gz.NewErrorMessage(gz.ErrorNameNotFound)
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.AddCollaborator{Organization: otherOrg, Team: "reviewers"}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, b, notFound.StatusCode, &jwt, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, notFound.ErrCode, t)
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.AddCollaborator{Organization: org, Team: "reviewers"}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, b, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &col))
+	assert.Equal(t, org, *col.Organization)
+	assert.Equal(t, "reviewers", *col.Team)
+
+	// Write collaborators can edit the model, but cannot delete or transfer it
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.AddCollaborator{Username: testUser2, Permission: "write"}))
+	gztest.AssertRouteMultipleArgs("POST", uri, b, http.StatusOK, &jwt, ctJSON, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("DELETE", modelURI, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.CreateTransferRequest{DestOwner: testUser2}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", modelURI+"/transfer-requests", b, expEm.StatusCode,
+		&jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+	getOwnerModelFromDb(t, testUser, "private_model")
+}
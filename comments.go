@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gazebo-web/fuel-server/bundles/comments"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// canModerateComments returns true if the given user can moderate the comments
+// of the resources of an owner, ie. hide comments and lock threads. Moderators
+// are the owner, the admins of the owner organization and the system admins.
+func canModerateComments(tx *gorm.DB, owner string, user *users.User) bool {
+	if user == nil {
+		return false
+	}
+	if globals.Permissions.IsSystemAdmin(*user.Username) {
+		return true
+	}
+	ok, _ := users.CanPerformWithRole(tx, owner, *user.Username, permissions.Admin)
+	return ok
+}
+
+// notifyMentions notifies the users mentioned in a comment, except the author
+// and the users in skip. Users that cannot read a private resource are not
+// notified.
+func notifyMentions(tx *gorm.DB, res commonres.Resource, c *comments.Comment, skip []string) *gz.ErrMsg {
+	skipped := map[string]bool{c.Author: true}
+	for _, username := range skip {
+		skipped[username] = true
+	}
+	for _, username := range comments.Mentions(c.Body) {
+		if skipped[username] {
+			continue
+		}
+		if _, em := users.ByUsername(tx, username, false); em != nil {
+			// Not a user
+			continue
+		}
+		if isPrivateResource(res) {
+			if ok, _ := globals.Permissions.IsAuthorized(username, *res.GetUUID(), permissions.Read); !ok {
+				continue
+			}
+		}
+		n := notifications.Notification{
+			Username:     username,
+			Event:        notifications.EventMention,
+			Actor:        c.Author,
+			ResourceType: resourceTypeOf(res),
+			Owner:        *res.GetOwner(),
+			Name:         *res.GetName(),
+			Message: fmt.Sprintf("%s mentioned you in a comment on the %s %s/%s.", c.Author,
+				resourceTypeOf(res), *res.GetOwner(), *res.GetName()),
+		}
+		if em := notifications.Create(tx, &n); em != nil {
+			return em
+		}
+	}
+	return nil
+}
+
+// CommentList returns a handler that lists the top level comments of a model
+// or world, oldest first. Comments of private resources are only returned to
+// the users that can read the resource.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model}/comments
+func CommentList(resType string) pagHandler {
+	return commentList(resType, false)
+}
+
+// CommentReplyList returns a handler that lists the replies to a comment of a
+// model or world, oldest first.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model}/comments/{id}/replies
+func CommentReplyList(resType string) pagHandler {
+	return commentList(resType, true)
+}
+
+// commentList returns a handler that lists the top level comments of a
+// resource, or the replies to a comment. The bodies of deleted comments are
+// removed, and so are the bodies of hidden comments unless the user is their
+// author or a moderator.
+func commentList(resType string, replies bool) pagHandler {
+	return func(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+		name, owner, em := readOwnerNameParams(resType, tx, r)
+		if em != nil {
+			return nil, nil, em
+		}
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, nil, em
+		}
+		var parentID *uint
+		if replies {
+			id, em := getRouteID(r)
+			if em != nil {
+				return nil, nil, em
+			}
+			if _, em := comments.Get(tx, *res.GetUUID(), id); em != nil {
+				return nil, nil, em
+			}
+			parentID = &id
+		}
+		list, pagination, em := comments.List(p, tx, *res.GetUUID(), parentID)
+		if em != nil {
+			return nil, nil, em
+		}
+		moderator := canModerateComments(tx, owner, user)
+		for i := range *list {
+			c := &(*list)[i]
+			c.Redact(moderator || (user != nil && c.Author == *user.Username))
+		}
+		return list, pagination, nil
+	}
+}
+
+// CommentCreate returns a handler that adds a comment to a model or world, or
+// a reply to one of its comments. The mentioned users are notified.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model}/comments
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	  -d '{"body":"Nice model @alice!", "parent_id":12}'
+func CommentCreate(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		var cc comments.CreateComment
+		if em := ParseStruct(&cc, r, false); em != nil {
+			return nil, em
+		}
+		c, em := comments.Create(tx, resType, *res.GetUUID(), *user.Username, cc)
+		if em != nil {
+			return nil, em
+		}
+		if em := notifyMentions(tx, res, c, nil); em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		return c, nil
+	}
+}
+
+// CommentUpdate returns a handler that updates a comment of a model or world.
+// Authors can edit the body of their comments, and the users mentioned for the
+// first time are notified. Moderators can hide and show comments.
+// You can request this method with the following curl request:
+//
+//	curl -k -X PATCH --url https://localhost:4430/1.0/{username}/models/{model}/comments/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>' -d '{"hidden":true}'
+func CommentUpdate(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		id, em := getRouteID(r)
+		if em != nil {
+			return nil, em
+		}
+		c, em := comments.Get(tx, *res.GetUUID(), id)
+		if em != nil {
+			return nil, em
+		}
+		var uc comments.UpdateComment
+		if em := ParseStruct(&uc, r, false); em != nil {
+			return nil, em
+		}
+		moderator := canModerateComments(tx, owner, user)
+		if (uc.Body != nil && c.Author != *user.Username) || (uc.Hidden != nil && !moderator) {
+			return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+		}
+		if uc.Body != nil {
+			previous := comments.Mentions(c.Body)
+			if em := comments.Edit(tx, c, *uc.Body); em != nil {
+				return nil, em
+			}
+			if em := notifyMentions(tx, res, c, previous); em != nil {
+				return nil, em
+			}
+		}
+		if uc.Hidden != nil {
+			if em := comments.SetHidden(tx, c, *user.Username, *uc.Hidden); em != nil {
+				return nil, em
+			}
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		return c, nil
+	}
+}
+
+// CommentRemove returns a handler that deletes a comment of a model or world.
+// Comments can be deleted by their author and by the moderators. Deleted
+// comments stay in the thread without their body, so their replies can still
+// be read.
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/models/{model}/comments/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func CommentRemove(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		id, em := getRouteID(r)
+		if em != nil {
+			return nil, em
+		}
+		c, em := comments.Get(tx, *res.GetUUID(), id)
+		if em != nil {
+			return nil, em
+		}
+		if c.Author != *user.Username && !canModerateComments(tx, owner, user) {
+			return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+		}
+		if em := comments.Remove(tx, c); em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return nil, nil
+	}
+}
+
+// CommentThreadIndex returns a handler that returns the moderation state of
+// the comments of a model or world, ie. whether the thread is locked.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model}/comments/thread
+func CommentThreadIndex(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		return comments.GetThread(tx, *res.GetUUID())
+	}
+}
+
+// CommentThreadUpdate returns a handler that locks or unlocks the comments of
+// a model or world. Locked threads don't accept new comments, replies or
+// edits. Only moderators can lock threads.
+// You can request this method with the following curl request:
+//
+//	curl -k -X PATCH --url https://localhost:4430/1.0/{username}/models/{model}/comments/thread
+//	  --header 'authorization: Bearer <your-jwt-token-here>' -d '{"locked":true}'
+func CommentThreadUpdate(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		if !canModerateComments(tx, owner, user) {
+			return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+		}
+		var ut comments.UpdateThread
+		if em := ParseStruct(&ut, r, false); em != nil {
+			return nil, em
+		}
+		t, em := comments.SetLocked(tx, *res.GetUUID(), *user.Username, *ut.Locked)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		return t, nil
+	}
+}
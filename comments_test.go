@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/comments"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"os"
+	"testing"
+)
+
+// TestModelComments tests comment threads on a model: replies, mentions and
+// moderation by the model owner.
+func TestModelComments(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+	createThreeTestModels(t, &jwt)
+
+	commentsURI := modelURL(testUser, "model1", "") + "/comments"
+	postComment := func(cc comments.CreateComment, jwt *string, status int) *comments.Comment {
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(cc))
+		ct := ctJSON
+		if status != http.StatusOK {
+			ct = ctTextPlain
+		}
+		bslice, _ := gztest.AssertRouteMultipleArgs("POST", commentsURI, b, status, jwt, ct, t)
+		var c comments.Comment
+		if status == http.StatusOK {
+			require.NoError(t, json.Unmarshal(*bslice, &c))
+		}
+		return &c
+	}
+	listComments := func(uri string, jwt *string) comments.Comments {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, jwt, ctJSON, t)
+		var list comments.Comments
+		require.NoError(t, json.Unmarshal(*bslice, &list))
+		return list
+	}
+
+	// Mentions notify the mentioned user
+	c := postComment(comments.CreateComment{Body: "Nice model @" + testUser + "!"}, &jwt2, http.StatusOK)
+	assert.Equal(t, user2, c.Author)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/users/%s/notifications", testUser), nil, http.StatusOK, &jwt, ctJSON, t)
+	var list notifications.Notifications
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, notifications.EventMention, list[0].Event)
+	assert.Equal(t, user2, list[0].Actor)
+
+	// Replies
+	reply := postComment(comments.CreateComment{Body: "Thanks", ParentID: &c.ID}, &jwt, http.StatusOK)
+	require.NotNil(t, reply.ParentID)
+	top := listComments(commentsURI, nil)
+	require.Len(t, top, 1)
+	assert.Equal(t, 1, top[0].Replies)
+	replies := listComments(fmt.Sprintf("%s/%d/replies", commentsURI, c.ID), nil)
+	require.Len(t, replies, 1)
+	assert.Equal(t, "Thanks", replies[0].Body)
+
+	// Only the author can edit a comment, and only moderators can hide it
+	commentURI := fmt.Sprintf("%s/%d", commentsURI, c.ID)
+	unauth := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	gztest.AssertRouteMultipleArgs("PATCH", commentURI, bytes.NewBufferString(`{"body":"edited"}`), unauth.StatusCode, &jwt, ctTextPlain, t)
+	gztest.AssertRouteMultipleArgs("PATCH", commentURI, bytes.NewBufferString(`{"hidden":true}`), unauth.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertRouteMultipleArgs("PATCH", commentURI, bytes.NewBufferString(`{"body":"edited"}`), http.StatusOK, &jwt2, ctJSON, t)
+	gztest.AssertRouteMultipleArgs("PATCH", commentURI, bytes.NewBufferString(`{"hidden":true}`), http.StatusOK, &jwt, ctJSON, t)
+	top = listComments(commentsURI, nil)
+	assert.True(t, top[0].Hidden)
+	assert.NotNil(t, top[0].EditedAt)
+	assert.Empty(t, top[0].Body)
+	// The author still sees the hidden comment
+	assert.Equal(t, "edited", listComments(commentsURI, &jwt2)[0].Body)
+
+	// Locked threads don't accept comments
+	threadURI := commentsURI + "/thread"
+	gztest.AssertRouteMultipleArgs("PATCH", threadURI, bytes.NewBufferString(`{"locked":true}`), unauth.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertRouteMultipleArgs("PATCH", threadURI, bytes.NewBufferString(`{"locked":true}`), http.StatusOK, &jwt, ctJSON, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", threadURI, nil, http.StatusOK, nil, ctJSON, t)
+	var thread comments.Thread
+	require.NoError(t, json.Unmarshal(*bslice, &thread))
+	assert.True(t, thread.Locked)
+	postComment(comments.CreateComment{Body: "Another one"}, &jwt2, unauth.StatusCode)
+
+	// Deleted comments keep their replies
+	gztest.AssertRouteMultipleArgs("DELETE", commentURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+	top = listComments(commentsURI, &jwt)
+	require.Len(t, top, 1)
+	assert.True(t, top[0].Deleted)
+	assert.Empty(t, top[0].Body)
+	assert.Len(t, listComments(fmt.Sprintf("%s/%d/replies", commentsURI, c.ID), nil), 1)
+}
@@ -14,13 +14,22 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gazebo-web/fuel-server/bundles/activity"
+	"github.com/gazebo-web/fuel-server/bundles/audit"
 	"github.com/gazebo-web/fuel-server/bundles/category"
 	"github.com/gazebo-web/fuel-server/bundles/collections"
+	"github.com/gazebo-web/fuel-server/bundles/comments"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/events"
 	"github.com/gazebo-web/fuel-server/bundles/license"
 	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/moderation"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/fuel-server/bundles/ratings"
 	"github.com/gazebo-web/fuel-server/bundles/reviews"
 	"github.com/gazebo-web/fuel-server/bundles/subt"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gazebo-web/fuel-server/bundles/worlds"
 	"github.com/gazebo-web/fuel-server/globals"
 	_ "github.com/go-sql-driver/mysql"
@@ -61,20 +70,43 @@ func DBMigrate(ctx context.Context, db *gorm.DB) {
 			&models.ModelMetadatum{},
 			&models.Tag{},
 			&gz.AccessToken{},
+			&users.AccessTokenDetails{},
 			&users.UniqueOwner{},
 			&users.User{},
 			&users.Organization{},
 			&users.Team{},
+			&users.OrganizationRole{},
+			&users.OrganizationInvitation{},
 			&collections.Collection{},
 			&collections.CollectionAsset{},
 			&models.Model{},
 			&models.ModelDownload{},
 			&models.ModelLike{},
 			&models.ModelReport{},
+			&models.ModelAlias{},
 			&worlds.World{},
 			&worlds.WorldLike{},
 			&worlds.WorldReport{},
 			&worlds.WorldDownload{},
+			&worlds.WorldAlias{},
+			&commonres.Collaborator{},
+			&commonres.ShareToken{},
+			&commonres.TransferRequest{},
+			&audit.Entry{},
+			&activity.Follow{},
+			&activity.Activity{},
+			&notifications.Watch{},
+			&notifications.Notification{},
+			&notifications.Preferences{},
+			&webhooks.Webhook{},
+			&webhooks.Delivery{},
+			&events.Event{},
+			&comments.Comment{},
+			&comments.Thread{},
+			&ratings.Rating{},
+			&moderation.Case{},
+			&moderation.Report{},
+			&users.SuspensionEntry{},
 			&worlds.ModelInclude{},
 			&worlds.WorldMetadatum{},
 			&reviews.ModelReview{},
@@ -137,14 +169,36 @@ func DBDropModels(ctx context.Context, db *gorm.DB) {
 			&models.Model{},
 			&models.ModelDownload{},
 			&models.ModelLike{},
+			&models.ModelAlias{},
 			&worlds.ModelInclude{},
 			&worlds.WorldReport{},
 			&worlds.World{},
 			&worlds.WorldLike{},
 			&worlds.WorldDownload{},
 			&worlds.WorldMetadatum{},
+			&worlds.WorldAlias{},
+			&commonres.Collaborator{},
+			&commonres.ShareToken{},
+			&commonres.TransferRequest{},
+			&audit.Entry{},
+			&activity.Follow{},
+			&activity.Activity{},
+			&notifications.Watch{},
+			&notifications.Notification{},
+			&notifications.Preferences{},
+			&webhooks.Webhook{},
+			&webhooks.Delivery{},
+			&events.Event{},
+			&comments.Comment{},
+			&comments.Thread{},
+			&ratings.Rating{},
+			&moderation.Case{},
+			&moderation.Report{},
+			&users.SuspensionEntry{},
 			&collections.CollectionAsset{},
 			&collections.Collection{},
+			&users.OrganizationInvitation{},
+			&users.OrganizationRole{},
 			&users.Team{},
 			&users.Organization{},
 			&users.User{},
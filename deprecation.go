@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/proto"
+	"github.com/gazebo-web/gz-go/v7"
+)
+
+// parseDeprecateRequest reads the optional deprecation data from the request body.
+func parseDeprecateRequest(r *http.Request) (*commonres.DeprecateResource, *gz.ErrMsg) {
+	var dr commonres.DeprecateResource
+	// The request body is optional.
+	if r.ContentLength == 0 {
+		return &dr, nil
+	}
+	if em := ParseStruct(&dr, r, false); em != nil {
+		return nil, em
+	}
+	return &dr, nil
+}
+
+// writeIgnDeprecationHeaders writes the deprecation headers of a resource into
+// the given response, if the resource is deprecated.
+// The "X-Ign-Resource-Deprecated" header contains the deprecation message, or
+// "true" if there is none. The "X-Ign-Resource-Successor" header contains the
+// "owner/name" of the successor resource, if any.
+func writeIgnDeprecationHeaders(w http.ResponseWriter, deprecated bool, message *string,
+	successor *fuel.Successor) {
+
+	if !deprecated {
+		return
+	}
+	value := "true"
+	if message != nil && *message != "" {
+		// Header values cannot contain line breaks.
+		value = strings.Join(strings.Fields(*message), " ")
+	}
+	w.Header().Set("X-Ign-Resource-Deprecated", value)
+	if successor != nil {
+		w.Header().Set("X-Ign-Resource-Successor", successor.GetOwner()+"/"+successor.GetName())
+	}
+}
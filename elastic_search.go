@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/bundles/users"
 	"github.com/gazebo-web/fuel-server/bundles/worlds"
@@ -16,6 +17,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -383,6 +385,18 @@ func createIndex(ctx context.Context, indexName string) {
 	var mappings = `{
     "mappings": {
       "properties": {
+        "archived": {
+          "type": "boolean"
+        },
+        "deprecated": {
+          "type": "boolean"
+        },
+        "rating_average": {
+          "type": "double"
+        },
+        "rating_count": {
+          "type": "integer"
+        },
         "categories": {
           "type": "text",
           "fields": {
@@ -729,6 +743,70 @@ func elasticSearch(index string, pr *gz.PaginationRequest, owner *string, order,
 		}
 	}
 
+	// Filter by the archived and deprecated states, if requested.
+	// Note: a "false" filter is expressed as "must_not true", to also match
+	// documents indexed before the field existed.
+	var filter, mustNot []interface{}
+	for _, field := range []string{"archived", "deprecated"} {
+		if value := readBoolParam(r, field); value != nil {
+			term := map[string]interface{}{
+				"term": map[string]interface{}{field: true},
+			}
+			if *value {
+				filter = append(filter, term)
+			} else {
+				mustNot = append(mustNot, term)
+			}
+		}
+	}
+	if len(filter) > 0 || len(mustNot) > 0 {
+		boolQuery := map[string]interface{}{"must": query["query"]}
+		if len(filter) > 0 {
+			boolQuery["filter"] = filter
+		}
+		if len(mustNot) > 0 {
+			boolQuery["must_not"] = mustNot
+		}
+		query = map[string]interface{}{
+			"query": map[string]interface{}{"bool": boolQuery},
+		}
+	}
+
+	// Deprecated resources rank lower, unless they were explicitly requested.
+	if readBoolParam(r, "deprecated") == nil {
+		query = map[string]interface{}{
+			"query": map[string]interface{}{
+				"boosting": map[string]interface{}{
+					"positive": query["query"],
+					"negative": map[string]interface{}{
+						"term": map[string]interface{}{"deprecated": true},
+					},
+					"negative_boost": 0.1,
+				},
+			},
+		}
+	}
+
+	// Sort by the star ratings, if requested. Documents indexed before the
+	// ratings existed are sorted last.
+	sortBy, em := readSortParam(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	if sortBy == commonres.SortByRating {
+		dir := "desc"
+		if strings.ToLower(order) == "asc" {
+			dir = "asc"
+		}
+		var sortFields []interface{}
+		for _, field := range []string{"rating_average", "rating_count"} {
+			sortFields = append(sortFields, map[string]interface{}{
+				field: map[string]interface{}{"order": dir, "missing": "_last", "unmapped_type": "double"},
+			})
+		}
+		query["sort"] = append(sortFields, "_score")
+	}
+
 	// Encode the search request.
 	if err := json.NewEncoder(&buf).Encode(query); err != nil {
 		return nil, nil, gz.NewErrorMessageWithArgs(gz.ErrorUnexpected, err,
@@ -817,6 +895,16 @@ func elasticSearch(index string, pr *gz.PaginationRequest, owner *string, order,
 	return result, &page, nil
 }
 
+// hitPositions returns the position of each resource ID in a list of search
+// hits.
+func hitPositions(ids []int64) map[uint]int {
+	position := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		position[uint(id)] = i
+	}
+	return position
+}
+
 func createWorldResults(ctx context.Context, user *users.User, tx *gorm.DB, elasticResult map[string]interface{}) (interface{}, int64) {
 	// Construct the set of models
 	worldsProto := fuel.Worlds{}
@@ -840,7 +928,13 @@ func createWorldResults(ctx context.Context, user *users.User, tx *gorm.DB, elas
 	var foundWorlds []worlds.World
 	count := int64(0)
 	// \todo: Add categories to world, and add back in `.Preload("Categories")` to the following line.
-	if err := tx.Preload("Tags").Preload("License").Where(resourceIDs).Find(&foundWorlds).Error; err == nil {
+	// Resources hidden by moderators, and those of suspended users, are not found.
+	if err := commonres.QueryForHidden(tx.Preload("Tags").Preload("License").Where(resourceIDs), user).Find(&foundWorlds).Error; err == nil {
+		// Keep the order of the search hits
+		position := hitPositions(resourceIDs)
+		sort.SliceStable(foundWorlds, func(i, j int) bool {
+			return position[foundWorlds[i].ID] < position[foundWorlds[j].ID]
+		})
 		for _, world := range foundWorlds {
 			if ok, _ := users.CheckPermissions(tx, *world.UUID, user, *world.Private, permissions.Read); ok {
 				count++
@@ -881,7 +975,13 @@ func createModelResults(ctx context.Context, user *users.User, tx *gorm.DB, elas
 	// Get all the models from the DB and add them to the result
 	var foundModels []*models.Model
 	count := int64(0)
-	if err := tx.Where(resourceIDs).Preload("Tags").Preload("Categories").Preload("License").Find(&foundModels).Error; err == nil {
+	// Resources hidden by moderators, and those of suspended users, are not found.
+	if err := commonres.QueryForHidden(tx.Where(resourceIDs), user).Preload("Tags").Preload("Categories").Preload("License").Find(&foundModels).Error; err == nil {
+		// Keep the order of the search hits
+		position := hitPositions(resourceIDs)
+		sort.SliceStable(foundModels, func(i, j int) bool {
+			return position[foundModels[i].ID] < position[foundModels[j].ID]
+		})
 		for _, model := range foundModels {
 			if ok, _ := users.CheckPermissions(tx, *model.UUID, user, *model.Private, permissions.Read); ok {
 				count++
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/events"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// eventPollInterval is how often new events are read from the DB and sent to
+// the subscribers.
+const eventPollInterval = time.Second
+
+// eventPurgeInterval is how often the expired events are removed.
+const eventPurgeInterval = time.Hour
+
+// eventBatch is the maximum number of events read from the DB at once.
+const eventBatch = 500
+
+// eventGracePeriod is how long an event can take to be committed. Concurrent
+// transactions can commit their events out of ID order, so the events newer
+// than this are read again on each poll, and the ones already sent are
+// skipped.
+const eventGracePeriod = 10 * time.Second
+
+// eventHeartbeat is how often a comment is sent to idle streams, to keep the
+// connection open through proxies.
+const eventHeartbeat = 30 * time.Second
+
+// eventReplayLimit is the maximum number of missed events sent to a client
+// that reconnects with a Last-Event-ID header.
+const eventReplayLimit = 100
+
+// eventSubscriberBuffer is the number of events buffered for each subscriber.
+// Events are dropped for subscribers that fall further behind.
+const eventSubscriberBuffer = 64
+
+// publishEvent records a lifecycle event of a model, world or collection, to
+// be streamed to the /events subscribers once the transaction is committed,
+// and enqueues its delivery to the webhooks of the resource owner. The actor
+// can be empty.
+func publishEvent(tx *gorm.DB, action, actor string, res commonres.Resource,
+	details map[string]interface{}) *gz.ErrMsg {
+
+	resType := resourceTypeOf(res)
+	e := events.Event{
+		Event:        resType + "." + action,
+		Actor:        actor,
+		ResourceType: resType,
+		Owner:        *res.GetOwner(),
+		Name:         *res.GetName(),
+		ResourceUUID: *res.GetUUID(),
+		Private:      isPrivateResource(res),
+	}
+	if len(details) > 0 {
+		b, err := json.Marshal(details)
+		if err != nil {
+			return gz.NewErrorMessageWithBase(gz.ErrorMarshalJSON, err)
+		}
+		e.Details = string(b)
+	}
+	if em := events.Record(tx, &e); em != nil {
+		return em
+	}
+	return webhooks.Enqueue(tx, e.Owner, webhooks.Payload{
+		Event:        e.Event,
+		Timestamp:    e.CreatedAt,
+		Actor:        actor,
+		ResourceType: resType,
+		Owner:        e.Owner,
+		Name:         e.Name,
+		Details:      details,
+	})
+}
+
+// eventSubscriber is an open /events stream.
+type eventSubscriber struct {
+	// The username of the subscriber. Empty for anonymous subscribers.
+	username string
+	filter   events.Filter
+	ch       chan events.Event
+}
+
+// eventBroker sends the events stored in the DB to the subscribers of this
+// server instance.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+	// The ID of the last event read. All the events up to it are older than
+	// the eventGracePeriod and were already sent.
+	lastID uint
+	// The IDs of the events after lastID that were already sent
+	sent map[uint]struct{}
+}
+
+// broker is the event broker of this server instance.
+var broker = &eventBroker{subscribers: map[*eventSubscriber]struct{}{}}
+
+// subscribe adds a subscriber to the broker.
+func (b *eventBroker) subscribe(s *eventSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[s] = struct{}{}
+}
+
+// unsubscribe removes a subscriber from the broker.
+func (b *eventBroker) unsubscribe(s *eventSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers, s)
+}
+
+// poll reads the new events from the DB and sends them to the subscribers
+// that can see them. The events created within the eventGracePeriod are read
+// again on the next poll, in case an event with a lower ID is committed late.
+func (b *eventBroker) poll(db *gorm.DB) *gz.ErrMsg {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.sent == nil {
+		b.sent = map[uint]struct{}{}
+	}
+	settled := time.Now().Add(-eventGracePeriod)
+	// lastID only moves past the events that are older than the grace period
+	// and have no newer events before them.
+	advance := true
+	for after := b.lastID; ; {
+		list, em := events.After(db, after, eventBatch)
+		if em != nil {
+			return em
+		}
+		for i := range list {
+			e := &list[i]
+			if _, ok := b.sent[e.ID]; !ok {
+				b.send(e)
+				b.sent[e.ID] = struct{}{}
+			}
+			if advance = advance && e.CreatedAt.Before(settled); advance {
+				b.lastID = e.ID
+			}
+			after = e.ID
+		}
+		if len(list) < eventBatch {
+			break
+		}
+	}
+	for id := range b.sent {
+		if id <= b.lastID {
+			delete(b.sent, id)
+		}
+	}
+	return nil
+}
+
+// send sends an event to the subscribers that can see it.
+func (b *eventBroker) send(e *events.Event) {
+	for s := range b.subscribers {
+		if !s.filter.Match(e) || !canSeeEvent(s.username, e) {
+			continue
+		}
+		// Don't block the broker on slow subscribers
+		select {
+		case s.ch <- *e:
+		default:
+		}
+	}
+}
+
+// canSeeEvent returns true if the given user can see an event. Events of
+// private resources are only visible to the users that can read the resource.
+func canSeeEvent(username string, e *events.Event) bool {
+	if !e.Private {
+		return true
+	}
+	if username == "" {
+		return false
+	}
+	if globals.Permissions.IsSystemAdmin(username) {
+		return true
+	}
+	ok, _ := globals.Permissions.IsAuthorized(username, e.ResourceUUID, permissions.Read)
+	return ok
+}
+
+// parseEventFilter reads the stream filter from the query parameters: owner,
+// type (model, world or collection) and name. A specific resource is selected
+// with the three of them.
+func parseEventFilter(r *http.Request) (*events.Filter, *gz.ErrMsg) {
+	query := r.URL.Query()
+	f := events.Filter{
+		Owner:        query.Get("owner"),
+		ResourceType: query.Get("type"),
+		Name:         query.Get("name"),
+	}
+	switch f.ResourceType {
+	case "", "model", "world", "collection":
+	default:
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"type"})
+	}
+	if f.Name != "" && (f.Owner == "" || f.ResourceType == "") {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorMissingField, nil,
+			[]string{"owner and type are required to filter by name"})
+	}
+	return &f, nil
+}
+
+// writeEvent writes an event to a Server-Sent Events stream.
+func writeEvent(w http.ResponseWriter, e *events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Event, data)
+	return err
+}
+
+// EventStream streams the lifecycle events of models, worlds and collections
+// as Server-Sent Events. The stream can be filtered by owner, resource type or
+// specific resource. Events of private resources are only sent to the users
+// that can read them. Clients reconnecting with a Last-Event-ID header get
+// the events they missed.
+// You can request this method with the following cURL request:
+//
+//	curl -k -N -X GET --url 'https://localhost:4430/1.0/events?owner=OpenRobotics&type=model'
+func EventStream(tx *gorm.DB, w http.ResponseWriter, r *http.Request) *gz.ErrMsg {
+	user, ok, errMsg := getUserFromJWT(tx, r)
+	if !ok && errMsg.ErrCode != gz.ErrorAuthJWTInvalid && errMsg.ErrCode != gz.ErrorAuthNoUser {
+		return &errMsg
+	}
+	f, em := parseEventFilter(r)
+	if em != nil {
+		return em
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return gz.NewErrorMessageWithArgs(gz.ErrorUnexpected, nil, []string{"Streaming is not supported"})
+	}
+
+	s := &eventSubscriber{filter: *f, ch: make(chan events.Event, eventSubscriberBuffer)}
+	if user != nil {
+		s.username = *user.Username
+	}
+	// Subscribe before reading the missed events, so none is lost in between.
+	broker.subscribe(s)
+	defer broker.unsubscribe(s)
+
+	var missed events.Events
+	if lastID, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 32); err == nil {
+		if missed, em = events.After(tx, uint(lastID), eventReplayLimit); em != nil {
+			return em
+		}
+	}
+
+	// Release the DB transaction. The stream can stay open for a long time.
+	if err := tx.Commit().Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	var sentID uint
+	send := func(e *events.Event) bool {
+		// Events replayed from the DB can also be received from the broker
+		if e.ID <= sentID || !s.filter.Match(e) || !canSeeEvent(s.username, e) {
+			return true
+		}
+		if err := writeEvent(w, e); err != nil {
+			return false
+		}
+		sentID = e.ID
+		return true
+	}
+	for i := range missed {
+		if !send(&missed[i]) {
+			return nil
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case e := <-s.ch:
+			if !send(&e) {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return nil
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// runEventBroker periodically sends the new events to the /events
+// subscribers, and removes the expired events, until the given context is done.
+// It is expected to be run in its own goroutine.
+func runEventBroker(ctx context.Context, db *gorm.DB) {
+	// Only stream the events produced from now on
+	lastID, em := events.LastID(db)
+	if em != nil {
+		gz.LoggerFromContext(ctx).Error("Failed to read the last event: ", em.LogString())
+	}
+	broker.mu.Lock()
+	broker.lastID = lastID
+	broker.mu.Unlock()
+
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+	for {
+		if em := broker.poll(db); em != nil {
+			gz.LoggerFromContext(ctx).Error("Failed to read new events: ", em.LogString())
+		}
+		if time.Since(lastPurge) > eventPurgeInterval {
+			if em := events.PurgeExpired(db); em != nil {
+				gz.LoggerFromContext(ctx).Error("Failed to purge expired events: ", em.LogString())
+			}
+			lastPurge = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
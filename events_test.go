@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/gazebo-web/fuel-server/bundles/events"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"os"
+	"testing"
+)
+
+// TestEventStream tests the filters of the /events stream and that the events
+// of private models are only sent to the users that can read them.
+func TestEventStream(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+
+	expEm := gz.NewErrorMessage(gz.ErrorFormInvalidValue)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", "/1.0/events?type=foo", nil, expEm.StatusCode, nil, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name()+" invalid type", bslice, expEm.ErrCode, t)
+	expEm = gz.NewErrorMessage(gz.ErrorMissingField)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", "/1.0/events?name=model1", nil, expEm.StatusCode, nil, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name()+" name without owner", bslice, expEm.ErrCode, t)
+
+	// Skip the events of previous tests
+	lastID, em := events.LastID(globals.Server.Db)
+	require.Nil(t, em)
+	broker.lastID = lastID
+
+	anonymous := &eventSubscriber{ch: make(chan events.Event, eventSubscriberBuffer)}
+	owner := &eventSubscriber{
+		username: testUser,
+		filter:   events.Filter{Owner: testUser, ResourceType: "model"},
+		ch:       make(chan events.Event, eventSubscriberBuffer),
+	}
+	broker.subscribe(anonymous)
+	defer broker.unsubscribe(anonymous)
+	broker.subscribe(owner)
+	defer broker.unsubscribe(owner)
+
+	createTestModelWithOwner(t, &jwt, "public", testUser, false)
+	createTestModelWithOwner(t, &jwt, "private", testUser, true)
+	require.Nil(t, broker.poll(globals.Server.Db))
+
+	received := func(s *eventSubscriber) []string {
+		var names []string
+		for len(s.ch) > 0 {
+			e := <-s.ch
+			assert.Equal(t, "model.create", e.Event)
+			assert.Equal(t, testUser, e.Owner)
+			names = append(names, e.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"public"}, received(anonymous))
+	assert.Equal(t, []string{"public", "private"}, received(owner))
+
+	// Events are not sent twice
+	require.Nil(t, broker.poll(globals.Server.Db))
+	assert.Empty(t, received(owner))
+
+	// Events committed out of ID order are still sent
+	tx := globals.Server.Db.Begin()
+	require.Nil(t, events.Record(tx, &events.Event{Event: "model.create", ResourceType: "model",
+		Owner: testUser, Name: "late", ResourceUUID: "late-uuid"}))
+	createTestModelWithOwner(t, &jwt, "early", testUser, false)
+	require.Nil(t, broker.poll(globals.Server.Db))
+	assert.Equal(t, []string{"early"}, received(owner))
+	require.NoError(t, tx.Commit().Error)
+	require.Nil(t, broker.poll(globals.Server.Db))
+	assert.Equal(t, []string{"late"}, received(owner))
+}
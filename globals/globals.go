@@ -13,7 +13,9 @@ import (
 	"github.com/gazebo-web/gz-go/v7/storage"
 	"github.com/go-playground/form"
 	"gopkg.in/go-playground/validator.v9"
+	"net"
 	"net/http/httptest"
+	"time"
 )
 
 // TODO: remove as much as possible from globals
@@ -92,3 +94,36 @@ var Storage storage.Storage
 
 // QueryCache is used to store/cache results for common queries.
 var QueryCache *memcache.Client
+
+// TrashRetention is the amount of time soft-deleted resources (models, worlds
+// and collections) can still be restored before being permanently removed.
+// It is set using the IGN_FUEL_TRASH_RETENTION_DAYS env var.
+var TrashRetention time.Duration
+
+// AuditRetention is the amount of time audit entries are kept before being
+// permanently removed. It is set using the IGN_FUEL_AUDIT_RETENTION_DAYS env var.
+var AuditRetention time.Duration
+
+// AutoHideReporters is the number of distinct users that must report a public
+// model or world within AutoHideWindow for it to be hidden automatically,
+// pending moderator review. Zero disables automatic hiding. It is set using
+// the IGN_FUEL_AUTO_HIDE_REPORTERS env var.
+var AutoHideReporters int
+
+// AutoHideWindow is the amount of time in which the reports are counted to
+// hide a resource automatically. It is set using the
+// IGN_FUEL_AUTO_HIDE_WINDOW_HOURS env var.
+var AutoHideWindow time.Duration
+
+// TrustedProxies are the networks of the reverse proxies and load balancers
+// the server runs behind. The X-Forwarded-For header is only honored in
+// requests coming from these networks. It is set using the
+// IGN_FUEL_TRUSTED_PROXIES env var, a comma separated list of IPs or CIDRs.
+var TrustedProxies []*net.IPNet
+
+// WebhookAllowedNetworks are the private networks webhook payloads can be
+// delivered to. Webhooks cannot reach loopback, private, link-local or
+// metadata addresses unless they are in these networks. It is set using the
+// IGN_FUEL_WEBHOOK_ALLOWED_NETWORKS env var, a comma separated list of IPs or
+// CIDRs.
+var WebhookAllowedNetworks []*net.IPNet
@@ -3,9 +3,13 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/audit"
 	res "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
 	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/go-playground/form"
 	"github.com/gorilla/mux"
@@ -15,11 +19,13 @@ import (
 	"log"
 	"mime"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // NoResult is a middleware that adapts a gz.HandlerWithResult into a gz.Handler.
@@ -196,12 +202,136 @@ func NameOwnerHandler(nameArg string, failIfNoUser bool,
 			return nil, em
 		}
 
-		result, em := handler(owner, name, user, tx, w, r)
-		if em != nil {
-			return nil, em
+		// A share token grants read access to the files of a private resource,
+		// even without a user.
+		if token := r.URL.Query().Get("share_token"); token != "" {
+			r = r.WithContext(res.NewContextWithShareToken(r.Context(), token))
 		}
-		return result, nil
+
+		return handler(owner, name, user, tx, w, r)
+	}
+}
+
+// aliasRoutes returns a copy of the given routes, where the handlers of the
+// model and world routes redirect the requests that use a previous name of a
+// renamed model or world. The redirect happens before the handlers run, so the
+// result writers never write after it.
+func aliasRoutes(routes gz.Routes) gz.Routes {
+	aliased := make(gz.Routes, len(routes))
+	for i, route := range routes {
+		nameArg := ""
+		if strings.Contains(route.URI, "/models/{model}") {
+			nameArg = "model"
+		} else if strings.Contains(route.URI, "/worlds/{world}") {
+			nameArg = "world"
+		}
+		if nameArg != "" {
+			route.Methods = aliasMethods(nameArg, route.Methods)
+			route.SecureMethods = aliasMethods(nameArg, route.SecureMethods)
+		}
+		aliased[i] = route
+	}
+	return aliased
+}
+
+// aliasMethods returns a copy of the given methods, where the handlers are
+// wrapped with aliasHandler.
+func aliasMethods(nameArg string, methods []gz.Method) []gz.Method {
+	aliased := make([]gz.Method, len(methods))
+	for i, m := range methods {
+		handlers := make(gz.FormatHandlers, len(m.Handlers))
+		for j, fh := range m.Handlers {
+			fh.Handler = aliasHandler(nameArg, fh.Handler)
+			handlers[j] = fh
+		}
+		m.Handlers = handlers
+		aliased[i] = m
 	}
+	return aliased
+}
+
+// aliasHandler wraps a handler to redirect the request instead, if it uses a
+// previous name of a renamed model or world.
+func aliasHandler(nameArg string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if redirectFromAlias(nameArg, globals.Server.Db, w, r) {
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// redirectFromAlias is a helper function that redirects the request to the
+// current URL of a renamed model or world, if the given name is one of its
+// previous names. GET and HEAD requests get a 301 (Moved Permanently) status,
+// while other methods get a 308 (Permanent Redirect) so the method and body are
+// preserved.
+// Requests are only redirected if the user can read the renamed resource, so
+// the new name of a private resource is not revealed.
+// It returns true if the request was redirected.
+func redirectFromAlias(nameArg string, tx *gorm.DB, w http.ResponseWriter, r *http.Request) bool {
+	name, owner, em := readOwnerNameParams(nameArg, tx, r)
+	if em != nil {
+		return false
+	}
+
+	var resource res.Resource
+	var private bool
+	switch nameArg {
+	case "model":
+		if _, err := models.GetModelByName(tx, name, owner); err == nil {
+			return false
+		}
+		model, err := models.GetModelByAlias(tx, name, owner)
+		if err != nil {
+			return false
+		}
+		resource, private = model, *model.Private
+	case "world":
+		if _, err := worlds.GetWorldByName(tx, name, owner); err == nil {
+			return false
+		}
+		world, err := worlds.GetWorldByAlias(tx, name, owner)
+		if err != nil {
+			return false
+		}
+		resource, private = world, *world.Private
+	default:
+		return false
+	}
+	// The user is nil if the request is not authenticated.
+	user, _, _ := getUserFromJWT(tx, r)
+	if ok, _ := users.CheckPermissions(tx, *resource.GetUUID(), user, private, permissions.Read); !ok {
+		return false
+	}
+	newOwner, newName := resource.GetOwner(), resource.GetName()
+
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+	var pairs []string
+	for k, v := range mux.Vars(r) {
+		switch k {
+		case "username":
+			v = *newOwner
+		case nameArg:
+			v = *newName
+		}
+		pairs = append(pairs, k, v)
+	}
+	u, err := route.URLPath(pairs...)
+	if err != nil {
+		return false
+	}
+	u.RawQuery = r.URL.RawQuery
+
+	status := http.StatusPermanentRedirect
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		status = http.StatusMovedPermanently
+	}
+	http.Redirect(w, r, u.String(), status)
+	return true
 }
 
 // readOwnerNameParams is a helper function that reads the owner's name,
@@ -237,6 +367,32 @@ func readOwnerNameParams(nameArg string, tx *gorm.DB,
 	return
 }
 
+// readBoolParam is a helper function that reads an optional boolean query
+// parameter, eg. the "archived" and "deprecated" filters of resource lists.
+// It returns nil if the parameter is not present or is not a valid boolean.
+func readBoolParam(r *http.Request, name string) *bool {
+	v, ok := r.URL.Query()[name]
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(v[0])
+	if err != nil {
+		return nil
+	}
+	return &b
+}
+
+// readSortParam is a helper function that reads the optional "sort" query
+// parameter of model and world lists. The only supported value is "rating",
+// which returns the best rated resources first.
+func readSortParam(r *http.Request) (string, *gz.ErrMsg) {
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy != "" && sortBy != res.SortByRating {
+		return "", gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"sort"})
+	}
+	return sortBy, nil
+}
+
 // readListParams is a helper function that reads the "owner", the "order" and "q"
 // parameters used to get a list of resources.
 // The order parameter can be asc or desc.
@@ -377,6 +533,7 @@ func getValidationErrorsExtraInfo(err error) []string {
 // getUserFromJWT returns the User associated to the http request's JWT token.
 // This function can return ErrorAuthJWTInvalid if the token cannot be
 // read, or ErrorAuthNoUser no user with such identity exists in the DB.
+// Suspended users get ErrorUnauthorized.
 func getUserFromJWT(tx *gorm.DB, r *http.Request) (*users.User, bool, gz.ErrMsg) {
 	var user *users.User
 
@@ -388,10 +545,25 @@ func getUserFromJWT(tx *gorm.DB, r *http.Request) (*users.User, bool, gz.ErrMsg)
 			return nil, false, gz.ErrorMessage(gz.ErrorUnauthorized)
 		}
 
+		if accessToken.Expires != nil && accessToken.Expires.Before(time.Now()) {
+			return nil, false, gz.ErrorMessage(gz.ErrorUnauthorized)
+		}
+
 		user = new(users.User)
 		if err := tx.Where("id = ?", accessToken.UserID).First(user).Error; err != nil {
 			return nil, false, *gz.NewErrorMessage(gz.ErrorUnauthorized)
 		}
+
+		// Make sure the token scopes allow the request.
+		details := users.GetAccessTokenDetails(tx, accessToken.ID)
+		user.TokenScopes = users.ScopesFromString(details.Scopes)
+		path := strings.TrimPrefix(r.URL.Path, "/"+globals.APIVersion)
+		if !users.ScopesAllowRequest(user.TokenScopes, r.Method, path, mux.Vars(r)) {
+			return nil, false, gz.ErrorMessage(gz.ErrorUnauthorized)
+		}
+		// The use is recorded outside the request transaction.
+		users.RecordAccessTokenUse(r.Context(), globals.Server.Db, accessToken, requestIP(r))
+		audit.SetActor(r.Context(), *user.Username, &accessToken.ID)
 	} else {
 		identity, valid := gz.GetUserIdentity(r)
 		if !valid {
@@ -403,12 +575,96 @@ func getUserFromJWT(tx *gorm.DB, r *http.Request) (*users.User, bool, gz.ErrMsg)
 		if em != nil {
 			return nil, false, *em
 		}
+		audit.SetActor(r.Context(), *user.Username, nil)
+	}
+
+	// Suspended users cannot authenticate.
+	if user.IsSuspended() {
+		return nil, false, *gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil,
+			[]string{"The user is suspended"})
 	}
 
 	errMsg := gz.ErrorMessageOK()
 	return user, true, errMsg
 }
 
+// requestIP returns the IP address of the client that made the request. The
+// X-Forwarded-For header is only honored if the request comes from one of the
+// configured trusted proxies. In that case the header is read from right to
+// left, skipping the trusted proxies, as the leftmost entries can be set by the
+// client.
+func requestIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !isTrustedProxy(ip) {
+		return ip
+	}
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// isTrustedProxy returns true if the given IP belongs to one of the trusted
+// proxy networks.
+func isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range globals.TrustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetworks parses a comma separated list of IPs and CIDRs. Bare IPs are
+// parsed as single address networks.
+func parseNetworks(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// getRouteID returns the value of the numeric "id" parameter from the HTTP
+// route.
+func getRouteID(r *http.Request) (uint, *gz.ErrMsg) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, gz.NewErrorMessageWithBase(gz.ErrorIDNotInRequest, err)
+	}
+	return uint(id), nil
+}
+
 // getRequestFiles return the multipart form files from the request field "file"
 // or "file[]"
 func getRequestFiles(r *http.Request) []*multipart.FileHeader {
@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/activity"
 	"github.com/gazebo-web/fuel-server/bundles/collections"
 	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gazebo-web/fuel-server/bundles/worlds"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
@@ -39,7 +41,7 @@ func CollectionList(p *gz.PaginationRequest, owner *string, order, search string
 		extend, _ = strconv.ParseBool(v[0])
 	}
 	s := &collections.Service{}
-	return s.CollectionList(p, tx, owner, order, search, extend, user)
+	return s.CollectionList(p, tx, owner, order, search, extend, user, readBoolParam(r, "archived"))
 }
 
 // CollectionIndex returns a single Collection. The returned value will be of
@@ -61,7 +63,16 @@ func CollectionIndex(owner, name string, user *users.User, tx *gorm.DB,
 func CollectionRemove(owner, name string, user *users.User, tx *gorm.DB,
 	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
 
-	if em := (&collections.Service{}).RemoveCollection(tx, owner, name, user); em != nil {
+	cs := &collections.Service{}
+	col, em := cs.GetCollection(tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+	setAuditBefore(r, col)
+	if em := cs.RemoveCollection(tx, owner, name, user); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventDelete, *user.Username, col, nil); em != nil {
 		return nil, em
 	}
 
@@ -100,6 +111,15 @@ func doCreateCollection(tx *gorm.DB, cb createCollectionFn, w http.ResponseWrite
 		return nil, em
 	}
 
+	if em := recordActivity(tx, &activity.Activity{Actor: *jwtUser.Username,
+		Verb: activity.VerbCreate, ObjectType: "collection", Owner: *col.Owner,
+		Name: *col.Name, ResourceUUID: *col.UUID}, col.Private); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventCreate, *jwtUser.Username, col, nil); em != nil {
+		return nil, em
+	}
+
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
 	// before writing "data" to ResponseWriter. Once you write data (not headers)
@@ -205,10 +225,21 @@ func CollectionUpdate(owner, name string, user *users.User, tx *gorm.DB,
 	}
 
 	col, em := (&collections.Service{}).UpdateCollection(r.Context(), tx, owner,
-		name, uc.Description, newFilesPath, uc.Private, user)
+		name, uc.Description, newFilesPath, uc.Private, uc.Archived, user)
 	if em != nil {
 		return nil, em
 	}
+	if em := recordActivity(tx, &activity.Activity{Actor: *user.Username,
+		Verb: activity.VerbUpdate, ObjectType: "collection", Owner: *col.Owner,
+		Name: *col.Name, ResourceUUID: *col.UUID}, col.Private); em != nil {
+		return nil, em
+	}
+	if em := onCollectionChange(tx, *user.Username, col, "the collection was updated"); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventUpdate, *user.Username, col, nil); em != nil {
+		return nil, em
+	}
 
 	infoStr := "Collection has been updated:" +
 		"\n\t name: " + *col.Name +
@@ -298,8 +329,21 @@ func collectionAssetAdd(colOwner, colName, assetType string, user *users.User,
 		return nil, em
 	}
 
-	if _, em := (&collections.Service{}).AddAsset(r.Context(), tx, colOwner, colName,
-		no, assetType, user); em != nil {
+	col, em := (&collections.Service{}).AddAsset(r.Context(), tx, colOwner, colName,
+		no, assetType, user)
+	if em != nil {
+		return nil, em
+	}
+	if em := collectionAssetActivity(tx, activity.VerbAddAsset, assetType, user, col, no); em != nil {
+		return nil, em
+	}
+	if em := onCollectionChange(tx, *user.Username, col,
+		fmt.Sprintf("the %s %s/%s was added", assetType, no.Owner, no.Name)); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventUpdate, *user.Username, col, map[string]interface{}{
+		"added": collections.NameOwnerPair{Owner: no.Owner, Name: no.Name}, "asset_type": assetType,
+	}); em != nil {
 		return nil, em
 	}
 
@@ -346,8 +390,21 @@ func collectionAssetRemove(colOwner, colName, assetType string, user *users.User
 		return nil, em
 	}
 
-	if _, em := (&collections.Service{}).RemoveAsset(r.Context(), tx, colOwner, colName,
-		no, assetType, user); em != nil {
+	col, em := (&collections.Service{}).RemoveAsset(r.Context(), tx, colOwner, colName,
+		no, assetType, user)
+	if em != nil {
+		return nil, em
+	}
+	if em := collectionAssetActivity(tx, activity.VerbRemoveAsset, assetType, user, col, no); em != nil {
+		return nil, em
+	}
+	if em := onCollectionChange(tx, *user.Username, col,
+		fmt.Sprintf("the %s %s/%s was removed", assetType, no.Owner, no.Name)); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventUpdate, *user.Username, col, map[string]interface{}{
+		"removed": collections.NameOwnerPair{Owner: no.Owner, Name: no.Name}, "asset_type": assetType,
+	}); em != nil {
 		return nil, em
 	}
 
@@ -481,6 +538,65 @@ func CollectionClone(sourceCollectionOwner, sourceCollectionName string,
 	return doCreateCollection(tx, createFn, w, r)
 }
 
+// CollectionForks returns the list of collections that were cloned from a given
+// collection.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/collections/{collection-name}/forks
+func CollectionForks(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	// Prepare pagination
+	pr, em := gz.NewPaginationRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	s := &collections.Service{}
+	forks, pagination, em := s.CollectionForks(pr, tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+
+	err := gz.WritePaginationHeaders(*pagination, w, r)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	return forks, nil
+}
+
+// CollectionRestore restores a deleted collection, as long as it is still in the owner's trash.
+// An optional new name can be given in case the original name is already taken.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/collections/{collection-name}/restore
+//	  -d '{"name":"optional new name"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func CollectionRestore(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	rr, em := parseRestoreRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	col, em := (&collections.Service{}).RestoreCollection(r.Context(), tx, owner, name, *rr, user)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromRequest(r).Info("Collection restored: " + owner + "/" + *col.Name)
+	return col, nil
+}
+
 // CollectionTransfer transfer ownership of a collection to an organization.
 // The source owner must have write permissions on the destination organization
 //
@@ -506,10 +622,23 @@ func CollectionTransfer(sourceOwner, collectionName string, user *users.User, tx
 		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, em.BaseError, []string{extra})
 	}
 
-	if em := transferMoveResource(tx, collection, sourceOwner, transferAsset.DestOwner); em != nil {
+	moved, em := transferMoveResource(tx, collection, sourceOwner, transferAsset.DestOwner)
+	if em != nil {
 		return nil, em
 	}
-	tx.Save(&collection)
+	if err := tx.Save(collection).Error; err != nil {
+		revertTransfers(r, moved)
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		revertTransfers(r, moved)
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
 
 	return &collection, nil
 }
@@ -5,10 +5,13 @@ import (
 	"log"
 	"mime/multipart"
 	"net/http"
+	"strings"
 
+	res "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/bundles/reviews"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
 )
@@ -22,6 +25,16 @@ func reviewFn(cmr reviews.CreateModelReview, tx *gorm.DB, jwtUser *users.User, w
 		return nil, em
 	}
 
+	// Let the model owner webhooks know about the review status
+	model, err := models.GetModelByID(tx, *cmr.ModelID)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	status := strings.ToLower(reviews.ToReviewStatus(modelReview.Status).String())
+	if em := publishEvent(tx, webhooks.EventReview, *jwtUser.Username, model, map[string]interface{}{"title": *modelReview.Title, "status": status}); em != nil {
+		return nil, em
+	}
+
 	return modelReview, nil
 }
 
@@ -124,6 +137,10 @@ func ReviewCreate(tx *gorm.DB, w http.ResponseWriter, r *http.Request) (interfac
 		errMsg := gz.ErrorMessage(gz.ErrorUnexpected)
 		return nil, &errMsg
 	}
+	// Archived models are read-only and do not accept new reviews
+	if res.IsArchived(model.Archived) {
+		return nil, res.NewArchivedErrorMessage(*model.Name)
+	}
 	cmr.ModelID = &model.ID
 
 	// create a new modelReview with prefilled modelID in cmr
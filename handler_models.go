@@ -1,12 +1,14 @@
 package main
 
 import (
+	"github.com/gazebo-web/fuel-server/bundles/activity"
 	"github.com/gazebo-web/fuel-server/bundles/category"
 	"github.com/gazebo-web/fuel-server/bundles/collections"
 	res "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/bundles/generics"
 	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/gorilla/mux"
@@ -14,6 +16,7 @@ import (
 	"log"
 	"mime/multipart"
 	"net/http"
+	"time"
 )
 
 // ModelList returns the list of models from a team/user. The returned value
@@ -27,6 +30,7 @@ import (
 // or  curl -k -X GET --url https://localhost:4430/1.0/models.json
 // or  curl -k -X GET --url https://localhost:4430/1.0/{username}/models with all the
 // above format variants.
+// Use the sort=rating query parameter to get the best rated models first.
 func ModelList(p *gz.PaginationRequest, owner *string, order, search string,
 	user *users.User, tx *gorm.DB, w http.ResponseWriter,
 	r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
@@ -39,7 +43,19 @@ func ModelList(p *gz.PaginationRequest, owner *string, order, search string,
 			categories = modelListCategoryHelper(tx, f, categories)
 		}
 	}
-	return ms.ModelList(p, tx, owner, order, search, nil, user, &categories, false)
+	sortBy, em := readSortParam(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	return ms.ModelList(p, tx, owner, res.ListOptions{
+		Order:      order,
+		SortBy:     sortBy,
+		Search:     search,
+		User:       user,
+		Categories: &categories,
+		Archived:   readBoolParam(r, "archived"),
+		Deprecated: readBoolParam(r, "deprecated"),
+	})
 }
 
 // modelListCategoryHelper append a category to filter in model list
@@ -67,7 +83,19 @@ func ModelLikeList(p *gz.PaginationRequest, owner *string, order, search string,
 		return nil, nil, em
 	}
 	ms := &models.Service{Storage: globals.Storage}
-	return ms.ModelList(p, tx, owner, order, search, likedBy, user, nil, false)
+	sortBy, em := readSortParam(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	return ms.ModelList(p, tx, owner, res.ListOptions{
+		Order:      order,
+		SortBy:     sortBy,
+		Search:     search,
+		LikedBy:    likedBy,
+		User:       user,
+		Archived:   readBoolParam(r, "archived"),
+		Deprecated: readBoolParam(r, "deprecated"),
+	})
 }
 
 // ModelOwnerVersionFileTree returns the file tree of a single model. The returned value
@@ -111,6 +139,7 @@ func ModelOwnerIndex(owner, modelName string, user *users.User, tx *gorm.DB,
 	}
 
 	writeIgnResourceVersionHeader(w, int(fuelModel.GetVersion()))
+	writeIgnDeprecationHeaders(w, fuelModel.GetDeprecated(), fuelModel.DeprecationMessage, fuelModel.Successor)
 
 	return fuelModel, nil
 }
@@ -127,6 +156,7 @@ func ModelOwnerRemove(owner, modelName string, user *users.User, tx *gorm.DB,
 	if em != nil {
 		return nil, em
 	}
+	setAuditBefore(r, model)
 
 	// Remove the model from the models table
 	if em = (&models.Service{Storage: globals.Storage}).RemoveModel(r.Context(), tx, owner, modelName, user); em != nil {
@@ -137,6 +167,9 @@ func ModelOwnerRemove(owner, modelName string, user *users.User, tx *gorm.DB,
 	if err := (&collections.Service{}).RemoveAssetFromAllCollections(tx, model.ID); err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
 	}
+	if em := publishEvent(tx, webhooks.EventDelete, *user.Username, model, nil); em != nil {
+		return nil, em
+	}
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -159,10 +192,23 @@ func ModelOwnerRemove(owner, modelName string, user *users.User, tx *gorm.DB,
 func ModelOwnerLikeCreate(owner, name string, user *users.User, tx *gorm.DB,
 	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
 
-	_, em := (&models.Service{Storage: globals.Storage}).CreateModelLike(tx, owner, name, user)
+	ms := &models.Service{Storage: globals.Storage}
+	if _, em := ms.CreateModelLike(tx, owner, name, user); em != nil {
+		return nil, em
+	}
+	model, em := ms.GetModel(tx, owner, name, user)
 	if em != nil {
 		return nil, em
 	}
+	if em := recordActivity(tx, &activity.Activity{Actor: *user.Username,
+		Verb: activity.VerbLike, ObjectType: "model", Owner: *model.Owner,
+		Name: *model.Name, ResourceUUID: *model.UUID}, model.Private); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventLike, *user.Username, model,
+		map[string]interface{}{"likes": model.Likes}); em != nil {
+		return nil, em
+	}
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -183,10 +229,18 @@ func ModelOwnerLikeCreate(owner, name string, user *users.User, tx *gorm.DB,
 func ModelOwnerLikeRemove(owner, name string, user *users.User, tx *gorm.DB,
 	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
 
-	_, em := (&models.Service{Storage: globals.Storage}).RemoveModelLike(tx, owner, name, user)
+	ms := &models.Service{Storage: globals.Storage}
+	if _, em := ms.RemoveModelLike(tx, owner, name, user); em != nil {
+		return nil, em
+	}
+	model, em := ms.GetModel(tx, owner, name, user)
 	if em != nil {
 		return nil, em
 	}
+	if em := publishEvent(tx, webhooks.EventUnlike, *user.Username, model,
+		map[string]interface{}{"likes": model.Likes}); em != nil {
+		return nil, em
+	}
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -238,6 +292,8 @@ func ModelOwnerVersionZip(owner, name string, user *users.User, tx *gorm.DB,
 	if em != nil {
 		return nil, em
 	}
+	writeIgnDeprecationHeaders(w, model.DeprecatedAt != nil, model.DeprecationMessage,
+		svc.SuccessorProto(tx, model, user))
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -281,13 +337,38 @@ func ReportModelCreate(owner, name string, user *users.User, tx *gorm.DB,
 		return nil, em
 	}
 
-	if _, em := (&models.Service{Storage: globals.Storage}).CreateModelReport(tx, owner, name, createModelReport.Reason); em != nil {
+	if _, em := (&models.Service{Storage: globals.Storage}).CreateModelReport(tx, owner, name, createModelReport.Reason, user); em != nil {
+		return nil, em
+	}
+	reported, err := models.GetModelByName(tx, name, owner)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNameNotFound, err)
+	}
+	// The reporter is not disclosed to the owner.
+	if em := publishEvent(tx, webhooks.EventReport, "", reported,
+		map[string]interface{}{"reason": createModelReport.Reason}); em != nil {
+		return nil, em
+	}
+	c, em := fileReport(tx, resourceSubject(reported, reported.ID), user, createModelReport.Reason)
+	if em != nil {
+		return nil, em
+	}
+	reporters, em := (&models.Service{Storage: globals.Storage}).CountModelReporters(tx, reported,
+		time.Now().Add(-globals.AutoHideWindow))
+	if em != nil {
+		return nil, em
+	}
+	hidden, em := autoHide(tx, reported, c, reporters)
+	if em != nil {
 		return nil, em
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
 	}
+	if hidden {
+		reindexResource(r.Context(), globals.Server.Db, reported)
+	}
 
 	if _, em := generics.SendReportEmail(name, owner, "models", createModelReport.Reason, r); em != nil {
 		return nil, em
@@ -3,8 +3,10 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/activity"
 	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/jinzhu/gorm"
@@ -63,6 +65,15 @@ func doCreateModel(tx *gorm.DB, cb createFn, w http.ResponseWriter, r *http.Requ
 		return nil, em
 	}
 
+	if em := recordActivity(tx, &activity.Activity{Actor: *jwtUser.Username,
+		Verb: activity.VerbCreate, ObjectType: "model", Owner: *model.Owner,
+		Name: *model.Name, ResourceUUID: *model.UUID}, model.Private); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventCreate, *jwtUser.Username, model, nil); em != nil {
+		return nil, em
+	}
+
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
 	// before writing "data" to ResponseWriter. Once you write data (not headers)
@@ -101,7 +112,7 @@ func modelFn(cm models.CreateModel, tx *gorm.DB, jwtUser *users.User, w http.Res
 			return nil, em
 		}
 	} else {
-		owner = *jwtUser.Username
+		owner = jwtUser.DefaultOwner()
 	}
 
 	// Get a new UUID and model folder
@@ -237,6 +248,129 @@ func ModelClone(owner, modelName string, ignored *users.User, tx *gorm.DB,
 	return doCreateModel(tx, createFn, w, r)
 }
 
+// ModelForks returns the list of models that were cloned from a given model.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model-name}/forks
+func ModelForks(owner, modelName string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	// Prepare pagination
+	pr, em := gz.NewPaginationRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	ms := &models.Service{Storage: globals.Storage}
+	forks, pagination, em := ms.ModelForks(pr, tx, owner, modelName, user)
+	if em != nil {
+		return nil, em
+	}
+
+	err := gz.WritePaginationHeaders(*pagination, w, r)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	return forks, nil
+}
+
+// ModelRestore restores a deleted model, as long as it is still in the owner's trash.
+// An optional new name can be given in case the original name is already taken.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model-name}/restore
+//	  -d '{"name":"optional new name"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ModelRestore(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	rr, em := parseRestoreRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	model, em := (&models.Service{Storage: globals.Storage}).RestoreModel(r.Context(), tx, owner, name, *rr, user)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromRequest(r).Info("Model restored: " + owner + "/" + *model.Name)
+	return model, nil
+}
+
+// ModelDeprecate marks a model as deprecated, with an optional message and
+// successor model. The returned value will be of type "fuel.Model".
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model-name}/deprecation
+//	  -d '{"message":"optional message", "successor_owner":"optional owner", "successor_name":"optional name"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ModelDeprecate(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	dr, em := parseDeprecateRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	s := &models.Service{Storage: globals.Storage}
+	model, em := s.DeprecateModel(r.Context(), tx, owner, name, *dr, user)
+	if em != nil {
+		return nil, em
+	}
+	if em := onDeprecation(tx, *user.Username, model, dr.Message); em != nil {
+		return nil, em
+	}
+	fuelModel := s.ModelToProto(model)
+	fuelModel.Successor = s.SuccessorProto(tx, model, user)
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromRequest(r).Info("Model deprecated: " + owner + "/" + name)
+	return fuelModel, nil
+}
+
+// ModelUndeprecate removes the deprecation of a model. The returned value will be
+// of type "fuel.Model".
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/models/{model-name}/deprecation
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ModelUndeprecate(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	s := &models.Service{Storage: globals.Storage}
+	model, em := s.UndeprecateModel(r.Context(), tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromRequest(r).Info("Model undeprecated: " + owner + "/" + name)
+	return s.ModelToProto(model), nil
+}
+
 // ModelUpdate modifies an existing model.
 // You can request this method with the following cURL request:
 //
@@ -289,11 +423,19 @@ func ModelUpdate(owner, modelName string, user *users.User, tx *gorm.DB,
 
 	um.Metadata = parseMetadata(r)
 
-	model, em := (&models.Service{Storage: globals.Storage}).UpdateModel(r.Context(), tx, owner, modelName,
-		um.Description, um.Tags, newFilesPath, um.Private, user, um.Metadata, um.Categories)
+	model, em := (&models.Service{Storage: globals.Storage}).UpdateModel(r.Context(), tx, owner, modelName, um.Name,
+		um.Description, um.Tags, newFilesPath, um.Private, um.Archived, user, um.Metadata, um.Categories)
 	if em != nil {
 		return nil, em
 	}
+	if newFilesPath != nil {
+		if em := onNewVersion(r.Context(), tx, *user.Username, "model", model,
+			model.Private); em != nil {
+			return nil, em
+		}
+	} else if em := publishEvent(tx, webhooks.EventUpdate, *user.Username, model, nil); em != nil {
+		return nil, em
+	}
 
 	infoStr := "Model has been updated:" +
 		"\n\t name: " + *model.Name +
@@ -337,10 +479,23 @@ func ModelTransfer(sourceOwner, modelName string, user *users.User, tx *gorm.DB,
 		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, em.BaseError, []string{extra})
 	}
 
-	if em := transferMoveResource(tx, model, sourceOwner, transferAsset.DestOwner); em != nil {
+	moved, em := transferMoveResource(tx, model, sourceOwner, transferAsset.DestOwner)
+	if em != nil {
 		return nil, em
 	}
-	tx.Save(&model)
+	if err := tx.Save(model).Error; err != nil {
+		revertTransfers(r, moved)
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		revertTransfers(r, moved)
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
 
 	return &model, nil
 }
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"github.com/gazebo-web/fuel-server/bundles/audit"
+	"github.com/gazebo-web/fuel-server/bundles/generics"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
@@ -191,6 +194,7 @@ func OrganizationUserCreate(orgName string, jwtUser *users.User, tx *gorm.DB,
 		return nil, em
 	}
 
+	auditMembership(r, orgName, orgUser.Username)
 	resp, em := (&users.OrganizationService{}).AddUserToOrg(r.Context(), tx, orgName, orgUser.Username, orgUser.Role, jwtUser)
 	if em != nil {
 		return nil, em
@@ -207,6 +211,13 @@ func OrganizationUserCreate(orgName string, jwtUser *users.User, tx *gorm.DB,
 	return resp, nil
 }
 
+// auditMembership records the role of a user in an organization as the state
+// before an audited membership change.
+func auditMembership(r *http.Request, orgName, username string) {
+	role := globals.Permissions.GetGroupsAndRolesForUser(username)[orgName]
+	audit.SetBefore(r.Context(), map[string]string{"username": username, "role": role})
+}
+
 // OrganizationUserRemove removes a user from an organization.
 // You can request this method with the following cURL request:
 //
@@ -225,6 +236,7 @@ func OrganizationUserRemove(orgName string, jwtUser *users.User, tx *gorm.DB,
 		return nil, gz.NewErrorMessage(gz.ErrorUserNotInRequest)
 	}
 
+	auditMembership(r, orgName, userToRemove)
 	resp, em := (&users.OrganizationService{}).RemoveUserFromOrg(r.Context(), tx,
 		orgName, userToRemove, jwtUser)
 	if em != nil {
@@ -376,3 +388,305 @@ func OrganizationTeamIndex(orgName string, jwtUser *users.User, tx *gorm.DB,
 	orgSvc := &users.OrganizationService{}
 	return orgSvc.GetTeamDetails(r.Context(), tx, orgName, teamName, jwtUser)
 }
+
+// getServiceAccountName returns the value of the "username" parameter from the
+// HTTP route. Returns an gz.ErrMsg if not present
+func getServiceAccountName(r *http.Request) (string, *gz.ErrMsg) {
+	username, present := mux.Vars(r)["username"]
+	if !present {
+		return "", gz.NewErrorMessage(gz.ErrorUserNotInRequest)
+	}
+	return username, nil
+}
+
+// OrganizationServiceAccountsList returns a paginated list with the service
+// accounts of an organization.
+func OrganizationServiceAccountsList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	orgName, em := getName(tx, r)
+	if em != nil {
+		return nil, nil, em
+	}
+	orgSvc := &users.OrganizationService{}
+	return orgSvc.GetServiceAccounts(p, tx, *orgName, user)
+}
+
+// OrganizationServiceAccountCreate adds a service account to an organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X POST https://localhost:4430/1.0/organizations/{orgName}/service-accounts
+//	  -H "Content-Type: application/json"
+//	  -d '{"username":"ci-bot", "name":"desc", "role":"member"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//
+// It returns the created service account
+func OrganizationServiceAccountCreate(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	var csa users.CreateServiceAccount
+	if em := ParseStruct(&csa, r, false); em != nil {
+		return nil, em
+	}
+
+	response, em := (&users.OrganizationService{}).CreateServiceAccount(r.Context(), tx, orgName, csa, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	return response, nil
+}
+
+// OrganizationServiceAccountRemove removes a service account from an
+// organization, along with its access tokens.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X DELETE https://localhost:4430/1.0/organizations/{orgName}/service-accounts/{username}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//
+// It returns the removed service account
+func OrganizationServiceAccountRemove(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	username, em := getServiceAccountName(r)
+	if em != nil {
+		return nil, em
+	}
+
+	response, em := (&users.OrganizationService{}).RemoveServiceAccount(r.Context(), tx,
+		orgName, username, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+
+	return response, nil
+}
+
+// OrganizationServiceAccountTokenCreate creates a personal access token for a
+// service account. Only organization admins can create them.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X POST https://localhost:4430/1.0/organizations/{orgName}/service-accounts/{username}/access-tokens
+//	  -H "Content-Type: application/json"
+//	  -d '{"name":"ci", "scopes":["models:write"]}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//
+// It returns the created access token. The full token is only returned once.
+func OrganizationServiceAccountTokenCreate(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	username, em := getServiceAccountName(r)
+	if em != nil {
+		return nil, em
+	}
+
+	// Scoped access tokens cannot manage access tokens.
+	if len(jwtUser.TokenScopes) > 0 {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+
+	var req users.AccessTokenCreateRequest
+	if em := ParseStruct(&req, r, false); em != nil {
+		return nil, em
+	}
+
+	serviceAccount, em := (&users.OrganizationService{}).GetServiceAccount(tx, orgName, username, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+	response, em := users.AccessTokenCreate(serviceAccount, tx, req)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	return response, nil
+}
+
+// OrganizationRolesList returns the custom roles of an organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/organizations/{name}/roles
+//	  --header 'authorization: Bearer <A_VALID_AUTH0_JWT_TOKEN>'
+func OrganizationRolesList(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	return (&users.OrganizationService{}).GetRoles(tx, orgName, jwtUser)
+}
+
+// OrganizationRoleCreate adds a custom role to an organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X POST https://localhost:4430/1.0/organizations/{orgName}/roles
+//	  -H "Content-Type: application/json"
+//	  -d '{"name":"maintainer", "description":"desc", "actions":["read","write"]}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//
+// It returns the created role
+func OrganizationRoleCreate(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	var cor users.CreateOrganizationRole
+	if em := ParseStruct(&cor, r, false); em != nil {
+		return nil, em
+	}
+
+	role, em := (&users.OrganizationService{}).CreateRole(r.Context(), tx, orgName, cor, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	if em := role.AddPermissions(); em != nil {
+		return nil, em
+	}
+
+	return users.CreateOrganizationRoleResponse(role), nil
+}
+
+// OrganizationRoleRemove removes a custom role from an organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X DELETE https://localhost:4430/1.0/organizations/{orgName}/roles/{role}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//
+// It returns the removed role
+func OrganizationRoleRemove(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	roleName, present := mux.Vars(r)["role"]
+	if !present {
+		return nil, gz.NewErrorMessage(gz.ErrorIDNotInRequest)
+	}
+
+	role, em := (&users.OrganizationService{}).RemoveRole(r.Context(), tx,
+		orgName, roleName, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	if em := role.RemovePermissions(); em != nil {
+		return nil, em
+	}
+
+	return users.CreateOrganizationRoleResponse(role), nil
+}
+
+// OrganizationInvitationsList returns a paginated list with the pending
+// invitations of an organization.
+func OrganizationInvitationsList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	orgName, em := getName(tx, r)
+	if em != nil {
+		return nil, nil, em
+	}
+	return (&users.OrganizationService{}).GetInvitations(p, tx, *orgName, user)
+}
+
+// OrganizationInvitationCreate invites a user to an organization, by username
+// or by email. The invitee is notified by email.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X POST https://localhost:4430/1.0/organizations/{orgName}/invitations
+//	  -H "Content-Type: application/json"
+//	  -d '{"username":"theUserToInvite", "role":"member", "teams":["team1"]}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//
+// It returns the created invitation
+func OrganizationInvitationCreate(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	var ci users.CreateInvitation
+	if em := ParseStruct(&ci, r, false); em != nil {
+		return nil, em
+	}
+
+	inv, em := (&users.OrganizationService{}).CreateInvitation(r.Context(), tx, orgName, ci, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+	recipient := users.InvitationRecipient(tx, inv)
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	response := users.CreateInvitationResponse(inv)
+	if em := generics.SendInvitationEmail(recipient, response.Username, inv.Organization,
+		inv.Inviter, inv.Role, inv.ID, inv.Token, inv.ExpiresAt, r); em != nil {
+		gz.LoggerFromRequest(r).Error("Unable to send invitation email: ", em.LogString())
+	}
+	return &response, nil
+}
+
+// OrganizationInvitationRevoke revokes a pending invitation of an organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X DELETE https://localhost:4430/1.0/organizations/{orgName}/invitations/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//
+// It returns the revoked invitation
+func OrganizationInvitationRevoke(orgName string, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	id, em := getRouteID(r)
+	if em != nil {
+		return nil, em
+	}
+
+	response, em := (&users.OrganizationService{}).RevokeInvitation(r.Context(), tx, orgName, id, jwtUser)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	return response, nil
+}
@@ -23,7 +23,17 @@ func Login(tx *gorm.DB, w http.ResponseWriter,
 		return nil, gz.NewErrorMessage(gz.ErrorAuthJWTInvalid)
 	}
 
-	return users.GetUserByIdentity(tx, identity)
+	user, em := users.ByIdentity(tx, identity, false)
+	if em != nil {
+		return nil, em
+	}
+	// Suspended users cannot authenticate.
+	if user.IsSuspended() {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil,
+			[]string{"The user is suspended"})
+	}
+	ur := users.CreateUserResponse(tx, user, user)
+	return &ur, nil
 }
 
 // UserCreate creates a new user
@@ -65,7 +75,9 @@ func UserList(p *gz.PaginationRequest, _ *users.User, tx *gorm.DB,
 		return nil, nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
 	}
 
-	return users.UserList(p, tx, user)
+	// Service accounts are only included if explicitly requested.
+	serviceAccounts := readBoolParam(r, "service_accounts")
+	return users.UserList(p, tx, user, serviceAccounts != nil && *serviceAccounts)
 }
 
 // UserIndex returns a single user
@@ -168,6 +180,11 @@ func AccessTokenDelete(username string, jwtUser *users.User, tx *gorm.DB, w http
 		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
 	}
 
+	// Scoped access tokens cannot manage access tokens.
+	if len(jwtUser.TokenScopes) > 0 {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+
 	// Read the access token to delete.
 	var accessToken gz.AccessToken
 	if em := ParseStruct(&accessToken, r, false); em != nil {
@@ -193,11 +210,68 @@ func AccessTokenCreate(username string, jwtUser *users.User, tx *gorm.DB, w http
 		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
 	}
 
-	// Parse the name of the token.
-	var accessTokenCreateInfo gz.AccessTokenCreateRequest
+	// Scoped access tokens cannot manage access tokens.
+	if len(jwtUser.TokenScopes) > 0 {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+
+	// Parse the name, scopes and expiration of the token.
+	var accessTokenCreateInfo users.AccessTokenCreateRequest
 	if em := ParseStruct(&accessTokenCreateInfo, r, false); em != nil {
 		return nil, em
 	}
 
 	return users.AccessTokenCreate(jwtUser, tx, accessTokenCreateInfo)
 }
+
+// UserInvitationsList returns a paginated list with the pending organization
+// invitations of a user.
+func UserInvitationsList(p *gz.PaginationRequest, jwtUser *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	// Users can only see their own invitations.
+	if mux.Vars(r)["username"] != *jwtUser.Username {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	return users.GetUserInvitations(p, tx, jwtUser)
+}
+
+// respondToInvitation returns a handler that accepts or declines a pending
+// organization invitation of a user. Invitations sent by email require the
+// token of the invitation email, in the token query parameter.
+// You can request these handlers with the following cURL requests:
+//
+//	curl -k -X POST https://localhost:4430/1.0/users/{username}/invitations/{id}/accept?token={token}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	curl -k -X POST https://localhost:4430/1.0/users/{username}/invitations/{id}/decline
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func respondToInvitation(accept bool) nameFn {
+	return func(username string, jwtUser *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		// Users can only respond to their own invitations.
+		if username != *jwtUser.Username {
+			return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+		}
+		id, em := getRouteID(r)
+		if em != nil {
+			return nil, em
+		}
+
+		response, em := users.RespondToInvitation(r.Context(), tx, jwtUser, id,
+			r.URL.Query().Get("token"), accept)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+
+		return response, nil
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/activity"
 	res "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
@@ -15,6 +16,7 @@ import (
 	"github.com/gazebo-web/fuel-server/bundles/collections"
 	"github.com/gazebo-web/fuel-server/bundles/generics"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gazebo-web/fuel-server/bundles/worlds"
 	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
@@ -59,12 +61,24 @@ func parseWorldMetadata(r *http.Request) *worlds.WorldMetadata {
 // or  curl -k -X GET --url https://localhost:4430/1.0/worlds.json
 // or  curl -k -X GET --url https://localhost:4430/1.0/{username}/worlds with all the
 // above format variants.
+// Use the sort=rating query parameter to get the best rated worlds first.
 func WorldList(p *gz.PaginationRequest, owner *string, order, search string,
 	user *users.User, tx *gorm.DB, w http.ResponseWriter,
 	r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
 
 	ws := &worlds.Service{Storage: globals.Storage}
-	return ws.WorldList(p, tx, owner, order, search, nil, user)
+	sortBy, em := readSortParam(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	return ws.WorldList(p, tx, owner, res.ListOptions{
+		Order:      order,
+		SortBy:     sortBy,
+		Search:     search,
+		User:       user,
+		Archived:   readBoolParam(r, "archived"),
+		Deprecated: readBoolParam(r, "deprecated"),
+	})
 }
 
 // WorldLikeList returns the list of worlds liked by a certain user. The returned value
@@ -82,7 +96,19 @@ func WorldLikeList(p *gz.PaginationRequest, owner *string, order, search string,
 		return nil, nil, em
 	}
 	ws := &worlds.Service{Storage: globals.Storage}
-	return ws.WorldList(p, tx, owner, order, search, likedBy, user)
+	sortBy, em := readSortParam(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	return ws.WorldList(p, tx, owner, res.ListOptions{
+		Order:      order,
+		SortBy:     sortBy,
+		Search:     search,
+		LikedBy:    likedBy,
+		User:       user,
+		Archived:   readBoolParam(r, "archived"),
+		Deprecated: readBoolParam(r, "deprecated"),
+	})
 }
 
 // WorldFileTree returns the file tree of a single world. The returned value
@@ -125,6 +151,7 @@ func WorldIndex(owner, name string, user *users.User, tx *gorm.DB,
 	}
 
 	writeIgnResourceVersionHeader(w, int(fuelWorld.GetVersion()))
+	writeIgnDeprecationHeaders(w, fuelWorld.GetDeprecated(), fuelWorld.DeprecationMessage, fuelWorld.Successor)
 
 	return fuelWorld, nil
 }
@@ -141,6 +168,7 @@ func WorldRemove(owner, name string, user *users.User, tx *gorm.DB,
 	if em != nil {
 		return nil, em
 	}
+	setAuditBefore(r, world)
 
 	// Remove the world from the worlds table
 	if em := (&worlds.Service{Storage: globals.Storage}).RemoveWorld(r.Context(), tx, owner, name, user); em != nil {
@@ -151,6 +179,9 @@ func WorldRemove(owner, name string, user *users.User, tx *gorm.DB,
 	if err := (&collections.Service{}).RemoveAssetFromAllCollections(tx, world.ID); err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
 	}
+	if em := publishEvent(tx, webhooks.EventDelete, *user.Username, world, nil); em != nil {
+		return nil, em
+	}
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -173,10 +204,23 @@ func WorldRemove(owner, name string, user *users.User, tx *gorm.DB,
 func WorldLikeCreate(owner, worldName string, user *users.User, tx *gorm.DB,
 	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
 
-	_, em := (&worlds.Service{Storage: globals.Storage}).CreateWorldLike(tx, owner, worldName, user)
+	ws := &worlds.Service{Storage: globals.Storage}
+	if _, em := ws.CreateWorldLike(tx, owner, worldName, user); em != nil {
+		return nil, em
+	}
+	world, em := ws.GetWorld(tx, owner, worldName, user)
 	if em != nil {
 		return nil, em
 	}
+	if em := recordActivity(tx, &activity.Activity{Actor: *user.Username,
+		Verb: activity.VerbLike, ObjectType: "world", Owner: *world.Owner,
+		Name: *world.Name, ResourceUUID: *world.UUID}, world.Private); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventLike, *user.Username, world,
+		map[string]interface{}{"likes": world.Likes}); em != nil {
+		return nil, em
+	}
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -197,10 +241,18 @@ func WorldLikeCreate(owner, worldName string, user *users.User, tx *gorm.DB,
 func WorldLikeRemove(owner, worldName string, user *users.User, tx *gorm.DB,
 	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
 
-	_, em := (&worlds.Service{Storage: globals.Storage}).RemoveWorldLike(tx, owner, worldName, user)
+	ws := &worlds.Service{Storage: globals.Storage}
+	if _, em := ws.RemoveWorldLike(tx, owner, worldName, user); em != nil {
+		return nil, em
+	}
+	world, em := ws.GetWorld(tx, owner, worldName, user)
 	if em != nil {
 		return nil, em
 	}
+	if em := publishEvent(tx, webhooks.EventUnlike, *user.Username, world,
+		map[string]interface{}{"likes": world.Likes}); em != nil {
+		return nil, em
+	}
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -254,6 +306,8 @@ func WorldZip(owner, name string, user *users.User, tx *gorm.DB,
 	if em != nil {
 		return nil, em
 	}
+	writeIgnDeprecationHeaders(w, world.DeprecatedAt != nil, world.DeprecationMessage,
+		svc.SuccessorProto(tx, world, user))
 
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
@@ -296,13 +350,38 @@ func ReportWorldCreate(owner, name string, user *users.User, tx *gorm.DB,
 		return nil, em
 	}
 
-	if _, em := (&worlds.Service{Storage: globals.Storage}).CreateWorldReport(tx, owner, name, createWorldReport.Reason); em != nil {
+	if _, em := (&worlds.Service{Storage: globals.Storage}).CreateWorldReport(tx, owner, name, createWorldReport.Reason, user); em != nil {
+		return nil, em
+	}
+	reported, err := worlds.GetWorldByName(tx, name, owner)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNameNotFound, err)
+	}
+	// The reporter is not disclosed to the owner.
+	if em := publishEvent(tx, webhooks.EventReport, "", reported,
+		map[string]interface{}{"reason": createWorldReport.Reason}); em != nil {
+		return nil, em
+	}
+	c, em := fileReport(tx, resourceSubject(reported, reported.ID), user, createWorldReport.Reason)
+	if em != nil {
+		return nil, em
+	}
+	reporters, em := (&worlds.Service{Storage: globals.Storage}).CountWorldReporters(tx, reported,
+		time.Now().Add(-globals.AutoHideWindow))
+	if em != nil {
+		return nil, em
+	}
+	hidden, em := autoHide(tx, reported, c, reporters)
+	if em != nil {
 		return nil, em
 	}
 
 	if err := tx.Commit().Error; err != nil {
 		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
 	}
+	if hidden {
+		reindexResource(r.Context(), globals.Server.Db, reported)
+	}
 
 	if _, em := generics.SendReportEmail(name, owner, "worlds", createWorldReport.Reason, r); em != nil {
 		return nil, em
@@ -333,6 +412,15 @@ func doCreateWorld(tx *gorm.DB, cb createWorldFn, w http.ResponseWriter, r *http
 		return nil, em
 	}
 
+	if em := recordActivity(tx, &activity.Activity{Actor: *jwtUser.Username,
+		Verb: activity.VerbCreate, ObjectType: "world", Owner: *world.Owner,
+		Name: *world.Name, ResourceUUID: *world.UUID}, world.Private); em != nil {
+		return nil, em
+	}
+	if em := publishEvent(tx, webhooks.EventCreate, *jwtUser.Username, world, nil); em != nil {
+		return nil, em
+	}
+
 	// commit the DB transaction
 	// Note: we commit the TX here on purpose, to be able to detect DB errors
 	// before writing "data" to ResponseWriter. Once you write data (not headers)
@@ -393,7 +481,7 @@ func WorldCreate(tx *gorm.DB, w http.ResponseWriter, r *http.Request) (interface
 				return nil, em
 			}
 		} else {
-			owner = *jwtUser.Username
+			owner = jwtUser.DefaultOwner()
 		}
 
 		// Get a new UUID and world folder
@@ -464,6 +552,129 @@ func WorldClone(owner, name string, ignored *users.User, tx *gorm.DB,
 	return doCreateWorld(tx, createFn, w, r)
 }
 
+// WorldForks returns the list of worlds that were cloned from a given world.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/worlds/{world-name}/forks
+func WorldForks(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	// Prepare pagination
+	pr, em := gz.NewPaginationRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	ws := &worlds.Service{Storage: globals.Storage}
+	forks, pagination, em := ws.WorldForks(pr, tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+
+	err := gz.WritePaginationHeaders(*pagination, w, r)
+	if err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	return forks, nil
+}
+
+// WorldRestore restores a deleted world, as long as it is still in the owner's trash.
+// An optional new name can be given in case the original name is already taken.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/worlds/{world-name}/restore
+//	  -d '{"name":"optional new name"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WorldRestore(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	rr, em := parseRestoreRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	world, em := (&worlds.Service{Storage: globals.Storage}).RestoreWorld(r.Context(), tx, owner, name, *rr, user)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromRequest(r).Info("World restored: " + owner + "/" + *world.Name)
+	return world, nil
+}
+
+// WorldDeprecate marks a world as deprecated, with an optional message and
+// successor world. The returned value will be of type "fuel.World".
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/worlds/{world-name}/deprecation
+//	  -d '{"message":"optional message", "successor_owner":"optional owner", "successor_name":"optional name"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WorldDeprecate(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	dr, em := parseDeprecateRequest(r)
+	if em != nil {
+		return nil, em
+	}
+
+	s := &worlds.Service{Storage: globals.Storage}
+	world, em := s.DeprecateWorld(r.Context(), tx, owner, name, *dr, user)
+	if em != nil {
+		return nil, em
+	}
+	if em := onDeprecation(tx, *user.Username, world, dr.Message); em != nil {
+		return nil, em
+	}
+	fuelWorld := s.WorldToProto(world)
+	fuelWorld.Successor = s.SuccessorProto(tx, world, user)
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromRequest(r).Info("World deprecated: " + owner + "/" + name)
+	return fuelWorld, nil
+}
+
+// WorldUndeprecate removes the deprecation of a world. The returned value will be
+// of type "fuel.World".
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/worlds/{world-name}/deprecation
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WorldUndeprecate(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	s := &worlds.Service{Storage: globals.Storage}
+	world, em := s.UndeprecateWorld(r.Context(), tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	gz.LoggerFromRequest(r).Info("World undeprecated: " + owner + "/" + name)
+	return s.WorldToProto(world), nil
+}
+
 // WorldUpdate modifies an existing world.
 // You can request this method with the following cURL request:
 //
@@ -515,11 +726,19 @@ func WorldUpdate(owner, worldName string, user *users.User, tx *gorm.DB,
 
 	uw.Metadata = parseWorldMetadata(r)
 
-	world, em := (&worlds.Service{Storage: globals.Storage}).UpdateWorld(r.Context(), tx, owner, worldName,
-		uw.Description, uw.Tags, newFilesPath, uw.Private, user, uw.Metadata)
+	world, em := (&worlds.Service{Storage: globals.Storage}).UpdateWorld(r.Context(), tx, owner, worldName, uw.Name,
+		uw.Description, uw.Tags, newFilesPath, uw.Private, uw.Archived, user, uw.Metadata)
 	if em != nil {
 		return nil, em
 	}
+	if newFilesPath != nil {
+		if em := onNewVersion(r.Context(), tx, *user.Username, "world", world,
+			world.Private); em != nil {
+			return nil, em
+		}
+	} else if em := publishEvent(tx, webhooks.EventUpdate, *user.Username, world, nil); em != nil {
+		return nil, em
+	}
 
 	infoStr := "World has been updated:" +
 		"\n\t name: " + *world.Name +
@@ -598,10 +817,23 @@ func WorldTransfer(sourceOwner, worldName string, user *users.User, tx *gorm.DB,
 		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, em.BaseError, []string{extra})
 	}
 
-	if em := transferMoveResource(tx, world, sourceOwner, transferAsset.DestOwner); em != nil {
+	moved, em := transferMoveResource(tx, world, sourceOwner, transferAsset.DestOwner)
+	if em != nil {
 		return nil, em
 	}
-	tx.Save(&world)
+	if err := tx.Save(world).Error; err != nil {
+		revertTransfers(r, moved)
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		revertTransfers(r, moved)
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
 
 	return &world, nil
 }
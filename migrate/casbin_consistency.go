@@ -0,0 +1,190 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// Types of casbin consistency issues.
+const (
+	// CasbinOrphanedPolicy is a policy on a resource that no longer exists, or a
+	// membership of an organization that was removed.
+	CasbinOrphanedPolicy = "orphaned_policy"
+	// CasbinMissingOwnerGrant is a missing read or write policy of the owner of
+	// a model, world or collection.
+	CasbinMissingOwnerGrant = "missing_owner_grant"
+	// CasbinStaleTeamRole is a team membership of a team that was removed, or
+	// of a user that no longer belongs to the team organization.
+	CasbinStaleTeamRole = "stale_team_role"
+)
+
+// resourceUUIDRegex matches the UUIDs used as casbin resources by models, worlds
+// and collections.
+var resourceUUIDRegex = regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$")
+
+// CasbinIssue is an inconsistency between the casbin policies and the
+// organizations, teams and resources in the database.
+//
+// swagger:model
+type CasbinIssue struct {
+	// The type of issue: orphaned_policy, missing_owner_grant or stale_team_role
+	Type string `json:"type"`
+	// The casbin rule: a (subject, resource, action) policy or a (user, role)
+	// role.
+	Rule []string `json:"rule"`
+	// Human readable description of the issue
+	Description string `json:"description"`
+	// True if the issue was fixed
+	Fixed bool `json:"fixed"`
+}
+
+// CasbinReport is the result of checking the consistency of the casbin
+// policies.
+//
+// swagger:model
+type CasbinReport struct {
+	Issues []CasbinIssue `json:"issues"`
+}
+
+// resourceOwner is the UUID and owner of a model, world or collection.
+type resourceOwner struct {
+	UUID  *string
+	Owner *string
+}
+
+// add appends an issue to the report.
+func (cr *CasbinReport) add(issueType, description string, rule []string, fixed bool) {
+	cr.Issues = append(cr.Issues, CasbinIssue{Type: issueType, Rule: rule,
+		Description: description, Fixed: fixed})
+}
+
+// CheckCasbinConsistency finds drift between the casbin policies and the
+// organizations, teams and resources in the database: orphaned policies,
+// missing owner grants and stale team roles. If fix is true, the issues are
+// also fixed, by removing the orphaned and stale policies and adding the
+// missing grants.
+// Fixes are applied through the given server's enforcer. Other servers keep
+// their policies in memory and must be restarted to load the fixes.
+func CheckCasbinConsistency(ctx context.Context, db *gorm.DB, fix bool) (*CasbinReport, *gz.ErrMsg) {
+	report := CasbinReport{Issues: make([]CasbinIssue, 0)}
+
+	// The live resources, by UUID, with their owner.
+	owners := map[string]string{}
+	for _, model := range []interface{}{&models.Model{}, &worlds.World{}, &collections.Collection{}} {
+		var rows []resourceOwner
+		if err := db.Model(model).Select("uuid, owner").Scan(&rows).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+		}
+		for _, row := range rows {
+			if row.UUID != nil && row.Owner != nil {
+				owners[*row.UUID] = *row.Owner
+			}
+		}
+	}
+
+	// The live and removed organizations, and the live teams.
+	var orgs users.Organizations
+	if err := db.Unscoped().Find(&orgs).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	removedOrgs := map[string]bool{}
+	for _, o := range orgs {
+		removedOrgs[*o.Name] = o.DeletedAt != nil
+	}
+	var teamList []users.Team
+	if err := users.QueryForTeams(db).Find(&teamList).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	teams := map[string]bool{}
+	for _, t := range teamList {
+		if t.Organization.Name != nil && t.Name != nil {
+			teams[users.CasbinNameForTeam(*t.Organization.Name, *t.Name)] = true
+		}
+	}
+
+	set := globals.Permissions.ExportPolicies()
+
+	// Orphaned resource policies
+	granted := map[string]bool{}
+	for _, rule := range set.Policies {
+		sub, res, act := rule[permissions.PolicyUser], rule[permissions.PolicyResource],
+			rule[permissions.PolicyAction]
+		if !resourceUUIDRegex.MatchString(res) {
+			continue
+		}
+		if _, ok := owners[res]; ok {
+			granted[sub+" "+res+" "+act] = true
+			continue
+		}
+		fixed := false
+		if fix {
+			fixed, _ = globals.Permissions.RemovePolicyRule(rule)
+		}
+		report.add(CasbinOrphanedPolicy, fmt.Sprintf("Resource [%s] does not exist", res), rule, fixed)
+	}
+
+	// Missing owner grants
+	for uuid, owner := range owners {
+		for _, action := range []permissions.Action{permissions.Read, permissions.Write} {
+			if granted[owner+" "+uuid+" "+action.String()] {
+				continue
+			}
+			fixed := false
+			if fix {
+				_, err := globals.Permissions.AddPermission(owner, uuid, action)
+				fixed = err == nil
+			}
+			report.add(CasbinMissingOwnerGrant, fmt.Sprintf("Owner [%s] cannot [%s] resource [%s]",
+				owner, action.String(), uuid), []string{owner, uuid, action.String()}, fixed)
+		}
+	}
+
+	// Orphaned organization memberships and stale team roles
+	for _, rule := range set.Roles {
+		user, group := rule[0], rule[1]
+		// Roles in a group have the form 'group_#role'.
+		if i := strings.Index(group, "_#"); i >= 0 {
+			group = group[:i]
+		}
+
+		var issueType, description string
+		if removed, ok := removedOrgs[group]; ok && removed {
+			issueType = CasbinOrphanedPolicy
+			description = fmt.Sprintf("Organization [%s] was removed", group)
+		} else if i := strings.Index(group, "_t_"); i > 0 {
+			if _, ok := removedOrgs[group[:i]]; !ok {
+				continue
+			}
+			if !teams[group] {
+				issueType = CasbinStaleTeamRole
+				description = fmt.Sprintf("Team [%s] does not exist", group)
+			} else if !globals.Permissions.UserBelongsToGroup(user, group[:i]) {
+				issueType = CasbinStaleTeamRole
+				description = fmt.Sprintf("User [%s] does not belong to organization [%s]", user, group[:i])
+			}
+		}
+		if issueType == "" {
+			continue
+		}
+		fixed := false
+		if fix {
+			fixed, _ = globals.Permissions.RemoveRoleRule(rule)
+		}
+		report.add(issueType, description, rule, fixed)
+	}
+
+	gz.LoggerFromContext(ctx).Info(fmt.Sprintf("Casbin consistency check found %d issues",
+		len(report.Issues)))
+	return &report, nil
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	"github.com/gazebo-web/fuel-server/bundles/comments"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/generics"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/moderation"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// moderationActions describes the outcome of each moderation action, in the
+// notifications sent to the owners.
+var moderationActions = map[string]string{
+	moderation.ActionHide:         "it was hidden",
+	moderation.ActionMakePrivate:  "it was made private",
+	moderation.ActionSuspendOwner: "your account was suspended",
+}
+
+// resourceSubject returns the moderation subject of a model, world or
+// collection.
+func resourceSubject(res commonres.Resource, id uint) moderation.Subject {
+	return moderation.Subject{
+		ResourceType: resourceTypeOf(res),
+		ResourceID:   id,
+		Owner:        *res.GetOwner(),
+		Name:         *res.GetName(),
+	}
+}
+
+// caseDescription describes the resource of a moderation case, for the
+// notifications.
+func caseDescription(c *moderation.Case) string {
+	switch c.ResourceType {
+	case moderation.TypeUser:
+		return "the user " + c.Owner
+	case moderation.TypeComment:
+		return fmt.Sprintf("a comment by %s on %s", c.Owner, c.Name)
+	}
+	return fmt.Sprintf("the %s %s/%s", c.ResourceType, c.Owner, c.Name)
+}
+
+// fileReport adds a report to the moderation queue, and returns its case.
+// Reports without a user are anonymous.
+func fileReport(tx *gorm.DB, s moderation.Subject, user *users.User,
+	reason string) (*moderation.Case, *gz.ErrMsg) {
+
+	reporter := ""
+	if user != nil {
+		reporter = *user.Username
+	}
+	return moderation.File(tx, s, reporter, reason)
+}
+
+// isHidden returns true if the given model, world or collection was hidden by
+// the moderators.
+func isHidden(res commonres.Resource) bool {
+	switch v := res.(type) {
+	case *models.Model:
+		return v.Hidden
+	case *worlds.World:
+		return v.Hidden
+	case *collections.Collection:
+		return v.Hidden
+	}
+	return false
+}
+
+// autoHide hides a public model or world from lists and search results,
+// pending moderator review, once it was reported by globals.AutoHideReporters
+// distinct users within globals.AutoHideWindow. The reporters argument is the
+// number of distinct users that reported the resource within the window. The
+// owner, or the owners and admins of an owning organization, are notified. It returns true if the resource was hidden, in which case
+// the caller must reindex it with reindexResource once the transaction is
+// committed.
+func autoHide(tx *gorm.DB, res commonres.Resource, c *moderation.Case,
+	reporters int) (bool, *gz.ErrMsg) {
+
+	if globals.AutoHideReporters <= 0 || reporters < globals.AutoHideReporters ||
+		c.AutoHidden || isPrivateResource(res) || isHidden(res) {
+		return false, nil
+	}
+	if em := updateResourceColumn(tx, res, "hidden", true); em != nil {
+		return false, em
+	}
+	if em := moderation.SetAutoHidden(tx, c); em != nil {
+		return false, em
+	}
+	for _, username := range ownerRecipients(tx, c.Owner) {
+		n := notifications.Notification{
+			Username:     username,
+			Event:        notifications.EventModeration,
+			ResourceType: c.ResourceType,
+			Owner:        c.Owner,
+			Name:         c.Name,
+			Message: fmt.Sprintf("The %s %s/%s was hidden from lists and search results after being "+
+				"reported by several users, pending moderator review.", c.ResourceType, c.Owner, c.Name),
+		}
+		if em := notifications.Create(tx, &n); em != nil {
+			return false, em
+		}
+	}
+	return true, nil
+}
+
+// ownerRecipients returns the users notified about the moderation of the
+// resources of an owner: the owner itself if it is a user, or the owners and
+// admins of an organization.
+func ownerRecipients(tx *gorm.DB, owner string) []string {
+	if _, em := users.ByUsername(tx, owner, false); em == nil {
+		return []string{owner}
+	}
+	var recipients []string
+	for _, username := range globals.Permissions.GetUsersForGroup(owner) {
+		if ok, _ := globals.Permissions.IsAuthorizedForRole(username, owner, permissions.Admin); ok {
+			recipients = append(recipients, username)
+		}
+	}
+	return recipients
+}
+
+// readReport parses the form of a report request.
+func readReport(r *http.Request) (*moderation.CreateReport, *gz.ErrMsg) {
+	// Parse form's values
+	if err := r.ParseMultipartForm(0); err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorForm, err)
+	}
+
+	// Delete temporary files from r.ParseMultipartForm(0)
+	defer func(form *multipart.Form) {
+		if err := form.RemoveAll(); err != nil {
+			log.Println("Failed to close form:", err)
+		}
+	}(r.MultipartForm)
+
+	var cr moderation.CreateReport
+	if em := ParseStruct(&cr, r, true); em != nil {
+		return nil, em
+	}
+	return &cr, nil
+}
+
+// ReportCollectionCreate reports a collection.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/collections/{collection}/report
+//	  -F reason="Spam"
+func ReportCollectionCreate(owner, name string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	cr, em := readReport(r)
+	if em != nil {
+		return nil, em
+	}
+	col, em := (&collections.Service{}).GetCollection(tx, owner, name, user)
+	if em != nil {
+		return nil, em
+	}
+	// The reporter is not disclosed to the owner.
+	if em := publishEvent(tx, webhooks.EventReport, "", col,
+		map[string]interface{}{"reason": cr.Reason}); em != nil {
+		return nil, em
+	}
+	if _, em := fileReport(tx, resourceSubject(col, col.ID), user, cr.Reason); em != nil {
+		return nil, em
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	if _, em := generics.SendReportEmail(name, owner, "collections", cr.Reason, r); em != nil {
+		return nil, em
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return nil, nil
+}
+
+// ReportUserCreate reports a user.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/users/{username}/report
+//	  -F reason="Impersonation"
+func ReportUserCreate(username string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	cr, em := readReport(r)
+	if em != nil {
+		return nil, em
+	}
+	reported, em := users.ByUsername(tx, username, false)
+	if em != nil {
+		return nil, em
+	}
+	s := moderation.Subject{
+		ResourceType: moderation.TypeUser,
+		ResourceID:   reported.ID,
+		Owner:        username,
+		Name:         username,
+	}
+	if _, em := fileReport(tx, s, user, cr.Reason); em != nil {
+		return nil, em
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return nil, nil
+}
+
+// CommentReport returns a handler that reports a comment of a model or world.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model}/comments/{id}/report
+//	  -F reason="Offensive"
+func CommentReport(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		cr, em := readReport(r)
+		if em != nil {
+			return nil, em
+		}
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		id, em := getRouteID(r)
+		if em != nil {
+			return nil, em
+		}
+		c, em := comments.Get(tx, *res.GetUUID(), id)
+		if em != nil {
+			return nil, em
+		}
+		if c.Deleted {
+			return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+		}
+		s := moderation.Subject{
+			ResourceType: moderation.TypeComment,
+			ResourceID:   c.ID,
+			Owner:        c.Author,
+			Name:         fmt.Sprintf("%s/%ss/%s", owner, resType, name),
+		}
+		if _, em := fileReport(tx, s, user, cr.Reason); em != nil {
+			return nil, em
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return nil, nil
+	}
+}
+
+// ModerationCaseList returns the moderation queue: a paginated list of the
+// cases with the given status, open by default. The most reported cases come
+// first. Use the type query parameter to get the cases of a resource type.
+// Only system admins can moderate.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/admin/moderation/cases?status=open&type=model
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ModerationCaseList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = moderation.StatusOpen
+	case moderation.StatusOpen, moderation.StatusDismissed, moderation.StatusActioned:
+	default:
+		return nil, nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"status"})
+	}
+	return moderation.List(p, tx, status, r.URL.Query().Get("type"))
+}
+
+// ModerationCaseIndex returns a moderation case with its reports. Only system
+// admins can moderate.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/admin/moderation/cases/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ModerationCaseIndex(_ string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	id, em := getRouteID(r)
+	if em != nil {
+		return nil, em
+	}
+	return moderation.Get(tx, id)
+}
+
+// ModerationCaseUpdate resolves a moderation case, or changes its notes. Cases
+// are either dismissed, or actioned with one of these actions: hide the
+// resource or comment, make the resource private, or suspend the owner.
+// Resources hidden automatically are shown again, unless the moderator hides
+// them. The reporters and, for actioned cases, the owner are notified. Only
+// system admins can moderate.
+// You can request this method with the following curl request:
+//
+//	curl -k -X PATCH --url https://localhost:4430/1.0/admin/moderation/cases/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	  -d '{"status":"actioned", "action":"hide", "notes":"Spam"}'
+func ModerationCaseUpdate(_ string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	id, em := getRouteID(r)
+	if em != nil {
+		return nil, em
+	}
+	c, em := moderation.Get(tx, id)
+	if em != nil {
+		return nil, em
+	}
+	var uc moderation.UpdateCase
+	if em := ParseStruct(&uc, r, false); em != nil {
+		return nil, em
+	}
+	if uc.Status != nil && c.Status != moderation.StatusOpen {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"status"})
+	}
+	// Only actioned cases have an action, and they require one.
+	actioned := uc.Status != nil && *uc.Status == moderation.StatusActioned
+	if actioned != (uc.Action != "") {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"action"})
+	}
+
+	if uc.Notes != nil {
+		if em := moderation.SetNotes(tx, c, *uc.Notes); em != nil {
+			return nil, em
+		}
+	}
+	// The resource to reindex once the transaction is committed, if any
+	var changed commonres.Resource
+	if uc.Status != nil {
+		if actioned {
+			res, em := applyModerationAction(tx, c, uc.Action, *user.Username)
+			if em != nil {
+				return nil, em
+			}
+			changed = res
+		}
+		if c.AutoHidden && uc.Action != moderation.ActionHide {
+			res, em := moderatedResource(tx, c)
+			if em != nil {
+				return nil, em
+			}
+			if em := updateResourceColumn(tx, res, "hidden", false); em != nil {
+				return nil, em
+			}
+			changed = res
+		}
+		if em := moderation.Resolve(tx, c, *user.Username, *uc.Status, uc.Action); em != nil {
+			return nil, em
+		}
+		if em := notifyModeration(tx, c); em != nil {
+			return nil, em
+		}
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	if changed != nil {
+		reindexResource(r.Context(), globals.Server.Db, changed)
+	} else if uc.Action == moderation.ActionSuspendOwner {
+		// The resources of the suspended owner are no longer listed.
+		if err := globals.QueryCache.DeleteAll(); err != nil {
+			gz.LoggerFromContext(r.Context()).Error("Failed to clear the memory cache.")
+		}
+	}
+	return c, nil
+}
+
+// moderatedResource returns the model, world or collection of a moderation
+// case.
+func moderatedResource(tx *gorm.DB, c *moderation.Case) (commonres.Resource, *gz.ErrMsg) {
+	var res commonres.Resource
+	switch c.ResourceType {
+	case moderation.TypeModel:
+		res = &models.Model{}
+	case moderation.TypeWorld:
+		res = &worlds.World{}
+	case moderation.TypeCollection:
+		res = &collections.Collection{}
+	default:
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"action"})
+	}
+	if q := tx.First(res, c.ResourceID); q.Error != nil {
+		if q.RecordNotFound() {
+			return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+		}
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, q.Error)
+	}
+	return res, nil
+}
+
+// applyModerationAction takes a moderation action on the resource of a case.
+// Models, worlds and collections can be hidden or made private, comments can
+// be hidden, and the owners of all of them can be suspended. It returns the
+// changed model, world or collection, to be reindexed once the transaction is
+// committed, or nil.
+func applyModerationAction(tx *gorm.DB, c *moderation.Case,
+	action, moderator string) (commonres.Resource, *gz.ErrMsg) {
+
+	if action == moderation.ActionSuspendOwner {
+		owner, em := users.ByUsername(tx, c.Owner, false)
+		if em != nil {
+			// Organizations cannot be suspended.
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"action"})
+		}
+		reason := fmt.Sprintf("Moderation case %d", c.ID)
+		if c.Notes != "" {
+			reason += ": " + c.Notes
+		}
+		_, em = users.Suspend(tx, owner, moderator, reason, nil)
+		return nil, em
+	}
+
+	if c.ResourceType == moderation.TypeComment {
+		if action != moderation.ActionHide {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"action"})
+		}
+		var comment comments.Comment
+		if err := tx.First(&comment, c.ResourceID).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorNonExistentResource, err)
+		}
+		return nil, comments.SetHidden(tx, &comment, moderator, true)
+	}
+
+	res, em := moderatedResource(tx, c)
+	if em != nil {
+		return nil, em
+	}
+	column := "hidden"
+	if action == moderation.ActionMakePrivate {
+		column = "private"
+	}
+	if em := updateResourceColumn(tx, res, column, true); em != nil {
+		return nil, em
+	}
+	return res, nil
+}
+
+// updateResourceColumn sets a boolean column of a model, world or collection.
+// The search index is not updated: call reindexResource once the transaction
+// is committed.
+func updateResourceColumn(tx *gorm.DB, res commonres.Resource,
+	column string, value bool) *gz.ErrMsg {
+
+	if err := tx.Model(res).UpdateColumn(column, value).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return nil
+}
+
+// notifyModeration notifies the reporters of a resolved case of its outcome.
+// For actioned cases, the owner, or the owners and admins of an owning
+// organization, are also notified of the action taken.
+func notifyModeration(tx *gorm.DB, c *moderation.Case) *gz.ErrMsg {
+	reporters, em := moderation.Reporters(tx, c)
+	if em != nil {
+		return em
+	}
+	outcome := "No action was taken."
+	if c.Status == moderation.StatusActioned {
+		outcome = "Action was taken."
+	}
+	newNotification := func(username, message string) notifications.Notification {
+		return notifications.Notification{
+			Username:     username,
+			Event:        notifications.EventModeration,
+			ResourceType: c.ResourceType,
+			Owner:        c.Owner,
+			Name:         c.Name,
+			Message:      message,
+		}
+	}
+	for _, reporter := range reporters {
+		n := newNotification(reporter, fmt.Sprintf("Your report about %s was reviewed. %s",
+			caseDescription(c), outcome))
+		if em := notifications.Create(tx, &n); em != nil {
+			return em
+		}
+	}
+	if c.Status != moderation.StatusActioned {
+		return nil
+	}
+	for _, username := range ownerRecipients(tx, c.Owner) {
+		n := newNotification(username, fmt.Sprintf("A moderator reviewed the reports about %s, and %s.",
+			caseDescription(c), moderationActions[c.Action]))
+		if em := notifications.Create(tx, &n); em != nil {
+			return em
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/moderation"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/proto"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"os"
+	"testing"
+)
+
+// TestModelModeration tests reporting models and users, and resolving the
+// moderation cases.
+func TestModelModeration(t *testing.T) {
+	// General test setup.
+	setup()
+	jwt := os.Getenv("IGN_TEST_JWT")
+	admin := createSysAdminUser(t)
+	defer removeUser(admin, t)
+	ownerJWT := createValidJWTForIdentity("another-user", t)
+	owner := createUserWithJWT(ownerJWT, t)
+	defer removeUserWithJWT(owner, ownerJWT, t)
+	// Reinstate the owner, so it can be removed.
+	defer globals.Server.Db.Model(&users.User{}).Where("username = ?", owner).UpdateColumn("suspended_at", nil)
+	reporterJWT := createValidJWTForIdentity("another-user-3", t)
+	reporter := createUserWithJWT(reporterJWT, t)
+	defer removeUserWithJWT(reporter, reporterJWT, t)
+	createThreeTestModels(t, &ownerJWT)
+
+	// Disable mail support and restore it in defer
+	from := globals.FlagsEmailSender
+	defer func() { globals.FlagsEmailSender = from }()
+	globals.FlagsEmailSender = ""
+
+	// Reports against the same model are grouped in a case
+	body := map[string]string{"reason": "spam"}
+	reportURI := modelURL(owner, "model1", "") + "/report"
+	gztest.SendMultipartPOST(t.Name(), t, reportURI, &reporterJWT, body, nil)
+	gztest.SendMultipartPOST(t.Name(), t, reportURI, nil, body, nil)
+
+	// Only system admins can moderate
+	casesURI := "/1.0/admin/moderation/cases"
+	unauth := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	gztest.AssertRouteMultipleArgs("GET", casesURI, nil, unauth.StatusCode, &reporterJWT, ctTextPlain, t)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", casesURI, nil, http.StatusOK, &jwt, ctJSON, t)
+	var cases moderation.Cases
+	require.NoError(t, json.Unmarshal(*bslice, &cases))
+	require.Len(t, cases, 1)
+	assert.Equal(t, "model", cases[0].ResourceType)
+	assert.Equal(t, owner, cases[0].Owner)
+	assert.Equal(t, "model1", cases[0].Name)
+	assert.Equal(t, 2, cases[0].ReportCount)
+
+	caseURI := fmt.Sprintf("%s/%d", casesURI, cases[0].ID)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", caseURI, nil, http.StatusOK, &jwt, ctJSON, t)
+	var c moderation.Case
+	require.NoError(t, json.Unmarshal(*bslice, &c))
+	require.Len(t, c.Reports, 2)
+	assert.Equal(t, reporter, c.Reports[0].Reporter)
+	assert.Empty(t, c.Reports[1].Reporter)
+
+	resolve := func(uri string, uc moderation.UpdateCase, status int) {
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(uc))
+		ct := ctJSON
+		if status != http.StatusOK {
+			ct = ctTextPlain
+		}
+		gztest.AssertRouteMultipleArgs("PATCH", uri, b, status, &jwt, ct, t)
+	}
+	actioned := moderation.StatusActioned
+	invalid := gz.NewErrorMessage(gz.ErrorFormInvalidValue)
+	// Actioned cases require an action
+	resolve(caseURI, moderation.UpdateCase{Status: &actioned}, invalid.StatusCode)
+	// Unknown actions are rejected
+	resolve(caseURI, moderation.UpdateCase{Status: &actioned, Action: "delete"}, invalid.StatusCode)
+	notes := "Spam links in the description"
+	resolve(caseURI, moderation.UpdateCase{Status: &actioned, Action: moderation.ActionHide, Notes: &notes}, http.StatusOK)
+	// Resolved cases cannot be resolved again
+	resolve(caseURI, moderation.UpdateCase{Status: &actioned, Action: moderation.ActionHide}, invalid.StatusCode)
+
+	// The hidden model is only listed to its owner
+	listURI := fmt.Sprintf("/1.0/%s/models", owner)
+	var list []*fuel.Model
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", listURI, nil, http.StatusOK, nil, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	assert.Len(t, list, 2)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", listURI, nil, http.StatusOK, &ownerJWT, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	assert.Len(t, list, 3)
+
+	// The list cache does not serve the hidden model to anonymous users after
+	// its owner requested the list of all models
+	globalList := func(jwt *string) []string {
+		var all []*fuel.Model
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", "/1.0/models", nil, http.StatusOK, jwt, ctJSON, t)
+		require.NoError(t, json.Unmarshal(*bslice, &all))
+		var names []string
+		for _, m := range all {
+			if m.GetOwner() == owner {
+				names = append(names, m.GetName())
+			}
+		}
+		return names
+	}
+	assert.Contains(t, globalList(&ownerJWT), "model1")
+	assert.NotContains(t, globalList(nil), "model1")
+
+	// The reporter and the owner are notified
+	for _, u := range []struct{ username, jwt string }{{reporter, reporterJWT}, {owner, ownerJWT}} {
+		bslice, _ = gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/users/%s/notifications", u.username),
+			nil, http.StatusOK, &u.jwt, ctJSON, t)
+		var ns notifications.Notifications
+		require.NoError(t, json.Unmarshal(*bslice, &ns))
+		require.Len(t, ns, 1)
+		assert.Equal(t, notifications.EventModeration, ns[0].Event)
+	}
+
+	// Report the owner and suspend it
+	gztest.SendMultipartPOST(t.Name(), t, fmt.Sprintf("/1.0/users/%s/report", owner), &reporterJWT, body, nil)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", casesURI+"?type=user", nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &cases))
+	require.Len(t, cases, 1)
+	caseURI = fmt.Sprintf("%s/%d", casesURI, cases[0].ID)
+	// Users cannot be hidden
+	resolve(caseURI, moderation.UpdateCase{Status: &actioned, Action: moderation.ActionHide}, invalid.StatusCode)
+	resolve(caseURI, moderation.UpdateCase{Status: &actioned, Action: moderation.ActionSuspendOwner}, http.StatusOK)
+	gztest.AssertRouteMultipleArgs("GET", "/1.0/users/"+owner, nil, unauth.StatusCode, &ownerJWT, ctTextPlain, t)
+
+	// Resolved cases leave the queue
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", casesURI, nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &cases))
+	assert.Len(t, cases, 0)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", casesURI+"?status=actioned", nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &cases))
+	assert.Len(t, cases, 2)
+}
+
+// TestModelAutoHide tests hiding a model automatically after being reported by
+// several users.
+func TestModelAutoHide(t *testing.T) {
+	// General test setup.
+	setup()
+	jwt := os.Getenv("IGN_TEST_JWT")
+	admin := createSysAdminUser(t)
+	defer removeUser(admin, t)
+	ownerJWT := createValidJWTForIdentity("another-user", t)
+	owner := createUserWithJWT(ownerJWT, t)
+	defer removeUserWithJWT(owner, ownerJWT, t)
+	jwt2 := createValidJWTForIdentity("another-user-2", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+	jwt3 := createValidJWTForIdentity("another-user-3", t)
+	user3 := createUserWithJWT(jwt3, t)
+	defer removeUserWithJWT(user3, jwt3, t)
+	createThreeTestModels(t, &ownerJWT)
+
+	// Disable mail support and restore it in defer
+	from := globals.FlagsEmailSender
+	defer func() { globals.FlagsEmailSender = from }()
+	globals.FlagsEmailSender = ""
+	reporters := globals.AutoHideReporters
+	defer func() { globals.AutoHideReporters = reporters }()
+	globals.AutoHideReporters = 2
+
+	listURI := fmt.Sprintf("/1.0/%s/models", owner)
+	countModels := func() int {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", listURI, nil, http.StatusOK, nil, ctJSON, t)
+		var list []*fuel.Model
+		require.NoError(t, json.Unmarshal(*bslice, &list))
+		return len(list)
+	}
+
+	// Repeated and anonymous reports are not counted
+	body := map[string]string{"reason": "spam"}
+	reportURI := modelURL(owner, "model1", "") + "/report"
+	gztest.SendMultipartPOST(t.Name(), t, reportURI, &jwt2, body, nil)
+	gztest.SendMultipartPOST(t.Name(), t, reportURI, &jwt2, body, nil)
+	gztest.SendMultipartPOST(t.Name(), t, reportURI, nil, body, nil)
+	assert.Equal(t, 3, countModels())
+
+	// A second reporter hides the model, pending review
+	gztest.SendMultipartPOST(t.Name(), t, reportURI, &jwt3, body, nil)
+	assert.Equal(t, 2, countModels())
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/users/%s/notifications", owner),
+		nil, http.StatusOK, &ownerJWT, ctJSON, t)
+	var ns notifications.Notifications
+	require.NoError(t, json.Unmarshal(*bslice, &ns))
+	require.Len(t, ns, 1)
+	assert.Equal(t, notifications.EventModeration, ns[0].Event)
+
+	// Dismissing the case shows the model again
+	casesURI := "/1.0/admin/moderation/cases"
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", casesURI, nil, http.StatusOK, &jwt, ctJSON, t)
+	var cases moderation.Cases
+	require.NoError(t, json.Unmarshal(*bslice, &cases))
+	require.Len(t, cases, 1)
+	assert.True(t, cases[0].AutoHidden)
+	assert.Equal(t, 4, cases[0].ReportCount)
+	b := new(bytes.Buffer)
+	dismissed := moderation.StatusDismissed
+	require.NoError(t, json.NewEncoder(b).Encode(moderation.UpdateCase{Status: &dismissed}))
+	gztest.AssertRouteMultipleArgs("PATCH", fmt.Sprintf("%s/%d", casesURI, cases[0].ID), b, http.StatusOK, &jwt, ctJSON, t)
+	assert.Equal(t, 3, countModels())
+
+	// The owners and admins of an organization are notified when its models
+	// are hidden
+	org := createOrganization(t)
+	defer removeOrganization(org, t)
+	addUserToOrg(owner, "admin", org, t)
+	addUserToOrg(user2, "member", org, t)
+	createTestModelWithOwner(t, &jwt, "org_model", org, false)
+	countNotifications := func(username, jwt string) int {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/users/%s/notifications", username),
+			nil, http.StatusOK, &jwt, ctJSON, t)
+		var ns notifications.Notifications
+		require.NoError(t, json.Unmarshal(*bslice, &ns))
+		return len(ns)
+	}
+	ownerCount := countNotifications(owner, ownerJWT)
+	memberCount := countNotifications(user2, jwt2)
+	orgReportURI := modelURL(org, "org_model", "") + "/report"
+	gztest.SendMultipartPOST(t.Name(), t, orgReportURI, &jwt2, body, nil)
+	gztest.SendMultipartPOST(t.Name(), t, orgReportURI, &jwt3, body, nil)
+	assert.Equal(t, ownerCount+1, countNotifications(owner, ownerJWT))
+	assert.Equal(t, memberCount, countNotifications(user2, jwt2))
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/generics"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// notificationEmailInterval is how often the pending notification emails are
+// sent.
+const notificationEmailInterval = time.Minute
+
+// notificationEmailBatch is the maximum number of notification emails sent on
+// each run of the email job.
+const notificationEmailBatch = 100
+
+// resourceTypeOf returns the type of a resource: model, world or collection.
+func resourceTypeOf(res commonres.Resource) string {
+	switch res.(type) {
+	case *models.Model:
+		return "model"
+	case *worlds.World:
+		return "world"
+	case *collections.Collection:
+		return "collection"
+	}
+	return ""
+}
+
+// notifyWatchers creates a notification for each user watching a resource,
+// except the actor. Watchers that can no longer read a private resource are not
+// notified.
+func notifyWatchers(tx *gorm.DB, event, actor string, res commonres.Resource, message string) *gz.ErrMsg {
+	watchers, em := notifications.Watchers(tx, *res.GetUUID())
+	if em != nil {
+		return em
+	}
+	private := isPrivateResource(res)
+	for _, username := range watchers {
+		if username == actor {
+			continue
+		}
+		if private {
+			if ok, _ := globals.Permissions.IsAuthorized(username, *res.GetUUID(), permissions.Read); !ok {
+				continue
+			}
+		}
+		n := notifications.Notification{
+			Username:     username,
+			Event:        event,
+			Actor:        actor,
+			ResourceType: resourceTypeOf(res),
+			Owner:        *res.GetOwner(),
+			Name:         *res.GetName(),
+			Message:      message,
+		}
+		if em := notifications.Create(tx, &n); em != nil {
+			return em
+		}
+	}
+	return nil
+}
+
+// onNewVersion records the activity, notifies the watchers and fires the
+// webhooks of a model or world when a new version is uploaded.
+func onNewVersion(ctx context.Context, tx *gorm.DB, actor, resType string,
+	res commonres.Resource, private *bool) *gz.ErrMsg {
+
+	version, err := commonres.GetLatestVersion(ctx, res)
+	if err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	if em := recordVersionActivity(tx, actor, resType, res, version, private); em != nil {
+		return em
+	}
+	if em := publishEvent(tx, webhooks.EventUpdate, actor, res,
+		map[string]interface{}{"version": version}); em != nil {
+		return em
+	}
+	message := fmt.Sprintf("Version %d of the %s %s/%s was uploaded by %s.", version, resType,
+		*res.GetOwner(), *res.GetName(), actor)
+	return notifyWatchers(tx, notifications.EventVersion, actor, res, message)
+}
+
+// onDeprecation notifies the watchers of a model or world that it was
+// deprecated.
+func onDeprecation(tx *gorm.DB, actor string, res commonres.Resource, deprecationMessage string) *gz.ErrMsg {
+	message := fmt.Sprintf("The %s %s/%s was deprecated by %s.", resourceTypeOf(res),
+		*res.GetOwner(), *res.GetName(), actor)
+	if deprecationMessage != "" {
+		message += " " + deprecationMessage
+	}
+	return notifyWatchers(tx, notifications.EventDeprecation, actor, res, message)
+}
+
+// onCollectionChange notifies the watchers of a collection that it changed.
+func onCollectionChange(tx *gorm.DB, actor string, col *collections.Collection, change string) *gz.ErrMsg {
+	message := fmt.Sprintf("The collection %s/%s was changed by %s: %s.", *col.Owner, *col.Name,
+		actor, change)
+	return notifyWatchers(tx, notifications.EventCollection, actor, col, message)
+}
+
+// watchHandler returns a handler that makes the JWT user watch (or stop
+// watching) a model, world or collection.
+func watchHandler(resType string, watch bool) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		if watch {
+			_, em = notifications.AddWatch(tx, *user.Username, resType, *res.GetUUID())
+		} else {
+			em = notifications.RemoveWatch(tx, *user.Username, *res.GetUUID())
+		}
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return nil, nil
+	}
+}
+
+// WatchCreate returns a handler that makes a user watch a model, world or
+// collection. Watchers are notified of new versions, transfers, deprecations
+// and collection changes.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model}/watch
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WatchCreate(resType string) nameAndOwner {
+	return watchHandler(resType, true)
+}
+
+// WatchRemove returns a handler that makes a user stop watching a model, world
+// or collection.
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/models/{model}/watch
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WatchRemove(resType string) nameAndOwner {
+	return watchHandler(resType, false)
+}
+
+// checkSelf returns an error if the username route parameter is not the JWT
+// user.
+func checkSelf(r *http.Request, user *users.User) *gz.ErrMsg {
+	if mux.Vars(r)["username"] != *user.Username {
+		return gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	return nil
+}
+
+// UserWatchesList returns a paginated list of the resources watched by a user.
+// Users can only see their own watches.
+func UserWatchesList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	if em := checkSelf(r, user); em != nil {
+		return nil, nil, em
+	}
+	watches, pagination, em := notifications.UserWatches(p, tx, *user.Username)
+	if em != nil {
+		return nil, nil, em
+	}
+	responses := make(notifications.WatchResponses, 0, len(watches))
+	for _, watch := range watches {
+		res, em := getResourceByUUID(tx, watch.ResourceType, watch.ResourceUUID)
+		if em != nil {
+			// The resource was removed
+			continue
+		}
+		responses = append(responses, notifications.WatchResponse{
+			ResourceType: watch.ResourceType,
+			Owner:        *res.GetOwner(),
+			Name:         *res.GetName(),
+			CreatedAt:    watch.CreatedAt,
+		})
+	}
+	return &responses, pagination, nil
+}
+
+// NotificationsList returns a paginated list of the notifications of a user,
+// newest first. Use the unread=true query parameter to only get the unread
+// notifications.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/users/{username}/notifications?unread=true
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func NotificationsList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	if em := checkSelf(r, user); em != nil {
+		return nil, nil, em
+	}
+	unread := readBoolParam(r, "unread")
+	return notifications.List(p, tx, *user.Username, unread != nil && *unread)
+}
+
+// markNotifications marks one notification, or all of them if all is true, as
+// read or unread.
+func markNotifications(all bool) nameFn {
+	return func(username string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		if username != *user.Username {
+			return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+		}
+		read := true
+		var id *uint
+		if !all {
+			routeID, em := getRouteID(r)
+			if em != nil {
+				return nil, em
+			}
+			id = &routeID
+			var un notifications.UpdateNotification
+			if em := ParseStruct(&un, r, false); em != nil {
+				return nil, em
+			}
+			read = *un.Read
+		}
+		if em := notifications.MarkRead(tx, username, id, read); em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return nil, nil
+	}
+}
+
+// NotificationUpdate marks a notification as read or unread.
+// You can request this method with the following curl request:
+//
+//	curl -k -X PATCH --url https://localhost:4430/1.0/users/{username}/notifications/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>' -d '{"read":true}'
+var NotificationUpdate = markNotifications(false)
+
+// NotificationsReadAll marks all the notifications of a user as read.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/users/{username}/notifications/read-all
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+var NotificationsReadAll = markNotifications(true)
+
+// NotificationPreferencesIndex returns the notification preferences of a user.
+func NotificationPreferencesIndex(username string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if username != *user.Username {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	return notifications.GetPreferences(tx, username)
+}
+
+// NotificationPreferencesUpdate changes which notifications are emailed to a
+// user.
+// You can request this method with the following curl request:
+//
+//	curl -k -X PATCH --url https://localhost:4430/1.0/users/{username}/notification-preferences
+//	  --header 'authorization: Bearer <your-jwt-token-here>' -d '{"email_versions":true}'
+func NotificationPreferencesUpdate(username string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if username != *user.Username {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	var up notifications.UpdatePreferences
+	if em := ParseStruct(&up, r, false); em != nil {
+		return nil, em
+	}
+	prefs, em := notifications.SetPreferences(tx, username, up)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return prefs, nil
+}
+
+// sendNotificationEmails emails the notifications that the recipients asked to
+// receive by email.
+func sendNotificationEmails(ctx context.Context, db *gorm.DB) *gz.ErrMsg {
+	pending, em := notifications.PendingEmails(db, notificationEmailBatch)
+	if em != nil {
+		return em
+	}
+	for i := range pending {
+		n := &pending[i]
+		subject := fmt.Sprintf("The %s %s/%s has changed", n.ResourceType, n.Owner, n.Name)
+		if user, em := users.ByUsername(db, n.Username, false); em == nil && user.Email != nil {
+			if em := generics.SendNotificationEmail(*user.Email, n.Username, subject, n.Message); em != nil {
+				gz.LoggerFromContext(ctx).Error("Unable to send notification email: ", em.LogString())
+			}
+		}
+		// The email is not retried, to avoid flooding the recipient.
+		if em := notifications.EmailSent(db, n); em != nil {
+			return em
+		}
+	}
+	return nil
+}
+
+// runNotificationEmailJob periodically sends the pending notification emails,
+// until the given context is done. It is expected to be run in its own
+// goroutine.
+func runNotificationEmailJob(ctx context.Context, db *gorm.DB) {
+	ticker := time.NewTicker(notificationEmailInterval)
+	defer ticker.Stop()
+	for {
+		if em := sendNotificationEmails(ctx, db); em != nil {
+			gz.LoggerFromContext(ctx).Error("Failed to send notification emails: ", em.LogString())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"os"
+	"testing"
+)
+
+// TestModelWatchNotifications tests watching a model and getting notified of
+// its changes.
+func TestModelWatchNotifications(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+	createThreeTestModels(t, &jwt)
+
+	watchURI := modelURL(testUser, "model1", "") + "/watch"
+	gztest.AssertRouteMultipleArgs("POST", watchURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+	// Watching twice is not an error
+	gztest.AssertRouteMultipleArgs("POST", watchURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/users/%s/watches", user2), nil, http.StatusOK, &jwt2, ctJSON, t)
+	var watches notifications.WatchResponses
+	require.NoError(t, json.Unmarshal(*bslice, &watches))
+	require.Len(t, watches, 1)
+	assert.Equal(t, "model", watches[0].ResourceType)
+	assert.Equal(t, "model1", watches[0].Name)
+
+	notificationsURI := fmt.Sprintf("/1.0/users/%s/notifications", user2)
+	getNotifications := func(query string) notifications.Notifications {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", notificationsURI+query, nil, http.StatusOK, &jwt2, ctJSON, t)
+		var list notifications.Notifications
+		require.NoError(t, json.Unmarshal(*bslice, &list))
+		return list
+	}
+	assert.Empty(t, getNotifications(""))
+
+	// Deprecating the model notifies the watcher
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.DeprecateResource{Message: "Use model2 instead"}))
+	gztest.AssertRouteMultipleArgs("POST", modelURL(testUser, "model1", "")+"/deprecation", b, http.StatusOK, &jwt, ctJSON, t)
+	list := getNotifications("")
+	require.Len(t, list, 1)
+	assert.Equal(t, notifications.EventDeprecation, list[0].Event)
+	assert.Equal(t, testUser, list[0].Actor)
+	assert.Equal(t, "model1", list[0].Name)
+	assert.Contains(t, list[0].Message, "Use model2 instead")
+	assert.False(t, list[0].Read)
+
+	// Users can only see and update their own notifications
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	gztest.AssertRouteMultipleArgs("GET", notificationsURI, nil, expEm.StatusCode, &jwt, ctTextPlain, t)
+	readURI := fmt.Sprintf("%s/%d", notificationsURI, list[0].ID)
+	read := true
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(notifications.UpdateNotification{Read: &read}))
+	gztest.AssertRouteMultipleArgs("PATCH", readURI, b, expEm.StatusCode, &jwt, ctTextPlain, t)
+
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(notifications.UpdateNotification{Read: &read}))
+	gztest.AssertRouteMultipleArgs("PATCH", readURI, b, http.StatusOK, &jwt2, ctJSON, t)
+	assert.Empty(t, getNotifications("?unread=true"))
+	require.Len(t, getNotifications(""), 1)
+
+	// Email preferences
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(notifications.UpdatePreferences{EmailVersions: &read}))
+	prefsURI := fmt.Sprintf("/1.0/users/%s/notification-preferences", user2)
+	bslice, _ = gztest.AssertRouteMultipleArgs("PATCH", prefsURI, b, http.StatusOK, &jwt2, ctJSON, t)
+	var prefs notifications.Preferences
+	require.NoError(t, json.Unmarshal(*bslice, &prefs))
+	assert.True(t, prefs.EmailVersions)
+	assert.False(t, prefs.EmailDeprecations)
+
+	// Stop watching
+	gztest.AssertRouteMultipleArgs("DELETE", watchURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+	expEm = gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	gztest.AssertRouteMultipleArgs("DELETE", watchURI, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/jinzhu/gorm"
 	"regexp"
+	"sync"
 )
 
 // Action - type int
@@ -21,10 +22,16 @@ const (
 	Read Action = iota
 	// Write
 	Write
+	// Delete a resource
+	Delete
+	// Transfer a resource to another owner
+	Transfer
+	// Add and remove members of an organization
+	ManageMembers
 )
 
 // Corresponding string value for an Action
-var actionStr = []string{"read", "write"}
+var actionStr = []string{"read", "write", "delete", "transfer", "manage-members"}
 
 // String function will return the english name of the Action
 func (a Action) String() string {
@@ -34,10 +41,10 @@ func (a Action) String() string {
 // ActionFrom returns the Action value corresponding to the given string. It will
 // return -1 if not found.
 func ActionFrom(str string) Action {
-	if actionStr[0] == str {
-		return Read
-	} else if actionStr[1] == str {
-		return Write
+	for i, s := range actionStr {
+		if s == str {
+			return Action(i)
+		}
 	}
 	return -1
 }
@@ -77,6 +84,26 @@ func RoleFrom(str string) (Role, *gz.ErrMsg) {
 	return -1, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, nil, []string{"role:", str})
 }
 
+// roleActions are the actions each built-in role can perform in a group.
+var roleActions = map[Role][]Action{
+	SystemAdmin: {Read, Write, Delete, Transfer, ManageMembers},
+	Owner:       {Read, Write, Delete, Transfer, ManageMembers},
+	Admin:       {Read, Write, Delete, Transfer, ManageMembers},
+	Member:      {Read, Write, Delete},
+}
+
+// IsBuiltinRole returns true if the given name is one of the built-in roles:
+// 'sysadmin', 'owner', 'admin' or 'member'.
+func IsBuiltinRole(name string) bool {
+	_, em := RoleFrom(name)
+	return em == nil
+}
+
+// ActionsForRole returns the actions a built-in role can perform in a group.
+func ActionsForRole(role Role) []Action {
+	return roleActions[role]
+}
+
 const (
 	// PolicyUser is the index of 'user' in a casbin policy tuple
 	PolicyUser = iota
@@ -119,9 +146,14 @@ type Enforcer interface {
 ///////////////////////////////////////////////////////////////////////////
 ///////////////////////////////////////////////////////////////////////////
 
+// policyModel is the path of the casbin model configuration.
+const policyModel = "permissions/policy.conf"
+
 // Private permission data objects
 type permissionsObj struct {
-	adapter  *gormadapter.Adapter
+	adapter *gormadapter.Adapter
+	// lock guards the enforcer, which is replaced when importing policies.
+	lock     sync.RWMutex
 	enforcer casbin.IEnforcer
 }
 
@@ -143,7 +175,7 @@ func (p *Permissions) Init(db *gorm.DB, sysAdmin string) error {
 		return err
 	}
 
-	enforcer, err := casbin.NewEnforcer("permissions/policy.conf", adapter)
+	enforcer, err := casbin.NewEnforcer(policyModel, adapter)
 	if err != nil {
 		return err
 	}
@@ -169,11 +201,18 @@ func (p *Permissions) InitWithEnforcerAndAdapter(e casbin.IEnforcer, a *gormadap
 	return nil
 }
 
+// enforcer returns the current casbin enforcer.
+func (p *Permissions) enforcer() casbin.IEnforcer {
+	p.data.lock.RLock()
+	defer p.data.lock.RUnlock()
+	return p.data.enforcer
+}
+
 // Reload reloads all casbin data
 // sysAdmin argument can contain a list of usernames separated by comma.
 func (p *Permissions) Reload(sysAdmin string) error {
 	// Load the policy from DB.
-	err := p.data.enforcer.LoadPolicy()
+	err := p.enforcer().LoadPolicy()
 	if err != nil {
 		return err
 	}
@@ -185,7 +224,7 @@ func (p *Permissions) Reload(sysAdmin string) error {
 // sysAdmin argument can contain a list of usernames separated by comma.
 func (p *Permissions) setSystemAdmin(sysAdmin string) {
 	saRole := roleToString(SystemAdmin)
-	_, err := p.data.enforcer.DeleteRole(saRole)
+	_, err := p.enforcer().DeleteRole(saRole)
 	if err != nil {
 		return
 	}
@@ -199,35 +238,218 @@ func (p *Permissions) setSystemAdmin(sysAdmin string) {
 
 // IsSystemAdmin returns a bool indicating if the given user is a system admin.
 func (p *Permissions) IsSystemAdmin(user string) bool {
-	result, _ := p.data.enforcer.HasRoleForUser(user, roleToString(SystemAdmin))
+	result, _ := p.enforcer().HasRoleForUser(user, roleToString(SystemAdmin))
 	return result
 }
 
-// IsAuthorized checks if user has the permission to perform an action on a
-// resource
+// IsAuthorized checks if user has the permission to read or write a resource.
+// Delete and Transfer are not granted by the resource policies, as they also
+// depend on the owner of the resource: use CanPerformAsOwner instead.
 func (p *Permissions) IsAuthorized(user, resource string, action Action) (bool, *gz.ErrMsg) {
+	if !p.authorize(user, resource, action, nil) {
+		return false, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	return true, nil
+}
+
+// ExplainAuthorized checks if user has the permission to read or write a
+// resource, like IsAuthorized, and also returns the steps followed to reach
+// the result.
+func (p *Permissions) ExplainAuthorized(user, resource string, action Action) (bool, []string) {
+	reasons := make([]string, 0)
+	ok := p.authorize(user, resource, action, func(reason string) {
+		reasons = append(reasons, reason)
+	})
+	return ok, reasons
+}
+
+// authorize returns true if user has the permission to read or write a
+// resource. If explain is not nil, it is called with each step followed to
+// reach the result.
+func (p *Permissions) authorize(user, resource string, action Action, explain func(string)) bool {
+	note := func(format string, args ...interface{}) {
+		if explain != nil {
+			explain(fmt.Sprintf(format, args...))
+		}
+	}
 	if p.IsSystemAdmin(user) {
+		note("User is a system admin, and can [%s] any resource", action)
+		return true
+	}
+	if action != Read && action != Write {
+		note("Action [%s] is not granted by the resource policies", action)
+		return false
+	}
+
+	valid, err := p.enforcer().Enforce(user, resource, actionToString(action))
+	if !valid || err != nil {
+		note("No grant allows the user to [%s] the resource", action)
+		return false
+	}
+	if action == Read {
+		if explain != nil {
+			p.explainGrants(user, resource, action, note)
+		}
+		return true
+	}
+	return p.canWriteResource(user, resource, note)
+}
+
+// explainGrants notes the policies that grant an action on a resource to the
+// user, or to the groups and roles the user belongs to.
+func (p *Permissions) explainGrants(user, resource string, action Action,
+	note func(string, ...interface{})) {
+
+	if p.enforcer().HasPermissionForUser(user, resource, actionToString(action)) {
+		note("User is granted [%s] directly", action)
+	}
+	groupRoles := p.GetGroupsAndRolesForUser(user)
+	roles, _ := p.enforcer().GetRolesForUser(user)
+	for _, r := range roles {
+		if ok, _ := p.enforcer().Enforce(r, resource, actionToString(action)); !ok {
+			continue
+		}
+		if role, isGroup := groupRoles[r]; isGroup {
+			note("User has role [%s] in group [%s], which is granted [%s]", role, r, action)
+		} else {
+			note("User belongs to [%s], which is granted [%s]", r, action)
+		}
+	}
+}
+
+// canWriteResource returns true if the user can write a resource it has write
+// access to. Users granted access directly (eg. owners and collaborators) can
+// write it. Users granted access through a group can only write it if their
+// role in the group allows it. Each step is noted with the given function.
+func (p *Permissions) canWriteResource(user, resource string, note func(string, ...interface{})) bool {
+	write := actionToString(Write)
+	if p.enforcer().HasPermissionForUser(user, resource, write) {
+		note("User is granted [%s] directly", Write)
+		return true
+	}
+	groupRoles := p.GetGroupsAndRolesForUser(user)
+	roles, _ := p.enforcer().GetRolesForUser(user)
+	for _, r := range roles {
+		if ok, _ := p.enforcer().Enforce(r, resource, write); !ok {
+			continue
+		}
+		role, isGroup := groupRoles[r]
+		if !isGroup {
+			note("User belongs to [%s], which is granted [%s]", r, Write)
+			return true
+		}
+		if ok, _ := p.CanPerformInGroup(user, r, Write); ok {
+			note("User has role [%s] in group [%s], which is granted [%s]", role, r, Write)
+			return true
+		}
+		note("User has role [%s] in group [%s], which is granted [%s], but the role "+
+			"does not allow it", role, r, Write)
+	}
+	note("No grant allows the user to [%s] the resource", Write)
+	return false
+}
+
+// CanPerformAsOwner returns true if the user can perform the given action on
+// the resources of an owner: the owner itself, system admins, and the members
+// of the owner organization whose role allows the action. Collaborators cannot
+// delete or transfer the resources they have write access to.
+func (p *Permissions) CanPerformAsOwner(user, owner string, action Action) (bool, *gz.ErrMsg) {
+	if user == owner || p.IsSystemAdmin(user) {
 		return true, nil
 	}
+	return p.CanPerformInGroup(user, owner, action)
+}
 
-	valid, err := p.data.enforcer.Enforce(user, resource, actionToString(action))
+// CanPerformInGroup returns true if the role of the user in the group allows
+// the given action. Built-in roles can perform the actions returned by
+// ActionsForRole. Custom roles can perform the actions they were created with.
+func (p *Permissions) CanPerformInGroup(user, group string, action Action) (bool, *gz.ErrMsg) {
+	if p.IsSystemAdmin(user) {
+		return true, nil
+	}
+	if role, em := p.GetUserRoleForGroup(user, group); em == nil {
+		for _, a := range roleActions[role] {
+			if a == action {
+				return true, nil
+			}
+		}
+		return false, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	valid, err := p.enforcer().Enforce(user, roleActionsResource(group), actionToString(action))
 	if !valid || err != nil {
 		return false, gz.NewErrorMessage(gz.ErrorUnauthorized)
 	}
 	return true, nil
 }
 
+// AddCustomRole sets up a custom role in a group. Users with a custom role can
+// read the group, and perform the given actions on the group resources.
+func (p *Permissions) AddCustomRole(group, role string, actions []Action) (bool, *gz.ErrMsg) {
+	if IsBuiltinRole(role) {
+		extra := fmt.Sprintf("Role [%s] is a built-in role", role)
+		return false, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+	}
+	groupRole := getCustomRoleForGroup(role, group)
+	if _, err := p.AddPermission(groupRole, group, Read); err != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	for _, a := range actions {
+		if _, err := p.AddPermission(groupRole, roleActionsResource(group), a); err != nil {
+			return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+	return true, nil
+}
+
+// RemoveCustomRole removes a custom role from a group, along with the
+// assignments of the role to users.
+func (p *Permissions) RemoveCustomRole(group, role string) (bool, *gz.ErrMsg) {
+	return p.RemoveRole(getCustomRoleForGroup(role, group))
+}
+
+// GetCustomRoleActions returns the actions a custom role can perform in a group.
+func (p *Permissions) GetCustomRoleActions(group, role string) []Action {
+	groupRole := getCustomRoleForGroup(role, group)
+	actions := make([]Action, 0)
+	for i, a := range actionStr {
+		if p.enforcer().HasPermissionForUser(groupRole, roleActionsResource(group), a) {
+			actions = append(actions, Action(i))
+		}
+	}
+	return actions
+}
+
+// GetUsersForCustomRole returns the users that have a custom role in a group.
+func (p *Permissions) GetUsersForCustomRole(group, role string) []string {
+	result, _ := p.enforcer().GetUsersForRole(getCustomRoleForGroup(role, group))
+	return result
+}
+
+// AddUserGroupCustomRole adds a user to a group with a custom role. The custom
+// role must have been set up with AddCustomRole.
+func (p *Permissions) AddUserGroupCustomRole(user, group, role string) (bool, *gz.ErrMsg) {
+	ok, em := p.AddRoleForUser(user, group)
+	if !ok {
+		return ok, em
+	}
+	ok, em = p.AddRoleForUser(user, getCustomRoleForGroup(role, group))
+	if !ok {
+		return ok, em
+	}
+	return p.SetRolePermissions(group)
+}
+
 // AddPermission adds a user (or group) permission on a resource
 // Returns true if the permission was added, false if the user already has the permission. A non-nil error indicates something went wrong.
 func (p *Permissions) AddPermission(user, resource string, action Action) (bool, error) {
-	valid, err := p.data.enforcer.AddPermissionForUser(user, resource, actionToString(action))
+	valid, err := p.enforcer().AddPermissionForUser(user, resource, actionToString(action))
 	return valid, err
 }
 
 // RemovePermission removes a user (or group) permission on a resource
 // Returns true if the permission was deleted, false if the user already did not have the permission. A non-nil error indicates something went wrong.
 func (p *Permissions) RemovePermission(user, resource string, action Action) (bool, error) {
-	valid, err := p.data.enforcer.DeletePermissionForUser(user, resource, actionToString(action))
+	valid, err := p.enforcer().DeletePermissionForUser(user, resource, actionToString(action))
 	return valid, err
 }
 
@@ -235,13 +457,25 @@ func (p *Permissions) RemovePermission(user, resource string, action Action) (bo
 func (p *Permissions) RemoveResource(resource string) (bool, *gz.ErrMsg) {
 	// policy is formatted in casbin as (user, resource, action)
 	// so the 1 in the arg below means resource.
-	valid, err := p.data.enforcer.RemoveFilteredPolicy(PolicyResource, resource)
+	valid, err := p.enforcer().RemoveFilteredPolicy(PolicyResource, resource)
 	if !valid || err != nil {
 		return false, gz.NewErrorMessage(gz.ErrorUnexpected)
 	}
 	return true, nil
 }
 
+// GetResourcePolicies returns the policies that grant actions on a resource.
+// Each policy is a (subject, resource, action) tuple.
+func (p *Permissions) GetResourcePolicies(resource string) [][]string {
+	return p.enforcer().GetFilteredPolicy(PolicyResource, resource)
+}
+
+// GetSystemAdmins returns the list of system admins.
+func (p *Permissions) GetSystemAdmins() []string {
+	result, _ := p.enforcer().GetUsersForRole(roleToString(SystemAdmin))
+	return result
+}
+
 // GetGroupsForUser returns the list of groups a user belongs to.
 func (p *Permissions) GetGroupsForUser(user string) []string {
 	groups := make([]string, 0)
@@ -256,7 +490,7 @@ func (p *Permissions) GetGroupsForUser(user string) []string {
 // groups as keys and the user role in those groups as values.
 func (p *Permissions) GetGroupsAndRolesForUser(user string) map[string]string {
 	m := make(map[string]string, 0)
-	roles, _ := p.data.enforcer.GetRolesForUser(user)
+	roles, _ := p.enforcer().GetRolesForUser(user)
 	re := regexp.MustCompile("(.+)_#([^_]+)$")
 	for _, r := range roles {
 		s := re.FindStringSubmatch(r)
@@ -271,7 +505,7 @@ func (p *Permissions) GetGroupsAndRolesForUser(user string) map[string]string {
 
 // GetUsersForGroup gets the users that belong to a group.
 func (p *Permissions) GetUsersForGroup(group string) []string {
-	result, _ := p.data.enforcer.GetUsersForRole(group)
+	result, _ := p.enforcer().GetUsersForRole(group)
 	return result
 }
 
@@ -282,7 +516,7 @@ func (p *Permissions) UserBelongsToGroup(user, group string) bool {
 
 // HasRoleForUser checks and see if a user has the specified role
 func (p *Permissions) HasRoleForUser(user, role string) bool {
-	result, _ := p.data.enforcer.HasRoleForUser(user, role)
+	result, _ := p.enforcer().HasRoleForUser(user, role)
 	return result
 }
 
@@ -318,13 +552,13 @@ func (p *Permissions) AddUserGroupRole(user, group string, role Role) (bool, *gz
 
 // AddRoleForUser adds a role for a user
 func (p *Permissions) AddRoleForUser(user, role string) (bool, *gz.ErrMsg) {
-	valid, _ := p.data.enforcer.HasRoleForUser(user, role)
+	valid, _ := p.enforcer().HasRoleForUser(user, role)
 	if valid {
 		extra := fmt.Sprintf("Role [%s] exist for user [%s]", role, user)
 		return false, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{extra})
 	}
 
-	added, _ := p.data.enforcer.AddRoleForUser(user, role)
+	added, _ := p.enforcer().AddRoleForUser(user, role)
 	if !added {
 		extra := fmt.Sprintf("Could not add role [%s] for user [%s]", role, user)
 		return false, gz.NewErrorMessageWithArgs(gz.ErrorUnexpected, nil, []string{extra})
@@ -339,7 +573,7 @@ func (p *Permissions) RemoveUserGroupRole(user, group string, role Role) (bool,
 
 // RemoveRoleForUser removes a role from a user
 func (p *Permissions) RemoveRoleForUser(user, role string) (bool, *gz.ErrMsg) {
-	valid, err := p.data.enforcer.DeleteRoleForUser(user, role)
+	valid, err := p.enforcer().DeleteRoleForUser(user, role)
 	if !valid || err != nil {
 		return false, gz.NewErrorMessage(gz.ErrorUnexpected)
 	}
@@ -359,7 +593,7 @@ func (p *Permissions) GetUserRoleForGroup(user, group string) (Role, *gz.ErrMsg)
 			return -1, em
 		}
 		groupRole := getRoleForGroup(role, group)
-		result, _ := p.data.enforcer.HasRoleForUser(user, groupRole)
+		result, _ := p.enforcer().HasRoleForUser(user, groupRole)
 		if result {
 			return role, nil
 		}
@@ -373,7 +607,17 @@ func (p *Permissions) GetUserRoleForGroup(user, group string) (Role, *gz.ErrMsg)
 func (p *Permissions) IsAuthorizedForRole(user, group string, role Role) (bool, *gz.ErrMsg) {
 	ur, em := p.GetUserRoleForGroup(user, group)
 	if em != nil {
-		return false, em
+		// Users with a custom role can act as an Admin or Member if their role
+		// allows all the actions of that built-in role.
+		if role != Admin && role != Member {
+			return false, em
+		}
+		for _, a := range roleActions[role] {
+			if ok, _ := p.CanPerformInGroup(user, group, a); !ok {
+				return false, em
+			}
+		}
+		return true, nil
 	}
 	if p.CompareRoles(ur, role) >= 0 {
 		return true, nil
@@ -391,7 +635,7 @@ func (p *Permissions) CompareRoles(role1, role2 Role) int {
 
 // RemoveUserFromGroup removes all roles from a user in a group
 func (p *Permissions) RemoveUserFromGroup(user, group string) (bool, *gz.ErrMsg) {
-	result, _ := p.data.enforcer.HasRoleForUser(user, group)
+	result, _ := p.enforcer().HasRoleForUser(user, group)
 	if !result {
 		extra := fmt.Sprintf("User [%s] does not belong to group [%s]", user, group)
 		return false, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, nil, []string{extra})
@@ -399,8 +643,8 @@ func (p *Permissions) RemoveUserFromGroup(user, group string) (bool, *gz.ErrMsg)
 
 	// Should not be able to remove the last owner.
 	ownerRole := getRoleForGroup(Owner, group)
-	owners, _ := p.data.enforcer.GetUsersForRole(ownerRole)
-	result, _ = p.data.enforcer.HasRoleForUser(user, ownerRole)
+	owners, _ := p.enforcer().GetUsersForRole(ownerRole)
+	result, _ = p.enforcer().HasRoleForUser(user, ownerRole)
 	if len(owners) == 1 && result {
 		extra := fmt.Sprintf("Cannot remove the last owner [%s] of an Org [%s]", user, group)
 		return false, gz.NewErrorMessageWithArgs(gz.ErrorUnexpected, nil, []string{extra})
@@ -410,32 +654,40 @@ func (p *Permissions) RemoveUserFromGroup(user, group string) (bool, *gz.ErrMsg)
 
 	// remove specific group roles of user (owner, admin, member)
 	role := getRoleForGroup(Member, group)
-	result, _ = p.data.enforcer.HasRoleForUser(user, role)
+	result, _ = p.enforcer().HasRoleForUser(user, role)
 	if result {
-		result, _ = p.data.enforcer.DeleteRoleForUser(user, role)
+		result, _ = p.enforcer().DeleteRoleForUser(user, role)
 		if !result {
 			return false, gz.NewErrorMessage(gz.ErrorUnexpected)
 		}
 	}
 	role = getRoleForGroup(Admin, group)
-	result, _ = p.data.enforcer.HasRoleForUser(user, role)
+	result, _ = p.enforcer().HasRoleForUser(user, role)
 	if result {
-		result, _ = p.data.enforcer.DeleteRoleForUser(user, role)
+		result, _ = p.enforcer().DeleteRoleForUser(user, role)
 		if !result {
 			return false, gz.NewErrorMessage(gz.ErrorUnexpected)
 		}
 	}
 
-	result, _ = p.data.enforcer.HasRoleForUser(user, ownerRole)
+	// remove the custom role of the user, if any
+	if role, ok := p.GetGroupsAndRolesForUser(user)[group]; ok && !IsBuiltinRole(role) {
+		result, _ = p.enforcer().DeleteRoleForUser(user, getCustomRoleForGroup(role, group))
+		if !result {
+			return false, gz.NewErrorMessage(gz.ErrorUnexpected)
+		}
+	}
+
+	result, _ = p.enforcer().HasRoleForUser(user, ownerRole)
 	// if the user was an owner, then remove that role too.
 	if result {
-		result, _ = p.data.enforcer.DeleteRoleForUser(user, ownerRole)
+		result, _ = p.enforcer().DeleteRoleForUser(user, ownerRole)
 		if !result {
 			return false, gz.NewErrorMessage(gz.ErrorUnexpected)
 		}
 	}
 
-	result, _ = p.data.enforcer.DeleteRoleForUser(user, group)
+	result, _ = p.enforcer().DeleteRoleForUser(user, group)
 	// finally, remove the user from the group too
 	if !result {
 		return false, gz.NewErrorMessage(gz.ErrorUnexpected)
@@ -448,7 +700,7 @@ func (p *Permissions) SetRolePermissions(group string) (bool, *gz.ErrMsg) {
 
 	groupRole := getRoleForGroup(Owner, group)
 	// check if permissions have already been set or not
-	if p.data.enforcer.HasPermissionForUser(groupRole) {
+	if p.enforcer().HasPermissionForUser(groupRole) {
 		return true, nil
 	}
 	_, err := p.AddPermission(groupRole, group, Read)
@@ -484,7 +736,7 @@ func (p *Permissions) RemoveRolePermissions(group string) (bool, *gz.ErrMsg) {
 
 	groupRole := getRoleForGroup(Owner, group)
 	// check if permissions were previously set
-	if !p.data.enforcer.HasPermissionForUser(groupRole) {
+	if !p.enforcer().HasPermissionForUser(groupRole) {
 		return true, nil
 	}
 	_, err := p.RemovePermission(groupRole, group, Read)
@@ -518,12 +770,12 @@ func (p *Permissions) RemoveRolePermissions(group string) (bool, *gz.ErrMsg) {
 // RemoveUser removes all policies involving the user
 func (p *Permissions) RemoveUser(user string) (bool, *gz.ErrMsg) {
 	// remove user resource permissions
-	_, err := p.data.enforcer.DeleteUser(user)
+	_, err := p.enforcer().DeleteUser(user)
 	if err != nil {
 		return false, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
 	}
 	// remove user roles
-	_, err = p.data.enforcer.DeletePermissionsForUser(user)
+	_, err = p.enforcer().DeletePermissionsForUser(user)
 	if err != nil {
 		return false, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
 	}
@@ -543,9 +795,152 @@ func (p *Permissions) RemoveGroup(group string) (bool, *gz.ErrMsg) {
 // RemoveRole removes all policies involving the role
 func (p *Permissions) RemoveRole(role string) (bool, *gz.ErrMsg) {
 	// casbin does not return a value for deleting roles
-	_, err := p.data.enforcer.DeleteRole(role)
+	_, err := p.enforcer().DeleteRole(role)
+	if err != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	return true, nil
+}
+
+// PolicySet is the full set of casbin policies. It is used to export and
+// import the policies, eg. for backups or to move data between environments.
+//
+// swagger:model
+type PolicySet struct {
+	// Permission policies, as (subject, resource, action) tuples
+	Policies [][]string `json:"policies"`
+	// Role policies, as (user, role) tuples. Users can have a group or a role
+	// in a group as role.
+	Roles [][]string `json:"roles"`
+}
+
+// ExportPolicies returns the full set of casbin policies. The system admin
+// roles are not exported, as they are configured when the server starts.
+func (p *Permissions) ExportPolicies() *PolicySet {
+	set := PolicySet{Policies: p.enforcer().GetPolicy(), Roles: make([][]string, 0)}
+	saRole := roleToString(SystemAdmin)
+	for _, rule := range p.enforcer().GetGroupingPolicy() {
+		if len(rule) > 1 && rule[1] == saRole {
+			continue
+		}
+		set.Roles = append(set.Roles, rule)
+	}
+	return &set
+}
+
+// ImportPolicies adds the given set of policies. Policies that already exist are
+// skipped. If replace is true, the existing policies are removed first, except
+// for the system admin roles. Replacing builds the new policies in a separate
+// enforcer, saves them in a DB transaction and only then starts using them, so
+// the existing policies are kept if anything fails.
+func (p *Permissions) ImportPolicies(db *gorm.DB, set *PolicySet, replace bool) (bool, *gz.ErrMsg) {
+	for _, rule := range set.Policies {
+		if len(rule) != 3 {
+			extra := fmt.Sprintf("Invalid policy %v", rule)
+			return false, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+		}
+	}
+	for _, rule := range set.Roles {
+		if len(rule) != 2 || rule[1] == roleToString(SystemAdmin) {
+			extra := fmt.Sprintf("Invalid role %v", rule)
+			return false, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{extra})
+		}
+	}
+
+	if replace {
+		return p.replacePolicies(db, set)
+	}
+	e := p.enforcer()
+	for _, rule := range set.Policies {
+		if _, err := e.AddPolicy(rule[0], rule[1], rule[2]); err != nil {
+			return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+	for _, rule := range set.Roles {
+		if _, err := e.AddGroupingPolicy(rule[0], rule[1]); err != nil {
+			return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+	return true, nil
+}
+
+// replacePolicies replaces all the policies with the given set, keeping the
+// system admin roles.
+func (p *Permissions) replacePolicies(db *gorm.DB, set *PolicySet) (bool, *gz.ErrMsg) {
+	// Build the new policies in memory.
+	e, err := casbin.NewEnforcer(policyModel)
 	if err != nil {
-		return false, nil
+		return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	}
+	e.EnableAutoSave(false)
+	for _, u := range p.GetSystemAdmins() {
+		if _, err := e.AddRoleForUser(u, roleToString(SystemAdmin)); err != nil {
+			return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+	for _, rule := range set.Policies {
+		if _, err := e.AddPolicy(rule[0], rule[1], rule[2]); err != nil {
+			return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+	for _, rule := range set.Roles {
+		if _, err := e.AddGroupingPolicy(rule[0], rule[1]); err != nil {
+			return false, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+
+	// Save them, replacing the existing ones.
+	tx := db.Begin()
+	if err := tx.Delete(&gormadapter.CasbinRule{}).Error; err != nil {
+		tx.Rollback()
+		return false, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	lines := make([]gormadapter.CasbinRule, 0, len(e.GetPolicy())+len(e.GetGroupingPolicy()))
+	for _, rule := range e.GetPolicy() {
+		lines = append(lines, gormadapter.CasbinRule{PType: "p", V0: rule[0], V1: rule[1], V2: rule[2]})
+	}
+	for _, rule := range e.GetGroupingPolicy() {
+		lines = append(lines, gormadapter.CasbinRule{PType: "g", V0: rule[0], V1: rule[1]})
+	}
+	for i := range lines {
+		if err := tx.Create(&lines[i]).Error; err != nil {
+			tx.Rollback()
+			return false, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	// Start using the new policies.
+	e.SetAdapter(p.data.adapter)
+	e.EnableAutoSave(true)
+	p.data.lock.Lock()
+	p.data.enforcer = e
+	p.data.lock.Unlock()
+	return true, nil
+}
+
+// RemovePolicyRule removes a (subject, resource, action) policy.
+func (p *Permissions) RemovePolicyRule(rule []string) (bool, *gz.ErrMsg) {
+	params := make([]interface{}, len(rule))
+	for i, v := range rule {
+		params[i] = v
+	}
+	if _, err := p.enforcer().RemovePolicy(params...); err != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	return true, nil
+}
+
+// RemoveRoleRule removes a (user, role) policy.
+func (p *Permissions) RemoveRoleRule(rule []string) (bool, *gz.ErrMsg) {
+	params := make([]interface{}, len(rule))
+	for i, v := range rule {
+		params[i] = v
+	}
+	if _, err := p.enforcer().RemoveGroupingPolicy(params...); err != nil {
+		return false, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
 	}
 	return true, nil
 }
@@ -569,3 +964,14 @@ func roleToString(role Role) string {
 func getRoleForGroup(role Role, group string) string {
 	return group + "_#" + roleToString(role)
 }
+
+// get the string representing a custom role of a group
+func getCustomRoleForGroup(role, group string) string {
+	return group + "_#" + role
+}
+
+// roleActionsResource returns the casbin resource used to store the actions
+// allowed to the custom roles of a group.
+func roleActionsResource(group string) string {
+	return group + "_#actions"
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/migrate"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// checkSystemAdmin returns an error if the requesting user is not a system
+// admin.
+func checkSystemAdmin(tx *gorm.DB, r *http.Request) *gz.ErrMsg {
+	user, ok, errMsg := getUserFromJWT(tx, r)
+	if !ok {
+		return &errMsg
+	}
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	return nil
+}
+
+// CasbinConsistencyCheck finds drift between the permission policies and the
+// organizations, teams and resources: orphaned policies, missing owner grants
+// and stale team roles. A POST request also fixes them.
+//
+// curl -k -X GET http://localhost:8000/1.0/admin/permissions/check --header "Private-token: YOUR_TOKEN"
+func CasbinConsistencyCheck(tx *gorm.DB, w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+	if em := checkSystemAdmin(tx, r); em != nil {
+		return nil, em
+	}
+	return migrate.CheckCasbinConsistency(r.Context(), tx, r.Method == http.MethodPost)
+}
+
+// CasbinPolicyExport returns the full set of permission policies, as JSON.
+//
+// curl -k -X GET http://localhost:8000/1.0/admin/permissions/policies --header "Private-token: YOUR_TOKEN"
+func CasbinPolicyExport(tx *gorm.DB, w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+	if em := checkSystemAdmin(tx, r); em != nil {
+		return nil, em
+	}
+	return globals.Permissions.ExportPolicies(), nil
+}
+
+// CasbinPolicyImport adds a set of permission policies, as returned by
+// CasbinPolicyExport. With the 'replace' query parameter, the existing
+// policies are removed first.
+//
+// curl -k -X POST http://localhost:8000/1.0/admin/permissions/policies?replace=true -d @policies.json --header "Private-token: YOUR_TOKEN"
+func CasbinPolicyImport(tx *gorm.DB, w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+	if em := checkSystemAdmin(tx, r); em != nil {
+		return nil, em
+	}
+	var set permissions.PolicySet
+	if em := ParseStruct(&set, r, false); em != nil {
+		return nil, em
+	}
+	replace := readBoolParam(r, "replace")
+	if ok, em := globals.Permissions.ImportPolicies(globals.Server.Db, &set, replace != nil && *replace); !ok {
+		return nil, em
+	}
+	return globals.Permissions.ExportPolicies(), nil
+}
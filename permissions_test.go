@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/migrate"
 	"github.com/gazebo-web/fuel-server/permissions"
 	"github.com/gazebo-web/gz-go/v7"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
 	"testing"
 )
 
@@ -127,6 +131,34 @@ func TestUserResourcePermissions(t *testing.T) {
 	}
 	testUserResourcePermissions(t, writePermissionsTestsData)
 
+	// test owner actions. Users with write access, such as collaborators, can
+	// only delete or transfer resources if they own them.
+	_, err = globals.Permissions.AddPermission("collaborator2", "resource2", permissions.Write)
+	assert.NoError(t, err)
+	ownerActionsTestsData := []struct {
+		user   string
+		action permissions.Action
+		exp    bool
+	}{
+		{"group2", permissions.Delete, true},
+		{"owner2", permissions.Transfer, true},
+		{"admin2", permissions.Delete, true},
+		{"admin2", permissions.Transfer, true},
+		{"member2", permissions.Delete, true},
+		{"member2", permissions.Transfer, false},
+		{"collaborator2", permissions.Delete, false},
+		{"collaborator2", permissions.Transfer, false},
+		{sysAdminForTest, permissions.Transfer, true},
+	}
+	for _, test := range ownerActionsTestsData {
+		ok, _ := globals.Permissions.CanPerformAsOwner(test.user, "group2", test.action)
+		assert.Equal(t, test.exp, ok, "%s %s", test.user, test.action)
+	}
+	ok, _ := globals.Permissions.IsAuthorized("collaborator2", "resource2", permissions.Write)
+	assert.True(t, ok)
+	ok, _ = globals.Permissions.IsAuthorized("collaborator2", "resource2", permissions.Delete)
+	assert.False(t, ok)
+
 	// test remove read permission
 	_, err = globals.Permissions.RemovePermission("user1", "resource1", permissions.Read)
 	assert.NoError(t, err)
@@ -363,3 +395,67 @@ func testGetUserGroups(t *testing.T, data []userGroupsTest) {
 		})
 	}
 }
+
+// TestCasbinConsistency tests finding and fixing drift between the casbin
+// policies and the resources in the database.
+func TestCasbinConsistency(t *testing.T) {
+	setup()
+	ctx := gz.NewContextWithLogger(context.Background(), gz.NewLoggerNoRollbar("test", gz.VerbosityDebug))
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	createTestModelWithOwner(t, &jwt, "consistency_model", testUser, true)
+	model := getOwnerModelFromDb(t, testUser, "consistency_model")
+
+	// Introduce drift: a missing owner grant and an orphaned policy.
+	orphan := "00000000-0000-0000-0000-000000000000"
+	_, err := globals.Permissions.RemovePermission(testUser, *model.UUID, permissions.Write)
+	require.NoError(t, err)
+	_, err = globals.Permissions.AddPermission(testUser, orphan, permissions.Read)
+	require.NoError(t, err)
+
+	issuesByType := func(report *migrate.CasbinReport) map[string][]migrate.CasbinIssue {
+		res := map[string][]migrate.CasbinIssue{}
+		for _, i := range report.Issues {
+			res[i.Type] = append(res[i.Type], i)
+		}
+		return res
+	}
+
+	// Checking does not fix the issues.
+	report, em := migrate.CheckCasbinConsistency(ctx, globals.Server.Db, false)
+	require.Nil(t, em)
+	issues := issuesByType(report)
+	require.Len(t, issues[migrate.CasbinMissingOwnerGrant], 1)
+	assert.Equal(t, []string{testUser, *model.UUID, "write"}, issues[migrate.CasbinMissingOwnerGrant][0].Rule)
+	assert.False(t, issues[migrate.CasbinMissingOwnerGrant][0].Fixed)
+	require.Len(t, issues[migrate.CasbinOrphanedPolicy], 1)
+	assert.Equal(t, []string{testUser, orphan, "read"}, issues[migrate.CasbinOrphanedPolicy][0].Rule)
+	ok, _ := globals.Permissions.IsAuthorized(testUser, *model.UUID, permissions.Write)
+	assert.False(t, ok)
+
+	// Fix the issues.
+	report, em = migrate.CheckCasbinConsistency(ctx, globals.Server.Db, true)
+	require.Nil(t, em)
+	for _, i := range report.Issues {
+		assert.True(t, i.Fixed, "Issue was not fixed: %v", i)
+	}
+	ok, _ = globals.Permissions.IsAuthorized(testUser, *model.UUID, permissions.Write)
+	assert.True(t, ok)
+	ok, _ = globals.Permissions.IsAuthorized(testUser, orphan, permissions.Read)
+	assert.False(t, ok)
+
+	report, em = migrate.CheckCasbinConsistency(ctx, globals.Server.Db, false)
+	require.Nil(t, em)
+	assert.Empty(t, report.Issues)
+
+	// Export and re-import the policies.
+	set := globals.Permissions.ExportPolicies()
+	assert.Contains(t, set.Policies, []string{testUser, *model.UUID, "write"})
+	_, em = globals.Permissions.ImportPolicies(globals.Server.Db, set, true)
+	require.Nil(t, em)
+	ok, _ = globals.Permissions.IsAuthorized(testUser, *model.UUID, permissions.Write)
+	assert.True(t, ok)
+	ok, _ = globals.Permissions.IsAuthorized(sysAdminForTest, *model.UUID, permissions.Write)
+	assert.True(t, ok)
+}
@@ -26,30 +26,38 @@ type Model struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	CreatedAt    *string      `protobuf:"bytes,2,opt,name=createdAt" json:"createdAt,omitempty"`
-	UpdatedAt    *string      `protobuf:"bytes,3,opt,name=updatedAt" json:"updatedAt,omitempty"`
-	DeletedAt    *string      `protobuf:"bytes,4,opt,name=deletedAt" json:"deletedAt,omitempty"`
-	Name         *string      `protobuf:"bytes,5,opt,name=name" json:"name,omitempty"`
-	Owner        *string      `protobuf:"bytes,7,opt,name=owner" json:"owner,omitempty"`
-	Description  *string      `protobuf:"bytes,8,opt,name=description" json:"description,omitempty"`
-	Likes        *int64       `protobuf:"varint,9,opt,name=likes" json:"likes,omitempty"`
-	Downloads    *int64       `protobuf:"varint,10,opt,name=downloads" json:"downloads,omitempty"`
-	Filesize     *int64       `protobuf:"varint,11,opt,name=filesize" json:"filesize,omitempty"`
-	UploadDate   *string      `protobuf:"bytes,12,opt,name=upload_date,json=uploadDate" json:"upload_date,omitempty"`
-	ModifyDate   *string      `protobuf:"bytes,13,opt,name=modify_date,json=modifyDate" json:"modify_date,omitempty"`
-	LicenseId    *uint64      `protobuf:"varint,14,opt,name=license_id,json=licenseId" json:"license_id,omitempty"`
-	LicenseName  *string      `protobuf:"bytes,15,opt,name=license_name,json=licenseName" json:"license_name,omitempty"`
-	LicenseUrl   *string      `protobuf:"bytes,16,opt,name=license_url,json=licenseUrl" json:"license_url,omitempty"`
-	LicenseImage *string      `protobuf:"bytes,17,opt,name=license_image,json=licenseImage" json:"license_image,omitempty"`
-	Permission   *int64       `protobuf:"varint,18,opt,name=permission" json:"permission,omitempty"`
-	UrlName      *string      `protobuf:"bytes,19,opt,name=url_name,json=urlName" json:"url_name,omitempty"`
-	ThumbnailUrl *string      `protobuf:"bytes,20,opt,name=thumbnail_url,json=thumbnailUrl" json:"thumbnail_url,omitempty"`
-	IsLiked      *bool        `protobuf:"varint,21,opt,name=is_liked,json=isLiked" json:"is_liked,omitempty"`
-	Version      *int64       `protobuf:"varint,22,opt,name=version" json:"version,omitempty"`
-	Private      *bool        `protobuf:"varint,23,opt,name=private" json:"private,omitempty"`
-	Tags         []string     `protobuf:"bytes,30,rep,name=tags" json:"tags,omitempty"`
-	Metadata     []*Metadatum `protobuf:"bytes,31,rep,name=metadata" json:"metadata,omitempty"`
-	Categories   []string     `protobuf:"bytes,32,rep,name=categories" json:"categories,omitempty"`
+	CreatedAt          *string     `protobuf:"bytes,2,opt,name=createdAt" json:"createdAt,omitempty"`
+	UpdatedAt          *string     `protobuf:"bytes,3,opt,name=updatedAt" json:"updatedAt,omitempty"`
+	DeletedAt          *string     `protobuf:"bytes,4,opt,name=deletedAt" json:"deletedAt,omitempty"`
+	Name               *string     `protobuf:"bytes,5,opt,name=name" json:"name,omitempty"`
+	Owner              *string     `protobuf:"bytes,7,opt,name=owner" json:"owner,omitempty"`
+	Description        *string     `protobuf:"bytes,8,opt,name=description" json:"description,omitempty"`
+	Likes              *int64      `protobuf:"varint,9,opt,name=likes" json:"likes,omitempty"`
+	Downloads          *int64      `protobuf:"varint,10,opt,name=downloads" json:"downloads,omitempty"`
+	Filesize           *int64      `protobuf:"varint,11,opt,name=filesize" json:"filesize,omitempty"`
+	UploadDate         *string     `protobuf:"bytes,12,opt,name=upload_date,json=uploadDate" json:"upload_date,omitempty"`
+	ModifyDate         *string     `protobuf:"bytes,13,opt,name=modify_date,json=modifyDate" json:"modify_date,omitempty"`
+	LicenseId          *uint64     `protobuf:"varint,14,opt,name=license_id,json=licenseId" json:"license_id,omitempty"`
+	LicenseName        *string     `protobuf:"bytes,15,opt,name=license_name,json=licenseName" json:"license_name,omitempty"`
+	LicenseUrl         *string     `protobuf:"bytes,16,opt,name=license_url,json=licenseUrl" json:"license_url,omitempty"`
+	LicenseImage       *string     `protobuf:"bytes,17,opt,name=license_image,json=licenseImage" json:"license_image,omitempty"`
+	Permission         *int64      `protobuf:"varint,18,opt,name=permission" json:"permission,omitempty"`
+	UrlName            *string     `protobuf:"bytes,19,opt,name=url_name,json=urlName" json:"url_name,omitempty"`
+	ThumbnailUrl       *string     `protobuf:"bytes,20,opt,name=thumbnail_url,json=thumbnailUrl" json:"thumbnail_url,omitempty"`
+	IsLiked            *bool       `protobuf:"varint,21,opt,name=is_liked,json=isLiked" json:"is_liked,omitempty"`
+	Version            *int64      `protobuf:"varint,22,opt,name=version" json:"version,omitempty"`
+	Private            *bool       `protobuf:"varint,23,opt,name=private" json:"private,omitempty"`
+	ForkedFrom         *ForkedFrom `protobuf:"bytes,24,opt,name=forked_from,json=forkedFrom" json:"forked_from,omitempty"`
+	Archived           *bool       `protobuf:"varint,25,opt,name=archived" json:"archived,omitempty"`
+	Deprecated         *bool       `protobuf:"varint,26,opt,name=deprecated" json:"deprecated,omitempty"`
+	DeprecationMessage *string     `protobuf:"bytes,27,opt,name=deprecation_message,json=deprecationMessage" json:"deprecation_message,omitempty"`
+	Successor          *Successor  `protobuf:"bytes,28,opt,name=successor" json:"successor,omitempty"`
+	// Average of the star ratings, from 1 to 5
+	RatingAverage *float64     `protobuf:"fixed64,29,opt,name=rating_average,json=ratingAverage" json:"rating_average,omitempty"`
+	RatingCount   *int64       `protobuf:"varint,33,opt,name=rating_count,json=ratingCount" json:"rating_count,omitempty"`
+	Tags          []string     `protobuf:"bytes,30,rep,name=tags" json:"tags,omitempty"`
+	Metadata      []*Metadatum `protobuf:"bytes,31,rep,name=metadata" json:"metadata,omitempty"`
+	Categories    []string     `protobuf:"bytes,32,rep,name=categories" json:"categories,omitempty"`
 }
 
 func (x *Model) Reset() {
@@ -231,6 +239,55 @@ func (x *Model) GetPrivate() bool {
 	return false
 }
 
+func (x *Model) GetForkedFrom() *ForkedFrom {
+	if x != nil {
+		return x.ForkedFrom
+	}
+	return nil
+}
+
+func (x *Model) GetArchived() bool {
+	if x != nil && x.Archived != nil {
+		return *x.Archived
+	}
+	return false
+}
+
+func (x *Model) GetDeprecated() bool {
+	if x != nil && x.Deprecated != nil {
+		return *x.Deprecated
+	}
+	return false
+}
+
+func (x *Model) GetDeprecationMessage() string {
+	if x != nil && x.DeprecationMessage != nil {
+		return *x.DeprecationMessage
+	}
+	return ""
+}
+
+func (x *Model) GetSuccessor() *Successor {
+	if x != nil {
+		return x.Successor
+	}
+	return nil
+}
+
+func (x *Model) GetRatingAverage() float64 {
+	if x != nil && x.RatingAverage != nil {
+		return *x.RatingAverage
+	}
+	return 0
+}
+
+func (x *Model) GetRatingCount() int64 {
+	if x != nil && x.RatingCount != nil {
+		return *x.RatingCount
+	}
+	return 0
+}
+
 func (x *Model) GetTags() []string {
 	if x != nil {
 		return x.Tags
@@ -252,6 +309,129 @@ func (x *Model) GetCategories() []string {
 	return nil
 }
 
+// ForkedFrom identifies the resource (and its version) a resource was cloned
+// from.
+// swagger:model
+type ForkedFrom struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Owner   *string `protobuf:"bytes,1,opt,name=owner" json:"owner,omitempty"`
+	Name    *string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Version *int64  `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+}
+
+func (x *ForkedFrom) Reset() {
+	*x = ForkedFrom{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_model_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ForkedFrom) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForkedFrom) ProtoMessage() {}
+
+func (x *ForkedFrom) ProtoReflect() protoreflect.Message {
+	mi := &file_model_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForkedFrom.ProtoReflect.Descriptor instead.
+func (*ForkedFrom) Descriptor() ([]byte, []int) {
+	return file_model_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ForkedFrom) GetOwner() string {
+	if x != nil && x.Owner != nil {
+		return *x.Owner
+	}
+	return ""
+}
+
+func (x *ForkedFrom) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ForkedFrom) GetVersion() int64 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+// Successor identifies the resource that replaces a deprecated resource.
+// swagger:model
+type Successor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Owner *string `protobuf:"bytes,1,opt,name=owner" json:"owner,omitempty"`
+	Name  *string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+}
+
+func (x *Successor) Reset() {
+	*x = Successor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_model_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Successor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Successor) ProtoMessage() {}
+
+func (x *Successor) ProtoReflect() protoreflect.Message {
+	mi := &file_model_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Successor.ProtoReflect.Descriptor instead.
+func (*Successor) Descriptor() ([]byte, []int) {
+	return file_model_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Successor) GetOwner() string {
+	if x != nil && x.Owner != nil {
+		return *x.Owner
+	}
+	return ""
+}
+
+func (x *Successor) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
 // swagger:model
 type Models struct {
 	state         protoimpl.MessageState
@@ -264,7 +444,7 @@ type Models struct {
 func (x *Models) Reset() {
 	*x = Models{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_model_proto_msgTypes[1]
+		mi := &file_model_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -277,7 +457,7 @@ func (x *Models) String() string {
 func (*Models) ProtoMessage() {}
 
 func (x *Models) ProtoReflect() protoreflect.Message {
-	mi := &file_model_proto_msgTypes[1]
+	mi := &file_model_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -290,7 +470,7 @@ func (x *Models) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Models.ProtoReflect.Descriptor instead.
 func (*Models) Descriptor() ([]byte, []int) {
-	return file_model_proto_rawDescGZIP(), []int{1}
+	return file_model_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Models) GetModels() []*Model {
@@ -318,7 +498,7 @@ type FileTree struct {
 func (x *FileTree) Reset() {
 	*x = FileTree{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_model_proto_msgTypes[2]
+		mi := &file_model_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -331,7 +511,7 @@ func (x *FileTree) String() string {
 func (*FileTree) ProtoMessage() {}
 
 func (x *FileTree) ProtoReflect() protoreflect.Message {
-	mi := &file_model_proto_msgTypes[2]
+	mi := &file_model_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -344,7 +524,7 @@ func (x *FileTree) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileTree.ProtoReflect.Descriptor instead.
 func (*FileTree) Descriptor() ([]byte, []int) {
-	return file_model_proto_rawDescGZIP(), []int{2}
+	return file_model_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *FileTree) GetName() string {
@@ -388,7 +568,7 @@ type FileTree_FileNode struct {
 func (x *FileTree_FileNode) Reset() {
 	*x = FileTree_FileNode{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_model_proto_msgTypes[3]
+		mi := &file_model_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -401,7 +581,7 @@ func (x *FileTree_FileNode) String() string {
 func (*FileTree_FileNode) ProtoMessage() {}
 
 func (x *FileTree_FileNode) ProtoReflect() protoreflect.Message {
-	mi := &file_model_proto_msgTypes[3]
+	mi := &file_model_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -414,7 +594,7 @@ func (x *FileTree_FileNode) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileTree_FileNode.ProtoReflect.Descriptor instead.
 func (*FileTree_FileNode) Descriptor() ([]byte, []int) {
-	return file_model_proto_rawDescGZIP(), []int{2, 0}
+	return file_model_proto_rawDescGZIP(), []int{4, 0}
 }
 
 func (x *FileTree_FileNode) GetName() string {
@@ -443,7 +623,7 @@ var File_model_proto protoreflect.FileDescriptor
 var file_model_proto_rawDesc = []byte{
 	0x0a, 0x0b, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x66,
 	0x75, 0x65, 0x6c, 0x1a, 0x0e, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x22, 0xd7, 0x05, 0x0a, 0x05, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1c, 0x0a,
+	0x6f, 0x74, 0x6f, 0x22, 0xf0, 0x07, 0x0a, 0x05, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1c, 0x0a,
 	0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x75,
 	0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
@@ -482,34 +662,60 @@ var file_model_proto_rawDesc = []byte{
 	0x07, 0x69, 0x73, 0x4c, 0x69, 0x6b, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
 	0x69, 0x6f, 0x6e, 0x18, 0x16, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
 	0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x18, 0x17, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04,
-	0x74, 0x61, 0x67, 0x73, 0x18, 0x1e, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73,
-	0x12, 0x2b, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x1f, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x66, 0x75, 0x65, 0x6c, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x75, 0x6d, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1e, 0x0a,
-	0x0a, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x18, 0x20, 0x20, 0x03, 0x28,
-	0x09, 0x52, 0x0a, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x22, 0x2d, 0x0a,
-	0x06, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x12, 0x23, 0x0a, 0x06, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x66, 0x75, 0x65, 0x6c, 0x2e, 0x4d,
-	0x6f, 0x64, 0x65, 0x6c, 0x52, 0x06, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x22, 0xed, 0x01, 0x0a,
-	0x08, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a,
-	0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77,
-	0x6e, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x34, 0x0a,
-	0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x17, 0x2e, 0x66, 0x75, 0x65, 0x6c, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x65, 0x65,
-	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x54,
-	0x72, 0x65, 0x65, 0x1a, 0x67, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12,
-	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x33, 0x0a, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64,
-	0x72, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x75, 0x65, 0x6c,
-	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x65, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x6f,
-	0x64, 0x65, 0x52, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x42, 0x28, 0x5a, 0x26,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x61, 0x7a, 0x65, 0x62,
-	0x6f, 0x2d, 0x77, 0x65, 0x62, 0x2f, 0x66, 0x75, 0x65, 0x6c, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65,
-	0x72, 0x2f, 0x66, 0x75, 0x65, 0x6c,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x31, 0x0a, 0x0b,
+	0x66, 0x6f, 0x72, 0x6b, 0x65, 0x64, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x18, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x66, 0x75, 0x65, 0x6c, 0x2e, 0x46, 0x6f, 0x72, 0x6b, 0x65, 0x64, 0x46,
+	0x72, 0x6f, 0x6d, 0x52, 0x0a, 0x66, 0x6f, 0x72, 0x6b, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d, 0x12,
+	0x1a, 0x0a, 0x08, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x64, 0x18, 0x19, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x64,
+	0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0a, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x2f, 0x0a, 0x13, 0x64,
+	0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x64, 0x65, 0x70, 0x72, 0x65, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2d, 0x0a, 0x09,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0f, 0x2e, 0x66, 0x75, 0x65, 0x6c, 0x2e, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72,
+	0x52, 0x09, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x72,
+	0x61, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x18, 0x1d, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0d, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x41, 0x76, 0x65, 0x72, 0x61,
+	0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x21, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x1e, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x2b, 0x0a, 0x08, 0x6d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x1f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x66, 0x75,
+	0x65, 0x6c, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x75, 0x6d, 0x52, 0x08, 0x6d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f,
+	0x72, 0x69, 0x65, 0x73, 0x18, 0x20, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x61, 0x74, 0x65,
+	0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x22, 0x50, 0x0a, 0x0a, 0x46, 0x6f, 0x72, 0x6b, 0x65, 0x64,
+	0x46, 0x72, 0x6f, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x35, 0x0a, 0x09, 0x53, 0x75, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22,
+	0x2d, 0x0a, 0x06, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x12, 0x23, 0x0a, 0x06, 0x6d, 0x6f, 0x64,
+	0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x66, 0x75, 0x65, 0x6c,
+	0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x06, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x22, 0xed,
+	0x01, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x65, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x34, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x18, 0x0a, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x75, 0x65, 0x6c, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72,
+	0x65, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x66, 0x69, 0x6c,
+	0x65, 0x54, 0x72, 0x65, 0x65, 0x1a, 0x67, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4e, 0x6f, 0x64,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x33, 0x0a, 0x08, 0x63, 0x68, 0x69,
+	0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x75,
+	0x65, 0x6c, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x54, 0x72, 0x65, 0x65, 0x2e, 0x46, 0x69, 0x6c, 0x65,
+	0x4e, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x42, 0x28,
+	0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x61, 0x7a,
+	0x65, 0x62, 0x6f, 0x2d, 0x77, 0x65, 0x62, 0x2f, 0x66, 0x75, 0x65, 0x6c, 0x2d, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x2f, 0x66, 0x75, 0x65, 0x6c,
 }
 
 var (
@@ -524,24 +730,28 @@ func file_model_proto_rawDescGZIP() []byte {
 	return file_model_proto_rawDescData
 }
 
-var file_model_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_model_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_model_proto_goTypes = []interface{}{
 	(*Model)(nil),             // 0: fuel.Model
-	(*Models)(nil),            // 1: fuel.Models
-	(*FileTree)(nil),          // 2: fuel.FileTree
-	(*FileTree_FileNode)(nil), // 3: fuel.FileTree.FileNode
-	(*Metadatum)(nil),         // 4: fuel.Metadatum
+	(*ForkedFrom)(nil),        // 1: fuel.ForkedFrom
+	(*Successor)(nil),         // 2: fuel.Successor
+	(*Models)(nil),            // 3: fuel.Models
+	(*FileTree)(nil),          // 4: fuel.FileTree
+	(*FileTree_FileNode)(nil), // 5: fuel.FileTree.FileNode
+	(*Metadatum)(nil),         // 6: fuel.Metadatum
 }
 var file_model_proto_depIdxs = []int32{
-	4, // 0: fuel.Model.metadata:type_name -> fuel.Metadatum
-	0, // 1: fuel.Models.models:type_name -> fuel.Model
-	3, // 2: fuel.FileTree.file_tree:type_name -> fuel.FileTree.FileNode
-	3, // 3: fuel.FileTree.FileNode.children:type_name -> fuel.FileTree.FileNode
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	1, // 0: fuel.Model.forked_from:type_name -> fuel.ForkedFrom
+	2, // 1: fuel.Model.successor:type_name -> fuel.Successor
+	6, // 2: fuel.Model.metadata:type_name -> fuel.Metadatum
+	0, // 3: fuel.Models.models:type_name -> fuel.Model
+	5, // 4: fuel.FileTree.file_tree:type_name -> fuel.FileTree.FileNode
+	5, // 5: fuel.FileTree.FileNode.children:type_name -> fuel.FileTree.FileNode
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_model_proto_init() }
@@ -564,7 +774,7 @@ func file_model_proto_init() {
 			}
 		}
 		file_model_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Models); i {
+			switch v := v.(*ForkedFrom); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -576,7 +786,7 @@ func file_model_proto_init() {
 			}
 		}
 		file_model_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FileTree); i {
+			switch v := v.(*Successor); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -588,6 +798,30 @@ func file_model_proto_init() {
 			}
 		}
 		file_model_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Models); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_model_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FileTree); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_model_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FileTree_FileNode); i {
 			case 0:
 				return &v.state
@@ -606,7 +840,7 @@ func file_model_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_model_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/ratings"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// ratedResource is a model or world that can be rated.
+type ratedResource interface {
+	commonres.Resource
+	GetID() uint
+}
+
+// getRatedResource returns the model or world of the rating routes. Private
+// resources are only returned to the users that can read them.
+func getRatedResource(resType, owner, name string, user *users.User,
+	tx *gorm.DB) (ratedResource, *gz.ErrMsg) {
+
+	res, em := getResource(resType, owner, name, user, tx)
+	if em != nil {
+		return nil, em
+	}
+	return res.(ratedResource), nil
+}
+
+// isArchivedResource returns true if a model or world is archived.
+func isArchivedResource(res commonres.Resource) bool {
+	switch v := res.(type) {
+	case *models.Model:
+		return commonres.IsArchived(v.Archived)
+	case *worlds.World:
+		return commonres.IsArchived(v.Archived)
+	}
+	return false
+}
+
+// reindexResource updates the search index of a model or world, and clears
+// the list cache. It is used after changes that bypass the model and world
+// services, like new ratings and moderation actions. It must be called once
+// the transaction of the change is committed, so that neither the index nor
+// the cache see changes that could still be rolled back.
+func reindexResource(ctx context.Context, db *gorm.DB, res commonres.Resource) {
+	switch v := res.(type) {
+	case *models.Model:
+		db.Model(v).Related(&v.Metadata)
+		models.ElasticSearchUpdateModel(ctx, db, *v)
+	case *worlds.World:
+		db.Model(v).Related(&v.Metadata)
+		worlds.ElasticSearchUpdateWorld(ctx, *v)
+	}
+	if err := globals.QueryCache.DeleteAll(); err != nil {
+		gz.LoggerFromContext(ctx).Error("Failed to clear the memory cache.")
+	}
+}
+
+// RatingList returns a handler that lists the star ratings of a model or world,
+// newest first. Use the version query parameter to get the ratings of a single
+// version.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model}/ratings?version=2
+func RatingList(resType string) pagHandler {
+	return func(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+		name, owner, em := readOwnerNameParams(resType, tx, r)
+		if em != nil {
+			return nil, nil, em
+		}
+		res, em := getRatedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, nil, em
+		}
+		var version *int
+		if v := r.URL.Query().Get("version"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, err, []string{"version"})
+			}
+			version = &n
+		}
+		return ratings.List(p, tx, resType, res.GetID(), version)
+	}
+}
+
+// RatingCreate returns a handler that rates a version of a model or world with
+// 1 to 5 stars and an optional written review. Rating the same version again
+// replaces the previous rating. Users cannot rate their own resources, nor the
+// resources of their organizations. Archived resources cannot be rated.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model}/ratings
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	  -d '{"stars":4, "text":"Great model, but heavy", "version":2}'
+func RatingCreate(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getRatedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		if ok, _ := users.CanPerformWithRole(tx, owner, *user.Username, permissions.Member); ok {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil,
+				[]string{"Owners cannot rate their own resources"})
+		}
+		if isArchivedResource(res) {
+			return nil, commonres.NewArchivedErrorMessage(name)
+		}
+		var cr ratings.CreateRating
+		if em := ParseStruct(&cr, r, false); em != nil {
+			return nil, em
+		}
+		latest, err := commonres.GetLatestVersion(r.Context(), res)
+		if err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+		version := latest
+		if cr.Version != nil {
+			if *cr.Version > latest {
+				return nil, gz.NewErrorMessage(gz.ErrorVersionNotFound)
+			}
+			version = *cr.Version
+		}
+		rating, changed, em := ratings.Rate(tx, resType, res, res.GetID(), version, user.ID, *user.Username, cr)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		// Only the aggregates are indexed and used to sort the lists.
+		if changed {
+			reindexResource(r.Context(), globals.Server.Db, res)
+		}
+		return rating, nil
+	}
+}
+
+// RatingRemove returns a handler that removes the rating of the JWT user for a
+// version of a model or world.
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/models/{model}/ratings/{version}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func RatingRemove(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		res, em := getRatedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		if isArchivedResource(res) {
+			return nil, commonres.NewArchivedErrorMessage(name)
+		}
+		version, err := strconv.Atoi(mux.Vars(r)["version"])
+		if err != nil {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, err, []string{"version"})
+		}
+		changed, em := ratings.Remove(tx, resType, res, res.GetID(), version, user.ID)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+		if changed {
+			reindexResource(r.Context(), globals.Server.Db, res)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return nil, nil
+	}
+}
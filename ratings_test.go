@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/ratings"
+	"github.com/gazebo-web/fuel-server/proto"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"os"
+	"testing"
+)
+
+// TestModelRatings tests star ratings of models, their aggregates and sorting
+// model lists by rating.
+func TestModelRatings(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+	jwt3 := createValidJWTForIdentity("another-user-3", t)
+	user3 := createUserWithJWT(jwt3, t)
+	defer removeUserWithJWT(user3, jwt3, t)
+	createThreeTestModels(t, &jwt)
+
+	rate := func(model string, cr ratings.CreateRating, jwt *string, status int) {
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(cr))
+		ct := ctJSON
+		if status != http.StatusOK {
+			ct = ctTextPlain
+		}
+		gztest.AssertRouteMultipleArgs("POST", modelURL(testUser, model, "")+"/ratings", b, status, jwt, ct, t)
+	}
+	getModel := func(model string) *fuel.Model {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", modelURL(testUser, model, ""), nil, http.StatusOK, &jwt, ctJSON, t)
+		var m fuel.Model
+		require.NoError(t, json.Unmarshal(*bslice, &m))
+		return &m
+	}
+
+	// Owners cannot rate their models
+	unauth := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	rate("model1", ratings.CreateRating{Stars: 5}, &jwt, unauth.StatusCode)
+	// Invalid stars and versions
+	invalid := gz.NewErrorMessage(gz.ErrorFormInvalidValue)
+	rate("model1", ratings.CreateRating{Stars: 6}, &jwt2, invalid.StatusCode)
+	version := 2
+	notFound := gz.NewErrorMessage(gz.ErrorVersionNotFound)
+	rate("model1", ratings.CreateRating{Stars: 4, Version: &version}, &jwt2, notFound.StatusCode)
+
+	// Rating a version again replaces the previous rating
+	rate("model1", ratings.CreateRating{Stars: 5}, &jwt2, http.StatusOK)
+	rate("model1", ratings.CreateRating{Stars: 2, Text: "Too heavy"}, &jwt2, http.StatusOK)
+	rate("model1", ratings.CreateRating{Stars: 4}, &jwt3, http.StatusOK)
+	m := getModel("model1")
+	assert.Equal(t, 3.0, m.GetRatingAverage())
+	assert.Equal(t, int64(2), m.GetRatingCount())
+
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", modelURL(testUser, "model1", "")+"/ratings?version=1", nil, http.StatusOK, nil, ctJSON, t)
+	var list ratings.Ratings
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	require.Len(t, list, 2)
+	assert.Equal(t, user3, list[0].Username)
+	assert.Equal(t, "Too heavy", list[1].Text)
+
+	// Sort by rating
+	rate("model2", ratings.CreateRating{Stars: 5}, &jwt2, http.StatusOK)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/%s/models?sort=rating", testUser), nil, http.StatusOK, &jwt, ctJSON, t)
+	var sorted []*fuel.Model
+	require.NoError(t, json.Unmarshal(*bslice, &sorted))
+	require.Len(t, sorted, 3)
+	assert.Equal(t, "model2", sorted[0].GetName())
+	assert.Equal(t, "model1", sorted[1].GetName())
+	gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/%s/models?sort=foo", testUser), nil, invalid.StatusCode, &jwt, ctTextPlain, t)
+
+	// Remove a rating
+	gztest.AssertRouteMultipleArgs("DELETE", modelURL(testUser, "model1", "")+"/ratings/1", nil, http.StatusOK, &jwt2, ctJSON, t)
+	expEm := gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	gztest.AssertRouteMultipleArgs("DELETE", modelURL(testUser, "model1", "")+"/ratings/1", nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	m = getModel("model1")
+	assert.Equal(t, 4.0, m.GetRatingAverage())
+	assert.Equal(t, int64(1), m.GetRatingCount())
+
+	// Users are counted once, with their rating of the latest version they rated
+	files := []gztest.FileDesc{{Path: "model.config", Contents: constModelConfigFileContents}}
+	code, _, _ := gztest.SendMultipartMethod(t.Name(), t, "PATCH", modelURL(testUser, "model1", ""), &jwt, nil, files)
+	require.Equal(t, http.StatusOK, code)
+	rate("model1", ratings.CreateRating{Stars: 2}, &jwt3, http.StatusOK)
+	m = getModel("model1")
+	assert.Equal(t, 2.0, m.GetRatingAverage())
+	assert.Equal(t, int64(1), m.GetRatingCount())
+
+	// Archived models cannot be rated
+	code, _, _ = gztest.SendMultipartMethod(t.Name(), t, "PATCH", modelURL(testUser, "model1", ""), &jwt,
+		map[string]string{"archived": "true"}, nil)
+	require.Equal(t, http.StatusOK, code)
+	rate("model1", ratings.CreateRating{Stars: 5}, &jwt2, unauth.StatusCode)
+	gztest.AssertRouteMultipleArgs("DELETE", modelURL(testUser, "model1", "")+"/ratings/2", nil, unauth.StatusCode, &jwt3, ctTextPlain, t)
+}
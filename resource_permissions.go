@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// isPrivateResource returns true if the given model, world or collection is
+// private.
+func isPrivateResource(resource commonres.Resource) bool {
+	var private *bool
+	switch r := resource.(type) {
+	case *models.Model:
+		private = r.Private
+	case *worlds.World:
+		private = r.Private
+	case *collections.Collection:
+		private = r.Private
+	}
+	return private != nil && *private
+}
+
+// ResourcePermissions returns a handler that lists the users that can read or
+// write a model, world or collection, and explains how each grant was derived:
+// direct grant, organization membership, team or sysadmin. Only owners, admins
+// of the owner organization and sysadmins can request it.
+// If the 'user' query parameter is given, the handler evaluates the permissions
+// of that user and returns the reasoning chain instead. The returned value will
+// be of type "users.ResourcePermissions" or "users.PermissionExplanation".
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model-name}/permissions?user={username}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ResourcePermissions(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		resource, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		if !globals.Permissions.IsSystemAdmin(*user.Username) {
+			if ok, em := users.CanPerformWithRole(tx, owner, *user.Username, permissions.Admin); !ok {
+				return nil, em
+			}
+		}
+
+		private := isPrivateResource(resource)
+		if username := r.URL.Query().Get("user"); username != "" {
+			if _, em := users.ByUsername(tx, username, false); em != nil {
+				return nil, em
+			}
+			return users.ExplainPermissions(*resource.GetUUID(), private, username), nil
+		}
+		return users.GetResourcePermissions(tx, *resource.GetUUID(), private), nil
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"os"
+	"testing"
+)
+
+// TestModelPermissions tests the effective permissions of a model.
+func TestModelPermissions(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	testUser2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(testUser2, jwt2, t)
+	createTestModelWithOwner(t, &jwt, "private_model", testUser, true)
+
+	modelURI := modelURL(testUser, "private_model", "")
+	uri := modelURI + "/permissions"
+
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.AddCollaborator{Username: testUser2}))
+	gztest.AssertRouteMultipleArgs("POST", modelURI+"/collaborators", b, http.StatusOK, &jwt, ctJSON, t)
+
+	// Only the owner can inspect the permissions
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", uri, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	var perms users.ResourcePermissions
+	require.NoError(t, json.Unmarshal(*bslice, &perms))
+	assert.True(t, perms.Private)
+	grants := map[string][]string{}
+	for _, g := range perms.Grants {
+		if g.Source == users.GrantSourceDirect {
+			grants[g.Username] = append(grants[g.Username], g.Action)
+		}
+	}
+	assert.ElementsMatch(t, []string{"read", "write"}, grants[testUser])
+	assert.ElementsMatch(t, []string{"read"}, grants[testUser2])
+
+	// Explain the permissions of a single user
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri+"?user="+testUser2, nil, http.StatusOK, &jwt, ctJSON, t)
+	var exp users.PermissionExplanation
+	require.NoError(t, json.Unmarshal(*bslice, &exp))
+	assert.True(t, exp.Read)
+	assert.False(t, exp.Write)
+	assert.Contains(t, exp.Reasons, "User is granted [read] directly")
+}
+
+// TestOrganizationModelPermissions tests explaining the permissions of an
+// organization model, obtained through organization roles, teams and the system
+// admin role.
+func TestOrganizationModelPermissions(t *testing.T) {
+	// General test setup.
+	setup()
+	admin := createSysAdminUser(t)
+	defer removeUser(admin, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	member := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(member, jwt2, t)
+	jwt3 := createValidJWTForIdentity("another-user-2", t)
+	orgAdmin := createUserWithJWT(jwt3, t)
+	defer removeUserWithJWT(orgAdmin, jwt3, t)
+	jwt4 := createValidJWTForIdentity("another-user-3", t)
+	reviewer := createUserWithJWT(jwt4, t)
+	defer removeUserWithJWT(reviewer, jwt4, t)
+
+	org := createOrganization(t)
+	defer removeOrganization(org, t)
+	addUserToOrg(member, "member", org, t)
+	addUserToOrg(orgAdmin, "admin", org, t)
+	visible := true
+	addTeamToOrg(org, jwt, users.CreateTeamForm{Name: "reviewers", Visible: &visible}, t)
+	updateOrgTeam(org, "reviewers", jwt, users.UpdateTeamForm{NewUsers: []string{reviewer}}, t)
+	createTestModelWithOwner(t, &jwt, "private_model", org, true)
+	model := getOwnerModelFromDb(t, org, "private_model")
+
+	modelURI := modelURL(org, "private_model", "")
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.AddCollaborator{Organization: org, Team: "reviewers"}))
+	gztest.AssertRouteMultipleArgs("POST", modelURI+"/collaborators", b, http.StatusOK, &jwt, ctJSON, t)
+
+	explain := func(username string) users.PermissionExplanation {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", modelURI+"/permissions?user="+username, nil,
+			http.StatusOK, &jwt, ctJSON, t)
+		var exp users.PermissionExplanation
+		require.NoError(t, json.Unmarshal(*bslice, &exp))
+		return exp
+	}
+
+	// The explanations agree with the enforced permissions
+	for _, username := range []string{admin, member, orgAdmin, reviewer} {
+		exp := explain(username)
+		read, _ := globals.Permissions.IsAuthorized(username, *model.UUID, permissions.Read)
+		write, _ := globals.Permissions.IsAuthorized(username, *model.UUID, permissions.Write)
+		assert.Equal(t, read, exp.Read, username)
+		assert.Equal(t, write, exp.Write, username)
+	}
+
+	// Organization members can read the model, but their role does not allow
+	// writing it
+	exp := explain(member)
+	assert.True(t, exp.Read)
+	assert.False(t, exp.Write)
+	assert.Contains(t, exp.Reasons, fmt.Sprintf("User has role [member] in group [%s], which is granted [read]", org))
+	assert.Contains(t, exp.Reasons, fmt.Sprintf("User has role [member] in group [%s], which is granted [write], "+
+		"but the role does not allow it", org))
+
+	// Organization admins can write it
+	exp = explain(orgAdmin)
+	assert.True(t, exp.Read)
+	assert.True(t, exp.Write)
+	assert.Contains(t, exp.Reasons, fmt.Sprintf("User has role [admin] in group [%s], which is granted [write]", org))
+
+	// Team members are granted access through the team
+	exp = explain(reviewer)
+	assert.True(t, exp.Read)
+	assert.False(t, exp.Write)
+	assert.Contains(t, exp.Reasons, fmt.Sprintf("User has role [member] in group [%s_t_reviewers], "+
+		"which is granted [read]", org))
+
+	// System admins can read and write any resource
+	exp = explain(admin)
+	assert.True(t, exp.Read)
+	assert.True(t, exp.Write)
+	assert.Contains(t, exp.Reasons, "User is a system admin, and can [write] any resource")
+}
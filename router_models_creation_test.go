@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/fuel-server/proto"
@@ -202,6 +203,112 @@ func TestModelTransfer(t *testing.T) {
 			}
 		})
 	}
+
+	// Organization members can transfer their models to the organization
+	addUserToOrg(testUser, "member", testOrg, t)
+	createTestModelWithOwner(t, &anotherJwt, "member_model", testUser, false)
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(map[string]string{"destOwner": testOrg}))
+	gztest.AssertRouteMultipleArgs("POST", "/1.0/"+testUser+"/models/member_model/transfer", b,
+		http.StatusOK, &anotherJwt, ctJSON, t)
+	getOwnerModelFromDb(t, testOrg, "member_model")
+}
+
+// TestModelTransferRequest tests transferring a model with a request that the
+// destination owner must accept.
+func TestModelTransferRequest(t *testing.T) {
+	// General test setup
+	setup()
+
+	jwtDef := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+
+	anotherJwt := createValidJWTForIdentity("another-user", t)
+	testUser := createUserWithJWT(anotherJwt, t)
+	defer removeUserWithJWT(testUser, anotherJwt, t)
+
+	createThreeTestModels(t, &jwtDef)
+
+	propose := func(model, destOwner string, jwt *string, expStatus int) *commonres.TransferRequestResponse {
+		uri := fmt.Sprintf("/1.0/%s/models/%s/transfer-requests", username, model)
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(commonres.CreateTransferRequest{DestOwner: destOwner}))
+		ct := ctJSON
+		if expStatus != http.StatusOK {
+			ct = ctTextPlain
+		}
+		bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, b, expStatus, jwt, ct, t)
+		if expStatus != http.StatusOK {
+			return nil
+		}
+		var tr commonres.TransferRequestResponse
+		require.NoError(t, json.Unmarshal(*bslice, &tr))
+		return &tr
+	}
+	listTransfers := func(uri string, jwt *string) commonres.TransferRequestResponses {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, jwt, ctJSON, t)
+		var trs commonres.TransferRequestResponses
+		require.NoError(t, json.Unmarshal(*bslice, &trs))
+		return trs
+	}
+	unauthorized := gz.NewErrorMessage(gz.ErrorUnauthorized).StatusCode
+
+	// Only the owner can propose a transfer, to an existing owner
+	propose("model1", testUser, &anotherJwt, unauthorized)
+	propose("model1", "unknown-owner", &jwtDef, gz.NewErrorMessage(gz.ErrorUserUnknown).StatusCode)
+	propose("model1", username, &jwtDef, gz.NewErrorMessage(gz.ErrorFormInvalidValue).StatusCode)
+
+	tr := propose("model1", testUser, &jwtDef, http.StatusOK)
+	require.NotNil(t, tr)
+	assert.Equal(t, commonres.TransferPending, tr.Status)
+	// A resource can only have one pending transfer request
+	propose("model1", testUser, &jwtDef, gz.NewErrorMessage(gz.ErrorResourceExists).StatusCode)
+
+	// The request is listed for both owners, but the model did not move yet
+	outgoing := listTransfers(fmt.Sprintf("/1.0/%s/transfers?direction=outgoing", username), &jwtDef)
+	require.Len(t, outgoing, 1)
+	assert.Equal(t, tr.ID, outgoing[0].ID)
+	assert.Empty(t, listTransfers(fmt.Sprintf("/1.0/%s/transfers?direction=incoming", username), &jwtDef))
+	incoming := listTransfers(fmt.Sprintf("/1.0/%s/transfers", testUser), &anotherJwt)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, "model1", incoming[0].ResourceName)
+	assert.Equal(t, username, incoming[0].SourceOwner)
+	gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/%s/transfers", testUser), nil,
+		unauthorized, &jwtDef, ctTextPlain, t)
+	getOwnerModelFromDb(t, username, "model1")
+
+	// Only the destination owner can accept, and only the source owner can cancel
+	acceptURI := fmt.Sprintf("/1.0/%s/transfers/%d/accept", testUser, tr.ID)
+	gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, unauthorized, &jwtDef, ctTextPlain, t)
+	cancelURI := fmt.Sprintf("/1.0/%s/transfers/%d", testUser, tr.ID)
+	expEm := gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	gztest.AssertRouteMultipleArgs("DELETE", cancelURI, nil, expEm.StatusCode, &anotherJwt, ctTextPlain, t)
+
+	bslice, _ := gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, http.StatusOK, &anotherJwt, ctJSON, t)
+	var accepted commonres.TransferRequestResponse
+	require.NoError(t, json.Unmarshal(*bslice, &accepted))
+	assert.Equal(t, commonres.TransferAccepted, accepted.Status)
+	getOwnerModelFromDb(t, testUser, "model1")
+	assert.Empty(t, listTransfers(fmt.Sprintf("/1.0/%s/transfers", testUser), &anotherJwt))
+	// Accepted requests cannot be accepted again
+	gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, expEm.StatusCode, &anotherJwt, ctTextPlain, t)
+
+	// Rejected requests leave the model with its owner
+	tr = propose("model2", testUser, &jwtDef, http.StatusOK)
+	require.NotNil(t, tr)
+	rejectURI := fmt.Sprintf("/1.0/%s/transfers/%d/reject", testUser, tr.ID)
+	gztest.AssertRouteMultipleArgs("POST", rejectURI, nil, http.StatusOK, &anotherJwt, ctJSON, t)
+	getOwnerModelFromDb(t, username, "model2")
+
+	// Cancelled requests cannot be accepted
+	tr = propose("model2", testUser, &jwtDef, http.StatusOK)
+	require.NotNil(t, tr)
+	cancelURI = fmt.Sprintf("/1.0/%s/transfers/%d", username, tr.ID)
+	gztest.AssertRouteMultipleArgs("DELETE", cancelURI, nil, http.StatusOK, &jwtDef, ctJSON, t)
+	acceptURI = fmt.Sprintf("/1.0/%s/transfers/%d/accept", testUser, tr.ID)
+	gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, expEm.StatusCode, &anotherJwt, ctTextPlain, t)
+	getOwnerModelFromDb(t, username, "model2")
 }
 
 // TestModelClone tests cloning a model
@@ -284,6 +391,34 @@ func TestModelClone(t *testing.T) {
 	resp := gztest.AssertRouteMultipleArgsStruct(reqArgs, http.StatusOK, "application/zip", t)
 	assert.True(t, resp.Ok, "Model Zip Download request didn't succeed")
 
+	// test that the fork lineage was recorded
+	require.NotNil(t, m.ForkedFromID, "Cloned model should record its source")
+	assert.Equal(t, model.ID, *m.ForkedFromID)
+	require.NotNil(t, m.ForkedFromVersion, "Cloned model should record its source version")
+	assert.Equal(t, 1, *m.ForkedFromVersion)
+
+	getURI = "/1.0/" + *m.Owner + "/models/" + clonedModelName
+	reqArgs = gztest.RequestArgs{Method: "GET", Route: getURI, Body: nil, SignedToken: &jwt}
+	resp = gztest.AssertRouteMultipleArgsStruct(reqArgs, http.StatusOK, ctJSON, t)
+	var gotClone fuel.Model
+	require.NoError(t, json.Unmarshal(*resp.BodyAsBytes, &gotClone))
+	require.NotNil(t, gotClone.ForkedFrom, "Cloned model should return forked_from")
+	assert.Equal(t, testUser, gotClone.ForkedFrom.GetOwner())
+	assert.Equal(t, *model.Name, gotClone.ForkedFrom.GetName())
+	assert.EqualValues(t, 1, gotClone.ForkedFrom.GetVersion())
+
+	// test the list of forks of the source model
+	getURI = "/1.0/" + testUser + "/models/" + *model.Name + "/forks"
+	reqArgs = gztest.RequestArgs{Method: "GET", Route: getURI, Body: nil, SignedToken: &jwt}
+	resp = gztest.AssertRouteMultipleArgsStruct(reqArgs, http.StatusOK, ctJSON, t)
+	var forks []*fuel.Model
+	require.NoError(t, json.Unmarshal(*resp.BodyAsBytes, &forks))
+	forkNames := []string{}
+	for _, f := range forks {
+		forkNames = append(forkNames, f.GetName())
+	}
+	assert.Contains(t, forkNames, clonedModelName)
+
 	// Now test with a failing VCS repository mock
 	SetFailingVCSFactory()
 	serverErrorTests := []postTest{
@@ -479,3 +614,182 @@ func TestModelUpdate(t *testing.T) {
 		})
 	}
 }
+
+// TestModelRename tests renaming a model and the redirects from its old name.
+func TestModelRename(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	createThreeTestModels(t, &jwt)
+
+	uri := modelURL(testUser, "model1", "")
+	newURI := modelURL(testUser, "renamed", "")
+
+	// Renaming to an existing model name should fail
+	gotCode, _, ok := gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &jwt,
+		map[string]string{"name": "model2"}, nil)
+	assert.True(t, ok, "Could not perform multipart request")
+	assert.Equal(t, gz.NewErrorMessage(gz.ErrorResourceExists).StatusCode, gotCode)
+
+	gotCode, bslice, ok := gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &jwt,
+		map[string]string{"name": "renamed"}, nil)
+	assert.True(t, ok, "Could not perform multipart request")
+	require.Equal(t, http.StatusOK, gotCode, string(*bslice))
+	var gotModel fuel.Model
+	require.NoError(t, json.Unmarshal(*bslice, &gotModel))
+	assert.Equal(t, "renamed", gotModel.GetName())
+	getOwnerModelFromDb(t, testUser, "renamed")
+
+	// Requests to the old name are redirected to the new one
+	reqArgs := gztest.RequestArgs{Method: "GET", Route: uri + "?page=1", SignedToken: &jwt}
+	resp := gztest.AssertRouteMultipleArgsStruct(reqArgs, http.StatusMovedPermanently, ctHTML, t)
+	assert.Equal(t, newURI+"?page=1", resp.RespRecorder.Header().Get("Location"))
+	// Only the redirect is written, without a result
+	assert.NotContains(t, resp.RespRecorder.Body.String(), "null")
+	gztest.AssertRouteMultipleArgs("GET", newURI, nil, http.StatusOK, &jwt, ctJSON, t)
+
+	// Renaming back to the old name is allowed
+	gotCode, _, ok = gztest.SendMultipartMethod(t.Name(), t, "PATCH", newURI, &jwt,
+		map[string]string{"name": "model1"}, nil)
+	assert.True(t, ok, "Could not perform multipart request")
+	assert.Equal(t, http.StatusOK, gotCode)
+	gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	resp = gztest.AssertRouteMultipleArgsStruct(gztest.RequestArgs{Method: "GET", Route: newURI,
+		SignedToken: &jwt}, http.StatusMovedPermanently, ctHTML, t)
+	assert.Equal(t, uri, resp.RespRecorder.Header().Get("Location"))
+
+	// A new model can take the old name, and be renamed in turn
+	rename := func(uri, name string) {
+		gotCode, bslice, ok := gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &jwt,
+			map[string]string{"name": name}, nil)
+		assert.True(t, ok, "Could not perform multipart request")
+		require.Equal(t, http.StatusOK, gotCode, string(*bslice))
+	}
+	rename(uri, "renamed2")
+	createTestModelWithOwner(t, &jwt, "model1", testUser, false)
+	rename(uri, "renamed3")
+	resp = gztest.AssertRouteMultipleArgsStruct(gztest.RequestArgs{Method: "GET", Route: uri,
+		SignedToken: &jwt}, http.StatusMovedPermanently, ctHTML, t)
+	assert.Equal(t, modelURL(testUser, "renamed3", ""), resp.RespRecorder.Header().Get("Location"))
+
+	// The new name of a private model is not revealed to other users
+	createTestModelWithOwner(t, &jwt, "secret", testUser, true)
+	rename(modelURL(testUser, "secret", ""), "secret2")
+	notFound := gz.NewErrorMessage(gz.ErrorNameNotFound)
+	gztest.AssertRouteMultipleArgs("GET", modelURL(testUser, "secret", ""), nil, notFound.StatusCode, nil, ctTextPlain, t)
+	gztest.AssertRouteMultipleArgsStruct(gztest.RequestArgs{Method: "GET", Route: modelURL(testUser, "secret", ""),
+		SignedToken: &jwt}, http.StatusMovedPermanently, ctHTML, t)
+}
+
+// TestModelArchive tests archiving a model and the restrictions on archived models.
+func TestModelArchive(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	createThreeTestModels(t, &jwt)
+
+	uri := modelURL(testUser, "model1", "")
+	archiveParams := map[string]string{"archived": "true"}
+	files := []gztest.FileDesc{
+		{Path: "model.config", Contents: constModelConfigFileContents},
+	}
+
+	// Other users cannot archive the model
+	otherJWT := createValidJWTForIdentity("another-user", t)
+	otherUser := createUserWithJWT(otherJWT, t)
+	defer removeUserWithJWT(otherUser, otherJWT, t)
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	gotCode, _, ok := gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &otherJWT, archiveParams, nil)
+	assert.True(t, ok, "Could not perform multipart request")
+	assert.Equal(t, expEm.StatusCode, gotCode)
+
+	gotCode, bslice, ok := gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &jwt, archiveParams, nil)
+	assert.True(t, ok, "Could not perform multipart request")
+	require.Equal(t, http.StatusOK, gotCode, string(*bslice))
+	var gotModel fuel.Model
+	require.NoError(t, json.Unmarshal(*bslice, &gotModel))
+	assert.True(t, gotModel.GetArchived())
+
+	// Files of archived models cannot be changed, but they can be downloaded
+	gotCode, bslice, ok = gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &jwt, nil, files)
+	assert.True(t, ok, "Could not perform multipart request")
+	require.Equal(t, expEm.StatusCode, gotCode)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+	gztest.AssertRouteMultipleArgs("GET", modelURL(testUser, "model1", "1")+".zip", nil, http.StatusOK, &jwt, "application/zip", t)
+
+	// Filter the model list by archived state
+	var list []*fuel.Model
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/%s/%s/models?archived=true", apiVersion, testUser), nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "model1", list[0].GetName())
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/%s/%s/models?archived=false", apiVersion, testUser), nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	assert.Len(t, list, 2)
+
+	// Unarchive the model. Files can be changed again.
+	gotCode, _, _ = gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &jwt, map[string]string{"archived": "false"}, nil)
+	require.Equal(t, http.StatusOK, gotCode)
+	gotCode, _, _ = gztest.SendMultipartMethod(t.Name(), t, "PATCH", uri, &jwt, nil, files)
+	assert.Equal(t, http.StatusOK, gotCode)
+}
+
+// TestModelDeprecation tests deprecating a model with a successor.
+func TestModelDeprecation(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	createThreeTestModels(t, &jwt)
+
+	uri := modelURL(testUser, "model1", "") + "/deprecation"
+
+	// A model cannot be its own successor
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.DeprecateResource{SuccessorName: "model1"}))
+	expEm := gz.NewErrorMessage(gz.ErrorFormInvalidValue)
+	bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, b, expEm.StatusCode, &jwt, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.DeprecateResource{
+		Message: "Use model2 instead", SuccessorName: "model2"}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, b, http.StatusOK, &jwt, ctJSON, t)
+	var gotModel fuel.Model
+	require.NoError(t, json.Unmarshal(*bslice, &gotModel))
+	assert.True(t, gotModel.GetDeprecated())
+	assert.Equal(t, "Use model2 instead", gotModel.GetDeprecationMessage())
+	require.NotNil(t, gotModel.Successor)
+	assert.Equal(t, "model2", gotModel.Successor.GetName())
+	assert.Equal(t, testUser, gotModel.Successor.GetOwner())
+
+	// Downloads include the deprecation headers
+	reqArgs := gztest.RequestArgs{Method: "GET", Route: modelURL(testUser, "model1", "1") + ".zip", SignedToken: &jwt}
+	resp := gztest.AssertRouteMultipleArgsStruct(reqArgs, http.StatusOK, "application/zip", t)
+	assert.Equal(t, "Use model2 instead", resp.RespRecorder.Header().Get("X-Ign-Resource-Deprecated"))
+	assert.Equal(t, testUser+"/model2", resp.RespRecorder.Header().Get("X-Ign-Resource-Successor"))
+
+	// Deprecated models rank lower, unless explicitly requested. Note: model1
+	// is the oldest model, so it would come first in ascending order.
+	var list []*fuel.Model
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/%s/%s/models?order=asc", apiVersion, testUser), nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	require.Len(t, list, 3)
+	assert.Equal(t, "model1", list[2].GetName())
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/%s/%s/models?deprecated=true", apiVersion, testUser), nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "model1", list[0].GetName())
+
+	// Remove the deprecation
+	bslice, _ = gztest.AssertRouteMultipleArgs("DELETE", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	gotModel = fuel.Model{}
+	require.NoError(t, json.Unmarshal(*bslice, &gotModel))
+	assert.False(t, gotModel.GetDeprecated())
+	assert.Nil(t, gotModel.Successor)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gazebo-web/gz-go/v7"
@@ -12,8 +13,11 @@ import (
 	"path"
 	"strconv"
 	"testing"
+	"time"
 
 	mocket "github.com/Selvatico/go-mocket"
+	"github.com/gazebo-web/fuel-server/bundles/comments"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
 	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/globals"
 	fuel "github.com/gazebo-web/fuel-server/proto"
@@ -789,3 +793,99 @@ func TestReportModelCreate(t *testing.T) {
 	gztest.SendMultipartPOST("ReportModelCreate", t, uri, nil, body, nil)
 	gztest.SendMultipartPOST("ReportModelCreate", t, uri, &jwt, body, nil)
 }
+
+// TestModelTrashAndRestore tests listing deleted models and restoring them.
+func TestModelTrashAndRestore(t *testing.T) {
+	// General test setup
+	setup()
+	jwt := os.Getenv("IGN_TEST_JWT")
+
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	createThreeTestModels(t, nil)
+
+	uri := modelURL(testUser, "model1", "")
+	trashURI := fmt.Sprintf("/%s/%s/trash", apiVersion, testUser)
+
+	// Delete the model and check it is in the trash
+	gztest.AssertRouteMultipleArgs("DELETE", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", trashURI, nil, http.StatusOK, &jwt, ctJSON, t)
+	var trash []commonres.TrashItem
+	require.NoError(t, json.Unmarshal(*bslice, &trash))
+	require.Len(t, trash, 1)
+	assert.Equal(t, "model", trash[0].Type)
+	assert.Equal(t, "model1", trash[0].Name)
+
+	// Other users cannot see the trash
+	otherJWT := createValidJWTForIdentity("another-user", t)
+	otherUser := createUserWithJWT(otherJWT, t)
+	defer removeUserWithJWT(otherUser, otherJWT, t)
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", trashURI, nil, expEm.StatusCode, &otherJWT, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	// Restore the model
+	gztest.AssertRouteMultipleArgs("POST", uri+"/restore", nil, http.StatusOK, &jwt, ctJSON, t)
+	gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", trashURI, nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &trash))
+	assert.Len(t, trash, 0)
+
+	// Delete it again, and create a new model with the same name. Restoring
+	// should fail unless a new name is given.
+	gztest.AssertRouteMultipleArgs("DELETE", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	createTestModelWithOwner(t, &jwt, "model1", testUser, false)
+	expEm = gz.NewErrorMessage(gz.ErrorResourceExists)
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri+"/restore", nil, expEm.StatusCode, &jwt, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.RestoreResource{Name: "restored"}))
+	gztest.AssertRouteMultipleArgs("POST", uri+"/restore", b, http.StatusOK, &jwt, ctJSON, t)
+	restored := getOwnerModelFromDb(t, testUser, "restored")
+	assert.Nil(t, restored.DeletedAt)
+
+	// Restoring a model that is not in the trash should fail
+	expEm = gz.NewErrorMessage(gz.ErrorNameNotFound)
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", modelURL(testUser, "model2", "")+"/restore", nil, expEm.StatusCode, &jwt, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+}
+
+// TestModelTrashPurge tests permanently removing the models past the trash
+// retention window.
+func TestModelTrashPurge(t *testing.T) {
+	// General test setup
+	setup()
+	jwt := os.Getenv("IGN_TEST_JWT")
+
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	createThreeTestModels(t, nil)
+	model := getOwnerModelFromDb(t, testUser, "model1")
+	uri := modelURL(testUser, "model1", "")
+
+	// Comment on the model, then delete it
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(comments.CreateComment{Body: "Nice model"}))
+	gztest.AssertRouteMultipleArgs("POST", uri+"/comments", b, http.StatusOK, &jwt, ctJSON, t)
+	gztest.AssertRouteMultipleArgs("DELETE", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+
+	// Models still in the trash are not purged
+	db := globals.Server.Db
+	require.Nil(t, purgeExpiredTrash(context.Background(), db))
+	var count int
+	db.Unscoped().Model(&models.Model{}).Where("id = ?", model.ID).Count(&count)
+	assert.Equal(t, 1, count)
+
+	// Expired models are removed, with their files and the rows referencing them
+	expired := time.Now().Add(-globals.TrashRetention - time.Hour)
+	require.NoError(t, db.Unscoped().Model(&models.Model{}).Where("id = ?", model.ID).
+		UpdateColumn("deleted_at", expired).Error)
+	require.Nil(t, purgeExpiredTrash(context.Background(), db))
+	db.Unscoped().Model(&models.Model{}).Where("id = ?", model.ID).Count(&count)
+	assert.Equal(t, 0, count)
+	db.Model(&comments.Comment{}).Where("resource_uuid = ?", *model.UUID).Count(&count)
+	assert.Equal(t, 0, count)
+	_, err := os.Stat(*model.Location)
+	assert.True(t, os.IsNotExist(err), "The model repository should be removed")
+}
@@ -2,15 +2,20 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/audit"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/gz-go/v7"
 	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
@@ -1301,3 +1306,448 @@ func TestOrganizationTeamUpdate(t *testing.T) {
 		})
 	}
 }
+
+// TestOrganizationServiceAccounts tests creating, listing and removing service
+// accounts of an organization, and using their access tokens.
+func TestOrganizationServiceAccounts(t *testing.T) {
+	setup()
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	testOrg := createOrganization(t)
+	defer removeOrganization(testOrg, t)
+	// create a member of the organization
+	jwt2 := createValidJWTForIdentity("another-user-2", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+	addUserToOrg(user2, "member", testOrg, t)
+
+	uri := fmt.Sprintf("/1.0/organizations/%s/service-accounts", testOrg)
+	input := users.CreateServiceAccount{Username: "cibot", Name: "CI"}
+
+	// Org members cannot create service accounts
+	body := new(bytes.Buffer)
+	assert.NoError(t, json.NewEncoder(body).Encode(input))
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, body, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	body = new(bytes.Buffer)
+	assert.NoError(t, json.NewEncoder(body).Encode(input))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, body, http.StatusOK, &myJWT, ctJSON, t)
+	var sa users.UserResponse
+	require.NoError(t, json.Unmarshal(*bslice, &sa))
+	assert.Equal(t, "cibot", sa.Username)
+	assert.Equal(t, testOrg, sa.ServiceAccountOrg)
+
+	// Service accounts are listed by the org, but not in the org users
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &jwt2, ctJSON, t)
+	var list users.UserResponses
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "cibot", list[0].Username)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", fmt.Sprintf("/1.0/organizations/%s/users", testOrg),
+		nil, http.StatusOK, &myJWT, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	for _, u := range list {
+		assert.NotEqual(t, "cibot", u.Username)
+	}
+
+	// Create an access token for the service account
+	body = new(bytes.Buffer)
+	assert.NoError(t, json.NewEncoder(body).Encode(map[string]interface{}{"name": "ci"}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri+"/cibot/access-tokens", body,
+		http.StatusOK, &myJWT, ctJSON, t)
+	var token gz.AccessTokenCreateResponse
+	require.NoError(t, json.Unmarshal(*bslice, &token))
+
+	sendWithToken := func(method, route string) int {
+		req, _ := http.NewRequest(method, route, nil)
+		req.Header.Set("Private-Token", token.Key)
+		respRec := httptest.NewRecorder()
+		globals.Server.Router.ServeHTTP(respRec, req)
+		return respRec.Code
+	}
+	assert.Equal(t, http.StatusOK, sendWithToken("GET", uri))
+
+	// Remove the service account. Its token stops working.
+	gztest.AssertRouteMultipleArgs("DELETE", uri+"/cibot", nil, http.StatusOK, &myJWT, ctJSON, t)
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken("GET", uri))
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &myJWT, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &list))
+	assert.Len(t, list, 0)
+}
+
+// TestOrganizationCustomRoles tests creating custom roles and the actions
+// users with those roles can perform on the organization resources.
+func TestOrganizationCustomRoles(t *testing.T) {
+	setup()
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	testOrg := createOrganization(t)
+	defer removeOrganization(testOrg, t)
+	jwtViewer := createValidJWTForIdentity("another-user-2", t)
+	viewer := createUserWithJWT(jwtViewer, t)
+	defer removeUserWithJWT(viewer, jwtViewer, t)
+	jwtMaintainer := createValidJWTForIdentity("another-user-3", t)
+	maintainer := createUserWithJWT(jwtMaintainer, t)
+	defer removeUserWithJWT(maintainer, jwtMaintainer, t)
+
+	uri := fmt.Sprintf("/1.0/organizations/%s/roles", testOrg)
+	createRole := func(cor users.CreateOrganizationRole, expStatus int) {
+		b := new(bytes.Buffer)
+		assert.NoError(t, json.NewEncoder(b).Encode(cor))
+		ct := ctJSON
+		if expStatus != http.StatusOK {
+			ct = ctTextPlain
+		}
+		gztest.AssertRouteMultipleArgs("POST", uri, b, expStatus, &myJWT, ct, t)
+	}
+	// Custom roles cannot be named like built-in roles
+	createRole(users.CreateOrganizationRole{Name: "admin", Actions: []string{"read"}},
+		gz.NewErrorMessage(gz.ErrorFormInvalidValue).StatusCode)
+	createRole(users.CreateOrganizationRole{Name: "viewer", Actions: []string{"read"}}, http.StatusOK)
+	createRole(users.CreateOrganizationRole{Name: "maintainer", Actions: []string{"read", "write"}},
+		http.StatusOK)
+
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &myJWT, ctJSON, t)
+	var roles users.OrganizationRoleResponses
+	require.NoError(t, json.Unmarshal(*bslice, &roles))
+	require.Len(t, roles, 2)
+	assert.Equal(t, "maintainer", roles[0].Name)
+	assert.Equal(t, []string{"read", "write"}, roles[0].Actions)
+
+	addUserToOrg(viewer, "viewer", testOrg, t)
+	addUserToOrg(maintainer, "maintainer", testOrg, t)
+	createTestModelWithOwner(t, &myJWT, "model1", testOrg, true)
+	model := modelURL(testOrg, "model1", "")
+
+	// Both can read the private model
+	gztest.AssertRouteMultipleArgs("GET", model, nil, http.StatusOK, &jwtViewer, ctJSON, t)
+	gztest.AssertRouteMultipleArgs("GET", model, nil, http.StatusOK, &jwtMaintainer, ctJSON, t)
+
+	// Only the maintainer can update it
+	gotCode, _, ok := gztest.SendMultipartMethod(t.Name(), t, "PATCH", model, &jwtViewer,
+		map[string]string{"description": "viewer"}, nil)
+	assert.True(t, ok, "Could not perform multipart request")
+	assert.Equal(t, http.StatusUnauthorized, gotCode)
+	gotCode, _, ok = gztest.SendMultipartMethod(t.Name(), t, "PATCH", model, &jwtMaintainer,
+		map[string]string{"description": "maintainer"}, nil)
+	assert.True(t, ok, "Could not perform multipart request")
+	assert.Equal(t, http.StatusOK, gotCode)
+
+	// But the maintainer cannot delete it, nor manage members
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ = gztest.AssertRouteMultipleArgs("DELETE", model, nil, expEm.StatusCode, &jwtMaintainer, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+	b := new(bytes.Buffer)
+	assert.NoError(t, json.NewEncoder(b).Encode(users.AddUserToOrgInput{Username: username, Role: "viewer"}))
+	gztest.AssertRouteMultipleArgs("POST", fmt.Sprintf("/1.0/organizations/%s/users", testOrg), b,
+		expEm.StatusCode, &jwtMaintainer, ctTextPlain, t)
+
+	// Roles in use cannot be removed
+	gztest.AssertRouteMultipleArgs("DELETE", uri+"/viewer", nil,
+		gz.NewErrorMessage(gz.ErrorFormInvalidValue).StatusCode, &myJWT, ctTextPlain, t)
+	gztest.AssertRouteMultipleArgs("DELETE", fmt.Sprintf("/1.0/organizations/%s/users/%s", testOrg, viewer),
+		nil, http.StatusOK, &myJWT, ctJSON, t)
+	gztest.AssertRouteMultipleArgs("DELETE", uri+"/viewer", nil, http.StatusOK, &myJWT, ctJSON, t)
+	gztest.AssertRouteMultipleArgs("GET", model, nil, expEm.StatusCode, &jwtViewer, ctTextPlain, t)
+}
+
+// TestOrganizationAudit tests that membership changes are recorded in the
+// organization audit log, and that only owners can see it.
+func TestOrganizationAudit(t *testing.T) {
+	setup()
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	testOrg := createOrganization(t)
+	defer removeOrganization(testOrg, t)
+	jwt2 := createValidJWTForIdentity("another-user-2", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+
+	addUserToOrg(user2, "member", testOrg, t)
+	uri := fmt.Sprintf("/1.0/organizations/%s/audit", testOrg)
+
+	// Members cannot see the audit log
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", uri, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	// Remove the member
+	gztest.AssertRouteMultipleArgs("DELETE", fmt.Sprintf("/1.0/organizations/%s/users/%s", testOrg, user2),
+		nil, http.StatusOK, &myJWT, ctJSON, t)
+
+	target := fmt.Sprintf("/organizations/%s/users", testOrg)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri+"?target="+target, nil,
+		http.StatusOK, &myJWT, ctJSON, t)
+	var entries audit.Entries
+	require.NoError(t, json.Unmarshal(*bslice, &entries))
+	require.Len(t, entries, 2)
+	// Newest first
+	removal := entries[0]
+	assert.Equal(t, "OrganizationUserUpdate", removal.Action)
+	assert.Equal(t, username, removal.Actor)
+	assert.Equal(t, "DELETE", removal.Method)
+	assert.Equal(t, target+"/"+user2, removal.Target)
+	assert.Equal(t, testOrg, removal.Owner)
+	assert.Equal(t, http.StatusOK, removal.Status)
+	assert.NotEmpty(t, removal.RequestID)
+	assert.JSONEq(t, fmt.Sprintf(`{"username":"%s","role":"member"}`, user2), removal.Before)
+	assert.Contains(t, removal.After, user2)
+	assert.Equal(t, "OrganizationUsers", entries[1].Action)
+	assert.JSONEq(t, fmt.Sprintf(`{"username":"%s","role":""}`, user2), entries[1].Before)
+
+	// Read-only requests are not recorded
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri+"?action=OrganizationAudit", nil,
+		http.StatusOK, &myJWT, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &entries))
+	assert.Empty(t, entries)
+}
+
+// TestOrganizationInvitations tests inviting users to an organization, and
+// accepting, declining and revoking invitations.
+func TestOrganizationInvitations(t *testing.T) {
+	setup()
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	testOrg := createOrganization(t)
+	defer removeOrganization(testOrg, t)
+	visible := true
+	addTeamToOrg(testOrg, myJWT, users.CreateTeamForm{Name: "team1", Visible: &visible}, t)
+	jwt2 := createValidJWTForIdentity("another-user-2", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+	jwt3 := createValidJWTForIdentity("another-user-3", t)
+	user3 := createUserWithJWT(jwt3, t)
+	defer removeUserWithJWT(user3, jwt3, t)
+
+	orgURI := fmt.Sprintf("/1.0/organizations/%s/invitations", testOrg)
+	invite := func(ci users.CreateInvitation, expStatus int) *users.InvitationResponse {
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(ci))
+		ct := ctJSON
+		if expStatus != http.StatusOK {
+			ct = ctTextPlain
+		}
+		bslice, _ := gztest.AssertRouteMultipleArgs("POST", orgURI, b, expStatus, &myJWT, ct, t)
+		if expStatus != http.StatusOK {
+			return nil
+		}
+		var inv users.InvitationResponse
+		require.NoError(t, json.Unmarshal(*bslice, &inv))
+		return &inv
+	}
+	listInvitations := func(uri string, jwt *string) users.InvitationResponses {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, jwt, ctJSON, t)
+		var invs users.InvitationResponses
+		require.NoError(t, json.Unmarshal(*bslice, &invs))
+		return invs
+	}
+	userURI := func(user string) string {
+		return fmt.Sprintf("/1.0/users/%s/invitations", user)
+	}
+
+	inv := invite(users.CreateInvitation{Username: user2, Role: "admin", Teams: []string{"team1"}},
+		http.StatusOK)
+	require.NotNil(t, inv)
+	assert.Equal(t, users.InvitationPending, inv.Status)
+	assert.Equal(t, []string{"team1"}, inv.Teams)
+	// Users cannot be invited twice, nor to unknown teams
+	invite(users.CreateInvitation{Username: user2, Role: "member"},
+		gz.NewErrorMessage(gz.ErrorResourceExists).StatusCode)
+	invite(users.CreateInvitation{Username: user3, Role: "member", Teams: []string{"unknown"}},
+		gz.NewErrorMessage(gz.ErrorNameNotFound).StatusCode)
+
+	// The invitee was not added yet
+	assert.False(t, globals.Permissions.UserBelongsToGroup(user2, testOrg))
+	assert.Len(t, listInvitations(orgURI, &myJWT), 1)
+	invs := listInvitations(userURI(user2), &jwt2)
+	require.Len(t, invs, 1)
+	assert.Equal(t, testOrg, invs[0].Organization)
+	assert.Equal(t, username, invs[0].Inviter)
+
+	// Only the invitee can accept the invitation
+	acceptURI := fmt.Sprintf("%s/%d/accept", userURI(user2), inv.ID)
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ := gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, expEm.StatusCode, &jwt3, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+	gztest.AssertRouteMultipleArgs("GET", userURI(user2), nil, expEm.StatusCode, &jwt3, ctTextPlain, t)
+
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, http.StatusOK, &jwt2, ctJSON, t)
+	var accepted users.InvitationResponse
+	require.NoError(t, json.Unmarshal(*bslice, &accepted))
+	assert.Equal(t, users.InvitationAccepted, accepted.Status)
+	assert.Equal(t, "admin", globals.Permissions.GetGroupsAndRolesForUser(user2)[testOrg])
+	assert.True(t, globals.Permissions.UserBelongsToGroup(user2, users.CasbinNameForTeam(testOrg, "team1")))
+	assert.Empty(t, listInvitations(userURI(user2), &jwt2))
+	// Accepted invitations cannot be accepted again
+	expEm = gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+
+	// Revoked invitations cannot be accepted
+	inv = invite(users.CreateInvitation{Username: user3, Role: "member"}, http.StatusOK)
+	require.NotNil(t, inv)
+	gztest.AssertRouteMultipleArgs("DELETE", fmt.Sprintf("%s/%d", orgURI, inv.ID), nil,
+		http.StatusOK, &myJWT, ctJSON, t)
+	assert.Empty(t, listInvitations(userURI(user3), &jwt3))
+	gztest.AssertRouteMultipleArgs("POST", fmt.Sprintf("%s/%d/accept", userURI(user3), inv.ID), nil,
+		expEm.StatusCode, &jwt3, ctTextPlain, t)
+
+	// Declined invitations do not add the user
+	inv = invite(users.CreateInvitation{Username: user3, Role: "member"}, http.StatusOK)
+	require.NotNil(t, inv)
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", fmt.Sprintf("%s/%d/decline", userURI(user3), inv.ID),
+		nil, http.StatusOK, &jwt3, ctJSON, t)
+	var declined users.InvitationResponse
+	require.NoError(t, json.Unmarshal(*bslice, &declined))
+	assert.Equal(t, users.InvitationDeclined, declined.Status)
+	assert.False(t, globals.Permissions.UserBelongsToGroup(user3, testOrg))
+
+	// Invitations sent by email cannot be claimed by changing the user email
+	inviter, em := getUserFromDb(username, t)
+	require.Nil(t, em)
+	email := "invitee@example.com"
+	emailInv, em := (&users.OrganizationService{}).CreateInvitation(context.Background(),
+		globals.Server.Db, testOrg, users.CreateInvitation{Email: email, Role: "owner"}, inviter)
+	require.Nil(t, em)
+	require.NotEmpty(t, emailInv.Token)
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(users.UpdateUserInput{Email: &email}))
+	gztest.AssertRouteMultipleArgs("PATCH", "/1.0/users/"+user3, b, http.StatusOK, &jwt3, ctJSON, t)
+	assert.Empty(t, listInvitations(userURI(user3), &jwt3))
+	emailAcceptURI := fmt.Sprintf("%s/%d/accept", userURI(user3), emailInv.ID)
+	gztest.AssertRouteMultipleArgs("POST", emailAcceptURI, nil, expEm.StatusCode, &jwt3, ctTextPlain, t)
+	gztest.AssertRouteMultipleArgs("POST", emailAcceptURI+"?token=invalid", nil, expEm.StatusCode,
+		&jwt3, ctTextPlain, t)
+	assert.False(t, globals.Permissions.UserBelongsToGroup(user3, testOrg))
+
+	// The token mailed to the address accepts the invitation, only once
+	tokenAcceptURI := emailAcceptURI + "?token=" + emailInv.Token
+	gztest.AssertRouteMultipleArgs("POST", tokenAcceptURI, nil, http.StatusOK, &jwt3, ctJSON, t)
+	assert.Equal(t, "owner", globals.Permissions.GetGroupsAndRolesForUser(user3)[testOrg])
+	gztest.AssertRouteMultipleArgs("POST", tokenAcceptURI, nil, expEm.StatusCode, &jwt3, ctTextPlain, t)
+}
+
+// TestOrganizationWebhooks tests the webhooks of an organization, and the
+// delivery of signed payloads.
+func TestOrganizationWebhooks(t *testing.T) {
+	setup()
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	testOrg := createOrganization(t)
+	defer removeOrganization(testOrg, t)
+	jwt2 := createValidJWTForIdentity("another-user-2", t)
+	user2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(user2, jwt2, t)
+	addUserToOrg(user2, "member", testOrg, t)
+
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+	}))
+	defer server.Close()
+
+	uri := fmt.Sprintf("/1.0/organizations/%s/webhooks", testOrg)
+	secret := "a-very-secret-webhook-key"
+	create := func(jwt *string, cw webhooks.CreateWebhook, expStatus int) *webhooks.Webhook {
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(cw))
+		ct := ctJSON
+		if expStatus != http.StatusOK {
+			ct = ctTextPlain
+		}
+		bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, b, expStatus, jwt, ct, t)
+		if expStatus != http.StatusOK {
+			return nil
+		}
+		var wh webhooks.Webhook
+		require.NoError(t, json.Unmarshal(*bslice, &wh))
+		return &wh
+	}
+
+	// Members cannot manage the webhooks
+	create(&jwt2, webhooks.CreateWebhook{URL: server.URL, Secret: secret},
+		gz.NewErrorMessage(gz.ErrorUnauthorized).StatusCode)
+	invalid := gz.NewErrorMessage(gz.ErrorFormInvalidValue).StatusCode
+	create(&myJWT, webhooks.CreateWebhook{URL: server.URL, Secret: secret,
+		Events: []string{"model.unknown"}}, invalid)
+	create(&myJWT, webhooks.CreateWebhook{URL: "ftp://example.com", Secret: secret}, invalid)
+	create(&myJWT, webhooks.CreateWebhook{URL: server.URL, Secret: "short"}, invalid)
+	// Webhooks cannot reach the internal network or the metadata service
+	create(&myJWT, webhooks.CreateWebhook{URL: "http://169.254.169.254/latest", Secret: secret}, invalid)
+	create(&myJWT, webhooks.CreateWebhook{URL: server.URL, Secret: secret}, invalid)
+
+	// unless it is explicitly allowed, as the test server is in the loopback network
+	var err error
+	globals.WebhookAllowedNetworks, err = parseNetworks("127.0.0.0/8")
+	require.NoError(t, err)
+	defer func() { globals.WebhookAllowedNetworks = nil }()
+	wh := create(&myJWT, webhooks.CreateWebhook{URL: server.URL, Secret: secret,
+		Events: []string{"model.create"}}, http.StatusOK)
+	assert.Equal(t, []string{"model.create"}, wh.Events)
+	assert.True(t, wh.Active)
+
+	// Creating a model of the organization sends a signed payload
+	createTestModelWithOwner(t, &myJWT, "webhook_model", testOrg, false)
+	require.Nil(t, sendWebhookDeliveries(context.Background(), globals.Server.Db))
+	require.Len(t, received, 1)
+	assert.Equal(t, "model.create", received[0].Header.Get(webhooks.EventHeader))
+	assert.Equal(t, webhooks.Sign(secret, bodies[0]), received[0].Header.Get(webhooks.SignatureHeader))
+	var payload webhooks.Payload
+	require.NoError(t, json.Unmarshal(bodies[0], &payload))
+	assert.Equal(t, "model.create", payload.Event)
+	assert.Equal(t, username, payload.Actor)
+	assert.Equal(t, testOrg, payload.Owner)
+	assert.Equal(t, "webhook_model", payload.Name)
+
+	deliveriesURI := fmt.Sprintf("%s/%d/deliveries", uri, wh.ID)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", deliveriesURI, nil, http.StatusOK, &myJWT, ctJSON, t)
+	var deliveries webhooks.Deliveries
+	require.NoError(t, json.Unmarshal(*bslice, &deliveries))
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhooks.DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+
+	// A delivery is sent once, even if several servers find it due at once
+	createTestModelWithOwner(t, &myJWT, "webhook_model2", testOrg, false)
+	due, em := webhooks.DueDeliveries(globals.Server.Db, 10)
+	require.Nil(t, em)
+	require.Len(t, due, 1)
+	other := due[0]
+	require.Nil(t, webhooks.Deliver(globals.Server.Db, &due[0]))
+	require.Nil(t, webhooks.Deliver(globals.Server.Db, &other))
+	assert.Len(t, received, 2)
+
+	// Inactive webhooks don't fire, and their pending deliveries fail
+	createTestModelWithOwner(t, &myJWT, "webhook_model3", testOrg, false)
+	active := false
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(webhooks.UpdateWebhook{Active: &active}))
+	whURI := fmt.Sprintf("%s/%d", uri, wh.ID)
+	gztest.AssertRouteMultipleArgs("PATCH", whURI, b, http.StatusOK, &myJWT, ctJSON, t)
+	createTestModelWithOwner(t, &myJWT, "webhook_model4", testOrg, false)
+	require.Nil(t, sendWebhookDeliveries(context.Background(), globals.Server.Db))
+	assert.Len(t, received, 2)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", deliveriesURI, nil, http.StatusOK, &myJWT, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &deliveries))
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, webhooks.DeliveryFailed, deliveries[0].Status)
+
+	// Remove the webhook and its delivery log
+	gztest.AssertRouteMultipleArgs("DELETE", whURI, nil, http.StatusOK, &myJWT, ctJSON, t)
+	expEm := gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	gztest.AssertRouteMultipleArgs("GET", deliveriesURI, nil, expEm.StatusCode, &myJWT, ctTextPlain, t)
+
+	for _, name := range []string{"webhook_model", "webhook_model2", "webhook_model3", "webhook_model4"} {
+		gztest.AssertRouteMultipleArgs("DELETE", modelURL(testOrg, name, ""), nil, http.StatusOK, &myJWT, ctJSON, t)
+	}
+}
@@ -1,19 +1,29 @@
 package main
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/gazebo-web/fuel-server/bundles/activity"
+	"github.com/gazebo-web/fuel-server/bundles/audit"
+	"github.com/gazebo-web/fuel-server/bundles/models"
 	"github.com/gazebo-web/fuel-server/bundles/users"
 	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/fuel-server/proto"
 	"github.com/gazebo-web/gz-go/v7"
 	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 // Tests for user related routes
@@ -269,6 +279,178 @@ func TestRemoveUser(t *testing.T) {
 	}
 }
 
+// TestUserAccountDeletion tests exporting the data of a user, and deleting the
+// account with a hand-off of its resources.
+func TestUserAccountDeletion(t *testing.T) {
+	setup()
+
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	testOrg := createOrganization(t)
+	defer removeOrganization(testOrg, t)
+
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	username2 := createUserWithJWT(jwt2, t)
+	addUserToOrg(username2, "admin", testOrg, t)
+	createThreeTestModels(t, &jwt2)
+
+	// Only the user can export its data
+	exportURI := fmt.Sprintf("/1.0/users/%s/export", username2)
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	gztest.AssertRouteMultipleArgs("GET", exportURI, nil, expEm.StatusCode, &myJWT, ctTextPlain, t)
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", exportURI, nil, http.StatusOK, &jwt2, ctZip, t)
+	zr, err := zip.NewReader(bytes.NewReader(*bslice), int64(len(*bslice)))
+	require.NoError(t, err)
+	files := make(map[string]bool)
+	for _, f := range zr.File {
+		files[f.Name] = true
+	}
+	for _, name := range []string{"profile.json", "organizations.json", "likes.json",
+		"reviews.json", "access_tokens.json", "model/model1/metadata.json", "model/model1/files.zip"} {
+		assert.True(t, files[name], "Missing file in export: %s", name)
+	}
+
+	deleteAccount := func(jwt *string, ad AccountDeletion, expStatus int) *AccountDeletionResponse {
+		uri := fmt.Sprintf("/1.0/users/%s/account-deletion", username2)
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(ad))
+		ct := ctJSON
+		if expStatus != http.StatusOK {
+			ct = ctTextPlain
+		}
+		bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, b, expStatus, jwt, ct, t)
+		if expStatus != http.StatusOK {
+			return nil
+		}
+		var response AccountDeletionResponse
+		require.NoError(t, json.Unmarshal(*bslice, &response))
+		return &response
+	}
+	dispositions := []ResourceDisposition{
+		{Type: "model", Name: "model1", Action: DispositionTransfer, DestOwner: testOrg},
+		{Type: "model", Name: "model2", Action: DispositionTransfer, DestOwner: username},
+	}
+
+	// Each owned resource needs a disposition
+	invalid := gz.NewErrorMessage(gz.ErrorFormInvalidValue).StatusCode
+	deleteAccount(&myJWT, AccountDeletion{Resources: dispositions}, expEm.StatusCode)
+	deleteAccount(&jwt2, AccountDeletion{Resources: dispositions}, invalid)
+	assert.NotNil(t, dbGetUser(username2))
+
+	dispositions = append(dispositions,
+		ResourceDisposition{Type: "model", Name: "model3", Action: DispositionDelete})
+	response := deleteAccount(&jwt2, AccountDeletion{Resources: dispositions}, http.StatusOK)
+	require.NotNil(t, response)
+	assert.Equal(t, username2, response.Username)
+	assert.Equal(t, []string{"model/model1"}, response.Transferred)
+	assert.Equal(t, []string{"model/model3"}, response.Deleted)
+	require.Len(t, response.TransferRequests, 1)
+	assert.Nil(t, dbGetUser(username2))
+	assert.Empty(t, globals.Permissions.GetGroupsAndRolesForUser(username2))
+
+	// model1 was moved to the organization, with its files, and model3 was
+	// removed
+	model1 := getOwnerModelFromDb(t, testOrg, "model1")
+	assert.DirExists(t, *model1.Location)
+	var count int
+	globals.Server.Db.Model(&models.Model{}).Where("owner = ? AND name = ?", username2, "model3").Count(&count)
+	assert.Zero(t, count)
+
+	// model2 is moved once the other user accepts it
+	getOwnerModelFromDb(t, username2, "model2")
+	acceptURI := fmt.Sprintf("/1.0/%s/transfers/%d/accept", username, response.TransferRequests[0].ID)
+	gztest.AssertRouteMultipleArgs("POST", acceptURI, nil, http.StatusOK, &myJWT, ctJSON, t)
+	model2 := getOwnerModelFromDb(t, username, "model2")
+	assert.DirExists(t, *model2.Location)
+
+	// The new owner can manage the model
+	ok, _ := globals.Permissions.IsAuthorized(username, *model2.UUID, permissions.Write)
+	assert.True(t, ok)
+}
+
+// TestUserFeed tests following users and the activity feed.
+func TestUserFeed(t *testing.T) {
+	setup()
+
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	jwt2 := createValidJWTForIdentity("another-user", t)
+	username2 := createUserWithJWT(jwt2, t)
+	defer removeUserWithJWT(username2, jwt2, t)
+
+	followingURI := fmt.Sprintf("/1.0/users/%s/following", username)
+	follow := func(owner string, expStatus int) {
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(activity.CreateFollow{Owner: owner}))
+		ct := ctJSON
+		if expStatus != http.StatusOK {
+			ct = ctTextPlain
+		}
+		gztest.AssertRouteMultipleArgs("POST", followingURI, b, expStatus, &myJWT, ct, t)
+	}
+	feedURI := fmt.Sprintf("/1.0/users/%s/feed", username)
+	getFeed := func() activity.Activities {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", feedURI, nil, http.StatusOK, &myJWT, ctJSON, t)
+		var feed activity.Activities
+		require.NoError(t, json.Unmarshal(*bslice, &feed))
+		return feed
+	}
+
+	follow(username, gz.NewErrorMessage(gz.ErrorFormInvalidValue).StatusCode)
+	follow("unknown-owner", gz.NewErrorMessage(gz.ErrorUserUnknown).StatusCode)
+	follow(username2, http.StatusOK)
+	// Following twice is not an error
+	follow(username2, http.StatusOK)
+
+	// The list of followed owners is public
+	bslice, _ := gztest.AssertRouteMultipleArgs("GET", followingURI, nil, http.StatusOK, nil, ctJSON, t)
+	var follows activity.Follows
+	require.NoError(t, json.Unmarshal(*bslice, &follows))
+	require.Len(t, follows, 1)
+	assert.Equal(t, username2, follows[0].Followee)
+
+	// The activities of the followed user are in the feed, except those on
+	// private resources
+	assert.Empty(t, getFeed())
+	createThreeTestModels(t, &jwt2)
+	createTestModelWithOwner(t, &jwt2, "private_model", username2, true)
+	feed := getFeed()
+	require.Len(t, feed, 3)
+	for i, name := range []string{"model3", "model2", "model1"} {
+		assert.Equal(t, username2, feed[i].Actor)
+		assert.Equal(t, activity.VerbCreate, feed[i].Verb)
+		assert.Equal(t, "model", feed[i].ObjectType)
+		assert.Equal(t, name, feed[i].Name)
+	}
+
+	// Activities on resources made private or hidden afterwards are left out
+	setModel := func(name, column string) {
+		require.NoError(t, globals.Server.Db.Model(&models.Model{}).
+			Where("owner = ? AND name = ?", username2, name).Update(column, true).Error)
+	}
+	setModel("model1", "private")
+	setModel("model2", "hidden")
+	feed = getFeed()
+	require.Len(t, feed, 1)
+	assert.Equal(t, "model3", feed[0].Name)
+
+	// Users can only see their own feed
+	expEm := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	gztest.AssertRouteMultipleArgs("GET", feedURI, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+
+	unfollowURI := fmt.Sprintf("%s/%s", followingURI, username2)
+	gztest.AssertRouteMultipleArgs("DELETE", unfollowURI, nil, expEm.StatusCode, &jwt2, ctTextPlain, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("DELETE", unfollowURI, nil, http.StatusOK, &myJWT, ctJSON, t)
+	var removed activity.Follow
+	require.NoError(t, json.Unmarshal(*bslice, &removed))
+	assert.Equal(t, username2, removed.Followee)
+	expEm = gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	gztest.AssertRouteMultipleArgs("DELETE", unfollowURI, nil, expEm.StatusCode, &myJWT, ctTextPlain, t)
+	assert.Empty(t, getFeed())
+}
+
 // updateUserTest includes the input and expected output for a
 // TestUserUpdate test case.
 type updateUserTest struct {
@@ -782,3 +964,199 @@ func TestPersonalAccessToken(t *testing.T) {
 	// now try to remove the 2nd user
 	removeUserWithJWT(username2, jwt2, t)
 }
+
+// TestScopedPersonalAccessToken tests that personal access tokens with scopes
+// can only perform the requests allowed by their scopes.
+func TestScopedPersonalAccessToken(t *testing.T) {
+	setup()
+	myJWT := os.Getenv("IGN_TEST_JWT")
+	username := createUser(t)
+	defer removeUser(username, t)
+	createThreeTestModels(t, &myJWT)
+
+	uri := "/1.0/users/" + username + "/access-tokens"
+
+	// Invalid scopes are rejected
+	body := new(bytes.Buffer)
+	assert.NoError(t, json.NewEncoder(body).Encode(map[string]interface{}{
+		"name": "badScope", "scopes": []string{"delete:everything"}}))
+	expEm := gz.NewErrorMessage(gz.ErrorFormInvalidValue)
+	bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, body, expEm.StatusCode, &myJWT, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	body = new(bytes.Buffer)
+	assert.NoError(t, json.NewEncoder(body).Encode(map[string]interface{}{
+		"name": "ciToken", "scopes": []string{users.ScopeRead}}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, body, http.StatusOK, &myJWT, ctJSON, t)
+	var newToken gz.AccessTokenCreateResponse
+	require.NoError(t, json.Unmarshal(*bslice, &newToken))
+
+	// The scopes are listed
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &myJWT, ctJSON, t)
+	var tokens users.AccessTokenResponses
+	require.NoError(t, json.Unmarshal(*bslice, &tokens))
+	require.Len(t, tokens, 1)
+	assert.Equal(t, []string{users.ScopeRead}, tokens[0].Scopes)
+
+	sendWithKey := func(key, method, route string) int {
+		req, _ := http.NewRequest(method, route, nil)
+		req.Header.Set("Private-Token", key)
+		respRec := httptest.NewRecorder()
+		globals.Server.Router.ServeHTTP(respRec, req)
+		return respRec.Code
+	}
+	sendWithToken := func(method, route string) int {
+		return sendWithKey(newToken.Key, method, route)
+	}
+
+	// A read-only token can download, but not delete
+	assert.Equal(t, http.StatusOK, sendWithToken("GET", modelURL(username, "model1", "1")+".zip"))
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken("DELETE", modelURL(username, "model1", "")))
+	gztest.AssertRouteMultipleArgs("GET", modelURL(username, "model1", ""), nil, http.StatusOK, &myJWT, ctJSON, t)
+
+	// The last used IP is recorded
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &myJWT, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &tokens))
+	require.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].LastUsedIP)
+
+	// The audit log identifies the token, without storing its key
+	var entry audit.Entry
+	require.NoError(t, globals.Server.Db.Where("actor = ? AND method = ? AND target = ?",
+		username, "POST", "/users/"+username+"/access-tokens").Last(&entry).Error)
+	assert.Contains(t, entry.After, "ciToken")
+	assert.NotContains(t, entry.After, newToken.Key)
+
+	// A token scoped to a resource cannot read other private resources
+	createTestModelWithOwner(t, &myJWT, "private_model", username, true)
+	body = new(bytes.Buffer)
+	assert.NoError(t, json.NewEncoder(body).Encode(map[string]interface{}{
+		"name": "model1Token", "scopes": []string{users.ScopeResourcePrefix + username + "/models/model1"}}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, body, http.StatusOK, &myJWT, ctJSON, t)
+	var resourceToken gz.AccessTokenCreateResponse
+	require.NoError(t, json.Unmarshal(*bslice, &resourceToken))
+	assert.Equal(t, http.StatusOK, sendWithKey(resourceToken.Key, "GET", modelURL(username, "model1", "")))
+	assert.Equal(t, http.StatusUnauthorized,
+		sendWithKey(resourceToken.Key, "GET", modelURL(username, "private_model", "")))
+	assert.Equal(t, http.StatusOK, sendWithToken("GET", modelURL(username, "private_model", "")))
+}
+
+// TestRequestIP tests that the X-Forwarded-For header is only honored in
+// requests coming from a trusted proxy.
+func TestRequestIP(t *testing.T) {
+	trusted := globals.TrustedProxies
+	defer func() { globals.TrustedProxies = trusted }()
+
+	var err error
+	globals.TrustedProxies, err = parseNetworks("10.0.0.0/8, 192.168.1.1")
+	require.NoError(t, err)
+	_, err = parseNetworks("not-an-ip")
+	assert.Error(t, err)
+
+	request := func(remoteAddr, forwarded string) *http.Request {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		if forwarded != "" {
+			req.Header.Set("X-Forwarded-For", forwarded)
+		}
+		return req
+	}
+
+	// Untrusted clients cannot spoof their IP
+	assert.Equal(t, "1.2.3.4", requestIP(request("1.2.3.4:1000", "5.6.7.8")))
+	// Trusted proxies forward the client IP
+	assert.Equal(t, "5.6.7.8", requestIP(request("10.1.1.1:1000", "5.6.7.8")))
+	assert.Equal(t, "5.6.7.8", requestIP(request("192.168.1.1:1000", "5.6.7.8, 10.2.2.2")))
+	// Entries added by the client are ignored
+	assert.Equal(t, "5.6.7.8", requestIP(request("10.1.1.1:1000", "9.9.9.9, 5.6.7.8")))
+	// Requests from trusted proxies without the header use the remote address
+	assert.Equal(t, "10.1.1.1", requestIP(request("10.1.1.1:1000", "")))
+}
+
+// TestUserSuspension tests suspending and reinstating a user.
+func TestUserSuspension(t *testing.T) {
+	// General test setup.
+	setup()
+	jwt := os.Getenv("IGN_TEST_JWT")
+	admin := createSysAdminUser(t)
+	defer removeUser(admin, t)
+	ownerJWT := createValidJWTForIdentity("another-user", t)
+	owner := createUserWithJWT(ownerJWT, t)
+	defer removeUserWithJWT(owner, ownerJWT, t)
+	// Reinstate the owner if the test fails, so it can be removed.
+	defer globals.Server.Db.Model(&users.User{}).Where("username = ?", owner).UpdateColumn("suspended_at", nil)
+	createThreeTestModels(t, &ownerJWT)
+
+	unauth := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	invalid := gz.NewErrorMessage(gz.ErrorFormInvalidValue)
+	notFound := gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	suspensionsURI := fmt.Sprintf("/1.0/admin/users/%s/suspensions", owner)
+	reinstatementURI := fmt.Sprintf("/1.0/admin/users/%s/reinstatement", owner)
+	send := func(uri string, v interface{}, jwt *string, status int) *[]byte {
+		b := new(bytes.Buffer)
+		require.NoError(t, json.NewEncoder(b).Encode(v))
+		ct := ctJSON
+		if status != http.StatusOK {
+			ct = ctTextPlain
+		}
+		bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, b, status, jwt, ct, t)
+		return bslice
+	}
+	countModels := func() int {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", "/1.0/models", nil, http.StatusOK, nil, ctJSON, t)
+		var list []*fuel.Model
+		require.NoError(t, json.Unmarshal(*bslice, &list))
+		return len(list)
+	}
+	require.Equal(t, 3, countModels())
+
+	// Only system admins can suspend users, and suspensions need a reason and
+	// an expiry in the future
+	reason := users.CreateSuspension{Reason: "Spam"}
+	send(suspensionsURI, reason, &ownerJWT, unauth.StatusCode)
+	send(suspensionsURI, users.CreateSuspension{}, &jwt, invalid.StatusCode)
+	past := time.Now().Add(-time.Hour)
+	send(suspensionsURI, users.CreateSuspension{Reason: "Spam", ExpiresAt: &past}, &jwt, invalid.StatusCode)
+
+	// Suspended users cannot authenticate, and their models are not listed
+	expiry := time.Now().Add(24 * time.Hour)
+	bslice := send(suspensionsURI, users.CreateSuspension{Reason: "Spam", ExpiresAt: &expiry}, &jwt, http.StatusOK)
+	var entry users.SuspensionEntry
+	require.NoError(t, json.Unmarshal(*bslice, &entry))
+	assert.Equal(t, users.SuspensionActionSuspend, entry.Action)
+	assert.Equal(t, "Spam", entry.Reason)
+	require.NotNil(t, entry.ExpiresAt)
+	gztest.AssertRouteMultipleArgs("GET", "/1.0/users/"+owner, nil, unauth.StatusCode, &ownerJWT, ctTextPlain, t)
+	gztest.AssertRouteMultipleArgs("GET", "/1.0/login", nil, unauth.StatusCode, &ownerJWT, ctTextPlain, t)
+	assert.Equal(t, 0, countModels())
+
+	// Reinstatement restores access and visibility
+	send(reinstatementURI, users.ReinstateUser{}, &jwt, invalid.StatusCode)
+	send(reinstatementURI, users.ReinstateUser{Reason: "Appeal"}, &jwt, http.StatusOK)
+	send(reinstatementURI, users.ReinstateUser{Reason: "Appeal"}, &jwt, notFound.StatusCode)
+	gztest.AssertRouteMultipleArgs("GET", "/1.0/users/"+owner, nil, http.StatusOK, &ownerJWT, ctJSON, t)
+	assert.Equal(t, 3, countModels())
+
+	// Both actions are in the suspension history, newest first
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", suspensionsURI, nil, http.StatusOK, &jwt, ctJSON, t)
+	var history users.SuspensionEntries
+	require.NoError(t, json.Unmarshal(*bslice, &history))
+	require.Len(t, history, 2)
+	assert.Equal(t, users.SuspensionActionReinstate, history[0].Action)
+	assert.Equal(t, "Appeal", history[0].Reason)
+	assert.Equal(t, admin, history[0].By)
+	assert.Equal(t, users.SuspensionActionSuspend, history[1].Action)
+
+	// Models are listed again once a suspension expires, and the cached lists
+	// are cleared
+	send(suspensionsURI, users.CreateSuspension{Reason: "Spam", ExpiresAt: &expiry}, &jwt, http.StatusOK)
+	assert.Equal(t, 0, countModels())
+	since := time.Now().Add(-time.Hour)
+	require.NoError(t, globals.Server.Db.Model(&users.User{}).Where("username = ?", owner).
+		UpdateColumn("suspended_until", time.Now().Add(-time.Minute)).Error)
+	next, em := clearCacheOnExpiredSuspensions(context.Background(), globals.Server.Db, since)
+	require.Nil(t, em)
+	assert.True(t, next.After(since))
+	assert.Equal(t, 3, countModels())
+	gztest.AssertRouteMultipleArgs("GET", "/1.0/login", nil, http.StatusOK, &ownerJWT, ctJSON, t)
+}
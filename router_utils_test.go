@@ -32,6 +32,7 @@ const (
 	ctTextPlain string = "text/plain; charset=utf-8"
 	ctJSON      string = "application/json"
 	ctZip       string = "application/zip"
+	ctHTML      string = "text/html; charset=utf-8"
 )
 
 // sptr returns a pointer to a given string.
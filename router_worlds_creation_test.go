@@ -394,6 +394,29 @@ func TestWorldClone(t *testing.T) {
 	resp := gztest.AssertRouteMultipleArgsStruct(reqArgs, http.StatusOK, "application/zip", t)
 	assert.True(t, resp.Ok, "World Zip Download request didn't succeed")
 
+	// test that the fork lineage is returned in the world lists
+	require.NotNil(t, w.ForkedFromID, "Cloned world should record its source")
+	assert.Equal(t, world.ID, *w.ForkedFromID)
+	listURIs := []string{
+		"/1.0/" + *w.Owner + "/worlds",
+		"/1.0/" + testUser + "/worlds/" + *world.Name + "/forks",
+	}
+	for _, listURI := range listURIs {
+		bslice, _ := gztest.AssertRouteMultipleArgs("GET", listURI, nil, http.StatusOK, &jwt, ctJSON, t)
+		var list []*fuel.World
+		require.NoError(t, json.Unmarshal(*bslice, &list))
+		var clone *fuel.World
+		for _, got := range list {
+			if got.GetName() == clonedName {
+				clone = got
+			}
+		}
+		require.NotNil(t, clone, "Cloned world should be listed in %s", listURI)
+		require.NotNil(t, clone.ForkedFrom, "Cloned world should return forked_from in %s", listURI)
+		assert.Equal(t, testUser, clone.ForkedFrom.GetOwner())
+		assert.Equal(t, *world.Name, clone.ForkedFrom.GetName())
+	}
+
 	// Now test with a failing VCS repository mock
 	SetFailingVCSFactory()
 	serverErrorTests := []postTest{
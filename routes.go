@@ -29,6 +29,7 @@ var routes = gz.Routes{
 			// can be defined with query parameter 'per_page'.
 			// The route supports the 'order' parameter, with values 'asc' and
 			// 'desc' (default: desc).
+			// The 'sort' parameter with value 'rating' returns the best rated models first.
 			// It also supports the 'q' parameter to perform a fulltext search on models
 			// name, description and tags.
 			//
@@ -103,6 +104,7 @@ var routes = gz.Routes{
 			// with a maximum of 100 items per page.
 			// The route supports the 'order' parameter, with values 'asc' and
 			// 'desc' (default: desc).
+			// The 'sort' parameter with value 'rating' returns the best rated models first.
 			// It also supports the 'q' parameter to perform a fulltext search on models
 			// name, description and tags.
 			//
@@ -197,6 +199,7 @@ var routes = gz.Routes{
 			// The page size can be controlled with query parameter 'per_page', with a maximum of
 			// 100 items per page.
 			// The route supports the 'order' parameter, with values 'asc' and 'desc' (default: desc).
+			// The 'sort' parameter with value 'rating' returns the best rated models first.
 			// It also supports the 'q' parameter to perform a fulltext search on models name,
 			// description and tags.
 			//
@@ -403,58 +406,56 @@ var routes = gz.Routes{
 		},
 	},
 
-	// Route that returns a model zip file from a team/user
+	// Route that proposes the transfer of a model to another owner
 	gz.Route{
-		Name:        "OwnerModelVersion",
-		Description: "Download a versioned model zip file belonging to an owner.",
-		URI:         "/{username}/models/{model}/{version}/{model}",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /{username}/models/{model}/{version}/{model} models singleOwnerModel
+		Name:        "OwnerModelTransferRequest",
+		Description: "Propose the transfer of a model to another owner.",
+		URI:         "/{username}/models/{model}/transfer-requests",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/models/{model}/transfer-requests models modelTransferRequest
 			//
-			// Get a single model zip file from an owner
+			// Propose the transfer of a model
 			//
-			// Return a model zip file given its owner, name, and version.
+			// The model is only transferred once the destination owner accepts
+			// the request.
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
-			//   - application/zip
+			//   - application/json
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: Model
+			//     200: TransferRequestResponse
 			gz.Method{
-				Type:        "GET",
-				Description: "Get a model of specified version belonging to the specified team/user",
+				Type:        "POST",
+				Description: "Propose the transfer of a model",
 				// Format handlers
-				// if empty file extension is given, it returns model's meta data
-				// and {version} is then ignored
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".zip", Handler: gz.Handler(NoResult(NameOwnerHandler("model", false, ModelOwnerVersionZip)))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelOwnerIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, TransferRequestCreate("model")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that clones a model
+	// Route that makes the JWT user watch a model
 	gz.Route{
-		Name:        "CloneModel",
-		Description: "Clone a model",
-		URI:         "/{username}/models/{model}/clone",
-		Headers:     gz.AuthHeadersOptional,
+		Name:        "OwnerModelWatch",
+		Description: "Watch a model to be notified of its changes.",
+		URI:         "/{username}/models/{model}/watch",
+		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/models/{model}/clone models cloneModel
-			//
-			// Clones a models
+			// swagger:route POST /{username}/models/{model}/watch models modelWatchCreate
 			//
-			// Clones a model.
+			// Watch a model
 			//
-			//   Consumes:
-			//   - application/json
+			// The watchers of a model are notified when it changes.
 			//
 			//   Produces:
 			//   - application/json
@@ -463,30 +464,18 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OK
+			//     200:
 			gz.Method{
 				Type:        "POST",
-				Description: "Clones a model",
+				Description: "Watch a model",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelClone))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, WatchCreate("model")))},
 				},
 			},
-		},
-	},
-
-	// Route that handles model reports
-	gz.Route{
-		Name:        "ReportModel",
-		Description: "Report a model",
-		URI:         "/{username}/models/{model}/report",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route POST /{username}/models/{model}/report models reportModel
-			//
-			// Reports a model.
+			// swagger:route DELETE /{username}/models/{model}/watch models modelWatchRemove
 			//
-			//   Consumes:
-			//   - application/json
+			// Stop watching a model
 			//
 			//   Produces:
 			//   - application/json
@@ -495,75 +484,61 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OK
+			//     200:
 			gz.Method{
-				Type:        "POST",
-				Description: "Reports a model",
+				Type:        "DELETE",
+				Description: "Stop watching a model",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("model", false, ReportModelCreate)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, WatchRemove("model")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
 	},
 
-	////////////
-	// Worlds //
-	////////////
-
-	// Route for all worlds
+	// Route that handles the comments of a model
 	gz.Route{
-		Name:        "Worlds",
-		Description: "Information about all worlds",
-		URI:         "/worlds",
+		Name:        "ModelComments",
+		Description: "Comments of a model.",
+		URI:         "/{username}/models/{model}/comments",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /worlds worlds listWorlds
+			// swagger:route GET /{username}/models/{model}/comments models modelCommentList
 			//
-			// Get list of worlds.
+			// Get the comments of a model
 			//
-			// Get a list of worlds. Worlds will be returned paginated,
-			// with pages of 20 worlds by default. The user can request a
-			// different page with query parameter 'page', and the page size
-			// can be defined with query parameter 'per_page'.
-			// The route supports the 'order' parameter, with values 'asc' and
-			// 'desc' (default: desc).
-			// It also supports the 'q' parameter to perform a fulltext search on worlds
-			// name, description and tags.
+			// Get the top level comments of a model, oldest first. Comments will be
+			// returned paginated, with pages of 20 comments by default. The bodies
+			// of deleted and hidden comments are not returned.
 			//
 			//   Produces:
 			//   - application/json
-			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: jsonWorlds
+			//     200: Comments
 			gz.Method{
 				Type:        "GET",
-				Description: "Get all worlds",
+				Description: "Get the comments of a model",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
-					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(SearchHandler(WorldList))},
-					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(CommentList("model")))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /worlds worlds createWorld
+			// swagger:route POST /{username}/models/{model}/comments models modelCommentCreate
 			//
-			// Create world
+			// Comment on a model
 			//
-			// Creates a new world. The request body should contain the
-			// following fields: 'name', 'description',
-			// 'license' (number), 'permission' (number). All values as strings.
-			// 'tags': a string containing a comma separated list of tags.
-			// The worlds owner will be retrieved from the passed JWT.
-			// 'file': multiple files in the multipart form.
+			// Add a comment to a model, or a reply to one of its comments with
+			// parent_id. The body is markdown. The users mentioned with
+			// @username are notified.
 			//
 			//   Consumes:
-			//   - multipart/form-data
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -572,73 +547,58 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbWorld
+			//     200: Comment
 			gz.Method{
 				Type:        "POST",
-				Description: "Create a new world",
+				Description: "Comment on a model",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(WorldCreate)},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, CommentCreate("model")))},
 				},
 			},
 		},
 	},
 
-	// Route that returns a list of worlds from a team/user (ie. an 'owner')
+	// Route that handles the moderation state of the comments of a model. It is
+	// declared before the single comment route, which would match it.
 	gz.Route{
-		Name:        "OwnerWorlds",
-		Description: "Information about worlds belonging to an owner. The {username} URI option will limit the scope to the specified user/team. Otherwise all worlds are considered.",
-		URI:         "/{username}/worlds",
+		Name:        "ModelCommentThread",
+		Description: "Lock state of the comments of a model.",
+		URI:         "/{username}/models/{model}/comments/thread",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/worlds worlds listOwnerWorlds
+			// swagger:route GET /{username}/models/{model}/comments/thread models modelCommentThread
 			//
-			// Get owner's worlds
-			//
-			// Get a list of worlds for the specified owner.
-			// Worlds will be returned paginated,
-			// with pages of 20 worlds by default. The user can request a
-			// different page with query parameter 'page' (first page is value 1).
-			// The page size can be controlled with query parameter 'per_page',
-			// with a maximum of 100 items per page.
-			// The route supports the 'order' parameter, with values 'asc' and
-			// 'desc' (default: desc).
-			// It also supports the 'q' parameter to perform a fulltext search on worlds
-			// name, description and tags.
+			// Get whether the comments of a model are locked
 			//
 			//   Produces:
 			//   - application/json
-			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: jsonWorlds
+			//     200: CommentThread
 			gz.Method{
 				Type:        "GET",
-				Description: "Get all worlds of the specified team/user",
+				Description: "Get the lock state of the comments of a model",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
-					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(SearchHandler(WorldList))},
-					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", false, CommentThreadIndex("model")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
-	},
-
-	// Route that handles likes to a world from an owner
-	gz.Route{
-		Name:        "WorldLikes",
-		Description: "Handles the likes of a world.",
-		URI:         "/{username}/worlds/{world}/likes",
-		Headers:     gz.AuthHeadersOptional,
-		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/worlds/{world}/likes worlds worldLikeCreate
+			// swagger:route PATCH /{username}/models/{model}/comments/thread models modelCommentThreadUpdate
 			//
-			// Like a world
+			// Lock or unlock the comments of a model
+			//
+			// Locked threads don't accept new comments, replies or edits. Only
+			// the owner of the model and the admins of the owner organization can
+			// lock threads.
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -647,18 +607,36 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: World
+			//     200: CommentThread
 			gz.Method{
-				Type:        "POST",
-				Description: "Like a world",
+				Type:        "PATCH",
+				Description: "Lock or unlock the comments of a model",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", true, WorldLikeCreate)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, CommentThreadUpdate("model")))},
 				},
 			},
-			// swagger:route DELETE /{username}/worlds/{world}/likes worlds worldUnlike
+		},
+	},
+
+	// Route that handles a comment of a model
+	gz.Route{
+		Name:        "ModelComment",
+		Description: "A comment of a model.",
+		URI:         "/{username}/models/{model}/comments/{id}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /{username}/models/{model}/comments/{id} models modelCommentUpdate
 			//
-			// Unlike a world
+			// Update a comment of a model
+			//
+			// Authors can edit the body of their comments. The owner of the model
+			// and the admins of the owner organization can hide and show
+			// comments.
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -667,37 +645,21 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: World
+			//     200: Comment
 			gz.Method{
-				Type:        "DELETE",
-				Description: "Unlike a world",
+				Type:        "PATCH",
+				Description: "Update a comment of a model",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", true, WorldLikeRemove)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, CommentUpdate("model")))},
 				},
 			},
-		},
-	},
-
-	// Route that returns a list of worlds liked by a user.
-	gz.Route{
-		Name:        "WorldLikeList",
-		Description: "Worlds liked by a user.",
-		URI:         "/{username}/likes/worlds",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /{username}/likes/worlds worlds worldLikeList
+			// swagger:route DELETE /{username}/models/{model}/comments/{id} models modelCommentRemove
 			//
-			// Get worlds liked by a user.
+			// Delete a comment of a model
 			//
-			// Get a list of worlds liked by the specified user.
-			// Worlds will be returned paginated, with pages of 20 worlds by default.
-			// The user can request a different page with query parameter 'page' (first page is value 1).
-			// The page size can be controlled with query parameter 'per_page', with a maximum of
-			// 100 items per page.
-			// The route supports the 'order' parameter, with values 'asc' and 'desc' (default: desc).
-			// It also supports the 'q' parameter to perform a fulltext search on world's name,
-			// description and tags.
+			// The comment stays in the thread without its body, so its replies can
+			// still be read.
 			//
 			//   Produces:
 			//   - application/json
@@ -706,129 +668,130 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: jsonWorlds
+			//     200:
 			gz.Method{
-				Type:        "GET",
-				Description: "Get all worlds liked by the specified user",
+				Type:        "DELETE",
+				Description: "Delete a comment of a model",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", SearchHandler(WorldLikeList))},
-					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", SearchHandler(WorldLikeList))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, CommentRemove("model")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that returns the files tree of a single world based on owner, name, and version
+	// Route that returns the replies to a comment of a model
 	gz.Route{
-		Name:        "WorldFileTree",
-		Description: "Route that returns the files tree of a single world.",
-		URI:         "/{username}/worlds/{world}/{version}/files",
+		Name:        "ModelCommentReplies",
+		Description: "Replies to a comment of a model.",
+		URI:         "/{username}/models/{model}/comments/{id}/replies",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/worlds/{world}/{version}/files worlds worldFileTree
+			// swagger:route GET /{username}/models/{model}/comments/{id}/replies models modelCommentReplyList
 			//
-			// World's file tree.
+			// Get the replies to a comment of a model
 			//
-			// Return the files information of a given world.
+			// Replies are returned oldest first, paginated like the comments.
 			//
 			//   Produces:
 			//   - application/json
-			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: WorldFileTree
+			//     200: Comments
 			gz.Method{
 				Type:        "GET",
-				Description: "Get file tree",
+				Description: "Get the replies to a comment of a model",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldFileTree))},
-					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("world", false, WorldFileTree))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldFileTree))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(CommentReplyList("model")))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that downloads an individual file from a world based on owner, name, and version
+	// Route that handles reports of a comment of a model
 	gz.Route{
-		Name:        "WorldIndividualFileDownload",
-		Description: "Download individual file from a world.",
-		URI:         "/{username}/worlds/{world}/{version}/files/{path:.+}",
+		Name:        "ModelCommentReport",
+		Description: "Reports of a comment of a model",
+		URI:         "/{username}/models/{model}/comments/{id}/report",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/worlds/{world}/{version}/files/{path} worlds downloadWorldFile
+			// swagger:route POST /{username}/models/{model}/comments/{id}/report models modelCommentReport
 			//
-			// Download an individual file from a world.
+			// Report a comment of a model
+			//
+			// The report is added to the moderation queue of the system admins.
+			//
+			//   Consumes:
+			//   - multipart/form-data
 			//
 			//   Produces:
-			//   - application/octet-stream
+			//   - application/json
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: fileResponse
+			//     200: OK
 			gz.Method{
-				Type:        "GET",
-				Description: "GET a file",
+				Type:        "POST",
+				Description: "Report a comment of a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, WorldIndividualFileDownload)))},
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("model", false, CommentReport("model"))))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that returns a world, by name, from a team/user
+	// Route that handles the star ratings of a model
 	gz.Route{
-		Name:        "WorldIndex",
-		Description: "Information about a world belonging to an owner.",
-		URI:         "/{username}/worlds/{world}",
+		Name:        "ModelRatings",
+		Description: "Star ratings of a model.",
+		URI:         "/{username}/models/{model}/ratings",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/worlds/{world} worlds singleOwnerWorld
+			// swagger:route GET /{username}/models/{model}/ratings models modelRatingList
 			//
-			// Get a single world from an owner
+			// Get the ratings of a model
 			//
-			// Return a world given its owner and name.
+			// Get the star ratings and written reviews of a model, newest first.
+			// Ratings will be returned paginated, with pages of 20 ratings by
+			// default. Use the 'version' parameter to get the ratings of a single
+			// version.
 			//
 			//   Produces:
 			//   - application/json
-			//   - application/x-protobuf
-			//   - application/zip
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: World
+			//     200: Ratings
 			gz.Method{
 				Type:        "GET",
-				Description: "Get a world belonging to the specified team/user",
+				Description: "Get the ratings of a model",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldIndex))},
-					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("world", false, WorldIndex))},
-					gz.FormatHandler{Extension: ".zip", Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, WorldZip)))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(RatingList("model")))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route PATCH /{username}/worlds/{world} worlds worldUpdate
+			// swagger:route POST /{username}/models/{model}/ratings models modelRatingCreate
 			//
-			// Update a world
+			// Rate a model
 			//
-			// Update a world
+			// Rate a version of a model with 1 to 5 stars and an optional written
+			// review. The latest version is rated if no version is given. Rating
+			// the same version again replaces the previous rating.
 			//
 			//   Consumes:
-			//   - multipart/form-data
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -837,51 +800,101 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: World
+			//     200: Rating
 			gz.Method{
-				Type:        "PATCH",
-				Description: "Edit a world",
+				Type:        "POST",
+				Description: "Rate a model",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WorldUpdate))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, RatingCreate("model")))},
 				},
 			},
-			// swagger:route DELETE /{username}/worlds/{world} world deleteWorld
-			//
-			// Delete a world
-			//
-			// Deletes a world given its owner and name.
-			//
-			//   Produces:
+		},
+	},
+
+	// Route that removes a star rating of a model
+	gz.Route{
+		Name:        "ModelRating",
+		Description: "Star rating of a version of a model.",
+		URI:         "/{username}/models/{model}/ratings/{version}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /{username}/models/{model}/ratings/{version} models modelRatingRemove
+			//
+			// Remove the rating of a version of a model
+			//
+			//   Produces:
 			//   - application/json
 			//
 			//   Schemes: https
 			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
 			gz.Method{
 				Type:        "DELETE",
-				Description: "Deletes a single world",
+				Description: "Remove the rating of a version of a model",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", true, WorldRemove)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, RatingRemove("model")))},
 				},
 			},
 		},
 	},
 
-	// Route that transfers a world
+	// Route that returns a model zip file from a team/user
 	gz.Route{
-		Name:        "OwnerWorldTransfer",
-		Description: "Transfer a world to another owner.",
-		URI:         "/{username}/worlds/{world}/transfer",
+		Name:        "OwnerModelVersion",
+		Description: "Download a versioned model zip file belonging to an owner.",
+		URI:         "/{username}/models/{model}/{version}/{model}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/models/{model}/{version}/{model} models singleOwnerModel
+			//
+			// Get a single model zip file from an owner
+			//
+			// Return a model zip file given its owner, name, and version.
+			//
+			//   Produces:
+			//   - application/zip
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Model
+			gz.Method{
+				Type:        "GET",
+				Description: "Get a model of specified version belonging to the specified team/user",
+				// Format handlers
+				// if empty file extension is given, it returns model's meta data
+				// and {version} is then ignored
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".zip", Handler: gz.Handler(NoResult(NameOwnerHandler("model", false, ModelOwnerVersionZip)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelOwnerIndex))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that clones a model
+	gz.Route{
+		Name:        "CloneModel",
+		Description: "Clone a model",
+		URI:         "/{username}/models/{model}/clone",
 		Headers:     gz.AuthHeadersOptional,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/worlds/{world}/transfer models worldTransfer
+			// swagger:route POST /{username}/models/{model}/clone models cloneModel
 			//
-			// Transfer a world
+			// Clones a models
+			//
+			// Clones a model.
 			//
 			//   Consumes:
-			//   - multipart/form-data
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -890,67 +903,69 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: Model
+			//     200: OK
 			gz.Method{
 				Type:        "POST",
-				Description: "Transfer a world",
-				// Format handlers
+				Description: "Clones a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WorldTransfer))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelClone))},
 				},
 			},
 		},
 	},
 
-	// Route that returns a world zip file from a team/user
+	// Route that returns the list of forks of a model
 	gz.Route{
-		Name:        "WorldVersion",
-		Description: "Download a versioned world zip file belonging to an owner.",
-		URI:         "/{username}/worlds/{world}/{version}/{world}",
+		Name:        "ModelForks",
+		Description: "List of models that were cloned from a model",
+		URI:         "/{username}/models/{model}/forks",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/worlds/{world}/{version}/{world} worlds singleOwnerWorld
+			// swagger:route GET /{username}/models/{model}/forks models modelForks
 			//
-			// Get a single world zip file from an owner
+			// List of forks of a model.
 			//
-			// Return a world zip file given its owner, name, and version.
+			// Return the paginated list of models that were cloned from a model.
+			// Only forks visible to the requesting user are returned.
 			//
 			//   Produces:
-			//   - application/zip
+			//   - application/json
+			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: World
+			//     200: jsonModels
 			gz.Method{
 				Type:        "GET",
-				Description: "Get a world of specified version belonging to the specified team/user",
+				Description: "List of forks of a model",
 				// Format handlers
-				// if empty file extension is given, it returns world's meta data
-				// and {version} is then ignored
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".zip", Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, WorldZip)))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldIndex))},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Models", NameOwnerHandler("model", false, ModelForks))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("model", false, ModelForks))},
+					gz.FormatHandler{Handler: gz.JSONListResult("Models", NameOwnerHandler("model", false, ModelForks))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that clones a world
+	// Route that restores a deleted model
 	gz.Route{
-		Name:        "CloneWorld",
-		Description: "Clone a world",
-		URI:         "/{username}/worlds/{world}/clone",
-		Headers:     gz.AuthHeadersOptional,
+		Name:        "RestoreModel",
+		Description: "Restore a deleted model",
+		URI:         "/{username}/models/{model}/restore",
+		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/worlds/{world}/clone worlds cloneWorld
+			// swagger:route POST /{username}/models/{model}/restore models restoreModel
 			//
-			// Clones a world
+			// Restores a deleted model
 			//
-			// Clones a world.
+			// Restores a model from the owner's trash, as long as it was deleted
+			// within the trash retention window. An optional 'name' can be given
+			// in the request body in case the original name is already taken.
 			//
 			//   Consumes:
 			//   - application/json
@@ -965,24 +980,29 @@ var routes = gz.Routes{
 			//     200: OK
 			gz.Method{
 				Type:        "POST",
-				Description: "Clones a world",
+				Description: "Restores a deleted model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldClone))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ModelRestore))},
 				},
 			},
 		},
 	},
 
-	// Route that handles world reports
+	// Route that deprecates a model
 	gz.Route{
-		Name:        "ReportWorld",
-		Description: "Report a world",
-		URI:         "/{username}/worlds/{world}/report",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route POST /{username}/worlds/{world}/report worlds reportWorld
+		Name:        "DeprecateModel",
+		Description: "Deprecate a model",
+		URI:         "/{username}/models/{model}/deprecation",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/models/{model}/deprecation models deprecateModel
 			//
-			// Reports a world.
+			// Deprecates a model
+			//
+			// Marks a model as deprecated. An optional 'message' and successor
+			// model ('successor_owner' and 'successor_name') can be given in the
+			// request body. Deprecated models rank lower in lists and searches.
 			//
 			//   Consumes:
 			//   - application/json
@@ -994,30 +1014,17 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OK
+			//     200: Model
 			gz.Method{
 				Type:        "POST",
-				Description: "Reports a world",
+				Description: "Deprecates a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, ReportWorldCreate)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ModelDeprecate))},
 				},
 			},
-		},
-		SecureMethods: gz.SecureMethods{},
-	},
-
-	// Route that returns the modelIncludes of a world.
-	gz.Route{
-		Name:        "WorldModelIncludes",
-		Description: "Route that returns the external models referenced by a world",
-		URI:         "/{username}/worlds/{world}/{version}/{world}/modelrefs",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /{username}/worlds/{world}/{version}/{world}/modelrefs worlds worldModelIncludes
-			//
-			// World's model references.
+			// swagger:route DELETE /{username}/models/{model}/deprecation models undeprecateModel
 			//
-			// Return the external models referenced by a world.
+			// Removes the deprecation of a model
 			//
 			//   Produces:
 			//   - application/json
@@ -1026,42 +1033,31 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: ModelIncludes
+			//     200: Model
 			gz.Method{
-				Type:        "GET",
-				Description: "World's ModelIncludes ",
+				Type:        "DELETE",
+				Description: "Removes the deprecation of a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldModelReferences))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldModelReferences))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ModelUndeprecate))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
 	},
 
-	/////////////////
-	// Collections //
-	/////////////////
-
-	// Route for all Collections
+	// Route that handles the collaborators of a model
 	gz.Route{
-		Name:        "Collection",
-		Description: "Information about all collections",
-		URI:         "/collections",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /collections collections listCollections
+		Name:        "ModelCollaborators",
+		Description: "Users and teams a model is shared with",
+		URI:         "/{username}/models/{model}/collaborators",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/models/{model}/collaborators models listModelCollaborators
 			//
-			// Get list of collections.
+			// List of collaborators of a model
 			//
-			// Get a list of collections. Collections will be returned paginated,
-			// with pages of 20 items by default. The user can request a
-			// different page with query parameter 'page', and the page size
-			// can be defined with query parameter 'per_page'.
-			// The route supports the 'order' parameter, with values 'asc' and
-			// 'desc' (default: desc).
-			// It also supports the 'q' parameter to perform a fulltext search on
-			// name and description.
+			// Returns the users and organization teams the model is shared with.
+			// Only the owner, or admins of the owner organization, can see them.
 			//
 			//   Produces:
 			//   - application/json
@@ -1070,27 +1066,24 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollections
+			//     200: Collaborators
 			gz.Method{
 				Type:        "GET",
-				Description: "Get all collections",
+				Description: "List of collaborators of a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(SearchHandler(CollectionList))},
-					gz.FormatHandler{Handler: gz.JSONResult(SearchHandler(CollectionList))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ResourceCollaborators("model")))},
 				},
 			},
-		},
-		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /collections collections createCollection
+			// swagger:route POST /{username}/models/{model}/collaborators models addModelCollaborator
 			//
-			// Create collection
+			// Shares a model with a user or team
 			//
-			// Creates a new collection. The request body should contain the
-			// following fields: 'name', 'description'. All values as strings.
-			// The collection owner will be retrieved from the passed JWT.
+			// Grants 'read' or 'write' access to a user ('username') or to an
+			// organization team ('organization' and 'team'). Sharing again with
+			// the same user or team updates the permission.
 			//
 			//   Consumes:
-			//   - multipart/form-data
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -1099,39 +1092,20 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollection
+			//     200: Collaborator
 			gz.Method{
 				Type:        "POST",
-				Description: "Create a new collection",
+				Description: "Shares a model with a user or team",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(CollectionCreate)},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ResourceCollaboratorAdd("model")))},
 				},
 			},
-		},
-	},
-
-	// Route that returns a list of collections from a team/user (ie. an 'owner')
-	gz.Route{
-		Name: "OwnerCollections",
-		Description: "Information about worlds belonging to an owner. The {username} URI option " +
-			"will limit the scope to the specified user/team. Otherwise all collections are considered.",
-		URI:     "/{username}/collections",
-		Headers: gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /{username}/collections collections listOwnerCollections
+			// swagger:route DELETE /{username}/models/{model}/collaborators models removeModelCollaborator
 			//
-			// Get owner's collections
+			// Stops sharing a model with a user or team
 			//
-			// Get a list of collections for the specified owner.
-			// Collections will be returned paginated,
-			// with pages of 20 items by default. The user can request a
-			// different page with query parameter 'page' (first page is value 1).
-			// The page size can be controlled with query parameter 'per_page',
-			// with a maximum of 10belonging0 items per page.
-			// The route supports the 'order' parameter, with values 'asc' and
-			// 'desc' (default: desc).
-			// It also supports the 'q' parameter to perform a fulltext search on
-			// name and description.
+			// The user or team is given with the 'username' or 'organization'
+			// and 'team' query parameters.
 			//
 			//   Produces:
 			//   - application/json
@@ -1140,32 +1114,31 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollections
+			//     200: Collaborator
 			gz.Method{
-				Type:        "GET",
-				Description: "Get all collections of the specified team/user",
-				// Format handlers
+				Type:        "DELETE",
+				Description: "Stops sharing a model with a user or team",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(SearchHandler(CollectionList))},
-					gz.FormatHandler{Handler: gz.JSONResult(SearchHandler(CollectionList))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ResourceCollaboratorRemove("model")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that returns a Collection, by name, from a team/user
+	// Route that handles the share tokens of a model
 	gz.Route{
-		Name:        "CollectionIndex",
-		Description: "Information about a collection belonging to an owner.",
-		URI:         "/{username}/collections/{collection}",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /{username}/collections/{collection} collections singleOwnerCollection
+		Name:        "ModelShareTokens",
+		Description: "Share tokens of a model",
+		URI:         "/{username}/models/{model}/share-tokens",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/models/{model}/share-tokens models listModelShareTokens
 			//
-			// Get a single collection from an owner
+			// List of share tokens of a model
 			//
-			// Return a collection given its owner and name.
+			// Returns the share tokens of the model, including the expired ones.
+			// The token secrets are not included.
 			//
 			//   Produces:
 			//   - application/json
@@ -1174,26 +1147,25 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollection
+			//     200: ShareTokens
 			gz.Method{
 				Type:        "GET",
-				Description: "Get a collection belonging to the specified team/user",
-				// Format handlers
+				Description: "List of share tokens of a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionIndex))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ResourceShareTokens("model")))},
 				},
 			},
-		},
-		SecureMethods: gz.SecureMethods{
-			// swagger:route PATCH /{username}/collections/{collection} collections collectionUpdate
+			// swagger:route POST /{username}/models/{model}/share-tokens models createModelShareToken
 			//
-			// Update a collection
+			// Creates a share token for a model
 			//
-			// Update a collection
+			// Creates a read-only token that expires at 'expires_at'. Anyone
+			// holding the token can download the zip, files and file tree of the
+			// model by adding the 'share_token' query parameter. The token is only
+			// returned in this response.
 			//
 			//   Consumes:
-			//   - multipart/form-data
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -1202,20 +1174,19 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollection
+			//     200: ShareTokenCreateResponse
 			gz.Method{
-				Type:        "PATCH",
-				Description: "Edit a collection",
-				// Format handlers
+				Type:        "POST",
+				Description: "Creates a share token for a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, CollectionUpdate))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ResourceShareTokenCreate("model")))},
 				},
 			},
-			// swagger:route DELETE /{username}/collections/{collection} collection deleteCollection
+			// swagger:route DELETE /{username}/models/{model}/share-tokens models revokeModelShareToken
 			//
-			// Delete a Collection
+			// Revokes a share token of a model
 			//
-			// Deletes a Collection given its owner and name.
+			// The token is given with the 'prefix' query parameter.
 			//
 			//   Produces:
 			//   - application/json
@@ -1224,31 +1195,33 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OK
+			//     200: ShareToken
 			gz.Method{
 				Type:        "DELETE",
-				Description: "Deletes a single collection",
-				// Format handlers
+				Description: "Revokes a share token of a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionRemove)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ResourceShareTokenRevoke("model")))},
 				},
 			},
 		},
 	},
 
+	// Route that explains who can read or write a model
 	gz.Route{
-		Name:        "OwnerCollectionTransfer",
-		Description: "Transfer a collection to another owner.",
-		URI:         "/{username}/collections/{collection}/transfer",
-		Headers:     gz.AuthHeadersOptional,
+		Name:        "ModelPermissions",
+		Description: "Effective permissions of a model",
+		URI:         "/{username}/models/{model}/permissions",
+		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/collections/{collection}/transfer collections collectionTransfer
+			// swagger:route GET /{username}/models/{model}/permissions models getModelPermissions
 			//
-			// Transfer a collection
+			// Effective permissions of a model
 			//
-			//   Consumes:
-			//   - multipart/form-data
+			// Lists every user that can read or write the model, and how each
+			// grant was derived: direct, organization, team or sysadmin. With the
+			// 'user' query parameter, it evaluates a single user and returns the
+			// reasoning chain. Only owners, admins and sysadmins can request it.
 			//
 			//   Produces:
 			//   - application/json
@@ -1257,28 +1230,30 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: Model
+			//     200: ResourcePermissions
 			gz.Method{
-				Type:        "POST",
-				Description: "Transfer a collection",
-				// Format handlers
+				Type:        "GET",
+				Description: "Effective permissions of a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, CollectionTransfer))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", true, ResourcePermissions("model")))},
 				},
 			},
 		},
 	},
-	// Route that clones a collection
+
+	// Route that handles model reports
 	gz.Route{
-		Name:        "CloneCollection",
-		Description: "Clone a collection",
-		URI:         "/{username}/collections/{collection}/clone",
+		Name:        "ReportModel",
+		Description: "Report a model",
+		URI:         "/{username}/models/{model}/report",
 		Headers:     gz.AuthHeadersOptional,
-		Methods:     gz.Methods{},
-		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/collections/{collection}/clone collections cloneCollection
+		Methods: gz.Methods{
+			// swagger:route POST /{username}/models/{model}/report models reportModel
 			//
-			// Clones a collection
+			// Reports a model.
+			//
+			// Public models reported by several users in a short period are
+			// hidden from lists and search results, pending moderator review.
 			//
 			//   Consumes:
 			//   - application/json
@@ -1293,86 +1268,73 @@ var routes = gz.Routes{
 			//     200: OK
 			gz.Method{
 				Type:        "POST",
-				Description: "Clones a collection",
-				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionClone))},
-				},
-			},
-		},
-	},
-
-	// Route that downloads an individual file from a collection.
-	// It is used to download the collection logo and banner.
-	gz.Route{
-		Name:        "CollectionIndividualFileDownload",
-		Description: "Download individual file from a collection.",
-		URI:         "/{username}/collections/{collection}/{version}/files/{path:.+}",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /{username}/collections/{collection}/{version}/files/{path} collections downloadColFile
-			//
-			// Download an individual file from a collection.
-			//
-			//   Produces:
-			//   - application/octet-stream
-			//
-			//   Schemes: https
-			//
-			//   Responses:
-			//     default: fuelError
-			//     200: fileResponse
-			gz.Method{
-				Type:        "GET",
-				Description: "GET a file",
+				Description: "Reports a model",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", false, CollectionIndividualFileDownload)))},
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("model", false, ReportModelCreate)))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route to list, add and remove models from collections.
+	////////////
+	// Worlds //
+	////////////
+
+	// Route for all worlds
 	gz.Route{
-		Name:        "CollectionModels",
-		Description: "Information about models from a collection",
-		URI:         "/{username}/collections/{collection}/models",
+		Name:        "Worlds",
+		Description: "Information about all worlds",
+		URI:         "/worlds",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/collections/{collection}/models collections collectionModels
+			// swagger:route GET /worlds worlds listWorlds
 			//
-			// Lists the models of a collection
+			// Get list of worlds.
 			//
-			// Return the list of models that belong to a collection
+			// Get a list of worlds. Worlds will be returned paginated,
+			// with pages of 20 worlds by default. The user can request a
+			// different page with query parameter 'page', and the page size
+			// can be defined with query parameter 'per_page'.
+			// The route supports the 'order' parameter, with values 'asc' and
+			// 'desc' (default: desc).
+			// The 'sort' parameter with value 'rating' returns the best rated worlds first.
+			// It also supports the 'q' parameter to perform a fulltext search on worlds
+			// name, description and tags.
 			//
 			//   Produces:
 			//   - application/json
+			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollectionAssets
+			//     200: jsonWorlds
 			gz.Method{
 				Type:        "GET",
-				Description: "Get the models associated to a collection",
-				// Format handlers
+				Description: "Get all worlds",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Models", NameOwnerHandler("collection", false, CollectionModelsList))},
-					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("collection", false, CollectionModelsList))},
-					gz.FormatHandler{Handler: gz.JSONListResult("Models", NameOwnerHandler("collection", false, CollectionModelsList))},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(SearchHandler(WorldList))},
+					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/collections/{collection}/models collections collectionModelAdd
+			// swagger:route POST /worlds worlds createWorld
 			//
-			// Add a model to a collection
+			// Create world
 			//
-			// Adds a model to a collection
+			// Creates a new world. The request body should contain the
+			// following fields: 'name', 'description',
+			// 'license' (number), 'permission' (number). All values as strings.
+			// 'tags': a string containing a comma separated list of tags.
+			// The worlds owner will be retrieved from the passed JWT.
+			// 'file': multiple files in the multipart form.
 			//
 			//   Consumes:
-			//   - application/json
+			//   - multipart/form-data
 			//
 			//   Produces:
 			//   - application/json
@@ -1381,84 +1343,74 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OK
+			//     200: dbWorld
 			gz.Method{
 				Type:        "POST",
-				Description: "Add a model to a collection",
-				// Format handlers
-				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionModelAdd)))},
-				},
-			},
-			// swagger:route DELETE /{username}/collections/{collection}/models collection collectionModelRemove
-			//
-			// Remove model from Collection
-			//
-			// Removes a model from a Collection
-			//
-			//   Consumes:
-			//   - application/json
-			//
-			//   Produces:
-			//   - application/json
-			//
-			//   Schemes: https
-			//
-			//   Responses:
-			//     default: fuelError
-			//     200: OK
-			gz.Method{
-				Type:        "DELETE",
-				Description: "Removes a model from a collection",
-				// Format handlers
+				Description: "Create a new world",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionModelRemove)))},
+					gz.FormatHandler{Handler: gz.JSONResult(WorldCreate)},
 				},
 			},
 		},
 	},
 
-	// Route to list, add and remove worlds from collections.
+	// Route that returns a list of worlds from a team/user (ie. an 'owner')
 	gz.Route{
-		Name:        "CollectionWorlds",
-		Description: "Information about Worlds from a collection",
-		URI:         "/{username}/collections/{collection}/worlds",
+		Name:        "OwnerWorlds",
+		Description: "Information about worlds belonging to an owner. The {username} URI option will limit the scope to the specified user/team. Otherwise all worlds are considered.",
+		URI:         "/{username}/worlds",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/collections/{collection}/worlds collections collectionWorlds
+			// swagger:route GET /{username}/worlds worlds listOwnerWorlds
 			//
-			// Lists the worlds of a collection
+			// Get owner's worlds
 			//
-			// Return the list of worlds that belong to a collection
+			// Get a list of worlds for the specified owner.
+			// Worlds will be returned paginated,
+			// with pages of 20 worlds by default. The user can request a
+			// different page with query parameter 'page' (first page is value 1).
+			// The page size can be controlled with query parameter 'per_page',
+			// with a maximum of 100 items per page.
+			// The route supports the 'order' parameter, with values 'asc' and
+			// 'desc' (default: desc).
+			// The 'sort' parameter with value 'rating' returns the best rated worlds first.
+			// It also supports the 'q' parameter to perform a fulltext search on worlds
+			// name, description and tags.
 			//
 			//   Produces:
 			//   - application/json
+			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollectionAssets
+			//     200: jsonWorlds
 			gz.Method{
 				Type:        "GET",
-				Description: "Get the worlds associated to a collection",
+				Description: "Get all worlds of the specified team/user",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", NameOwnerHandler("collection", false, CollectionWorldsList))},
-					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("collection", false, CollectionWorldsList))},
-					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", NameOwnerHandler("collection", false, CollectionWorldsList))},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(SearchHandler(WorldList))},
+					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", SearchHandler(WorldList))},
 				},
 			},
 		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that handles likes to a world from an owner
+	gz.Route{
+		Name:        "WorldLikes",
+		Description: "Handles the likes of a world.",
+		URI:         "/{username}/worlds/{world}/likes",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /{username}/collections/{collection}/worlds collections collectionWorldAdd
-			//
-			// Add a world to a collection
-			//
-			// Adds a world to a collection
+			// swagger:route POST /{username}/worlds/{world}/likes worlds worldLikeCreate
 			//
-			//   Consumes:
-			//   - application/json
+			// Like a world
 			//
 			//   Produces:
 			//   - application/json
@@ -1467,23 +1419,18 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OK
+			//     200: World
 			gz.Method{
 				Type:        "POST",
-				Description: "Add a world to a collection",
+				Description: "Like a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionWorldAdd)))},
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", true, WorldLikeCreate)))},
 				},
 			},
-			// swagger:route DELETE /{username}/collections/{collection}/worlds collection collectionWorldRemove
-			//
-			// Remove world from Collection
-			//
-			// Removes a world from a Collection
+			// swagger:route DELETE /{username}/worlds/{world}/likes worlds worldUnlike
 			//
-			//   Consumes:
-			//   - application/json
+			// Unlike a world
 			//
 			//   Produces:
 			//   - application/json
@@ -1492,30 +1439,38 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OK
+			//     200: World
 			gz.Method{
 				Type:        "DELETE",
-				Description: "Removes a world from a collection",
+				Description: "Unlike a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionWorldRemove)))},
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", true, WorldLikeRemove)))},
 				},
 			},
 		},
 	},
 
-	// Route that returns the list of collections associated to a model
+	// Route that returns a list of worlds liked by a user.
 	gz.Route{
-		Name:        "ModelCollections",
-		Description: "List of collections associated to a model.",
-		URI:         "/{username}/models/{model}/collections",
+		Name:        "WorldLikeList",
+		Description: "Worlds liked by a user.",
+		URI:         "/{username}/likes/worlds",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/models/{model}/collections collections modelCollections
+			// swagger:route GET /{username}/likes/worlds worlds worldLikeList
 			//
-			// List of collections associated to a model.
+			// Get worlds liked by a user.
 			//
-			// List of collections associated to a model.
+			// Get a list of worlds liked by the specified user.
+			// Worlds will be returned paginated, with pages of 20 worlds by default.
+			// The user can request a different page with query parameter 'page' (first page is value 1).
+			// The page size can be controlled with query parameter 'per_page', with a maximum of
+			// 100 items per page.
+			// The route supports the 'order' parameter, with values 'asc' and 'desc' (default: desc).
+			// The 'sort' parameter with value 'rating' returns the best rated worlds first.
+			// It also supports the 'q' parameter to perform a fulltext search on world's name,
+			// description and tags.
 			//
 			//   Produces:
 			//   - application/json
@@ -1524,146 +1479,129 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollections
+			//     200: jsonWorlds
 			gz.Method{
 				Type:        "GET",
-				Description: "List of collections associated to a model",
+				Description: "Get all worlds liked by the specified user",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelCollections))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelCollections))},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", SearchHandler(WorldLikeList))},
+					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", SearchHandler(WorldLikeList))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that returns the list of collections associated to a world
+	// Route that returns the files tree of a single world based on owner, name, and version
 	gz.Route{
-		Name:        "WorldCollections",
-		Description: "List of collections associated to a world.",
-		URI:         "/{username}/worlds/{world}/collections",
+		Name:        "WorldFileTree",
+		Description: "Route that returns the files tree of a single world.",
+		URI:         "/{username}/worlds/{world}/{version}/files",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /{username}/worlds/{world}/collections collections worldCollections
+			// swagger:route GET /{username}/worlds/{world}/{version}/files worlds worldFileTree
 			//
-			// List of collections associated to a world.
+			// World's file tree.
 			//
-			// List of collections associated to a world.
+			// Return the files information of a given world.
 			//
 			//   Produces:
 			//   - application/json
+			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: dbCollections
+			//     200: WorldFileTree
 			gz.Method{
 				Type:        "GET",
-				Description: "List of collections associated to a world",
-				// Format handlers
+				Description: "Get file tree",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldCollections))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldCollections))},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldFileTree))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("world", false, WorldFileTree))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldFileTree))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{},
 	},
 
-	///////////
-	// Users //
-	///////////
-
-	// Route that returns login information for a given JWT
+	// Route that downloads an individual file from a world based on owner, name, and version
 	gz.Route{
-		Name:        "Login",
-		Description: "Login a user",
-		URI:         "/login",
-		Headers:     gz.AuthHeadersRequired,
-		Methods:     gz.Methods{},
-		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /login users loginUser
-			//
-			// Login user
+		Name:        "WorldIndividualFileDownload",
+		Description: "Download individual file from a world.",
+		URI:         "/{username}/worlds/{world}/{version}/files/{path:.+}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/worlds/{world}/{version}/files/{path} worlds downloadWorldFile
 			//
-			// Returns information about the user associated with the given JWT.
+			// Download an individual file from a world.
 			//
 			//   Produces:
-			//   - application/json
+			//   - application/octet-stream
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: UserResponse
+			//     200: fileResponse
 			gz.Method{
 				Type:        "GET",
-				Description: "Login a user",
+				Description: "GET a file",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(Login)},
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, WorldIndividualFileDownload)))},
 				},
 			},
 		},
+		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that returns information about all users
+	// Route that returns a world, by name, from a team/user
 	gz.Route{
-		Name:        "Users",
-		Description: "Route for all users",
-		URI:         "/users",
+		Name:        "WorldIndex",
+		Description: "Information about a world belonging to an owner.",
+		URI:         "/{username}/worlds/{world}",
 		Headers:     gz.AuthHeadersOptional,
-		Methods:     gz.Methods{},
-		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /users users listUsers
-			//
-			// Get a list of users. Access limited to administrators.
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/worlds/{world} worlds singleOwnerWorld
 			//
-			// Returns a paginated list of users,
-			// with pages of 20 users by default. Only system administrators can
-			// access this route. The administrator can request a
-			// different page with query parameter 'page' (first page is value 1).
-			// The page size can be controlled with query parameter 'per_page',
-			// with a maximum of 100 items per page.
+			// Get a single world from an owner
 			//
-			//   Parameters:
-			//   + name: Private-Token
-			//     description: A personal access token.
-			//     in: header
-			//     required: true
-			//     type: string
-			//   + name: page
-			//     description: Request a specific page of users.
-			//     in: query
-			//     required: false
-			//     type: integer
+			// Return a world given its owner and name.
 			//
 			//   Produces:
 			//   - application/json
+			//   - application/x-protobuf
+			//   - application/zip
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: UserResponses
+			//     200: World
 			gz.Method{
 				Type:        "GET",
-				Description: "Get all users information",
+				Description: "Get a world belonging to the specified team/user",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(UserList))},
-					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(UserList))},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldIndex))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("world", false, WorldIndex))},
+					gz.FormatHandler{Extension: ".zip", Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, WorldZip)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldIndex))},
 				},
 			},
-			// swagger:route POST /users users createUser
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /{username}/worlds/{world} worlds worldUpdate
 			//
-			// Create user
+			// Update a world
 			//
-			// Creates a new user. Note: the user identity will be retrieved from the passed JWT.
+			// Update a world
 			//
 			//   Consumes:
-			//   - application/json
+			//   - multipart/form-data
 			//
 			//   Produces:
 			//   - application/json
@@ -1672,75 +1610,88 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: UserResponse
+			//     200: World
 			gz.Method{
-				Type:        "POST",
-				Description: "Create a new user",
+				Type:        "PATCH",
+				Description: "Edit a world",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(UserCreate)},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WorldUpdate))},
 				},
 			},
-		},
-	},
-
-	// Route that returns information about a user
-	gz.Route{
-		Name:        "UserIndex",
-		Description: "Access information about a single user.",
-		URI:         "/users/{username}",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /users/{username} users singleUser
+			// swagger:route DELETE /{username}/worlds/{world} world deleteWorld
 			//
-			// Get a user
+			// Delete a world
 			//
-			// Return a user given its username and a valid JWT.
+			// Deletes a world given its owner and name.
 			//
 			//   Produces:
 			//   - application/json
 			//
 			//   Schemes: https
 			//
-			//   Responses:
-			//     default: fuelError
-			//     200: UserResponse
 			gz.Method{
-				Type:        "GET",
-				Description: "Get user information",
+				Type:        "DELETE",
+				Description: "Deletes a single world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("username", false, UserIndex))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", false, UserIndex))},
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", true, WorldRemove)))},
 				},
 			},
 		},
+	},
 
+	// Route that transfers a world
+	gz.Route{
+		Name:        "OwnerWorldTransfer",
+		Description: "Transfer a world to another owner.",
+		URI:         "/{username}/worlds/{world}/transfer",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route DELETE /users/{username} users deleteUser
+			// swagger:route POST /{username}/worlds/{world}/transfer models worldTransfer
 			//
-			// Delete a user
+			// Transfer a world
 			//
-			// Deletes a user given its username and a valid JWT.
+			//   Consumes:
+			//   - multipart/form-data
 			//
 			//   Produces:
 			//   - application/json
 			//
 			//   Schemes: https
 			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Model
 			gz.Method{
-				Type:        "DELETE",
-				Description: "Remove a user",
+				Type:        "POST",
+				Description: "Transfer a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, UserRemove))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WorldTransfer))},
 				},
 			},
+		},
+	},
 
-			// swagger:route PATCH /users/{username} users updateUser
+	// Route that proposes the transfer of a world to another owner
+	gz.Route{
+		Name:        "OwnerWorldTransferRequest",
+		Description: "Propose the transfer of a world to another owner.",
+		URI:         "/{username}/worlds/{world}/transfer-requests",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/worlds/{world}/transfer-requests worlds worldTransferRequest
 			//
-			// Update a user
+			// Propose the transfer of a world
 			//
-			// Updates a user given its username and a valid JWT.
+			// The world is only transferred once the destination owner accepts
+			// the request.
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -1749,108 +1700,114 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: UserResponse
+			//     200: TransferRequestResponse
 			gz.Method{
-				Type:        "PATCH",
-				Description: "Update a user",
+				Type:        "POST",
+				Description: "Propose the transfer of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, UserUpdate))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, TransferRequestCreate("world")))},
 				},
 			},
 		},
 	},
 
-	// Routes to get and create access tokens.
+	// Route that makes the JWT user watch a world
 	gz.Route{
-		Name:        "AccessTokens",
-		Description: "Routes to get and create access tokens.",
-		URI:         "/users/{username}/access-tokens",
+		Name:        "OwnerWorldWatch",
+		Description: "Watch a world to be notified of its changes.",
+		URI:         "/{username}/worlds/{world}/watch",
 		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
-
 		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /users/{username}/access-tokens users getAccessToken
+			// swagger:route POST /{username}/worlds/{world}/watch worlds worldWatchCreate
 			//
-			// Get the acccess tokens for a user.
+			// Watch a world
+			//
+			// The watchers of a world are notified when it changes.
 			//
 			//   Produces:
 			//   - application/json
 			//
 			//   Schemes: https
 			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
 			gz.Method{
-				Type:        "GET",
-				Description: "Get a user's access tokens",
+				Type:        "POST",
+				Description: "Watch a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(AccessTokenList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WatchCreate("world")))},
 				},
 			},
-
-			// swagger:route POST /users/{username}/access-tokens users createAccessToken
-			//
-			// Creates an access token.
+			// swagger:route DELETE /{username}/worlds/{world}/watch worlds worldWatchRemove
 			//
-			// Creates an access token for a user.
+			// Stop watching a world
 			//
 			//   Produces:
 			//   - application/json
 			//
 			//   Schemes: https
 			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
 			gz.Method{
-				Type:        "POST",
-				Description: "Create an access token",
+				Type:        "DELETE",
+				Description: "Stop watching a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, AccessTokenCreate))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WatchRemove("world")))},
 				},
 			},
 		},
 	},
 
-	// Routes to revoke access tokens
+	// Route that handles the comments of a world
 	gz.Route{
-		Name:        "AccessTokens",
-		Description: "Route to revoke access tokens.",
-		URI:         "/users/{username}/access-tokens/revoke",
-		Headers:     gz.AuthHeadersRequired,
-		Methods:     gz.Methods{},
-
-		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /users/{username}/access-tokens/revoke users revokeAccessToken
+		Name:        "WorldComments",
+		Description: "Comments of a world.",
+		URI:         "/{username}/worlds/{world}/comments",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/worlds/{world}/comments worlds worldCommentList
 			//
-			// Delete an acccess token that belongs to a user.
+			// Get the comments of a world
+			//
+			// Get the top level comments of a world, oldest first. Comments will be
+			// returned paginated, with pages of 20 comments by default. The bodies
+			// of deleted and hidden comments are not returned.
 			//
 			//   Produces:
 			//   - application/json
 			//
 			//   Schemes: https
 			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Comments
 			gz.Method{
-				Type:        "POST",
-				Description: "Delete a user's access token",
+				Type:        "GET",
+				Description: "Get the comments of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, AccessTokenDelete))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(CommentList("world")))},
 				},
 			},
 		},
-	},
-
-	// Route that returns the details of a single user or organization
-	gz.Route{
-		Name:        "OwnerProfile",
-		Description: "Access the details of a single user OR organization.",
-		URI:         "/profile/{username}",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
-			// swagger:route GET /profile/{username} users ownerProfile
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/worlds/{world}/comments worlds worldCommentCreate
 			//
-			// Get the profile of an owner
+			// Comment on a world
 			//
-			// Get the profile of an owner
+			// Add a comment to a world, or a reply to one of its comments with
+			// parent_id. The body is markdown. The users mentioned with
+			// @username are notified.
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -1859,40 +1816,29 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OwnerProfile
+			//     200: Comment
 			gz.Method{
-				Type:        "GET",
-				Description: "Get profile information",
+				Type:        "POST",
+				Description: "Comment on a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("username", false, OwnerProfile))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", false, OwnerProfile))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, CommentCreate("world")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
 	},
 
-	//////////////
-	// Licenses //
-	//////////////
-
-	// Route that returns information about all available licenses
+	// Route that handles the moderation state of the comments of a world. It is
+	// declared before the single comment route, which would match it.
 	gz.Route{
-		Name:        "Licenses",
-		Description: "Route for all licenses",
-		URI:         "/licenses",
+		Name:        "WorldCommentThread",
+		Description: "Lock state of the comments of a world.",
+		URI:         "/{username}/worlds/{world}/comments/thread",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /licenses licenses listLicenses
+			// swagger:route GET /{username}/worlds/{world}/comments/thread worlds worldCommentThread
 			//
-			// List licenses
-			//
-			// Get the list of licenses. Licenses will be returned paginated,
-			// with pages of 20 items by default. The user can request a
-			// different page with query parameter 'page' (first page is value 1).
-			// The page size can be controlled with query parameter 'per_page',
-			// with a maximum of 100 items per page.
+			// Get whether the comments of a world are locked
 			//
 			//   Produces:
 			//   - application/json
@@ -1901,115 +1847,120 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: Licenses
+			//     200: CommentThread
 			gz.Method{
 				Type:        "GET",
-				Description: "Get all licenses",
+				Description: "Get the lock state of the comments of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(LicenseList))},
-					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(LicenseList))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, CommentThreadIndex("world")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{},
-	},
-
-	//////////////
-	// Categories //
-	//////////////
-
-	// Categories route with slug
-	// PATCH:
-	gz.Route{
-		Name:        "Categories",
-		Description: "Routes for categories with slug",
-		URI:         "/categories/{slug}",
-		Headers:     gz.AuthHeadersOptional,
-		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /{username}/worlds/{world}/comments/thread worlds worldCommentThreadUpdate
+			//
+			// Lock or unlock the comments of a world
+			//
+			// Locked threads don't accept new comments, replies or edits. Only
+			// the owner of the world and the admins of the owner organization can
+			// lock threads.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: CommentThread
 			gz.Method{
 				Type:        "PATCH",
-				Description: "Update a category",
-				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{
-						Extension: "",
-						Handler:   gz.JSONResult(CategoryUpdate),
-					},
-				},
-			},
-			gz.Method{
-				Type:        "DELETE",
-				Description: "Delete a category",
+				Description: "Lock or unlock the comments of a world",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{
-						Extension: "",
-						Handler:   gz.JSONResult(CategoryDelete),
-					},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, CommentThreadUpdate("world")))},
 				},
 			},
 		},
 	},
 
-	// Categories route
-	// GET: Get the list of categories
-	// POST: Create a new category
+	// Route that handles a comment of a world
 	gz.Route{
-		Name:        "Categories",
-		Description: "Route for categories",
-		URI:         "/categories",
-		Headers:     gz.AuthHeadersOptional,
-		Methods: gz.Methods{
+		Name:        "WorldComment",
+		Description: "A comment of a world.",
+		URI:         "/{username}/worlds/{world}/comments/{id}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /{username}/worlds/{world}/comments/{id} worlds worldCommentUpdate
+			//
+			// Update a comment of a world
+			//
+			// Authors can edit the body of their comments. The owner of the world
+			// and the admins of the owner organization can hide and show
+			// comments.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Comment
 			gz.Method{
-				Type:        "GET",
-				Description: "Get all categories",
+				Type:        "PATCH",
+				Description: "Update a comment of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{
-						Extension: ".json",
-						Handler:   gz.JSONResult(CategoryList),
-					},
-					gz.FormatHandler{
-						Extension: "",
-						Handler:   gz.JSONResult(CategoryList),
-					},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, CommentUpdate("world")))},
 				},
 			},
-		},
-		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /{username}/worlds/{world}/comments/{id} worlds worldCommentRemove
+			//
+			// Delete a comment of a world
+			//
+			// The comment stays in the thread without its body, so its replies can
+			// still be read.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
 			gz.Method{
-				Type:        "POST",
-				Description: "Create a new category",
+				Type:        "DELETE",
+				Description: "Delete a comment of a world",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{
-						Extension: "",
-						Handler:   gz.JSONResult(CategoryCreate),
-					},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, CommentRemove("world")))},
 				},
 			},
 		},
 	},
 
-	///////////////////
-	// Organizations //
-	///////////////////
-
-	// Route that returns information about all organizations
+	// Route that returns the replies to a comment of a world
 	gz.Route{
-		Name:        "Organizations",
-		Description: "Route for all organizations",
-		URI:         "/organizations",
+		Name:        "WorldCommentReplies",
+		Description: "Replies to a comment of a world.",
+		URI:         "/{username}/worlds/{world}/comments/{id}/replies",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /organizations organizations listOrganizations
+			// swagger:route GET /{username}/worlds/{world}/comments/{id}/replies worlds worldCommentReplyList
 			//
-			// List organizations
+			// Get the replies to a comment of a world
 			//
-			// Get the list of organizations. Organizations will be returned paginated,
-			// with pages of 20 organizations by default. The user can request a
-			// different page with query parameter 'page' (first page is value 1).
-			// The page size can be controlled with query parameter 'per_page',
-			// with a maximum of 100 items per page.
+			// Replies are returned oldest first, paginated like the comments.
 			//
 			//   Produces:
 			//   - application/json
@@ -2018,26 +1969,34 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OrganizationResponses
+			//     200: Comments
 			gz.Method{
 				Type:        "GET",
-				Description: "Get all organizations information",
+				Description: "Get the replies to a comment of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(OrganizationList))},
-					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(OrganizationList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(CommentReplyList("world")))},
 				},
 			},
 		},
-		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /organizations organizations createOrganization
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that handles reports of a comment of a world
+	gz.Route{
+		Name:        "WorldCommentReport",
+		Description: "Reports of a comment of a world",
+		URI:         "/{username}/worlds/{world}/comments/{id}/report",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route POST /{username}/worlds/{world}/comments/{id}/report worlds worldCommentReport
 			//
-			// Create organization
+			// Report a comment of a world
 			//
-			// Creates a new organization. Note: the user identity will be retrieved from the passed JWT.
+			// The report is added to the moderation queue of the system admins.
 			//
 			//   Consumes:
-			//   - application/json
+			//   - multipart/form-data
 			//
 			//   Produces:
 			//   - application/json
@@ -2046,29 +2005,33 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OrganizationResponse
+			//     200: OK
 			gz.Method{
 				Type:        "POST",
-				Description: "Create a new organization",
+				Description: "Report a comment of a world",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(OrganizationCreate)},
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, CommentReport("world"))))},
 				},
 			},
 		},
+		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that returns information about an organization
+	// Route that handles the star ratings of a world
 	gz.Route{
-		Name:        "OrganizationIndex",
-		Description: "Access information about a single organization.",
-		URI:         "/organizations/{name}",
+		Name:        "WorldRatings",
+		Description: "Star ratings of a world.",
+		URI:         "/{username}/worlds/{world}/ratings",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /organizations/{name} organizations singleOrganization
+			// swagger:route GET /{username}/worlds/{world}/ratings worlds worldRatingList
 			//
-			// Get an organization
+			// Get the ratings of a world
 			//
-			// Return an organization given its name and a valid JWT.
+			// Get the star ratings and written reviews of a world, newest first.
+			// Ratings will be returned paginated, with pages of 20 ratings by
+			// default. Use the 'version' parameter to get the ratings of a single
+			// version.
 			//
 			//   Produces:
 			//   - application/json
@@ -2077,23 +2040,27 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OrganizationResponse
+			//     200: Ratings
 			gz.Method{
 				Type:        "GET",
-				Description: "Get organization information",
+				Description: "Get the ratings of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("name", false, OrganizationIndex))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", false, OrganizationIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(RatingList("world")))},
 				},
 			},
 		},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route DELETE /organizations/{name} organizations deleteOrganizations
+			// swagger:route POST /{username}/worlds/{world}/ratings worlds worldRatingCreate
 			//
-			// Delete an organization
+			// Rate a world
 			//
-			// Deletes an organization given its name and a valid JWT.
+			// Rate a version of a world with 1 to 5 stars and an optional written
+			// review. The latest version is rated if no version is given. Rating
+			// the same version again replaces the previous rating.
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -2102,23 +2069,29 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OrganizationResponse
+			//     200: Rating
 			gz.Method{
-				Type:        "DELETE",
-				Description: "Remove an organization",
+				Type:        "POST",
+				Description: "Rate a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationRemove))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, RatingCreate("world")))},
 				},
 			},
-			// swagger:route PATCH /organizations/{name} organizations organizationUpdate
-			//
-			// Update an organization
-			//
-			// Update an organization
+		},
+	},
+
+	// Route that removes a star rating of a world
+	gz.Route{
+		Name:        "WorldRating",
+		Description: "Star rating of a version of a world.",
+		URI:         "/{username}/worlds/{world}/ratings/{version}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /{username}/worlds/{world}/ratings/{version} worlds worldRatingRemove
 			//
-			//   Consumes:
-			//   - application/json
+			// Remove the rating of a version of a world
 			//
 			//   Produces:
 			//   - application/json
@@ -2127,54 +2100,67 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: OrganizationResponse
+			//     200:
 			gz.Method{
-				Type:        "PATCH",
-				Description: "Edit an organization",
+				Type:        "DELETE",
+				Description: "Remove the rating of a version of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationUpdate))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, RatingRemove("world")))},
 				},
 			},
 		},
 	},
-	// Route that returns information about organization users
+
+	// Route that returns a world zip file from a team/user
 	gz.Route{
-		Name:        "OrganizationUsers",
-		Description: "Base route to list of users of an Organization",
-		URI:         "/organizations/{name}/users",
+		Name:        "WorldVersion",
+		Description: "Download a versioned world zip file belonging to an owner.",
+		URI:         "/{username}/worlds/{world}/{version}/{world}",
 		Headers:     gz.AuthHeadersOptional,
 		Methods: gz.Methods{
-			// swagger:route GET /organizations/{name}/users organizations orgUsers
+			// swagger:route GET /{username}/worlds/{world}/{version}/{world} worlds singleOwnerWorld
 			//
-			// Get the list of users of an organization
+			// Get a single world zip file from an owner
 			//
-			// Return the list of users of an organization.
+			// Return a world zip file given its owner, name, and version.
 			//
 			//   Produces:
-			//   - application/json
+			//   - application/zip
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: UserResponses
+			//     200: World
 			gz.Method{
 				Type:        "GET",
-				Description: "Get the list of users of an organization",
+				Description: "Get a world of specified version belonging to the specified team/user",
 				// Format handlers
+				// if empty file extension is given, it returns world's meta data
+				// and {version} is then ignored
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(OrganizationUserList))},
-					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(OrganizationUserList))},
+					gz.FormatHandler{Extension: ".zip", Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, WorldZip)))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldIndex))},
 				},
 			},
 		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that clones a world
+	gz.Route{
+		Name:        "CloneWorld",
+		Description: "Clone a world",
+		URI:         "/{username}/worlds/{world}/clone",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route POST /organizations/{name}/users organizations addUserToOrganization
+			// swagger:route POST /{username}/worlds/{world}/clone worlds cloneWorld
 			//
-			// Adds a user to an organization
+			// Clones a world
 			//
-			// Adds a user to an organization.
+			// Clones a world.
 			//
 			//   Consumes:
 			//   - application/json
@@ -2186,62 +2172,3441 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: UserResponse
+			//     200: OK
 			gz.Method{
 				Type:        "POST",
-				Description: "Adds a user to an Organization",
+				Description: "Clones a world",
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationUserCreate))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldClone))},
 				},
 			},
 		},
 	},
-	// Route that returns information about organization users
+
+	// Route that returns the list of forks of a world
 	gz.Route{
-		Name:        "OrganizationUserUpdate",
-		Description: "Route to update and delete a member of an organization",
-		URI:         "/organizations/{name}/users/{username}",
-		Headers:     gz.AuthHeadersRequired,
-		Methods:     gz.Methods{},
-		SecureMethods: gz.SecureMethods{
-			// swagger:route DELETE /organizations/{name}/users/{username} organizations orgUserDelete
+		Name:        "WorldForks",
+		Description: "List of worlds that were cloned from a world",
+		URI:         "/{username}/worlds/{world}/forks",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/worlds/{world}/forks worlds worldForks
 			//
-			// Removes a user from an organization
+			// List of forks of a world.
 			//
-			// Removes a user from an organization
+			// Return the paginated list of worlds that were cloned from a world.
+			// Only forks visible to the requesting user are returned.
 			//
 			//   Produces:
 			//   - application/json
+			//   - application/x-protobuf
 			//
 			//   Schemes: https
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: UserResponse
+			//     200: jsonWorlds
 			gz.Method{
-				Type:        "DELETE",
-				Description: "Removes a user from an organization",
+				Type:        "GET",
+				Description: "List of forks of a world",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationUserRemove))},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", NameOwnerHandler("world", false, WorldForks))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("world", false, WorldForks))},
+					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", NameOwnerHandler("world", false, WorldForks))},
 				},
 			},
 		},
+		SecureMethods: gz.SecureMethods{},
 	},
 
-	// Route that returns information about organization teams
+	// Route that restores a deleted world
 	gz.Route{
-		Name:        "OrganizationTeams",
-		Description: "Base route to list of teams of an Organization",
-		URI:         "/organizations/{name}/teams",
+		Name:        "RestoreWorld",
+		Description: "Restore a deleted world",
+		URI:         "/{username}/worlds/{world}/restore",
 		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /organizations/{name}/teams organizations orgTeams
+			// swagger:route POST /{username}/worlds/{world}/restore worlds restoreWorld
+			//
+			// Restores a deleted world
+			//
+			// Restores a world from the owner's trash, as long as it was deleted
+			// within the trash retention window. An optional 'name' can be given
+			// in the request body in case the original name is already taken.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Restores a deleted world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WorldRestore))},
+				},
+			},
+		},
+	},
+
+	// Route that deprecates a world
+	gz.Route{
+		Name:        "DeprecateWorld",
+		Description: "Deprecate a world",
+		URI:         "/{username}/worlds/{world}/deprecation",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/worlds/{world}/deprecation worlds deprecateWorld
+			//
+			// Deprecates a world
+			//
+			// Marks a world as deprecated. An optional 'message' and successor
+			// world ('successor_owner' and 'successor_name') can be given in the
+			// request body. Deprecated worlds rank lower in lists and searches.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: World
+			gz.Method{
+				Type:        "POST",
+				Description: "Deprecates a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WorldDeprecate))},
+				},
+			},
+			// swagger:route DELETE /{username}/worlds/{world}/deprecation worlds undeprecateWorld
+			//
+			// Removes the deprecation of a world
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: World
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Removes the deprecation of a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, WorldUndeprecate))},
+				},
+			},
+		},
+	},
+
+	// Route that handles the collaborators of a world
+	gz.Route{
+		Name:        "WorldCollaborators",
+		Description: "Users and teams a world is shared with",
+		URI:         "/{username}/worlds/{world}/collaborators",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/worlds/{world}/collaborators worlds listWorldCollaborators
+			//
+			// List of collaborators of a world
+			//
+			// Returns the users and organization teams the world is shared with.
+			// Only the owner, or admins of the owner organization, can see them.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Collaborators
+			gz.Method{
+				Type:        "GET",
+				Description: "List of collaborators of a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, ResourceCollaborators("world")))},
+				},
+			},
+			// swagger:route POST /{username}/worlds/{world}/collaborators worlds addWorldCollaborator
+			//
+			// Shares a world with a user or team
+			//
+			// Grants 'read' or 'write' access to a user ('username') or to an
+			// organization team ('organization' and 'team'). Sharing again with
+			// the same user or team updates the permission.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Collaborator
+			gz.Method{
+				Type:        "POST",
+				Description: "Shares a world with a user or team",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, ResourceCollaboratorAdd("world")))},
+				},
+			},
+			// swagger:route DELETE /{username}/worlds/{world}/collaborators worlds removeWorldCollaborator
+			//
+			// Stops sharing a world with a user or team
+			//
+			// The user or team is given with the 'username' or 'organization'
+			// and 'team' query parameters.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Collaborator
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Stops sharing a world with a user or team",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, ResourceCollaboratorRemove("world")))},
+				},
+			},
+		},
+	},
+
+	// Route that handles the share tokens of a world
+	gz.Route{
+		Name:        "WorldShareTokens",
+		Description: "Share tokens of a world",
+		URI:         "/{username}/worlds/{world}/share-tokens",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/worlds/{world}/share-tokens worlds listWorldShareTokens
+			//
+			// List of share tokens of a world
+			//
+			// Returns the share tokens of the world, including the expired ones.
+			// The token secrets are not included.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ShareTokens
+			gz.Method{
+				Type:        "GET",
+				Description: "List of share tokens of a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, ResourceShareTokens("world")))},
+				},
+			},
+			// swagger:route POST /{username}/worlds/{world}/share-tokens worlds createWorldShareToken
+			//
+			// Creates a share token for a world
+			//
+			// Creates a read-only token that expires at 'expires_at'. Anyone
+			// holding the token can download the zip, files and file tree of the
+			// world by adding the 'share_token' query parameter. The token is only
+			// returned in this response.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ShareTokenCreateResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Creates a share token for a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, ResourceShareTokenCreate("world")))},
+				},
+			},
+			// swagger:route DELETE /{username}/worlds/{world}/share-tokens worlds revokeWorldShareToken
+			//
+			// Revokes a share token of a world
+			//
+			// The token is given with the 'prefix' query parameter.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ShareToken
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Revokes a share token of a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, ResourceShareTokenRevoke("world")))},
+				},
+			},
+		},
+	},
+
+	// Route that explains who can read or write a world
+	gz.Route{
+		Name:        "WorldPermissions",
+		Description: "Effective permissions of a world",
+		URI:         "/{username}/worlds/{world}/permissions",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/worlds/{world}/permissions worlds getWorldPermissions
+			//
+			// Effective permissions of a world
+			//
+			// Lists every user that can read or write the world, and how each
+			// grant was derived: direct, organization, team or sysadmin. With the
+			// 'user' query parameter, it evaluates a single user and returns the
+			// reasoning chain. Only owners, admins and sysadmins can request it.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ResourcePermissions
+			gz.Method{
+				Type:        "GET",
+				Description: "Effective permissions of a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", true, ResourcePermissions("world")))},
+				},
+			},
+		},
+	},
+
+	// Route that handles world reports
+	gz.Route{
+		Name:        "ReportWorld",
+		Description: "Report a world",
+		URI:         "/{username}/worlds/{world}/report",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route POST /{username}/worlds/{world}/report worlds reportWorld
+			//
+			// Reports a world.
+			//
+			// Public worlds reported by several users in a short period are
+			// hidden from lists and search results, pending moderator review.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Reports a world",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("world", false, ReportWorldCreate)))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that returns the modelIncludes of a world.
+	gz.Route{
+		Name:        "WorldModelIncludes",
+		Description: "Route that returns the external models referenced by a world",
+		URI:         "/{username}/worlds/{world}/{version}/{world}/modelrefs",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/worlds/{world}/{version}/{world}/modelrefs worlds worldModelIncludes
+			//
+			// World's model references.
+			//
+			// Return the external models referenced by a world.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ModelIncludes
+			gz.Method{
+				Type:        "GET",
+				Description: "World's ModelIncludes ",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldModelReferences))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldModelReferences))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	/////////////////
+	// Collections //
+	/////////////////
+
+	// Route for all Collections
+	gz.Route{
+		Name:        "Collection",
+		Description: "Information about all collections",
+		URI:         "/collections",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /collections collections listCollections
+			//
+			// Get list of collections.
+			//
+			// Get a list of collections. Collections will be returned paginated,
+			// with pages of 20 items by default. The user can request a
+			// different page with query parameter 'page', and the page size
+			// can be defined with query parameter 'per_page'.
+			// The route supports the 'order' parameter, with values 'asc' and
+			// 'desc' (default: desc).
+			// It also supports the 'q' parameter to perform a fulltext search on
+			// name and description.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollections
+			gz.Method{
+				Type:        "GET",
+				Description: "Get all collections",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(SearchHandler(CollectionList))},
+					gz.FormatHandler{Handler: gz.JSONResult(SearchHandler(CollectionList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /collections collections createCollection
+			//
+			// Create collection
+			//
+			// Creates a new collection. The request body should contain the
+			// following fields: 'name', 'description'. All values as strings.
+			// The collection owner will be retrieved from the passed JWT.
+			//
+			//   Consumes:
+			//   - multipart/form-data
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollection
+			gz.Method{
+				Type:        "POST",
+				Description: "Create a new collection",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(CollectionCreate)},
+				},
+			},
+		},
+	},
+
+	// Route that returns a list of collections from a team/user (ie. an 'owner')
+	gz.Route{
+		Name: "OwnerCollections",
+		Description: "Information about worlds belonging to an owner. The {username} URI option " +
+			"will limit the scope to the specified user/team. Otherwise all collections are considered.",
+		URI:     "/{username}/collections",
+		Headers: gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/collections collections listOwnerCollections
+			//
+			// Get owner's collections
+			//
+			// Get a list of collections for the specified owner.
+			// Collections will be returned paginated,
+			// with pages of 20 items by default. The user can request a
+			// different page with query parameter 'page' (first page is value 1).
+			// The page size can be controlled with query parameter 'per_page',
+			// with a maximum of 10belonging0 items per page.
+			// The route supports the 'order' parameter, with values 'asc' and
+			// 'desc' (default: desc).
+			// It also supports the 'q' parameter to perform a fulltext search on
+			// name and description.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollections
+			gz.Method{
+				Type:        "GET",
+				Description: "Get all collections of the specified team/user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(SearchHandler(CollectionList))},
+					gz.FormatHandler{Handler: gz.JSONResult(SearchHandler(CollectionList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that returns a Collection, by name, from a team/user
+	gz.Route{
+		Name:        "CollectionIndex",
+		Description: "Information about a collection belonging to an owner.",
+		URI:         "/{username}/collections/{collection}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/collections/{collection} collections singleOwnerCollection
+			//
+			// Get a single collection from an owner
+			//
+			// Return a collection given its owner and name.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollection
+			gz.Method{
+				Type:        "GET",
+				Description: "Get a collection belonging to the specified team/user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionIndex))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /{username}/collections/{collection} collections collectionUpdate
+			//
+			// Update a collection
+			//
+			// Update a collection
+			//
+			//   Consumes:
+			//   - multipart/form-data
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollection
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Edit a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, CollectionUpdate))},
+				},
+			},
+			// swagger:route DELETE /{username}/collections/{collection} collection deleteCollection
+			//
+			// Delete a Collection
+			//
+			// Deletes a Collection given its owner and name.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Deletes a single collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionRemove)))},
+				},
+			},
+		},
+	},
+
+	gz.Route{
+		Name:        "OwnerCollectionTransfer",
+		Description: "Transfer a collection to another owner.",
+		URI:         "/{username}/collections/{collection}/transfer",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/collections/{collection}/transfer collections collectionTransfer
+			//
+			// Transfer a collection
+			//
+			//   Consumes:
+			//   - multipart/form-data
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Model
+			gz.Method{
+				Type:        "POST",
+				Description: "Transfer a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, CollectionTransfer))},
+				},
+			},
+		},
+	},
+
+	// Route that proposes the transfer of a collection to another owner
+	gz.Route{
+		Name:        "OwnerCollectionTransferRequest",
+		Description: "Propose the transfer of a collection to another owner.",
+		URI:         "/{username}/collections/{collection}/transfer-requests",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/collections/{collection}/transfer-requests collections collectionTransferRequest
+			//
+			// Propose the transfer of a collection
+			//
+			// The collection is only transferred once the destination owner accepts
+			// the request.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TransferRequestResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Propose the transfer of a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, TransferRequestCreate("collection")))},
+				},
+			},
+		},
+	},
+
+	// Route that makes the JWT user watch a collection
+	gz.Route{
+		Name:        "OwnerCollectionWatch",
+		Description: "Watch a collection to be notified of its changes.",
+		URI:         "/{username}/collections/{collection}/watch",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/collections/{collection}/watch collections collectionWatchCreate
+			//
+			// Watch a collection
+			//
+			// The watchers of a collection are notified when it changes.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
+			gz.Method{
+				Type:        "POST",
+				Description: "Watch a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, WatchCreate("collection")))},
+				},
+			},
+			// swagger:route DELETE /{username}/collections/{collection}/watch collections collectionWatchRemove
+			//
+			// Stop watching a collection
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Stop watching a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, WatchRemove("collection")))},
+				},
+			},
+		},
+	},
+	// Route that clones a collection
+	gz.Route{
+		Name:        "CloneCollection",
+		Description: "Clone a collection",
+		URI:         "/{username}/collections/{collection}/clone",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/collections/{collection}/clone collections cloneCollection
+			//
+			// Clones a collection
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Clones a collection",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionClone))},
+				},
+			},
+		},
+	},
+
+	// Route that handles the collaborators of a collection
+	gz.Route{
+		Name:        "CollectionCollaborators",
+		Description: "Users and teams a collection is shared with",
+		URI:         "/{username}/collections/{collection}/collaborators",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/collections/{collection}/collaborators collections listCollectionCollaborators
+			//
+			// List of collaborators of a collection
+			//
+			// Returns the users and organization teams the collection is shared with.
+			// Only the owner, or admins of the owner organization, can see them.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Collaborators
+			gz.Method{
+				Type:        "GET",
+				Description: "List of collaborators of a collection",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, ResourceCollaborators("collection")))},
+				},
+			},
+			// swagger:route POST /{username}/collections/{collection}/collaborators collections addCollectionCollaborator
+			//
+			// Shares a collection with a user or team
+			//
+			// Grants 'read' or 'write' access to a user ('username') or to an
+			// organization team ('organization' and 'team'). Sharing again with
+			// the same user or team updates the permission.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Collaborator
+			gz.Method{
+				Type:        "POST",
+				Description: "Shares a collection with a user or team",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, ResourceCollaboratorAdd("collection")))},
+				},
+			},
+			// swagger:route DELETE /{username}/collections/{collection}/collaborators collections removeCollectionCollaborator
+			//
+			// Stops sharing a collection with a user or team
+			//
+			// The user or team is given with the 'username' or 'organization'
+			// and 'team' query parameters.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Collaborator
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Stops sharing a collection with a user or team",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, ResourceCollaboratorRemove("collection")))},
+				},
+			},
+		},
+	},
+
+	// Route that explains who can read or write a collection
+	gz.Route{
+		Name:        "CollectionPermissions",
+		Description: "Effective permissions of a collection",
+		URI:         "/{username}/collections/{collection}/permissions",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/collections/{collection}/permissions collections getCollectionPermissions
+			//
+			// Effective permissions of a collection
+			//
+			// Lists every user that can read or write the collection, and how each
+			// grant was derived: direct, organization, team or sysadmin. With the
+			// 'user' query parameter, it evaluates a single user and returns the
+			// reasoning chain. Only owners, admins and sysadmins can request it.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ResourcePermissions
+			gz.Method{
+				Type:        "GET",
+				Description: "Effective permissions of a collection",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, ResourcePermissions("collection")))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the list of forks of a collection
+	gz.Route{
+		Name:        "CollectionForks",
+		Description: "List of collections that were cloned from a collection",
+		URI:         "/{username}/collections/{collection}/forks",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/collections/{collection}/forks collections collectionForks
+			//
+			// List of forks of a collection.
+			//
+			// Return the paginated list of collections that were cloned from a collection.
+			// Only forks visible to the requesting user are returned.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollections
+			gz.Method{
+				Type:        "GET",
+				Description: "List of forks of a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionForks))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", false, CollectionForks))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that handles reports of a collection
+	gz.Route{
+		Name:        "ReportCollection",
+		Description: "Reports of a collection",
+		URI:         "/{username}/collections/{collection}/report",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route POST /{username}/collections/{collection}/report collections reportCollection
+			//
+			// Report a collection
+			//
+			// The report is added to the moderation queue of the system admins.
+			//
+			//   Consumes:
+			//   - multipart/form-data
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Report a collection",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", false, ReportCollectionCreate)))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that restores a deleted collection
+	gz.Route{
+		Name:        "RestoreCollection",
+		Description: "Restore a deleted collection",
+		URI:         "/{username}/collections/{collection}/restore",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/collections/{collection}/restore collections restoreCollection
+			//
+			// Restores a deleted collection
+			//
+			// Restores a collection from the owner's trash, as long as it was deleted
+			// within the trash retention window. An optional 'name' can be given
+			// in the request body in case the original name is already taken.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Restores a deleted collection",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("collection", true, CollectionRestore))},
+				},
+			},
+		},
+	},
+
+	// Route that downloads an individual file from a collection.
+	// It is used to download the collection logo and banner.
+	gz.Route{
+		Name:        "CollectionIndividualFileDownload",
+		Description: "Download individual file from a collection.",
+		URI:         "/{username}/collections/{collection}/{version}/files/{path:.+}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/collections/{collection}/{version}/files/{path} collections downloadColFile
+			//
+			// Download an individual file from a collection.
+			//
+			//   Produces:
+			//   - application/octet-stream
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: fileResponse
+			gz.Method{
+				Type:        "GET",
+				Description: "GET a file",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", false, CollectionIndividualFileDownload)))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route to list, add and remove models from collections.
+	gz.Route{
+		Name:        "CollectionModels",
+		Description: "Information about models from a collection",
+		URI:         "/{username}/collections/{collection}/models",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/collections/{collection}/models collections collectionModels
+			//
+			// Lists the models of a collection
+			//
+			// Return the list of models that belong to a collection
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollectionAssets
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the models associated to a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Models", NameOwnerHandler("collection", false, CollectionModelsList))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("collection", false, CollectionModelsList))},
+					gz.FormatHandler{Handler: gz.JSONListResult("Models", NameOwnerHandler("collection", false, CollectionModelsList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/collections/{collection}/models collections collectionModelAdd
+			//
+			// Add a model to a collection
+			//
+			// Adds a model to a collection
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Add a model to a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionModelAdd)))},
+				},
+			},
+			// swagger:route DELETE /{username}/collections/{collection}/models collection collectionModelRemove
+			//
+			// Remove model from Collection
+			//
+			// Removes a model from a Collection
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Removes a model from a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionModelRemove)))},
+				},
+			},
+		},
+	},
+
+	// Route to list, add and remove worlds from collections.
+	gz.Route{
+		Name:        "CollectionWorlds",
+		Description: "Information about Worlds from a collection",
+		URI:         "/{username}/collections/{collection}/worlds",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/collections/{collection}/worlds collections collectionWorlds
+			//
+			// Lists the worlds of a collection
+			//
+			// Return the list of worlds that belong to a collection
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollectionAssets
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the worlds associated to a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONListResult("Worlds", NameOwnerHandler("collection", false, CollectionWorldsList))},
+					gz.FormatHandler{Extension: ".proto", Handler: gz.ProtoResult(NameOwnerHandler("collection", false, CollectionWorldsList))},
+					gz.FormatHandler{Handler: gz.JSONListResult("Worlds", NameOwnerHandler("collection", false, CollectionWorldsList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/collections/{collection}/worlds collections collectionWorldAdd
+			//
+			// Add a world to a collection
+			//
+			// Adds a world to a collection
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Add a world to a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionWorldAdd)))},
+				},
+			},
+			// swagger:route DELETE /{username}/collections/{collection}/worlds collection collectionWorldRemove
+			//
+			// Remove world from Collection
+			//
+			// Removes a world from a Collection
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Removes a world from a collection",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameOwnerHandler("collection", true, CollectionWorldRemove)))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the list of collections associated to a model
+	gz.Route{
+		Name:        "ModelCollections",
+		Description: "List of collections associated to a model.",
+		URI:         "/{username}/models/{model}/collections",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/models/{model}/collections collections modelCollections
+			//
+			// List of collections associated to a model.
+			//
+			// List of collections associated to a model.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollections
+			gz.Method{
+				Type:        "GET",
+				Description: "List of collections associated to a model",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelCollections))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("model", false, ModelCollections))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that returns the list of collections associated to a world
+	gz.Route{
+		Name:        "WorldCollections",
+		Description: "List of collections associated to a world.",
+		URI:         "/{username}/worlds/{world}/collections",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /{username}/worlds/{world}/collections collections worldCollections
+			//
+			// List of collections associated to a world.
+			//
+			// List of collections associated to a world.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: dbCollections
+			gz.Method{
+				Type:        "GET",
+				Description: "List of collections associated to a world",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldCollections))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameOwnerHandler("world", false, WorldCollections))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Route that returns the trash of an owner
+	gz.Route{
+		Name:        "OwnerTrash",
+		Description: "Deleted resources of an owner that can still be restored",
+		URI:         "/{username}/trash",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/trash trash ownerTrash
+			//
+			// Get the trash of an owner
+			//
+			// Returns the list of models, worlds and collections deleted by an
+			// owner that can still be restored. Only the owner, or members of
+			// the owner organization with write access, can see it.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TrashItems
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the trash of an owner",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("username", true, OwnerTrash))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, OwnerTrash))},
+				},
+			},
+		},
+	},
+
+	///////////
+	// Users //
+	///////////
+
+	// Route that returns login information for a given JWT
+	gz.Route{
+		Name:        "Login",
+		Description: "Login a user",
+		URI:         "/login",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /login users loginUser
+			//
+			// Login user
+			//
+			// Returns information about the user associated with the given JWT.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "GET",
+				Description: "Login a user",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(Login)},
+				},
+			},
+		},
+	},
+
+	// Route that returns information about all users
+	gz.Route{
+		Name:        "Users",
+		Description: "Route for all users",
+		URI:         "/users",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users users listUsers
+			//
+			// Get a list of users. Access limited to administrators.
+			//
+			// Returns a paginated list of users,
+			// with pages of 20 users by default. Only system administrators can
+			// access this route. The administrator can request a
+			// different page with query parameter 'page' (first page is value 1).
+			// The page size can be controlled with query parameter 'per_page',
+			// with a maximum of 100 items per page.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
+			//   + name: page
+			//     description: Request a specific page of users.
+			//     in: query
+			//     required: false
+			//     type: integer
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get all users information",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(UserList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(UserList))},
+				},
+			},
+			// swagger:route POST /users users createUser
+			//
+			// Create user
+			//
+			// Creates a new user. Note: the user identity will be retrieved from the passed JWT.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Create a new user",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(UserCreate)},
+				},
+			},
+		},
+	},
+
+	// Route that returns information about a user
+	gz.Route{
+		Name:        "UserIndex",
+		Description: "Access information about a single user.",
+		URI:         "/users/{username}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /users/{username} users singleUser
+			//
+			// Get a user
+			//
+			// Return a user given its username and a valid JWT.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "GET",
+				Description: "Get user information",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("username", false, UserIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", false, UserIndex))},
+				},
+			},
+		},
+
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /users/{username} users deleteUser
+			//
+			// Delete a user
+			//
+			// Deletes a user given its username and a valid JWT.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Remove a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, UserRemove))},
+				},
+			},
+
+			// swagger:route PATCH /users/{username} users updateUser
+			//
+			// Update a user
+			//
+			// Updates a user given its username and a valid JWT.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Update a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, UserUpdate))},
+				},
+			},
+		},
+	},
+
+	// Route that handles reports of a user
+	gz.Route{
+		Name:        "ReportUser",
+		Description: "Reports of a user",
+		URI:         "/users/{username}/report",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route POST /users/{username}/report users reportUser
+			//
+			// Report a user
+			//
+			// The report is added to the moderation queue of the system admins.
+			//
+			//   Consumes:
+			//   - multipart/form-data
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OK
+			gz.Method{
+				Type:        "POST",
+				Description: "Report a user",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameHandler("username", false, ReportUserCreate)))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	// Routes to get and create access tokens.
+	gz.Route{
+		Name:        "AccessTokens",
+		Description: "Routes to get and create access tokens.",
+		URI:         "/users/{username}/access-tokens",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/access-tokens users getAccessToken
+			//
+			// Get the acccess tokens for a user.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			gz.Method{
+				Type:        "GET",
+				Description: "Get a user's access tokens",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(AccessTokenList, true))},
+				},
+			},
+
+			// swagger:route POST /users/{username}/access-tokens users createAccessToken
+			//
+			// Creates an access token.
+			//
+			// Creates an access token for a user. Optional 'scopes' restrict the
+			// requests the token can make: 'read', 'models:write', 'worlds:write',
+			// 'org:{name}:admin' and 'resource:{owner}/{models|worlds|collections}/{name}'.
+			// An optional 'expires' date can also be given.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			gz.Method{
+				Type:        "POST",
+				Description: "Create an access token",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, AccessTokenCreate))},
+				},
+			},
+		},
+	},
+
+	// Routes to revoke access tokens
+	gz.Route{
+		Name:        "AccessTokens",
+		Description: "Route to revoke access tokens.",
+		URI:         "/users/{username}/access-tokens/revoke",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /users/{username}/access-tokens/revoke users revokeAccessToken
+			//
+			// Delete an acccess token that belongs to a user.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			gz.Method{
+				Type:        "POST",
+				Description: "Delete a user's access token",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, AccessTokenDelete))},
+				},
+			},
+		},
+	},
+
+	// Route that exports the data of a user
+	gz.Route{
+		Name:        "UserExport",
+		Description: "Route to export the data of a user.",
+		URI:         "/users/{username}/export",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/export users userExport
+			//
+			// Export the data of a user
+			//
+			// Return a zip archive with the profile, organizations, likes,
+			// reviews, access tokens metadata and all the owned models, worlds
+			// and collections of the user.
+			//
+			//   Produces:
+			//   - application/zip
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			gz.Method{
+				Type:        "GET",
+				Description: "Export the data of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(NoResult(NameHandler("username", true, UserExport)))},
+				},
+			},
+		},
+	},
+	// Route that deletes a user account
+	gz.Route{
+		Name:        "UserAccountDeletion",
+		Description: "Route to delete a user account, handing off its resources.",
+		URI:         "/users/{username}/account-deletion",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /users/{username}/account-deletion users userAccountDeletion
+			//
+			// Delete a user account
+			//
+			// The request must say whether to transfer or delete each resource
+			// owned by the user. Access tokens, permissions and organization
+			// and team memberships of the user are removed.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: AccountDeletionResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Delete a user account",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, UserAccountDelete))},
+				},
+			},
+		},
+	},
+
+	// Route that returns and updates the owners followed by a user
+	gz.Route{
+		Name:        "UserFollowing",
+		Description: "Route to list and follow users and organizations.",
+		URI:         "/users/{username}/following",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /users/{username}/following users userFollowing
+			//
+			// Get the users and organizations followed by a user
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Follows
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the users and organizations followed by a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(FollowingList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(FollowingList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /users/{username}/following users userFollow
+			//
+			// Follow a user or organization
+			//
+			// The activities of the followed users and organizations are
+			// listed in the user feed.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Follow
+			gz.Method{
+				Type:        "POST",
+				Description: "Follow a user or organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, FollowCreate))},
+				},
+			},
+		},
+	},
+	// Route to stop following a user or organization
+	gz.Route{
+		Name:        "UserFollowingRemove",
+		Description: "Route to stop following a user or organization.",
+		URI:         "/users/{username}/following/{owner}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /users/{username}/following/{owner} users userUnfollow
+			//
+			// Stop following a user or organization
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Follow
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Stop following a user or organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, FollowRemove))},
+				},
+			},
+		},
+	},
+	// Route that returns the activity feed of a user
+	gz.Route{
+		Name:        "UserFeed",
+		Description: "Route to get the activities of the users and organizations followed by a user.",
+		URI:         "/users/{username}/feed",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/feed users userFeed
+			//
+			// Get the activity feed of a user
+			//
+			// Return the creations, new versions, collection changes and likes
+			// of the users and organizations followed by the user, newest
+			// first. Activities on private resources are not included.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Activities
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the activity feed of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(UserFeed, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(UserFeed, true))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the resources watched by a user
+	gz.Route{
+		Name:        "UserWatches",
+		Description: "Route to list the models, worlds and collections watched by a user.",
+		URI:         "/users/{username}/watches",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/watches users userWatches
+			//
+			// Get the resources watched by a user
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: WatchResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the resources watched by a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(UserWatchesList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(UserWatchesList, true))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the notifications of a user
+	gz.Route{
+		Name:        "UserNotifications",
+		Description: "Route to list the notifications of a user.",
+		URI:         "/users/{username}/notifications",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/notifications users userNotifications
+			//
+			// Get the notifications of a user
+			//
+			// Return the notifications about the resources watched by the
+			// user, newest first. Use unread=true to only get the unread
+			// notifications.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Notifications
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the notifications of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(NotificationsList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(NotificationsList, true))},
+				},
+			},
+		},
+	},
+
+	// Route that marks all the notifications of a user as read
+	gz.Route{
+		Name:        "UserNotificationsReadAll",
+		Description: "Route to mark all the notifications of a user as read.",
+		URI:         "/users/{username}/notifications/read-all",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /users/{username}/notifications/read-all users userNotificationsReadAll
+			//
+			// Mark all the notifications of a user as read
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
+			gz.Method{
+				Type:        "POST",
+				Description: "Mark all the notifications of a user as read",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, NotificationsReadAll))},
+				},
+			},
+		},
+	},
+
+	// Route that marks a notification as read or unread
+	gz.Route{
+		Name:        "UserNotificationUpdate",
+		Description: "Route to mark a notification as read or unread.",
+		URI:         "/users/{username}/notifications/{id}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /users/{username}/notifications/{id} users userNotificationUpdate
+			//
+			// Mark a notification as read or unread
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Mark a notification as read or unread",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, NotificationUpdate))},
+				},
+			},
+		},
+	},
+
+	// Route that manages the notification preferences of a user
+	gz.Route{
+		Name:        "UserNotificationPreferences",
+		Description: "Route to get and update the notification preferences of a user.",
+		URI:         "/users/{username}/notification-preferences",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/notification-preferences users userNotificationPreferences
+			//
+			// Get the notification preferences of a user
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Preferences
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the notification preferences of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, NotificationPreferencesIndex))},
+				},
+			},
+			// swagger:route PATCH /users/{username}/notification-preferences users updateNotificationPreferences
+			//
+			// Update the notification preferences of a user
+			//
+			// Choose which notifications are also sent by email.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Preferences
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Update the notification preferences of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, NotificationPreferencesUpdate))},
+				},
+			},
+		},
+	},
+
+	// Route that manages the webhooks of a user
+	gz.Route{
+		Name:        "UserWebhooks",
+		Description: "Route to list and create the webhooks of a user.",
+		URI:         "/users/{username}/webhooks",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/webhooks users userWebhooks
+			//
+			// Get the webhooks of a user
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Webhooks
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the webhooks of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookList, true))},
+				},
+			},
+			// swagger:route POST /users/{username}/webhooks users userWebhookCreate
+			//
+			// Create a webhook for a user
+			//
+			// The webhook receives signed JSON payloads on the lifecycle
+			// events of the user resources.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Webhook
+			gz.Method{
+				Type:        "POST",
+				Description: "Create a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, WebhookCreate))},
+				},
+			},
+		},
+	},
+
+	// Route that updates or removes a webhook of a user
+	gz.Route{
+		Name:        "UserWebhook",
+		Description: "Route to update or remove a webhook of a user.",
+		URI:         "/users/{username}/webhooks/{id}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /users/{username}/webhooks/{id} users userWebhookUpdate
+			//
+			// Update a webhook of a user
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Webhook
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Update a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, WebhookUpdate))},
+				},
+			},
+			// swagger:route DELETE /users/{username}/webhooks/{id} users userWebhookRemove
+			//
+			// Remove a webhook of a user and its delivery log
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Remove a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, WebhookRemove))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the delivery log of a webhook of a user
+	gz.Route{
+		Name:        "UserWebhookDeliveries",
+		Description: "Route to get the delivery log of a webhook of a user.",
+		URI:         "/users/{username}/webhooks/{id}/deliveries",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/webhooks/{id}/deliveries users userWebhookDeliveries
+			//
+			// Get the delivery log of a webhook
+			//
+			// Return the payloads sent to the webhook, newest first, with
+			// their status, number of attempts and last response.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: WebhookDeliveries
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the delivery log of a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookDeliveryList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookDeliveryList, true))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the pending organization invitations of a user
+	gz.Route{
+		Name:        "UserInvitations",
+		Description: "Route to list the pending organization invitations of a user.",
+		URI:         "/users/{username}/invitations",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /users/{username}/invitations users userInvitations
+			//
+			// Get the pending organization invitations of a user
+			//
+			// Return the invitations sent to the user username or email that
+			// were not accepted, declined, revoked nor expired.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: InvitationResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the pending invitations of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(UserInvitationsList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(UserInvitationsList, true))},
+				},
+			},
+		},
+	},
+	// Route to accept an organization invitation
+	gz.Route{
+		Name:        "UserInvitationAccept",
+		Description: "Route to accept an organization invitation.",
+		URI:         "/users/{username}/invitations/{id}/accept",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /users/{username}/invitations/{id}/accept users acceptInvitation
+			//
+			// Accept an organization invitation
+			//
+			// The user joins the organization with the invitation role, and
+			// the invitation teams.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: InvitationResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Accept an organization invitation",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, respondToInvitation(true)))},
+				},
+			},
+		},
+	},
+	// Route to decline an organization invitation
+	gz.Route{
+		Name:        "UserInvitationDecline",
+		Description: "Route to decline an organization invitation.",
+		URI:         "/users/{username}/invitations/{id}/decline",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /users/{username}/invitations/{id}/decline users declineInvitation
+			//
+			// Decline an organization invitation
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: InvitationResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Decline an organization invitation",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, respondToInvitation(false)))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the pending transfer requests of an owner
+	gz.Route{
+		Name:        "OwnerTransfers",
+		Description: "Route to list the pending transfer requests of a user or organization.",
+		URI:         "/{username}/transfers",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /{username}/transfers users ownerTransfers
+			//
+			// Get the pending transfer requests of an owner
+			//
+			// Return both the incoming and outgoing transfer requests that
+			// were not accepted, rejected, cancelled nor expired. Use the
+			// direction query parameter (incoming or outgoing) to only get
+			// one side.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TransferRequestResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the pending transfer requests of an owner",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(OwnerTransfersList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(OwnerTransfersList, true))},
+				},
+			},
+		},
+	},
+	// Route to cancel a transfer request
+	gz.Route{
+		Name:        "OwnerTransferCancel",
+		Description: "Route to cancel a transfer request.",
+		URI:         "/{username}/transfers/{id}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /{username}/transfers/{id} users cancelTransfer
+			//
+			// Cancel a transfer request
+			//
+			// Only the source owner can cancel a pending transfer request.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TransferRequestResponse
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Cancel a transfer request",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, TransferRequestCancel))},
+				},
+			},
+		},
+	},
+	// Route to accept a transfer request
+	gz.Route{
+		Name:        "OwnerTransferAccept",
+		Description: "Route to accept a transfer request.",
+		URI:         "/{username}/transfers/{id}/accept",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/transfers/{id}/accept users acceptTransfer
+			//
+			// Accept a transfer request
+			//
+			// The resource is moved to the destination owner.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TransferRequestResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Accept a transfer request",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, TransferRequestRespond(true)))},
+				},
+			},
+		},
+	},
+	// Route to reject a transfer request
+	gz.Route{
+		Name:        "OwnerTransferReject",
+		Description: "Route to reject a transfer request.",
+		URI:         "/{username}/transfers/{id}/reject",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /{username}/transfers/{id}/reject users rejectTransfer
+			//
+			// Reject a transfer request
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TransferRequestResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Reject a transfer request",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, TransferRequestRespond(false)))},
+				},
+			},
+		},
+	},
+
+	// Route that returns the details of a single user or organization
+	gz.Route{
+		Name:        "OwnerProfile",
+		Description: "Access the details of a single user OR organization.",
+		URI:         "/profile/{username}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /profile/{username} users ownerProfile
+			//
+			// Get the profile of an owner
+			//
+			// Get the profile of an owner
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OwnerProfile
+			gz.Method{
+				Type:        "GET",
+				Description: "Get profile information",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("username", false, OwnerProfile))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", false, OwnerProfile))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	//////////////
+	// Licenses //
+	//////////////
+
+	// Route that returns information about all available licenses
+	gz.Route{
+		Name:        "Licenses",
+		Description: "Route for all licenses",
+		URI:         "/licenses",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /licenses licenses listLicenses
+			//
+			// List licenses
+			//
+			// Get the list of licenses. Licenses will be returned paginated,
+			// with pages of 20 items by default. The user can request a
+			// different page with query parameter 'page' (first page is value 1).
+			// The page size can be controlled with query parameter 'per_page',
+			// with a maximum of 100 items per page.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Licenses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get all licenses",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(LicenseList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(LicenseList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
+
+	//////////////
+	// Categories //
+	//////////////
+
+	// Categories route with slug
+	// PATCH:
+	gz.Route{
+		Name:        "Categories",
+		Description: "Routes for categories with slug",
+		URI:         "/categories/{slug}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Update a category",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{
+						Extension: "",
+						Handler:   gz.JSONResult(CategoryUpdate),
+					},
+				},
+			},
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Delete a category",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{
+						Extension: "",
+						Handler:   gz.JSONResult(CategoryDelete),
+					},
+				},
+			},
+		},
+	},
+
+	// Categories route
+	// GET: Get the list of categories
+	// POST: Create a new category
+	gz.Route{
+		Name:        "Categories",
+		Description: "Route for categories",
+		URI:         "/categories",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			gz.Method{
+				Type:        "GET",
+				Description: "Get all categories",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{
+						Extension: ".json",
+						Handler:   gz.JSONResult(CategoryList),
+					},
+					gz.FormatHandler{
+						Extension: "",
+						Handler:   gz.JSONResult(CategoryList),
+					},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			gz.Method{
+				Type:        "POST",
+				Description: "Create a new category",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{
+						Extension: "",
+						Handler:   gz.JSONResult(CategoryCreate),
+					},
+				},
+			},
+		},
+	},
+
+	///////////////////
+	// Organizations //
+	///////////////////
+
+	// Route that returns information about all organizations
+	gz.Route{
+		Name:        "Organizations",
+		Description: "Route for all organizations",
+		URI:         "/organizations",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /organizations organizations listOrganizations
+			//
+			// List organizations
+			//
+			// Get the list of organizations. Organizations will be returned paginated,
+			// with pages of 20 organizations by default. The user can request a
+			// different page with query parameter 'page' (first page is value 1).
+			// The page size can be controlled with query parameter 'per_page',
+			// with a maximum of 100 items per page.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get all organizations information",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(OrganizationList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(OrganizationList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /organizations organizations createOrganization
+			//
+			// Create organization
+			//
+			// Creates a new organization. Note: the user identity will be retrieved from the passed JWT.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Create a new organization",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(OrganizationCreate)},
+				},
+			},
+		},
+	},
+
+	// Route that returns information about an organization
+	gz.Route{
+		Name:        "OrganizationIndex",
+		Description: "Access information about a single organization.",
+		URI:         "/organizations/{name}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /organizations/{name} organizations singleOrganization
+			//
+			// Get an organization
+			//
+			// Return an organization given its name and a valid JWT.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationResponse
+			gz.Method{
+				Type:        "GET",
+				Description: "Get organization information",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("name", false, OrganizationIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", false, OrganizationIndex))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /organizations/{name} organizations deleteOrganizations
+			//
+			// Delete an organization
+			//
+			// Deletes an organization given its name and a valid JWT.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationResponse
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Remove an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationRemove))},
+				},
+			},
+			// swagger:route PATCH /organizations/{name} organizations organizationUpdate
+			//
+			// Update an organization
+			//
+			// Update an organization
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationResponse
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Edit an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationUpdate))},
+				},
+			},
+		},
+	},
+	// Route that returns information about organization users
+	gz.Route{
+		Name:        "OrganizationUsers",
+		Description: "Base route to list of users of an Organization",
+		URI:         "/organizations/{name}/users",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /organizations/{name}/users organizations orgUsers
+			//
+			// Get the list of users of an organization
+			//
+			// Return the list of users of an organization.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the list of users of an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(OrganizationUserList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(OrganizationUserList))},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /organizations/{name}/users organizations addUserToOrganization
+			//
+			// Adds a user to an organization
+			//
+			// Adds a user to an organization.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Adds a user to an Organization",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationUserCreate))},
+				},
+			},
+		},
+	},
+	// Route that returns information about organization users
+	gz.Route{
+		Name:        "OrganizationUserUpdate",
+		Description: "Route to update and delete a member of an organization",
+		URI:         "/organizations/{name}/users/{username}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /organizations/{name}/users/{username} organizations orgUserDelete
+			//
+			// Removes a user from an organization
+			//
+			// Removes a user from an organization
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Removes a user from an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationUserRemove))},
+				},
+			},
+		},
+	},
+
+	// Route that returns information about organization teams
+	gz.Route{
+		Name:        "OrganizationTeams",
+		Description: "Base route to list of teams of an Organization",
+		URI:         "/organizations/{name}/teams",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/teams organizations orgTeams
 			//
 			// Get the list of teams of an organization
 			//
-			// Return the list of teams of an organization.
+			// Return the list of teams of an organization.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TeamResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the list of teams of an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(OrganizationTeamsList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(OrganizationTeamsList))},
+				},
+			},
+			// swagger:route POST /organizations/{name}/teams organizations addTeamToOrganization
+			//
+			// Adds a team to an organization
+			//
+			// Adds a team to an organization.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TeamResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Adds a team to an Organization",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamCreate))},
+				},
+			},
+		},
+	},
+	// Route that returns information about an organization team
+	gz.Route{
+		Name:        "OrganizationTeamIndex",
+		Description: "Route to get, update and delete a team of an organization",
+		URI:         "/organizations/{name}/teams/{teamname}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/teams/{teamname} organizations singleTeam
+			//
+			// Get a single team of an organization
+			//
+			// Return a team given its organization and team name.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TeamResponse
+			gz.Method{
+				Type:        "GET",
+				Description: "Get a team from an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamIndex))},
+				},
+			},
+			// swagger:route PATCH /organizations/{name}/teams/{teamname} organizations orgTeamUpdate
+			//
+			// Updates a team
+			//
+			// Updates a team of an organization
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TeamResponse
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Updates a team",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamUpdate))},
+				},
+			},
+			// swagger:route DELETE /organizations/{name}/teams/{teamname} organizations orgTeamDelete
+			//
+			// Removes a team
+			//
+			// Removes a team from an organization
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: TeamResponse
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Removes a team",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamRemove))},
+				},
+			},
+		},
+	},
+	// Route that returns information about organization custom roles
+	gz.Route{
+		Name:        "OrganizationRoles",
+		Description: "Base route to list and create custom roles of an Organization",
+		URI:         "/organizations/{name}/roles",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/roles organizations orgRoles
+			//
+			// Get the list of custom roles of an organization
+			//
+			// Return the list of custom roles of an organization, with the
+			// actions each role can perform.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationRoleResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the list of custom roles of an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("name", true, OrganizationRolesList))},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationRolesList))},
+				},
+			},
+			// swagger:route POST /organizations/{name}/roles organizations addRoleToOrganization
+			//
+			// Creates a custom role
+			//
+			// Creates a custom role in an organization. The role maps to a set of
+			// actions: read, write, delete, transfer and manage-members.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationRoleResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Creates a custom role in an Organization",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationRoleCreate))},
+				},
+			},
+		},
+	},
+	// Route to remove an organization custom role
+	gz.Route{
+		Name:        "OrganizationRoleIndex",
+		Description: "Route to delete a custom role of an organization",
+		URI:         "/organizations/{name}/roles/{role}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /organizations/{name}/roles/{role} organizations orgRoleDelete
+			//
+			// Removes a custom role
+			//
+			// Removes a custom role from an organization. The role cannot be
+			// assigned to any user.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: OrganizationRoleResponse
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Removes a custom role",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationRoleRemove))},
+				},
+			},
+		},
+	},
+	// Route that returns information about organization invitations
+	gz.Route{
+		Name:        "OrganizationInvitations",
+		Description: "Base route to list and create invitations of an Organization",
+		URI:         "/organizations/{name}/invitations",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/invitations organizations orgInvitations
+			//
+			// Get the list of pending invitations of an organization
+			//
+			// Return the list of pending invitations of an organization.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: InvitationResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the list of pending invitations of an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(OrganizationInvitationsList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(OrganizationInvitationsList, true))},
+				},
+			},
+			// swagger:route POST /organizations/{name}/invitations organizations orgInvitationCreate
+			//
+			// Invites a user to an organization
+			//
+			// Invites a user to an organization, by username or email, with
+			// a role and optional teams. The user joins the organization once
+			// the invitation is accepted.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: InvitationResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Invites a user to an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationInvitationCreate))},
+				},
+			},
+		},
+	},
+	// Route to revoke an organization invitation
+	gz.Route{
+		Name:        "OrganizationInvitationIndex",
+		Description: "Route to revoke a pending invitation of an organization",
+		URI:         "/organizations/{name}/invitations/{id}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /organizations/{name}/invitations/{id} organizations orgInvitationRevoke
+			//
+			// Revokes an invitation
+			//
+			// Revokes a pending invitation of an organization.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: InvitationResponse
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Revokes an invitation",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationInvitationRevoke))},
+				},
+			},
+		},
+	},
+	// Route that returns the audit log of an organization
+	gz.Route{
+		Name:        "OrganizationAudit",
+		Description: "Route to get the audit log of an organization",
+		URI:         "/organizations/{name}/audit",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/audit organizations orgAudit
+			//
+			// Get the audit log of an organization
+			//
+			// Return the list of mutating operations performed on the
+			// organization and its resources. Only organization owners can see
+			// the audit log. The list can be filtered with the actor, action,
+			// target, since and until query parameters.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: AuditEntries
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the audit log of an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(OrganizationAuditList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(OrganizationAuditList, true))},
+				},
+			},
+		},
+	},
+	// Route that manages the webhooks of an organization
+	gz.Route{
+		Name:        "OrganizationWebhooks",
+		Description: "Route to list and create the webhooks of an organization.",
+		URI:         "/organizations/{name}/webhooks",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/webhooks organizations orgWebhooks
+			//
+			// Get the webhooks of an organization
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Webhooks
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the webhooks of an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookList, true))},
+				},
+			},
+			// swagger:route POST /organizations/{name}/webhooks organizations orgWebhookCreate
+			//
+			// Create a webhook for an organization
+			//
+			// The webhook receives signed JSON payloads on the lifecycle
+			// events of the organization resources.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Webhook
+			gz.Method{
+				Type:        "POST",
+				Description: "Create a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, WebhookCreate))},
+				},
+			},
+		},
+	},
+	// Route that updates or removes a webhook of an organization
+	gz.Route{
+		Name:        "OrganizationWebhook",
+		Description: "Route to update or remove a webhook of an organization.",
+		URI:         "/organizations/{name}/webhooks/{id}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route PATCH /organizations/{name}/webhooks/{id} organizations orgWebhookUpdate
+			//
+			// Update a webhook of an organization
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: Webhook
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Update a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, WebhookUpdate))},
+				},
+			},
+			// swagger:route DELETE /organizations/{name}/webhooks/{id} organizations orgWebhookRemove
+			//
+			// Remove a webhook of an organization and its delivery log
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200:
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Remove a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, WebhookRemove))},
+				},
+			},
+		},
+	},
+	// Route that returns the delivery log of a webhook of an organization
+	gz.Route{
+		Name:        "OrganizationWebhookDeliveries",
+		Description: "Route to get the delivery log of a webhook of an organization.",
+		URI:         "/organizations/{name}/webhooks/{id}/deliveries",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/webhooks/{id}/deliveries organizations orgWebhookDeliveries
+			//
+			// Get the delivery log of a webhook
+			//
+			// Return the payloads sent to the webhook, newest first, with
+			// their status, number of attempts and last response.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: WebhookDeliveries
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the delivery log of a webhook",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookDeliveryList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(WebhookDeliveryList, true))},
+				},
+			},
+		},
+	},
+	// Route that returns information about organization service accounts
+	gz.Route{
+		Name:        "OrganizationServiceAccounts",
+		Description: "Base route to list and create service accounts of an Organization",
+		URI:         "/organizations/{name}/service-accounts",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /organizations/{name}/service-accounts organizations orgServiceAccounts
+			//
+			// Get the list of service accounts of an organization
+			//
+			// Return the list of service accounts of an organization.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponses
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the list of service accounts of an organization",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(OrganizationServiceAccountsList))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(OrganizationServiceAccountsList))},
+				},
+			},
+			// swagger:route POST /organizations/{name}/service-accounts organizations addServiceAccountToOrganization
+			//
+			// Creates a service account
+			//
+			// Creates a service account owned by an organization. Service accounts
+			// have no login and authenticate with access tokens.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "POST",
+				Description: "Creates a service account in an Organization",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationServiceAccountCreate))},
+				},
+			},
+		},
+	},
+	// Route to remove an organization service account
+	gz.Route{
+		Name:        "OrganizationServiceAccountIndex",
+		Description: "Route to delete a service account of an organization",
+		URI:         "/organizations/{name}/service-accounts/{username}",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /organizations/{name}/service-accounts/{username} organizations orgServiceAccountDelete
+			//
+			// Removes a service account
+			//
+			// Removes a service account from an organization, along with its access tokens.
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: UserResponse
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Removes a service account",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationServiceAccountRemove))},
+				},
+			},
+		},
+	},
+	// Route to create access tokens for an organization service account
+	gz.Route{
+		Name:        "OrganizationServiceAccountAccessTokens",
+		Description: "Route to create access tokens for a service account of an organization",
+		URI:         "/organizations/{name}/service-accounts/{username}/access-tokens",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /organizations/{name}/service-accounts/{username}/access-tokens organizations orgServiceAccountTokenCreate
+			//
+			// Creates an access token for a service account
+			//
+			// Creates a personal access token for a service account. The full
+			// token is only returned once.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			gz.Method{
+				Type:        "POST",
+				Description: "Creates an access token for a service account",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationServiceAccountTokenCreate))},
+				},
+			},
+		},
+	},
+	// Route to create an elastic search config
+	gz.Route{
+		Name:        "ElasticSearch",
+		Description: "Route to create an ElasticSearch config",
+		URI:         "/admin/search",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /admin/search search elasticSearchUpdate
+			//
+			// Get a list of the available ElasticSearch configurations.
+			//
+			// Zero or more ElasticSearch configurations may be specified. The
+			// configuration marked as `primary` is the active ElasticSearch server.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ElasticSearchConfigs
+			gz.Method{
+				Type:        "GET",
+				Description: "Gets a list of the ElasticSearch configs",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(ListElasticSearchHandler)},
+				},
+			},
+
+			// swagger:route POST /admin/search search elasticSearchUpdate
+			//
+			// Creates an ElasticSearch server configuration.
+			//
+			// Use this route to tell Fuel about a new ElasticSearch server.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
+			//   + name: address
+			//     description: URL address of an Elastic Search server.
+			//     in: body
+			//     required: true
+			//     type: string
+			//   + name: primary
+			//     description: "true" to make this configuration the primary config.
+			//     in: body
+			//     required: false
+			//     type: string
+			//     default: false
+			//   + name: username
+			//     description: Username for ElasticSearch authentication
+			//     in: body
+			//     required: false
+			//     type: string
+			//   + name: password
+			//     description: Password for ElasticSearch authentication
+			//     in: body
+			//     required: false
+			//     type: string
 			//
 			//   Produces:
 			//   - application/json
@@ -2250,25 +5615,76 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: TeamResponses
+			//     200: ElasticSearchConfig
 			gz.Method{
-				Type:        "GET",
-				Description: "Get the list of teams of an organization",
+				Type:        "POST",
+				Description: "Creates an ElasticSearch config",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandler(OrganizationTeamsList))},
-					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandler(OrganizationTeamsList))},
+					gz.FormatHandler{Handler: gz.JSONResult(CreateElasticSearchHandler)},
 				},
 			},
-			// swagger:route POST /organizations/{name}/teams organizations addTeamToOrganization
+		},
+	},
+	// Route to reconnect to the primary elastic search config
+	gz.Route{
+		Name:        "ElasticSearch",
+		Description: "Route to reconnect to the primary elastic search config",
+		URI:         "/admin/search/reconnect",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /admin/search/reconnect search elasticSearchUpdate
 			//
-			// Adds a team to an organization
+			// Reconnects to the primary ElasticSearch server.
 			//
-			// Adds a team to an organization.
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
 			//
-			//   Consumes:
+			//   Produces:
 			//   - application/json
 			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: AdminSearchResponse
+			gz.Method{
+				Type:        "GET",
+				Description: "Reconnect to the primary ElasticSearch config",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(ReconnectElasticSearchHandler)},
+				},
+			},
+		},
+	},
+	// Route to rebuild to the primary elastic search indices
+	gz.Route{
+		Name:        "ElasticSearch",
+		Description: "Route to rebuild to the primary elastic search indices",
+		URI:         "/admin/search/rebuild",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /admin/search/rebuild search elasticSearchUpdate
+			//
+			// Rebuilds the primary ElasticSearch indices.
+			//
+			// Rebuilding the indices may take several minutes. Use this route when
+			// or if the ElasticSearch indices have become out of date.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
+			//
 			//   Produces:
 			//   - application/json
 			//
@@ -2276,29 +5692,39 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: TeamResponse
+			//     200: AdminSearchResponse
 			gz.Method{
-				Type:        "POST",
-				Description: "Adds a team to an Organization",
+				Type:        "GET",
+				Description: "Rebuild the primary ElasticSearch indices",
+				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamCreate))},
+					gz.FormatHandler{Handler: gz.JSONResult(RebuildElasticSearchHandler)},
 				},
 			},
 		},
 	},
-	// Route that returns information about an organization team
+	// Route to update to the primary elastic search indices
 	gz.Route{
-		Name:        "OrganizationTeamIndex",
-		Description: "Route to get, update and delete a team of an organization",
-		URI:         "/organizations/{name}/teams/{teamname}",
+		Name:        "ElasticSearch",
+		Description: "Route to update to the primary elastic search indices",
+		URI:         "/admin/search/update",
 		Headers:     gz.AuthHeadersOptional,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /organizations/{name}/teams/{teamname} organizations singleTeam
+			// swagger:route GET /admin/search/update search elasticSearchUpdate
 			//
-			// Get a single team of an organization
+			// Updates the primary ElasticSearch servers indices.
 			//
-			// Return a team given its organization and team name.
+			// This route will populate the primary ElasticSearch server with new
+			// data contained in the Fuel database. This route may take several
+			// minutes to complete.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
 			//
 			//   Produces:
 			//   - application/json
@@ -2307,21 +5733,137 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: TeamResponse
+			//     200: AdminSearchResponse
 			gz.Method{
 				Type:        "GET",
-				Description: "Get a team from an organization",
+				Description: "Update the primary ElasticSearch indices",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamIndex))},
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamIndex))},
+					gz.FormatHandler{Handler: gz.JSONResult(UpdateElasticSearchHandler)},
 				},
 			},
-			// swagger:route PATCH /organizations/{name}/teams/{teamname} organizations orgTeamUpdate
+		},
+	},
+	// Route to manage an elastic search config
+	gz.Route{
+		Name:        "ElasticSearch",
+		Description: "Route to manage an ElasticSearch config",
+		URI:         "/admin/search/{config_id}",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route DELETE /admin/search/{config_id} search elasticSearchUpdate
 			//
-			// Updates a team
+			// Deletes an ElasticSearch server configuration.
 			//
-			// Updates a team of an organization
+			// Use this route to remove and ElasticSearch configuration.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
+			//   + name: config_id
+			//     description: ID of the ElasticSearch configuration.
+			//     in: path
+			//     required: true
+			//     type: integer
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ElasticSearchConfig
+			gz.Method{
+				Type:        "DELETE",
+				Description: "Deletes an ElasticSearch config",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(DeleteElasticSearchHandler)},
+				},
+			},
+			// swagger:route PATCH /admin/search/{config_id} search elasticSearchUpdate
+			//
+			// Updates an ElasticSearch server configuration.
+			//
+			// Set the username, password, address, and primary status of an
+			// ElasticSearch server configuration.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
+			//   + name: config_id
+			//     description: ID of the ElasticSearch configuration.
+			//     in: path
+			//     required: true
+			//     type: integer
+			//   + name: address
+			//     description: URL address of an Elastic Search server.
+			//     in: body
+			//     required: true
+			//     type: string
+			//   + name: primary
+			//     description: "true" to make this configuration the primary config.
+			//     in: body
+			//     required: false
+			//     type: string
+			//     default: false
+			//   + name: username
+			//     description: Username for ElasticSearch authentication
+			//     in: body
+			//     required: false
+			//     type: string
+			//   + name: password
+			//     description: Password for ElasticSearch authentication
+			//     in: body
+			//     required: false
+			//     type: string
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ElasticSearchConfig
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Modify an ElasticSearch config",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(ModifyElasticSearchHandler)},
+				},
+			},
+		},
+	},
+	// Route to check the consistency of the permission policies
+	gz.Route{
+		Name:        "PermissionsCheck",
+		Description: "Route to check and fix the consistency of the permission policies",
+		URI:         "/admin/permissions/check",
+		Headers:     gz.AuthHeadersOptional,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route GET /admin/permissions/check permissions permissionsCheck
+			//
+			// Checks the consistency of the permission policies.
+			//
+			// Finds orphaned policies, missing owner grants and stale team roles.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
 			//
 			//   Produces:
 			//   - application/json
@@ -2330,20 +5872,28 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: TeamResponse
+			//     200: CasbinReport
 			gz.Method{
-				Type:        "PATCH",
-				Description: "Updates a team",
+				Type:        "GET",
+				Description: "Check the consistency of the permission policies",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamUpdate))},
+					gz.FormatHandler{Handler: gz.JSONResult(CasbinConsistencyCheck)},
 				},
 			},
-			// swagger:route DELETE /organizations/{name}/teams/{teamname} organizations orgTeamDelete
+			// swagger:route POST /admin/permissions/check permissions permissionsFix
 			//
-			// Removes a team
+			// Fixes the consistency of the permission policies.
 			//
-			// Removes a team from an organization
+			// Removes orphaned policies and stale team roles, and adds missing
+			// owner grants.
+			//
+			//   Parameters:
+			//   + name: Private-Token
+			//     description: A personal access token.
+			//     in: header
+			//     required: true
+			//     type: string
 			//
 			//   Produces:
 			//   - application/json
@@ -2352,31 +5902,31 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: TeamResponse
+			//     200: CasbinReport
 			gz.Method{
-				Type:        "DELETE",
-				Description: "Removes a team",
+				Type:        "POST",
+				Description: "Fix the consistency of the permission policies",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("name", true, OrganizationTeamRemove))},
+					gz.FormatHandler{Handler: gz.JSONResult(CasbinConsistencyCheck)},
 				},
 			},
 		},
 	},
-	// Route to create an elastic search config
+	// Route to export and import the permission policies
 	gz.Route{
-		Name:        "ElasticSearch",
-		Description: "Route to create an ElasticSearch config",
-		URI:         "/admin/search",
+		Name:        "PermissionsPolicies",
+		Description: "Route to export and import the permission policies",
+		URI:         "/admin/permissions/policies",
 		Headers:     gz.AuthHeadersOptional,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /admin/search search elasticSearchUpdate
+			// swagger:route GET /admin/permissions/policies permissions permissionsExport
 			//
-			// Get a list of the available ElasticSearch configurations.
+			// Exports the permission policies.
 			//
-			// Zero or more ElasticSearch configurations may be specified. The
-			// configuration marked as `primary` is the active ElasticSearch server.
+			// Returns the full set of permission policies as JSON, except for the
+			// system admins.
 			//
 			//   Parameters:
 			//   + name: Private-Token
@@ -2392,21 +5942,21 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: ElasticSearchConfigs
+			//     200: PolicySet
 			gz.Method{
 				Type:        "GET",
-				Description: "Gets a list of the ElasticSearch configs",
+				Description: "Export the permission policies",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(ListElasticSearchHandler)},
+					gz.FormatHandler{Handler: gz.JSONResult(CasbinPolicyExport)},
 				},
 			},
-
-			// swagger:route POST /admin/search search elasticSearchUpdate
+			// swagger:route POST /admin/permissions/policies permissions permissionsImport
 			//
-			// Creates an ElasticSearch server configuration.
+			// Imports permission policies.
 			//
-			// Use this route to tell Fuel about a new ElasticSearch server.
+			// Adds the given policies. With the 'replace' query parameter, the
+			// existing policies are removed first.
 			//
 			//   Parameters:
 			//   + name: Private-Token
@@ -2414,27 +5964,9 @@ var routes = gz.Routes{
 			//     in: header
 			//     required: true
 			//     type: string
-			//   + name: address
-			//     description: URL address of an Elastic Search server.
-			//     in: body
-			//     required: true
-			//     type: string
-			//   + name: primary
-			//     description: "true" to make this configuration the primary config.
-			//     in: body
-			//     required: false
-			//     type: string
-			//     default: false
-			//   + name: username
-			//     description: Username for ElasticSearch authentication
-			//     in: body
-			//     required: false
-			//     type: string
-			//   + name: password
-			//     description: Password for ElasticSearch authentication
-			//     in: body
-			//     required: false
-			//     type: string
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -2443,28 +5975,32 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: ElasticSearchConfig
+			//     200: PolicySet
 			gz.Method{
 				Type:        "POST",
-				Description: "Creates an ElasticSearch config",
+				Description: "Import permission policies",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(CreateElasticSearchHandler)},
+					gz.FormatHandler{Handler: gz.JSONResult(CasbinPolicyImport)},
 				},
 			},
 		},
 	},
-	// Route to reconnect to the primary elastic search config
+	// Route to query the audit log
 	gz.Route{
-		Name:        "ElasticSearch",
-		Description: "Route to reconnect to the primary elastic search config",
-		URI:         "/admin/search/reconnect",
-		Headers:     gz.AuthHeadersOptional,
+		Name:        "Audit",
+		Description: "Route to query the audit log",
+		URI:         "/admin/audit",
+		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /admin/search/reconnect search elasticSearchUpdate
+			// swagger:route GET /admin/audit audit auditList
 			//
-			// Reconnects to the primary ElasticSearch server.
+			// Get the audit log
+			//
+			// Return the list of mutating operations, newest first. Only system
+			// admins can see the audit log. The list can be filtered with the
+			// actor, action, owner, target, since and until query parameters.
 			//
 			//   Parameters:
 			//   + name: Private-Token
@@ -2480,38 +6016,35 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: AdminSearchResponse
+			//     200: AuditEntries
 			gz.Method{
 				Type:        "GET",
-				Description: "Reconnect to the primary ElasticSearch config",
+				Description: "Get the audit log",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(ReconnectElasticSearchHandler)},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(AuditList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(AuditList, true))},
 				},
 			},
 		},
 	},
-	// Route to rebuild to the primary elastic search indices
+	// Route to get the moderation queue
 	gz.Route{
-		Name:        "ElasticSearch",
-		Description: "Route to rebuild to the primary elastic search indices",
-		URI:         "/admin/search/rebuild",
-		Headers:     gz.AuthHeadersOptional,
+		Name:        "ModerationCases",
+		Description: "Route to get the moderation queue",
+		URI:         "/admin/moderation/cases",
+		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /admin/search/rebuild search elasticSearchUpdate
-			//
-			// Rebuilds the primary ElasticSearch indices.
+			// swagger:route GET /admin/moderation/cases moderation moderationCaseList
 			//
-			// Rebuilding the indices may take several minutes. Use this route when
-			// or if the ElasticSearch indices have become out of date.
+			// Get the moderation queue
 			//
-			//   Parameters:
-			//   + name: Private-Token
-			//     description: A personal access token.
-			//     in: header
-			//     required: true
-			//     type: string
+			// Return the moderation cases, which group the reports against each
+			// model, world, collection, user or comment. The 'status' parameter
+			// can be open (default), dismissed or actioned. The most reported
+			// cases come first. Use the 'type' parameter to get the cases of a
+			// resource type. Only system admins can moderate.
 			//
 			//   Produces:
 			//   - application/json
@@ -2520,39 +6053,32 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: AdminSearchResponse
+			//     200: ModerationCases
 			gz.Method{
 				Type:        "GET",
-				Description: "Rebuild the primary ElasticSearch indices",
+				Description: "Get the moderation queue",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(RebuildElasticSearchHandler)},
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(ModerationCaseList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(ModerationCaseList, true))},
 				},
 			},
 		},
 	},
-	// Route to update to the primary elastic search indices
+	// Route to review a moderation case
 	gz.Route{
-		Name:        "ElasticSearch",
-		Description: "Route to update to the primary elastic search indices",
-		URI:         "/admin/search/update",
-		Headers:     gz.AuthHeadersOptional,
+		Name:        "ModerationCase",
+		Description: "Route to review a moderation case",
+		URI:         "/admin/moderation/cases/{id}",
+		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route GET /admin/search/update search elasticSearchUpdate
-			//
-			// Updates the primary ElasticSearch servers indices.
+			// swagger:route GET /admin/moderation/cases/{id} moderation moderationCaseIndex
 			//
-			// This route will populate the primary ElasticSearch server with new
-			// data contained in the Fuel database. This route may take several
-			// minutes to complete.
+			// Get a moderation case
 			//
-			//   Parameters:
-			//   + name: Private-Token
-			//     description: A personal access token.
-			//     in: header
-			//     required: true
-			//     type: string
+			// Return a moderation case with its reports. Only system admins can
+			// moderate.
 			//
 			//   Produces:
 			//   - application/json
@@ -2561,42 +6087,91 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: AdminSearchResponse
+			//     200: ModerationCase
 			gz.Method{
 				Type:        "GET",
-				Description: "Update the primary ElasticSearch indices",
+				Description: "Get a moderation case",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(UpdateElasticSearchHandler)},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("id", true, ModerationCaseIndex))},
+				},
+			},
+			// swagger:route PATCH /admin/moderation/cases/{id} moderation moderationCaseUpdate
+			//
+			// Resolve a moderation case
+			//
+			// Dismiss an open case, or action it by hiding the resource, making
+			// it private or suspending its owner. Resources hidden automatically
+			// after repeated reports are shown again, unless the moderator hides
+			// them. The moderator notes can be changed at any time. The
+			// reporters and, for actioned cases, the owner are notified.
+			//
+			//   Consumes:
+			//   - application/json
+			//
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ModerationCase
+			gz.Method{
+				Type:        "PATCH",
+				Description: "Resolve a moderation case",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("id", true, ModerationCaseUpdate))},
 				},
 			},
 		},
 	},
-	// Route to manage an elastic search config
+
+	// Route to get the suspension history of a user, and to suspend them
 	gz.Route{
-		Name:        "ElasticSearch",
-		Description: "Route to manage an ElasticSearch config",
-		URI:         "/admin/search/{config_id}",
-		Headers:     gz.AuthHeadersOptional,
+		Name:        "UserSuspensions",
+		Description: "Route to get the suspension history of a user, and to suspend them",
+		URI:         "/admin/users/{username}/suspensions",
+		Headers:     gz.AuthHeadersRequired,
 		Methods:     gz.Methods{},
 		SecureMethods: gz.SecureMethods{
-			// swagger:route DELETE /admin/search/{config_id} search elasticSearchUpdate
+			// swagger:route GET /admin/users/{username}/suspensions users userSuspensionList
 			//
-			// Deletes an ElasticSearch server configuration.
+			// Get the suspension history of a user
 			//
-			// Use this route to remove and ElasticSearch configuration.
+			// Return the suspensions and reinstatements of a user, newest first,
+			// with their reasons and the system admins that performed them. Only
+			// system admins can suspend users.
 			//
-			//   Parameters:
-			//   + name: Private-Token
-			//     description: A personal access token.
-			//     in: header
-			//     required: true
-			//     type: string
-			//   + name: config_id
-			//     description: ID of the ElasticSearch configuration.
-			//     in: path
-			//     required: true
-			//     type: integer
+			//   Produces:
+			//   - application/json
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: SuspensionEntries
+			gz.Method{
+				Type:        "GET",
+				Description: "Get the suspension history of a user",
+				// Format handlers
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Extension: ".json", Handler: gz.JSONResult(PaginationHandlerWithUser(UserSuspensionList, true))},
+					gz.FormatHandler{Handler: gz.JSONResult(PaginationHandlerWithUser(UserSuspensionList, true))},
+				},
+			},
+			// swagger:route POST /admin/users/{username}/suspensions users userSuspend
+			//
+			// Suspend a user
+			//
+			// Suspend a user with a reason and an optional expiry. Suspended users
+			// cannot authenticate, neither with a JWT nor with an access token.
+			// Their resources are kept intact, but hidden from lists and search
+			// results until they are reinstated or the suspension expires.
+			//
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -2605,54 +6180,34 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: ElasticSearchConfig
+			//     200: SuspensionEntry
 			gz.Method{
-				Type:        "DELETE",
-				Description: "Deletes an ElasticSearch config",
+				Type:        "POST",
+				Description: "Suspend a user",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(DeleteElasticSearchHandler)},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, UserSuspend))},
 				},
 			},
-			// swagger:route PATCH /admin/search/{config_id} search elasticSearchUpdate
+		},
+	},
+	// Route to reinstate a suspended user
+	gz.Route{
+		Name:        "UserReinstatement",
+		Description: "Route to reinstate a suspended user",
+		URI:         "/admin/users/{username}/reinstatement",
+		Headers:     gz.AuthHeadersRequired,
+		Methods:     gz.Methods{},
+		SecureMethods: gz.SecureMethods{
+			// swagger:route POST /admin/users/{username}/reinstatement users userReinstate
 			//
-			// Updates an ElasticSearch server configuration.
+			// Reinstate a suspended user
 			//
-			// Set the username, password, address, and primary status of an
-			// ElasticSearch server configuration.
+			// Lift the suspension of a user, with a reason. The user can
+			// authenticate again and their resources are listed again.
 			//
-			//   Parameters:
-			//   + name: Private-Token
-			//     description: A personal access token.
-			//     in: header
-			//     required: true
-			//     type: string
-			//   + name: config_id
-			//     description: ID of the ElasticSearch configuration.
-			//     in: path
-			//     required: true
-			//     type: integer
-			//   + name: address
-			//     description: URL address of an Elastic Search server.
-			//     in: body
-			//     required: true
-			//     type: string
-			//   + name: primary
-			//     description: "true" to make this configuration the primary config.
-			//     in: body
-			//     required: false
-			//     type: string
-			//     default: false
-			//   + name: username
-			//     description: Username for ElasticSearch authentication
-			//     in: body
-			//     required: false
-			//     type: string
-			//   + name: password
-			//     description: Password for ElasticSearch authentication
-			//     in: body
-			//     required: false
-			//     type: string
+			//   Consumes:
+			//   - application/json
 			//
 			//   Produces:
 			//   - application/json
@@ -2661,13 +6216,13 @@ var routes = gz.Routes{
 			//
 			//   Responses:
 			//     default: fuelError
-			//     200: ElasticSearchConfig
+			//     200: SuspensionEntry
 			gz.Method{
-				Type:        "PATCH",
-				Description: "Modify an ElasticSearch config",
+				Type:        "POST",
+				Description: "Reinstate a suspended user",
 				// Format handlers
 				Handlers: gz.FormatHandlers{
-					gz.FormatHandler{Handler: gz.JSONResult(ModifyElasticSearchHandler)},
+					gz.FormatHandler{Handler: gz.JSONResult(NameHandler("username", true, UserReinstate))},
 				},
 			},
 		},
@@ -2783,4 +6338,46 @@ var routes = gz.Routes{
 			},
 		},
 	},
+
+	////////////
+	// Events //
+	////////////
+
+	// Route that streams the lifecycle events of models, worlds and collections
+	gz.Route{
+		Name:        "Events",
+		Description: "Server-Sent Events stream of the changes of models, worlds and collections",
+		URI:         "/events",
+		Headers:     gz.AuthHeadersOptional,
+		Methods: gz.Methods{
+			// swagger:route GET /events events eventStream
+			//
+			// Stream resource events
+			//
+			// Stream the creation, update, deletion, transfer, like, unlike,
+			// report and review events of models, worlds and collections as
+			// Server-Sent Events. The stream can be filtered with the owner,
+			// type (model, world or collection) and name query parameters.
+			// Events of private resources are only sent to the users that can
+			// read them. Reconnecting clients can send the Last-Event-ID header
+			// to get the events they missed.
+			//
+			//   Produces:
+			//   - text/event-stream
+			//
+			//   Schemes: https
+			//
+			//   Responses:
+			//     default: fuelError
+			//     200: ResourceEvents
+			gz.Method{
+				Type:        "GET",
+				Description: "Stream resource events",
+				Handlers: gz.FormatHandlers{
+					gz.FormatHandler{Handler: gz.Handler(EventStream)},
+				},
+			},
+		},
+		SecureMethods: gz.SecureMethods{},
+	},
 } // routes
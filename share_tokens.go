@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// ResourceShareTokens returns a handler that lists the share tokens of a model
+// or world. The returned value will be of type "commonres.ShareTokens".
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/models/{model-name}/share-tokens
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ResourceShareTokens(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		resource, em := getSharedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		return commonres.GetShareTokens(tx, resource)
+	}
+}
+
+// ResourceShareTokenCreate returns a handler that creates a read-only share
+// token for a model or world. The token can be used in the 'share_token' query
+// parameter to download the zip, files and file tree of the resource, without
+// a Fuel account. The returned value will be of type
+// "commonres.ShareTokenCreateResponse".
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/models/{model-name}/share-tokens
+//	  -d '{"name":"optional description", "expires_at":"2030-01-01T00:00:00Z"}'
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ResourceShareTokenCreate(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		var cst commonres.CreateShareToken
+		if em := ParseStruct(&cst, r, false); em != nil {
+			return nil, em
+		}
+
+		resource, em := getSharedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		token, em := commonres.NewShareToken(r.Context(), tx, resource, &cst, user)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+		return token, nil
+	}
+}
+
+// ResourceShareTokenRevoke returns a handler that revokes a share token of a
+// model or world. The token is given with the 'prefix' query parameter. The
+// returned value will be of type "commonres.ShareToken".
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/models/{model-name}/share-tokens?prefix={prefix}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func ResourceShareTokenRevoke(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		prefix := r.URL.Query().Get("prefix")
+		if prefix == "" {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorMissingField, nil, []string{"prefix"})
+		}
+
+		resource, em := getSharedResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		token, em := commonres.RevokeShareToken(r.Context(), tx, resource, prefix)
+		if em != nil {
+			return nil, em
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+		}
+		return token, nil
+	}
+}
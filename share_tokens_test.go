@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/gz-go/v7"
+	gztest "github.com/gazebo-web/gz-go/v7/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestModelShareTokens tests downloading a private model with a share token.
+func TestModelShareTokens(t *testing.T) {
+	// General test setup.
+	setup()
+	testUser := createUser(t)
+	defer removeUser(testUser, t)
+	jwt := os.Getenv("IGN_TEST_JWT")
+	createTestModelWithOwner(t, &jwt, "private_model", testUser, true)
+
+	uri := modelURL(testUser, "private_model", "") + "/share-tokens"
+	zipURI := modelURL(testUser, "private_model", "1") + ".zip"
+	treeURI := modelURL(testUser, "private_model", "") + "/1/files"
+
+	// Tokens must expire in the future
+	b := new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.CreateShareToken{ExpiresAt: time.Now().Add(-time.Hour)}))
+	expEm := gz.NewErrorMessage(gz.ErrorFormInvalidValue)
+	bslice, _ := gztest.AssertRouteMultipleArgs("POST", uri, b, expEm.StatusCode, &jwt, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, expEm.ErrCode, t)
+
+	b = new(bytes.Buffer)
+	require.NoError(t, json.NewEncoder(b).Encode(commonres.CreateShareToken{Name: "reviewers",
+		ExpiresAt: time.Now().Add(time.Hour)}))
+	bslice, _ = gztest.AssertRouteMultipleArgs("POST", uri, b, http.StatusOK, &jwt, ctJSON, t)
+	var created commonres.ShareTokenCreateResponse
+	require.NoError(t, json.Unmarshal(*bslice, &created))
+	assert.Equal(t, "read", created.Scope)
+	require.NotEmpty(t, created.Token)
+
+	var tokens commonres.ShareTokens
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", uri, nil, http.StatusOK, &jwt, ctJSON, t)
+	require.NoError(t, json.Unmarshal(*bslice, &tokens))
+	require.Len(t, tokens, 1)
+	assert.Equal(t, created.Prefix, tokens[0].Prefix)
+
+	// Anonymous users can download the private model only with the token
+	unauth := gz.NewErrorMessage(gz.ErrorUnauthorized)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", zipURI, nil, unauth.StatusCode, nil, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, unauth.ErrCode, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", zipURI+"?share_token=invalid", nil, unauth.StatusCode, nil, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, unauth.ErrCode, t)
+	gztest.AssertRouteMultipleArgs("GET", zipURI+"?share_token="+created.Token, nil, http.StatusOK, nil, "application/zip", t)
+	gztest.AssertRouteMultipleArgs("GET", treeURI+"?share_token="+created.Token, nil, http.StatusOK, nil, ctJSON, t)
+
+	// Revoked tokens no longer work
+	gztest.AssertRouteMultipleArgs("DELETE", uri+"?prefix="+created.Prefix, nil, http.StatusOK, &jwt, ctJSON, t)
+	bslice, _ = gztest.AssertRouteMultipleArgs("GET", zipURI+"?share_token="+created.Token, nil, unauth.StatusCode, nil, ctTextPlain, t)
+	gztest.AssertBackendErrorCode(t.Name(), bslice, unauth.ErrCode, t)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// UserSuspensionList returns the suspension history of a user: a paginated
+// list of their suspensions and reinstatements, newest first. Only system
+// admins can suspend users.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/admin/users/{username}/suspensions
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func UserSuspensionList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	suspended, em := users.ByUsername(tx, mux.Vars(r)["username"], false)
+	if em != nil {
+		return nil, nil, em
+	}
+	return users.SuspensionHistory(p, tx, suspended)
+}
+
+// UserSuspend suspends a user, with a reason and an optional expiry. Suspended
+// users cannot authenticate, and their resources are hidden from lists and
+// search results until they are reinstated or the suspension expires. Their
+// resources are kept intact. Only system admins can suspend users.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/admin/users/{username}/suspensions
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	  -d '{"reason":"Spam", "expires_at":"2030-01-01T00:00:00Z"}'
+func UserSuspend(username string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	if username == *user.Username {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil,
+			[]string{"System admins cannot suspend themselves"})
+	}
+	suspended, em := users.ByUsername(tx, username, false)
+	if em != nil {
+		return nil, em
+	}
+	var cs users.CreateSuspension
+	if em := ParseStruct(&cs, r, false); em != nil {
+		return nil, em
+	}
+	if cs.ExpiresAt != nil && !cs.ExpiresAt.After(time.Now()) {
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil, []string{"expires_at"})
+	}
+	entry, em := users.Suspend(tx, suspended, *user.Username, cs.Reason, cs.ExpiresAt)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	if err := globals.QueryCache.DeleteAll(); err != nil {
+		gz.LoggerFromContext(r.Context()).Error("Failed to clear the memory cache.")
+	}
+	return entry, nil
+}
+
+// UserReinstate lifts the suspension of a user. The user can authenticate
+// again, and their resources are listed again. Only system admins can
+// reinstate users.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/admin/users/{username}/reinstatement
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	  -d '{"reason":"Appeal accepted"}'
+func UserReinstate(username string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if !globals.Permissions.IsSystemAdmin(*user.Username) {
+		return nil, gz.NewErrorMessage(gz.ErrorUnauthorized)
+	}
+	suspended, em := users.ByUsername(tx, username, false)
+	if em != nil {
+		return nil, em
+	}
+	var ru users.ReinstateUser
+	if em := ParseStruct(&ru, r, false); em != nil {
+		return nil, em
+	}
+	entry, em := users.Reinstate(tx, suspended, *user.Username, ru.Reason)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	if err := globals.QueryCache.DeleteAll(); err != nil {
+		gz.LoggerFromContext(r.Context()).Error("Failed to clear the memory cache.")
+	}
+	return entry, nil
+}
+
+// suspensionExpiryInterval is the time between two checks for expired
+// suspensions.
+const suspensionExpiryInterval = time.Minute
+
+// clearCacheOnExpiredSuspensions clears the memory cache if a suspension
+// expired after since, as the cached lists leave out the resources of suspended
+// users. It returns the time of the check, to be used as since in the next one.
+func clearCacheOnExpiredSuspensions(ctx context.Context, db *gorm.DB,
+	since time.Time) (time.Time, *gz.ErrMsg) {
+
+	now := time.Now()
+	count, em := users.CountExpiredSuspensions(db, since, now)
+	if em != nil {
+		return since, em
+	}
+	if count > 0 {
+		if err := globals.QueryCache.DeleteAll(); err != nil {
+			gz.LoggerFromContext(ctx).Error("Failed to clear the memory cache.")
+		}
+	}
+	return now, nil
+}
+
+// runSuspensionExpiryJob periodically clears the memory cache when
+// suspensions expire, until the given context is done. It is expected to be
+// run in its own goroutine.
+func runSuspensionExpiryJob(ctx context.Context, db *gorm.DB) {
+	ticker := time.NewTicker(suspensionExpiryInterval)
+	defer ticker.Stop()
+	since := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var em *gz.ErrMsg
+		if since, em = clearCacheOnExpiredSuspensions(ctx, db, since); em != nil {
+			gz.LoggerFromContext(ctx).Error("Failed to check the expired suspensions: ", em.LogString())
+		}
+	}
+}
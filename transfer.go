@@ -2,14 +2,23 @@ package main
 
 import (
 	"fmt"
+	"github.com/gazebo-web/fuel-server/bundles/audit"
+	"github.com/gazebo-web/fuel-server/bundles/collections"
 	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/generics"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/notifications"
 	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
 	"github.com/gazebo-web/fuel-server/globals"
 	"github.com/gazebo-web/fuel-server/permissions"
 	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gorilla/mux"
 	"github.com/jinzhu/gorm"
 
 	"net/http"
+	"time"
 )
 
 // TransferAsset encapsulates data required to transfer an asset, such as a
@@ -36,54 +45,469 @@ func processTransferRequest(sourceOwner string, tx *gorm.DB, r *http.Request) (*
 		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, em.BaseError, []string{extra})
 	}
 
-	// Step 2: check write permissions of the requesting user
+	// Step 2: check the requesting user can write the resources of the
+	// organization
 	if ok, em := globals.Permissions.IsAuthorized(sourceOwner,
 		transferAsset.DestOwner, permissions.Write); !ok {
 		extra := fmt.Sprintf("User [%s] is not authorized", sourceOwner)
 		return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, em.BaseError, []string{extra})
 	}
 
+	audit.SetBefore(r.Context(), map[string]string{"owner": sourceOwner})
 	return &transferAsset, nil
 }
 
+// transferredResource is a resource moved by transferMoveResource. If the
+// transfer cannot be committed, revertTransfers moves its files back and
+// restores its permissions.
+type transferredResource struct {
+	moved *commonres.MovedResource
+	uuid  string
+	// The permissions added and removed by the transfer
+	changes []permissionChange
+}
+
+// permissionChange is a permission of a resource added or removed by a
+// transfer.
+type permissionChange struct {
+	subject string
+	action  permissions.Action
+	added   bool
+}
+
 // transferMoveResource will move an resource, such as a model, world, or collection,
-// from a user to an organization.
-func transferMoveResource(tx *gorm.DB, resource commonres.Resource, sourceOwner, destOwner string) *gz.ErrMsg {
+// from a user to an organization. The files and the permissions of the resource
+// are moved right away: call revertTransfers with the returned
+// transferredResource if the DB transaction cannot be committed.
+func transferMoveResource(tx *gorm.DB, resource commonres.Resource, sourceOwner,
+	destOwner string) (*transferredResource, *gz.ErrMsg) {
 
 	// Attempt to move the resource
-	if em := commonres.MoveResource(resource, destOwner); em != nil {
-		return em
+	moved, em := commonres.MoveResource(resource, destOwner)
+	if em != nil {
+		return nil, em
 	}
-
-	// Add permissions to destination owner
-	_, err := globals.Permissions.AddPermission(destOwner, *resource.GetUUID(), permissions.Read)
-	if err != nil {
+	transferred := &transferredResource{moved: moved, uuid: *resource.GetUUID()}
+	transferred.changes, em = moveOwnerPermissions(transferred.uuid, sourceOwner, destOwner)
+	if em == nil {
+		em = publishTransfer(tx, resource, sourceOwner, destOwner)
+	}
+	if em != nil {
 		// Revert move
-		commonres.MoveResource(resource, sourceOwner)
-		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		transferred.revert()
+		return nil, em
 	}
+	return transferred, nil
+}
 
-	_, err = globals.Permissions.AddPermission(destOwner, *resource.GetUUID(), permissions.Write)
-	if err != nil {
-		// Revert move
-		commonres.MoveResource(resource, sourceOwner)
-		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+// moveOwnerPermissions gives the read and write permissions of a resource to
+// destOwner, and removes the ones of sourceOwner. The changes made are returned
+// even on error, so they can be reverted.
+func moveOwnerPermissions(uuid, sourceOwner, destOwner string) ([]permissionChange, *gz.ErrMsg) {
+	var changes []permissionChange
+	actions := []permissions.Action{permissions.Read, permissions.Write}
+
+	// Add permissions to destination owner
+	for _, action := range actions {
+		added, err := globals.Permissions.AddPermission(destOwner, uuid, action)
+		if err != nil {
+			return changes, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+		if added {
+			changes = append(changes, permissionChange{subject: destOwner, action: action, added: true})
+		}
 	}
 
 	// Remove permissions from original owner
-	_, err = globals.Permissions.RemovePermission(sourceOwner, *resource.GetUUID(), permissions.Read)
-	if err != nil {
-		// Revert move
-		commonres.MoveResource(resource, sourceOwner)
-		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+	for _, action := range actions {
+		removed, err := globals.Permissions.RemovePermission(sourceOwner, uuid, action)
+		if err != nil {
+			return changes, gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+		if removed {
+			changes = append(changes, permissionChange{subject: sourceOwner, action: action})
+		}
 	}
+	return changes, nil
+}
 
-	_, err = globals.Permissions.RemovePermission(sourceOwner, *resource.GetUUID(), permissions.Write)
-	if err != nil {
-		// Revert move
-		commonres.MoveResource(resource, sourceOwner)
-		return gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+// revert moves the files of a transferred resource back, and undoes the
+// changes to its permissions. It returns the first error found, but reverts as
+// much as it can.
+func (t *transferredResource) revert() *gz.ErrMsg {
+	em := t.moved.Revert()
+	for i := len(t.changes) - 1; i >= 0; i-- {
+		c := t.changes[i]
+		var err error
+		if c.added {
+			_, err = globals.Permissions.RemovePermission(c.subject, t.uuid, c.action)
+		} else {
+			_, err = globals.Permissions.AddPermission(c.subject, t.uuid, c.action)
+		}
+		if err != nil && em == nil {
+			em = gz.NewErrorMessageWithBase(gz.ErrorUnexpected, err)
+		}
+	}
+	return em
+}
+
+// publishTransfer tells the watchers and webhooks of both owners about the
+// transfer of a resource.
+func publishTransfer(tx *gorm.DB, resource commonres.Resource, sourceOwner,
+	destOwner string) *gz.ErrMsg {
+
+	// The event is published for the destination owner. The webhooks of the
+	// source owner are also told about the transfer.
+	resType := resourceTypeOf(resource)
+	details := map[string]interface{}{"source_owner": sourceOwner, "dest_owner": destOwner}
+	if em := publishEvent(tx, webhooks.EventTransfer, "", resource, details); em != nil {
+		return em
+	}
+	if em := webhooks.Enqueue(tx, sourceOwner, webhooks.Payload{
+		Event:        resType + "." + webhooks.EventTransfer,
+		ResourceType: resType,
+		Owner:        destOwner,
+		Name:         *resource.GetName(),
+		Details:      details,
+	}); em != nil {
+		return em
 	}
 
+	message := fmt.Sprintf("The %s %s was transferred from %s to %s.", resType,
+		*resource.GetName(), sourceOwner, destOwner)
+	if em := notifyWatchers(tx, notifications.EventTransfer, "", resource, message); em != nil {
+		return em
+	}
 	return nil
 }
+
+// revertTransfers moves the files and the permissions of the transferred
+// resources back, when the transfer could not be committed.
+func revertTransfers(r *http.Request, transferred ...*transferredResource) {
+	for _, t := range transferred {
+		if em := t.revert(); em != nil {
+			gz.LoggerFromRequest(r).Error("Unable to revert the transfer of a resource: ", em.LogString())
+		}
+	}
+}
+
+// getResourceByUUID returns the model, world or collection with the given
+// UUID.
+func getResourceByUUID(tx *gorm.DB, resType, uuid string) (commonres.Resource, *gz.ErrMsg) {
+	var resource commonres.Resource
+	switch resType {
+	case "model":
+		resource = &models.Model{}
+	case "world":
+		resource = &worlds.World{}
+	case "collection":
+		resource = &collections.Collection{}
+	default:
+		return nil, gz.NewErrorMessage(gz.ErrorUnexpected)
+	}
+	if tx.Where("uuid = ?", uuid).First(resource).RecordNotFound() {
+		extra := fmt.Sprintf("The %s of the transfer request no longer exists", resType)
+		return nil, gz.NewErrorMessageWithArgs(gz.ErrorNameNotFound, nil, []string{extra})
+	}
+	return resource, nil
+}
+
+// checkTransferName returns an error if the destination owner already has a
+// resource of the same type and name.
+func checkTransferName(tx *gorm.DB, resType, destOwner, name string) *gz.ErrMsg {
+	var resource interface{}
+	switch resType {
+	case "model":
+		resource = &models.Model{}
+	case "world":
+		resource = &worlds.World{}
+	case "collection":
+		resource = &collections.Collection{}
+	default:
+		return gz.NewErrorMessage(gz.ErrorUnexpected)
+	}
+	var count int
+	if err := tx.Model(resource).Where("owner = ? AND name = ?", destOwner, name).
+		Count(&count).Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+	if count > 0 {
+		extra := fmt.Sprintf("[%s] already has a %s named [%s]", destOwner, resType, name)
+		return gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil, []string{extra})
+	}
+	return nil
+}
+
+// ownerEmail returns the email of a user or organization, or an empty string
+// if it has none.
+func ownerEmail(tx *gorm.DB, owner string) string {
+	if org, em := users.ByOrganizationName(tx, owner, false); em == nil {
+		if org.Email != nil {
+			return *org.Email
+		}
+		return ""
+	}
+	if user, em := users.ByUsername(tx, owner, false); em == nil && user.Email != nil {
+		return *user.Email
+	}
+	return ""
+}
+
+// notifyTransfer emails both the source and the destination owners about a
+// change in a transfer request. It is expected to be called once the change
+// was committed.
+func notifyTransfer(tr *commonres.TransferRequest, actor string, r *http.Request) {
+	verb := tr.Status
+	if tr.Status == commonres.TransferPending {
+		verb = "requested"
+	}
+	subject := fmt.Sprintf("Transfer of %s %s/%s %s", tr.ResourceType, tr.SourceOwner,
+		tr.ResourceName, verb)
+	message := fmt.Sprintf("The transfer of the %s [%s] from [%s] to [%s] was %s by [%s].",
+		tr.ResourceType, tr.ResourceName, tr.SourceOwner, tr.DestOwner, verb, actor)
+	for _, owner := range []string{tr.SourceOwner, tr.DestOwner} {
+		recipient := ownerEmail(globals.Server.Db, owner)
+		if em := generics.SendTransferEmail(recipient, owner, subject, message, r); em != nil {
+			gz.LoggerFromRequest(r).Error("Unable to send transfer email: ", em.LogString())
+		}
+	}
+}
+
+// TransferRequestCreate returns a handler that proposes the transfer of a
+// model, world or collection to any user or organization. The resource is only
+// moved once the destination owner accepts the request. Both owners are
+// notified by email.
+// You can request this method with the following curl request:
+//
+//	curl -k -X POST -H "Content-Type: application/json" http://localhost:8000/1.0/{username}/models/{modelname}/transfer-requests --header "Private-Token: {private-token}" -d '{"destOwner":"destination_owner_name"}'
+func TransferRequestCreate(resType string) nameAndOwner {
+	return func(owner, name string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		var ctr commonres.CreateTransferRequest
+		if em := ParseStruct(&ctr, r, false); em != nil {
+			return nil, em
+		}
+
+		resource, em := getResource(resType, owner, name, user, tx)
+		if em != nil {
+			return nil, em
+		}
+		if ok, em := globals.Permissions.CanPerformAsOwner(*user.Username, owner, permissions.Transfer); !ok {
+			return nil, em
+		}
+
+		// The destination can be any user or organization.
+		if _, em := users.OwnerByName(tx, ctr.DestOwner, false); em != nil {
+			return nil, em
+		}
+		if ctr.DestOwner == owner {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+				[]string{"Source and destination owners are identical"})
+		}
+		if em := checkTransferName(tx, resType, ctr.DestOwner, name); em != nil {
+			return nil, em
+		}
+		var count int
+		if err := commonres.QueryForPendingTransfers(tx).Where("resource_uuid = ?",
+			*resource.GetUUID()).Count(&count).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+		}
+		if count > 0 {
+			return nil, gz.NewErrorMessageWithArgs(gz.ErrorResourceExists, nil,
+				[]string{"A pending transfer request already exists"})
+		}
+
+		days := ctr.ExpiresInDays
+		if days == 0 {
+			days = commonres.DefaultTransferExpiryDays
+		}
+		tr := commonres.TransferRequest{
+			ResourceType: resType,
+			ResourceUUID: *resource.GetUUID(),
+			ResourceName: name,
+			SourceOwner:  owner,
+			DestOwner:    ctr.DestOwner,
+			Requestor:    *user.Username,
+			Status:       commonres.TransferPending,
+			ExpiresAt:    time.Now().Add(time.Duration(days) * 24 * time.Hour),
+		}
+		if err := tx.Create(&tr).Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+
+		notifyTransfer(&tr, *user.Username, r)
+		response := commonres.NewTransferRequestResponse(&tr)
+		return &response, nil
+	}
+}
+
+// OwnerTransfersList returns a paginated list with the pending transfer
+// requests of an owner, both incoming and outgoing. The 'direction' query
+// parameter can be used to only get the 'incoming' or 'outgoing' requests.
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/transfers?direction=incoming
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func OwnerTransfersList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	owner := mux.Vars(r)["username"]
+	if ok, em := users.VerifyOwner(tx, owner, *user.Username, permissions.Read); !ok {
+		return nil, nil, em
+	}
+
+	q := commonres.QueryForPendingTransfers(tx)
+	switch r.URL.Query().Get("direction") {
+	case "incoming":
+		q = q.Where("dest_owner = ?", owner)
+	case "outgoing":
+		q = q.Where("source_owner = ?", owner)
+	case "":
+		q = q.Where("dest_owner = ? OR source_owner = ?", owner, owner)
+	default:
+		return nil, nil, gz.NewErrorMessageWithArgs(gz.ErrorFormInvalidValue, nil,
+			[]string{"direction"})
+	}
+
+	var requests []commonres.TransferRequest
+	pagination, err := gz.PaginateQuery(q.Order("created_at desc"), &requests, *p)
+	if err != nil {
+		return nil, nil, gz.NewErrorMessageWithBase(gz.ErrorInvalidPaginationRequest, err)
+	}
+	if !pagination.PageFound {
+		return nil, nil, gz.NewErrorMessage(gz.ErrorPaginationPageNotFound)
+	}
+	responses := make(commonres.TransferRequestResponses, 0, len(requests))
+	for i := range requests {
+		responses = append(responses, commonres.NewTransferRequestResponse(&requests[i]))
+	}
+	return &responses, pagination, nil
+}
+
+// getPendingTransfer returns the pending transfer request with the ID given in
+// the route, where column is the given owner. It also checks that the user can
+// act on behalf of the owner.
+func getPendingTransfer(tx *gorm.DB, r *http.Request, column, owner string,
+	user *users.User) (*commonres.TransferRequest, *gz.ErrMsg) {
+
+	id, em := getRouteID(r)
+	if em != nil {
+		return nil, em
+	}
+	if ok, em := globals.Permissions.CanPerformAsOwner(*user.Username, owner, permissions.Transfer); !ok {
+		return nil, em
+	}
+	var tr commonres.TransferRequest
+	if commonres.QueryForPendingTransfers(tx).Where("id = ? AND "+column+" = ?", id, owner).
+		First(&tr).RecordNotFound() {
+		return nil, gz.NewErrorMessage(gz.ErrorNonExistentResource)
+	}
+	return &tr, nil
+}
+
+// TransferRequestRespond returns a handler that accepts or rejects a pending
+// transfer request of the destination owner. On acceptance, the resource is
+// moved to the destination owner. Both owners are notified by email.
+// You can request these handlers with the following curl requests:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/transfers/{id}/accept
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	curl -k -X POST --url https://localhost:4430/1.0/{username}/transfers/{id}/reject
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func TransferRequestRespond(accept bool) nameFn {
+	return func(destOwner string, user *users.User, tx *gorm.DB,
+		w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+		tr, em := getPendingTransfer(tx, r, "dest_owner", destOwner, user)
+		if em != nil {
+			return nil, em
+		}
+
+		var moved *transferredResource
+		tr.Status = commonres.TransferRejected
+		if accept {
+			tr.Status = commonres.TransferAccepted
+			resource, em := getResourceByUUID(tx, tr.ResourceType, tr.ResourceUUID)
+			if em != nil {
+				return nil, em
+			}
+			// The resource could have been moved after the request was sent.
+			if *resource.GetOwner() != tr.SourceOwner {
+				extra := fmt.Sprintf("The %s no longer belongs to [%s]", tr.ResourceType, tr.SourceOwner)
+				return nil, gz.NewErrorMessageWithArgs(gz.ErrorUnauthorized, nil, []string{extra})
+			}
+			if em := checkTransferName(tx, tr.ResourceType, destOwner, *resource.GetName()); em != nil {
+				return nil, em
+			}
+			audit.SetBefore(r.Context(), map[string]string{"owner": tr.SourceOwner})
+			if moved, em = transferMoveResource(tx, resource, tr.SourceOwner, destOwner); em != nil {
+				return nil, em
+			}
+			if err := tx.Save(resource).Error; err != nil {
+				revertTransfers(r, moved)
+				return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+			}
+		}
+		if err := tx.Model(tr).Update("status", tr.Status).Error; err != nil {
+			if moved != nil {
+				revertTransfers(r, moved)
+			}
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+
+		// commit the DB transaction
+		// Note: we commit the TX here on purpose, to be able to detect DB errors
+		// before writing "data" to ResponseWriter. Once you write data (not headers)
+		// into it the status code is set to 200 (OK).
+		if err := tx.Commit().Error; err != nil {
+			if moved != nil {
+				revertTransfers(r, moved)
+			}
+			return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+		}
+
+		notifyTransfer(tr, *user.Username, r)
+		response := commonres.NewTransferRequestResponse(tr)
+		return &response, nil
+	}
+}
+
+// TransferRequestCancel cancels a pending transfer request of the source
+// owner. Both owners are notified by email.
+// You can request this method with the following curl request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/{username}/transfers/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func TransferRequestCancel(sourceOwner string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	tr, em := getPendingTransfer(tx, r, "source_owner", sourceOwner, user)
+	if em != nil {
+		return nil, em
+	}
+	tr.Status = commonres.TransferCancelled
+	if err := tx.Model(tr).Update("status", tr.Status).Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+
+	notifyTransfer(tr, *user.Username, r)
+	response := commonres.NewTransferRequestResponse(tr)
+	return &response, nil
+}
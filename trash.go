@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/collections"
+	commonres "github.com/gazebo-web/fuel-server/bundles/common_resources"
+	"github.com/gazebo-web/fuel-server/bundles/models"
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/worlds"
+	"github.com/gazebo-web/fuel-server/globals"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/jinzhu/gorm"
+)
+
+// trashPurgeInterval is how often the trash purge job runs.
+const trashPurgeInterval = 24 * time.Hour
+
+// OwnerTrash returns the list of soft-deleted models, worlds and collections of
+// an owner that can still be restored. The returned value will be of type
+// "commonres.TrashItems", sorted by deletion date (newest first).
+// You can request this method with the following curl request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/{username}/trash
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func OwnerTrash(owner string, user *users.User, tx *gorm.DB, w http.ResponseWriter,
+	r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	// Only the owner (or members of the owner organization with write access)
+	// can see the trash.
+	if ok, em := users.VerifyOwner(tx, owner, *user.Username, permissions.Write); !ok {
+		return nil, em
+	}
+
+	items, em := (&models.Service{Storage: globals.Storage}).ModelTrash(tx, owner)
+	if em != nil {
+		return nil, em
+	}
+	worldItems, em := (&worlds.Service{Storage: globals.Storage}).WorldTrash(tx, owner)
+	if em != nil {
+		return nil, em
+	}
+	colItems, em := (&collections.Service{}).CollectionTrash(tx, owner)
+	if em != nil {
+		return nil, em
+	}
+	items = append(items, worldItems...)
+	items = append(items, colItems...)
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// parseRestoreRequest reads the optional restore data from the request body.
+func parseRestoreRequest(r *http.Request) (*commonres.RestoreResource, *gz.ErrMsg) {
+	var rr commonres.RestoreResource
+	// The request body is optional.
+	if r.ContentLength == 0 {
+		return &rr, nil
+	}
+	if em := ParseStruct(&rr, r, false); em != nil {
+		return nil, em
+	}
+	return &rr, nil
+}
+
+// purgeExpiredTrash permanently removes the models, worlds and collections that
+// were soft-deleted before the trash retention window. Their files are removed
+// once the transaction is committed.
+func purgeExpiredTrash(ctx context.Context, db *gorm.DB) *gz.ErrMsg {
+	tx := db.Begin()
+	if err := tx.Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorNoDatabase, err)
+	}
+
+	purgedModels, em := (&models.Service{Storage: globals.Storage}).PurgeExpiredModels(ctx, tx)
+	if em != nil {
+		tx.Rollback()
+		return em
+	}
+	purgedWorlds, em := (&worlds.Service{Storage: globals.Storage}).PurgeExpiredWorlds(ctx, tx)
+	if em != nil {
+		tx.Rollback()
+		return em
+	}
+	purgedCollections, em := (&collections.Service{}).PurgeExpiredCollections(ctx, tx)
+	if em != nil {
+		tx.Rollback()
+		return em
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+
+	purged := append(append(purgedModels, purgedWorlds...), purgedCollections...)
+	for _, p := range purged {
+		p.RemoveFiles(ctx)
+	}
+	return nil
+}
+
+// runTrashPurgeJob periodically purges the expired trash, until the given
+// context is done. It is expected to be run in its own goroutine.
+func runTrashPurgeJob(ctx context.Context, db *gorm.DB) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		if em := purgeExpiredTrash(ctx, db); em != nil {
+			gz.LoggerFromContext(ctx).Error("Failed to purge expired trash: ", em.LogString())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
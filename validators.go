@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
 	"github.com/gazebo-web/gz-go/v7"
 	"gopkg.in/go-playground/validator.v9"
 	"log"
@@ -48,6 +49,10 @@ func InstallCustomValidators(validate *validator.Validate) {
 	if err != nil {
 		log.Fatalln("Failed to install custom validator:", err)
 	}
+	err = validate.RegisterValidation("webhookevent", isWebhookEvent)
+	if err != nil {
+		log.Fatalln("Failed to install custom validator:", err)
+	}
 }
 
 func loadBlacklist() {
@@ -98,6 +103,12 @@ func notIncludePercent(fl validator.FieldLevel) bool {
 	return !strings.Contains(fl.Field().String(), "%")
 }
 
+// isWebhookEvent is a function that validates the field value is an event
+// webhooks can subscribe to.
+func isWebhookEvent(fl validator.FieldLevel) bool {
+	return webhooks.IsEvent(fl.Field().String())
+}
+
 // isExpFeatures is a function that validates if the field's value is a comma
 // separated list of words, and that each word belongs to the
 // expFeatures whitelist.
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gazebo-web/fuel-server/bundles/users"
+	"github.com/gazebo-web/fuel-server/bundles/webhooks"
+	"github.com/gazebo-web/fuel-server/permissions"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// webhookDeliveryInterval is how often the due webhook deliveries are sent.
+const webhookDeliveryInterval = 30 * time.Second
+
+// webhookDeliveryBatch is the maximum number of deliveries sent on each run of
+// the delivery job.
+const webhookDeliveryBatch = 100
+
+// webhookOwner returns the user or organization from the route parameters of
+// the webhook routes.
+func webhookOwner(r *http.Request) string {
+	params := mux.Vars(r)
+	if name, ok := params["name"]; ok {
+		return name
+	}
+	return params["username"]
+}
+
+// checkWebhookAccess returns an error if the given user cannot manage the
+// webhooks of an owner. Users manage their own webhooks. Organization webhooks
+// are managed by the organization admins and owners.
+func checkWebhookAccess(tx *gorm.DB, owner string, user *users.User) *gz.ErrMsg {
+	if _, em := users.OwnerByName(tx, owner, false); em != nil {
+		return em
+	}
+	if ok, em := users.CanPerformWithRole(tx, owner, *user.Username, permissions.Admin); !ok {
+		if em == nil {
+			em = gz.NewErrorMessage(gz.ErrorUnauthorized)
+		}
+		return em
+	}
+	return nil
+}
+
+// WebhookList returns a paginated list of the webhooks of a user or
+// organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/organizations/{name}/webhooks
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WebhookList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	owner := webhookOwner(r)
+	if em := checkWebhookAccess(tx, owner, user); em != nil {
+		return nil, nil, em
+	}
+	return webhooks.List(p, tx, owner)
+}
+
+// WebhookCreate creates a webhook for a user or organization. The payloads are
+// signed with the given secret.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X POST --url https://localhost:4430/1.0/organizations/{name}/webhooks
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+//	  -d '{"url":"https://ci.example.com/hook", "secret":"a-long-secret-key", "events":["model.update"]}'
+func WebhookCreate(owner string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if em := checkWebhookAccess(tx, owner, user); em != nil {
+		return nil, em
+	}
+	var cw webhooks.CreateWebhook
+	if em := ParseStruct(&cw, r, false); em != nil {
+		return nil, em
+	}
+	wh, em := webhooks.Create(tx, owner, *user.Username, cw)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return wh, nil
+}
+
+// WebhookUpdate updates a webhook of a user or organization.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X PATCH --url https://localhost:4430/1.0/organizations/{name}/webhooks/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>' -d '{"active":false}'
+func WebhookUpdate(owner string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if em := checkWebhookAccess(tx, owner, user); em != nil {
+		return nil, em
+	}
+	id, em := getRouteID(r)
+	if em != nil {
+		return nil, em
+	}
+	var uw webhooks.UpdateWebhook
+	if em := ParseStruct(&uw, r, false); em != nil {
+		return nil, em
+	}
+	wh, em := webhooks.Update(tx, owner, id, uw)
+	if em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbSave, err)
+	}
+	return wh, nil
+}
+
+// WebhookRemove removes a webhook of a user or organization, and its delivery
+// log.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X DELETE --url https://localhost:4430/1.0/organizations/{name}/webhooks/{id}
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WebhookRemove(owner string, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.ErrMsg) {
+
+	if em := checkWebhookAccess(tx, owner, user); em != nil {
+		return nil, em
+	}
+	id, em := getRouteID(r)
+	if em != nil {
+		return nil, em
+	}
+	if em := webhooks.Remove(tx, owner, id); em != nil {
+		return nil, em
+	}
+
+	// commit the DB transaction
+	// Note: we commit the TX here on purpose, to be able to detect DB errors
+	// before writing "data" to ResponseWriter. Once you write data (not headers)
+	// into it the status code is set to 200 (OK).
+	if err := tx.Commit().Error; err != nil {
+		return nil, gz.NewErrorMessageWithBase(gz.ErrorDbDelete, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return nil, nil
+}
+
+// WebhookDeliveryList returns a paginated list of the deliveries of a webhook,
+// newest first.
+// You can request this method with the following cURL request:
+//
+//	curl -k -X GET --url https://localhost:4430/1.0/organizations/{name}/webhooks/{id}/deliveries
+//	  --header 'authorization: Bearer <your-jwt-token-here>'
+func WebhookDeliveryList(p *gz.PaginationRequest, user *users.User, tx *gorm.DB,
+	w http.ResponseWriter, r *http.Request) (interface{}, *gz.PaginationResult, *gz.ErrMsg) {
+
+	owner := webhookOwner(r)
+	if em := checkWebhookAccess(tx, owner, user); em != nil {
+		return nil, nil, em
+	}
+	id, em := getRouteID(r)
+	if em != nil {
+		return nil, nil, em
+	}
+	wh, em := webhooks.Get(tx, owner, id)
+	if em != nil {
+		return nil, nil, em
+	}
+	return webhooks.ListDeliveries(p, tx, wh.ID)
+}
+
+// sendWebhookDeliveries sends the webhook deliveries that are due.
+func sendWebhookDeliveries(ctx context.Context, db *gorm.DB) *gz.ErrMsg {
+	due, em := webhooks.DueDeliveries(db, webhookDeliveryBatch)
+	if em != nil {
+		return em
+	}
+	for i := range due {
+		if em := webhooks.Deliver(db, &due[i]); em != nil {
+			gz.LoggerFromContext(ctx).Error("Unable to deliver webhook payload: ", em.LogString())
+		}
+	}
+	return nil
+}
+
+// runWebhookDeliveryJob periodically sends the pending webhook deliveries,
+// including the retries, until the given context is done. It is expected to be
+// run in its own goroutine.
+func runWebhookDeliveryJob(ctx context.Context, db *gorm.DB) {
+	ticker := time.NewTicker(webhookDeliveryInterval)
+	defer ticker.Stop()
+	for {
+		if em := sendWebhookDeliveries(ctx, db); em != nil {
+			gz.LoggerFromContext(ctx).Error("Failed to send webhook deliveries: ", em.LogString())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}